@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/export"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+)
+
+// exportPageSize is how many notes or webs are loaded at once while the archive is written
+const exportPageSize = 100
+
+var errEmailRecentlyDeleted = errors.New("email belongs to a recently deleted account")
+
+// tombstoneEmailHash is how the email of a deleted user is kept, the address itself is gone with the user
+func tombstoneEmailHash(email string) string {
+	return util.HashSecretToken(strings.ToLower(email))
+}
+
+// abortIfEmailRecentlyDeleted responds 403 when the email belonged to a user deleted within the grace period.
+// It returns true when the request was aborted.
+func (server *Server) abortIfEmailRecentlyDeleted(ctx *gin.Context, email string) bool {
+	tombstone, err := server.store.GetUserTombstone(ctx, tombstoneEmailHash(email))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+
+	if time.Since(tombstone.DeletedAt) < server.config.AccountDeletionGracePeriod {
+		ctx.JSON(http.StatusForbidden, errorResponse(errEmailRecentlyDeleted))
+		return true
+	}
+	return false
+}
+
+// Streams a zip archive of everything the user has.
+// manifest.json describes the notes and webs, notes are in notes/ as markdown and webs in webs/ as the fetched html.
+// @Produce application/zip
+// @Success 200 {file} file
+// @Router /users/me/export [get]
+// @Tags user
+// @Security AccessToken
+func (server *Server) exportUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="inkclip-export-%s.zip"`, time.Now().UTC().Format("20060102")))
+	ctx.Status(http.StatusOK)
+
+	w := export.NewWriter(ctx.Writer, export.User{
+		ID:        user.ID,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+	})
+
+	// the status is already sent, a failure leaves the archive without its central directory so the client sees it broken
+	if err := server.exportNotes(ctx, w, user.ID); err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+	if err := server.exportWebs(ctx, w, user.ID); err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+	if err := w.Close(); err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
+func (server *Server) exportNotes(ctx *gin.Context, w *export.Writer, userID uuid.UUID) error {
+	afterID := uuid.Nil
+	for {
+		notes, err := server.store.ListNotesByUserIdAfterId(ctx, db.ListNotesByUserIdAfterIdParams{
+			UserID: userID,
+			ID:     afterID,
+			Limit:  exportPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(notes) == 0 {
+			return nil
+		}
+
+		noteIDs := make([]uuid.UUID, len(notes))
+		for i, note := range notes {
+			noteIDs[i] = note.ID
+		}
+
+		tags, err := server.store.ListTagsByNoteIds(ctx, noteIDs)
+		if err != nil {
+			return err
+		}
+		tagsByNote := make(map[uuid.UUID][]string)
+		for _, tag := range tags {
+			tagsByNote[tag.NoteID] = append(tagsByNote[tag.NoteID], tag.Name)
+		}
+
+		noteWebs, err := server.store.ListNoteWebsByNoteIds(ctx, noteIDs)
+		if err != nil {
+			return err
+		}
+		webIDsByNote := make(map[uuid.UUID][]uuid.UUID)
+		for _, noteWeb := range noteWebs {
+			webIDsByNote[noteWeb.NoteID] = append(webIDsByNote[noteWeb.NoteID], noteWeb.WebID)
+		}
+
+		for _, note := range notes {
+			err := w.AddNote(export.Note{
+				ID:        note.ID,
+				Title:     note.Title,
+				IsPublic:  note.IsPublic,
+				Version:   note.Version,
+				WebIDs:    webIDsByNote[note.ID],
+				Tags:      tagsByNote[note.ID],
+				CreatedAt: note.CreatedAt,
+				UpdatedAt: note.UpdatedAt,
+			}, note.Content)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(notes) < exportPageSize {
+			return nil
+		}
+		afterID = notes[len(notes)-1].ID
+	}
+}
+
+func (server *Server) exportWebs(ctx *gin.Context, w *export.Writer, userID uuid.UUID) error {
+	afterID := uuid.Nil
+	for {
+		webs, err := server.store.ListWebsByUserIdAfterId(ctx, db.ListWebsByUserIdAfterIdParams{
+			UserID: userID,
+			ID:     afterID,
+			Limit:  exportPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(webs) == 0 {
+			return nil
+		}
+
+		webIDs := make([]uuid.UUID, len(webs))
+		for i, web := range webs {
+			webIDs[i] = web.ID
+		}
+
+		tags, err := server.store.ListTagsByWebIds(ctx, webIDs)
+		if err != nil {
+			return err
+		}
+		tagsByWeb := make(map[uuid.UUID][]string)
+		for _, tag := range tags {
+			tagsByWeb[tag.WebID] = append(tagsByWeb[tag.WebID], tag.Name)
+		}
+
+		for _, web := range webs {
+			var publishedAt *time.Time
+			if web.PublishedAt.Valid {
+				publishedAt = &web.PublishedAt.Time
+			}
+
+			err := w.AddWeb(export.Web{
+				ID:           web.ID,
+				URL:          web.Url,
+				Title:        web.Title,
+				ThumbnailURL: web.ThumbnailUrl,
+				SiteName:     web.SiteName,
+				Author:       web.Author,
+				Description:  web.Description,
+				PublishedAt:  publishedAt,
+				Tags:         tagsByWeb[web.ID],
+				CreatedAt:    web.CreatedAt,
+			}, web.Html, web.ArticleMarkdown)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(webs) < exportPageSize {
+			return nil
+		}
+		afterID = webs[len(webs)-1].ID
+	}
+}
+
+type deleteUserRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// Deletes the user with every web, note, tag and session.
+// The email can't be registered again during ACCOUNT_DELETION_GRACE_PERIOD.
+// @Param request body api.deleteUserRequest true "query params"
+// @Success 200 {} {}
+// @Router /users/me [delete]
+// @Tags user
+// @Security AccessToken
+func (server *Server) deleteUser(ctx *gin.Context) {
+	var req deleteUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	// the profile goes with the user by cascade, its avatar is in the blob store
+	profile, err := server.store.GetProfile(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.TxDeleteUser(ctx, db.TxDeleteUserParams{
+		UserID:    user.ID,
+		EmailHash: tombstoneEmailHash(user.Email),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.deleteAvatarBlob(ctx, profile.AvatarKey)
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
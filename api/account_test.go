@@ -0,0 +1,387 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockblob "github.com/inkclip/backend/blob/mock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/export"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func requireExportManifest(t *testing.T, body *bytes.Buffer) (*zip.Reader, export.Manifest) {
+	r, err := zip.NewReader(bytes.NewReader(body.Bytes()), int64(body.Len()))
+	require.NoError(t, err)
+
+	f, err := r.Open(export.ManifestName)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var manifest export.Manifest
+	require.NoError(t, json.NewDecoder(f).Decode(&manifest))
+	return r, manifest
+}
+
+func TestExportUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	note := randomNote(t, user.ID)
+	web := randomWeb(t, user.ID)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListNotesByUserIdAfterId(gomock.Any(), gomock.Eq(db.ListNotesByUserIdAfterIdParams{
+						UserID: user.ID,
+						ID:     uuid.Nil,
+						Limit:  exportPageSize,
+					})).
+					Times(1).
+					Return([]db.Note{note}, nil)
+				store.EXPECT().
+					ListTagsByNoteIds(gomock.Any(), gomock.Eq([]uuid.UUID{note.ID})).
+					Times(1).
+					Return([]db.ListTagsByNoteIdsRow{{Name: "go", NoteID: note.ID}}, nil)
+				store.EXPECT().
+					ListNoteWebsByNoteIds(gomock.Any(), gomock.Eq([]uuid.UUID{note.ID})).
+					Times(1).
+					Return([]db.NoteWeb{{NoteID: note.ID, WebID: web.ID}}, nil)
+				store.EXPECT().
+					ListWebsByUserIdAfterId(gomock.Any(), gomock.Eq(db.ListWebsByUserIdAfterIdParams{
+						UserID: user.ID,
+						ID:     uuid.Nil,
+						Limit:  exportPageSize,
+					})).
+					Times(1).
+					Return([]db.Web{web}, nil)
+				store.EXPECT().
+					ListTagsByWebIds(gomock.Any(), gomock.Eq([]uuid.UUID{web.ID})).
+					Times(1).
+					Return([]db.ListTagsByWebIdsRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment")
+
+				r, manifest := requireExportManifest(t, recorder.Body)
+				require.Equal(t, user.ID, manifest.User.ID)
+
+				require.Len(t, manifest.Notes, 1)
+				require.Equal(t, note.ID, manifest.Notes[0].ID)
+				require.Equal(t, []string{"go"}, manifest.Notes[0].Tags)
+				require.Equal(t, []uuid.UUID{web.ID}, manifest.Notes[0].WebIDs)
+
+				require.Len(t, manifest.Webs, 1)
+				require.Equal(t, web.Url, manifest.Webs[0].URL)
+
+				f, err := r.Open(manifest.Webs[0].HTMLPath)
+				require.NoError(t, err)
+				html, err := io.ReadAll(f)
+				require.NoError(t, err)
+				require.Equal(t, web.Html, string(html))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+				store.EXPECT().
+					ListNotesByUserIdAfterId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/me/export", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name           string
+		body           gin.H
+		setupAuth      func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs     func(store *mockdb.MockStore)
+		buildBlobStubs func(blobStore *mockblob.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Profile{}, sql.ErrNoRows)
+				store.EXPECT().
+					TxDeleteUser(gomock.Any(), gomock.Eq(db.TxDeleteUserParams{
+						UserID:    user.ID,
+						EmailHash: tombstoneEmailHash(user.Email),
+					})).
+					Times(1).
+					Return(db.UserTombstone{}, nil)
+			},
+			buildBlobStubs: func(blobStore *mockblob.MockStore) {
+				blobStore.EXPECT().
+					Delete(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "DeletesAvatar",
+			body: gin.H{"password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Profile{UserID: user.ID, AvatarKey: "avatars/old.png"}, nil)
+				store.EXPECT().
+					TxDeleteUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTombstone{}, nil)
+			},
+			buildBlobStubs: func(blobStore *mockblob.MockStore) {
+				blobStore.EXPECT().
+					Delete(gomock.Any(), gomock.Eq("avatars/old.png")).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"password": util.RandomString(8)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					TxDeleteUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoPassword",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxDeleteUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxDeleteUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			blobStore := mockblob.NewMockStore(ctrl)
+			if tc.buildBlobStubs != nil {
+				tc.buildBlobStubs(blobStore)
+			}
+			server.blobStore = blobStore
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodDelete, "/users/me", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRegisterDeletedEmailAPI(t *testing.T) {
+	email := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		deletedAt     time.Time
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "WithinGracePeriod",
+			deletedAt: time.Now().Add(-time.Hour),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "AfterGracePeriod",
+			deletedAt: time.Now().Add(-48 * time.Hour),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TemporaryUser{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// got past the tombstone
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUserTombstone(gomock.Any(), gomock.Eq(tombstoneEmailHash(email))).
+				Times(1).
+				Return(db.UserTombstone{EmailHash: tombstoneEmailHash(email), DeletedAt: tc.deletedAt}, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.AccountDeletionGracePeriod = 24 * time.Hour
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": email, "password": util.RandomString(8)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/register", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+)
+
+var errAdminSelf = errors.New("admins can't change the role of or block their own account")
+
+type adminUserResponse struct {
+	userResponse
+	BlockedAt *time.Time `json:"blocked_at,omitempty"`
+}
+
+func newAdminUserResponse(user db.User) adminUserResponse {
+	res := adminUserResponse{userResponse: newUserResponse(user)}
+	if user.BlockedAt.Valid {
+		res.BlockedAt = &user.BlockedAt.Time
+	}
+	return res
+}
+
+type listAdminUserRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=100"`
+	// part of the email, every user when empty
+	Email string `form:"email" binding:"max=254"`
+}
+
+type listAdminUserResponse struct {
+	Users []adminUserResponse `json:"users"`
+}
+
+// Lists users, newest first.
+// @Param page_id query int true "Page ID"
+// @Param page_size query int true "Page size"
+// @Param email query string false "Part of the email"
+// @Success 200 {object} api.listAdminUserResponse
+// @Router /admin/users [get]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) listAdminUser(ctx *gin.Context) {
+	var req listAdminUserRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	users, err := server.store.SearchUsers(ctx, db.SearchUsersParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+		Email:  req.Email,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listAdminUserResponse{Users: make([]adminUserResponse, len(users))}
+	for i, user := range users {
+		res.Users[i] = newAdminUserResponse(user)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+type adminUserRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// bindAdminUser returns the user of the id in the path, it responds and returns false when there is none
+func (server *Server) bindAdminUser(ctx *gin.Context) (db.User, bool) {
+	var req adminUserRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.User{}, false
+	}
+
+	id, _ := uuid.Parse(req.ID)
+	user, err := server.store.GetUser(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	return user, true
+}
+
+// @Param id path string true "User ID"
+// @Success 200 {object} api.adminUserResponse
+// @Router /admin/users/{id} [get]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) getAdminUser(ctx *gin.Context) {
+	user, ok := server.bindAdminUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+type updateAdminUserRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// Admins can't change their own role, so that there is always one left.
+// @Param id path string true "User ID"
+// @Param request body api.updateAdminUserRoleRequest true "query params"
+// @Success 200 {object} api.adminUserResponse
+// @Router /admin/users/{id}/role [put]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) updateAdminUserRole(ctx *gin.Context) {
+	var req updateAdminUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.bindAdminUser(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if user.ID == authPayload.UserID {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAdminSelf))
+		return
+	}
+
+	user, err := server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		ID:   user.ID,
+		Role: req.Role,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// Blocks the account: it can't log in, its tokens are refused and its sessions are revoked.
+// @Param id path string true "User ID"
+// @Success 200 {object} api.adminUserResponse
+// @Router /admin/users/{id}/block [post]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) blockAdminUser(ctx *gin.Context) {
+	user, ok := server.bindAdminUser(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if user.ID == authPayload.UserID {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAdminSelf))
+		return
+	}
+
+	user, err := server.store.TxBlockUser(ctx, db.TxBlockUserParams{UserID: user.ID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// Lets the account log in again, the sessions revoked by the block stay revoked.
+// @Param id path string true "User ID"
+// @Success 200 {object} api.adminUserResponse
+// @Router /admin/users/{id}/unblock [post]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) unblockAdminUser(ctx *gin.Context) {
+	user, ok := server.bindAdminUser(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := server.store.UpdateUserBlockedAt(ctx, db.UpdateUserBlockedAtParams{
+		ID:        user.ID,
+		BlockedAt: sql.NullTime{},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// Logs the user out everywhere, the access and refresh tokens of every session stop working.
+// @Param id path string true "User ID"
+// @Success 200 {} {}
+// @Router /admin/users/{id}/sessions [delete]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) revokeAdminUserSessions(ctx *gin.Context) {
+	user, ok := server.bindAdminUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := server.store.BlockSessionsByUserId(ctx, user.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+type adminStatsResponse struct {
+	Users                int64 `json:"users"`
+	BlockedUsers         int64 `json:"blocked_users"`
+	PendingRegistrations int64 `json:"pending_registrations"`
+	ActiveSessions       int64 `json:"active_sessions"`
+	Notes                int64 `json:"notes"`
+	Webs                 int64 `json:"webs"`
+	// webs waiting to be fetched or retried
+	PendingWebJobs int64 `json:"pending_web_jobs"`
+}
+
+// @Success 200 {object} api.adminStatsResponse
+// @Router /admin/stats [get]
+// @Tags admin
+// @Security AccessToken
+func (server *Server) getAdminStats(ctx *gin.Context) {
+	stats, err := server.store.GetSystemStats(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, adminStatsResponse(stats))
+}
@@ -0,0 +1,364 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	admin.Role = roleAdmin
+	user, _ := randomUser(t)
+	blocked := user
+	blocked.BlockedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "ListUsers",
+			method: http.MethodGet,
+			url:    "/admin/users?page_id=2&page_size=10&email=example",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Eq(db.SearchUsersParams{Limit: 10, Offset: 10, Email: "example"})).
+					Times(1).
+					Return([]db.User{user, blocked}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "hashed_password")
+
+				var res listAdminUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Users, 2)
+				require.Nil(t, res.Users[0].BlockedAt)
+				require.NotNil(t, res.Users[1].BlockedAt)
+			},
+		},
+		{
+			name:   "ListUsersNotAdmin",
+			method: http.MethodGet,
+			url:    "/admin/users?page_id=1&page_size=10",
+			role:   roleUser,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "ListUsersInvalidPageSize",
+			method: http.MethodGet,
+			url:    "/admin/users?page_id=1&page_size=1000",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SearchUsers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "GetUser",
+			method: http.MethodGet,
+			url:    "/admin/users/" + user.ID.String(),
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res adminUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, user.ID, res.ID)
+				require.Equal(t, roleUser, res.Role)
+			},
+		},
+		{
+			name:   "GetUserNotFound",
+			method: http.MethodGet,
+			url:    "/admin/users/" + user.ID.String(),
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "UpdateRole",
+			method: http.MethodPut,
+			url:    "/admin/users/" + user.ID.String() + "/role",
+			body:   gin.H{"role": roleAdmin},
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				promoted := user
+				promoted.Role = roleAdmin
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Eq(db.UpdateUserRoleParams{ID: user.ID, Role: roleAdmin})).
+					Times(1).
+					Return(promoted, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"role":"admin"`)
+			},
+		},
+		{
+			name:   "UpdateRoleInvalid",
+			method: http.MethodPut,
+			url:    "/admin/users/" + user.ID.String() + "/role",
+			body:   gin.H{"role": "root"},
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "UpdateOwnRole",
+			method: http.MethodPut,
+			url:    "/admin/users/" + admin.ID.String() + "/role",
+			body:   gin.H{"role": roleUser},
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					UpdateUserRole(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Block",
+			method: http.MethodPost,
+			url:    "/admin/users/" + user.ID.String() + "/block",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					TxBlockUser(gomock.Any(), gomock.Eq(db.TxBlockUserParams{UserID: user.ID})).
+					Times(1).
+					Return(blocked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res adminUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotNil(t, res.BlockedAt)
+			},
+		},
+		{
+			name:   "BlockSelf",
+			method: http.MethodPost,
+			url:    "/admin/users/" + admin.ID.String() + "/block",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.ID)).
+					Times(1).
+					Return(admin, nil)
+				store.EXPECT().
+					TxBlockUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "BlockNotAdmin",
+			method: http.MethodPost,
+			url:    "/admin/users/" + user.ID.String() + "/block",
+			role:   roleUser,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxBlockUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Unblock",
+			method: http.MethodPost,
+			url:    "/admin/users/" + user.ID.String() + "/unblock",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(blocked, nil)
+				store.EXPECT().
+					UpdateUserBlockedAt(gomock.Any(), gomock.Eq(db.UpdateUserBlockedAtParams{ID: user.ID})).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "blocked_at")
+			},
+		},
+		{
+			name:   "RevokeSessions",
+			method: http.MethodDelete,
+			url:    "/admin/users/" + user.ID.String() + "/sessions",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					BlockSessionsByUserId(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Stats",
+			method: http.MethodGet,
+			url:    "/admin/stats",
+			role:   roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSystemStats(gomock.Any()).
+					Times(1).
+					Return(db.GetSystemStatsRow{Users: 3, BlockedUsers: 1, Notes: 7}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res adminStatsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, adminStatsResponse{Users: 3, BlockedUsers: 1, Notes: 7}, res)
+			},
+		},
+		{
+			name:   "StatsNotAdmin",
+			method: http.MethodGet,
+			url:    "/admin/stats",
+			role:   roleUser,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetSystemStats(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUserAuthorization(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(db.GetUserAuthorizationRow{Role: tc.role}, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginBlockedUser(t *testing.T) {
+	user, password := randomUser(t)
+	user.BlockedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"email": user.Email, "password": password})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Contains(t, recorder.Body.String(), errAccountBlocked.Error())
+}
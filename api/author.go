@@ -0,0 +1,304 @@
+package api
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/feed"
+	"github.com/inkclip/backend/markdown"
+)
+
+const (
+	// author pages may be stale for a minute, feeds for 15, readers poll them far more often than they change
+	authorPageCacheControl = "public, max-age=60"
+	feedCacheControl       = "public, max-age=900"
+	// feedSize is how many of the latest public notes are in a feed
+	feedSize = 20
+	// excerptLength is the most characters of a note in a feed summary
+	excerptLength = 200
+)
+
+// publicAuthor is what every page of an author starts from
+type publicAuthor struct {
+	profile db.Profile
+	state   db.GetPublicNotesStateRow
+}
+
+// lastModified is the latest change to the profile or the public notes.
+// A public note that is deleted or made private moves it with public_notes_removed_at, since the latest update can only go back then.
+func (author publicAuthor) lastModified() time.Time {
+	lastModified := author.profile.UpdatedAt
+	for _, t := range []time.Time{author.state.LastUpdatedAt, author.profile.PublicNotesRemovedAt} {
+		if t.After(lastModified) {
+			lastModified = t
+		}
+	}
+	return lastModified
+}
+
+// etag is a weak validator of a representation of the author, variant tells the representations apart
+func (author publicAuthor) etag(variant string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%d|%d|%d|%d|%s",
+		author.profile.UserID,
+		author.profile.Handle,
+		author.profile.UpdatedAt.UnixNano(),
+		author.profile.PublicNotesRemovedAt.UnixNano(),
+		author.state.Count,
+		author.state.LastUpdatedAt.UnixNano(),
+		variant,
+	)
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// bindPublicAuthor finds the author of the handle in the path, it responds and returns false when there is none.
+// Only the profile and an aggregate of the notes are read, so a cached page is validated without listing anything.
+func (server *Server) bindPublicAuthor(ctx *gin.Context) (publicAuthor, bool) {
+	var req handleRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return publicAuthor{}, false
+	}
+
+	profile, err := server.store.GetPublicProfileByHandle(ctx, strings.ToLower(req.Handle))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return publicAuthor{}, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return publicAuthor{}, false
+	}
+
+	state, err := server.store.GetPublicNotesState(ctx, profile.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return publicAuthor{}, false
+	}
+
+	return publicAuthor{profile: profile, state: state}, true
+}
+
+// notModified sets the cache headers and responds 304 when the copy of the client, named by
+// If-None-Match or else If-Modified-Since, is still current
+func notModified(ctx *gin.Context, cacheControl string, etag string, lastModified time.Time) bool {
+	ctx.Header("Cache-Control", cacheControl)
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		if !etagMatches(ifNoneMatch, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(ctx.GetHeader("If-Modified-Since"))
+		// Last-Modified has a precision of a second
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// etagMatches is the weak comparison of If-None-Match, a list of tags or "*"
+func etagMatches(header string, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// publicNotesWithWebs lists a page of the public notes of the user with their webs
+func (server *Server) publicNotesWithWebs(ctx *gin.Context, userID uuid.UUID, limit int32, offset int32) ([]db.Note, map[uuid.UUID][]db.Web, error) {
+	notes, err := server.store.ListPublicNotesByUserId(ctx, db.ListPublicNotesByUserIdParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	noteIDs := make([]uuid.UUID, len(notes))
+	for i := range notes {
+		noteIDs[i] = notes[i].ID
+	}
+	webRows, err := server.store.ListWebByNoteIds(ctx, noteIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return notes, websByNoteID(webRows), nil
+}
+
+type authorPageRequest struct {
+	// 1 when not given
+	PageID int32 `form:"page_id" binding:"omitempty,min=1"`
+	// 10 when not given
+	PageSize int32 `form:"page_size" binding:"omitempty,min=5,max=10"`
+}
+
+type authorPageResponse struct {
+	Profile profileResponse `json:"profile"`
+	Notes   []noteResponse  `json:"notes"`
+	// NoteCount is the number of public notes over every page
+	NoteCount int64 `json:"note_count"`
+}
+
+// The public notes of an author, most recently updated first.
+// @Param handle path string true "Handle"
+// @Param request query api.authorPageRequest false "query params"
+// @Success 200 {object} api.authorPageResponse
+// @Success 304 {} {}
+// @Router /u/{handle} [get]
+// @Tags author
+func (server *Server) getAuthorPage(ctx *gin.Context) {
+	var req authorPageRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.PageID == 0 {
+		req.PageID = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 10
+	}
+
+	author, ok := server.bindPublicAuthor(ctx)
+	if !ok {
+		return
+	}
+	variant := fmt.Sprintf("page:%d:%d", req.PageID, req.PageSize)
+	if notModified(ctx, authorPageCacheControl, author.etag(variant), author.lastModified()) {
+		return
+	}
+
+	notes, webs, err := server.publicNotesWithWebs(ctx, author.profile.UserID, req.PageSize, (req.PageID-1)*req.PageSize)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := authorPageResponse{
+		Profile:   newProfileResponse(author.profile),
+		Notes:     make([]noteResponse, len(notes)),
+		NoteCount: author.state.Count,
+	}
+	for i, note := range notes {
+		// tags are how the owner organises notes, they aren't shown publicly
+		res.Notes[i] = newNoteResponse(note, webs[note.ID], nil)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// The latest public notes of an author as an Atom feed.
+// @Param handle path string true "Handle"
+// @Produce xml
+// @Success 200 {string} string "Atom document"
+// @Success 304 {} {}
+// @Router /u/{handle}/feed.atom [get]
+// @Tags author
+func (server *Server) getAuthorAtomFeed(ctx *gin.Context) {
+	server.serveAuthorFeed(ctx, "atom", feed.AtomContentType, (*feed.Feed).Atom)
+}
+
+// The latest public notes of an author as an RSS 2.0 feed.
+// @Param handle path string true "Handle"
+// @Produce xml
+// @Success 200 {string} string "RSS document"
+// @Success 304 {} {}
+// @Router /u/{handle}/feed.rss [get]
+// @Tags author
+func (server *Server) getAuthorRSSFeed(ctx *gin.Context) {
+	server.serveAuthorFeed(ctx, "rss", feed.RSSContentType, (*feed.Feed).RSS)
+}
+
+func (server *Server) serveAuthorFeed(ctx *gin.Context, format string, contentType string, render func(*feed.Feed) ([]byte, error)) {
+	author, ok := server.bindPublicAuthor(ctx)
+	if !ok {
+		return
+	}
+	if notModified(ctx, feedCacheControl, author.etag(format), author.lastModified()) {
+		return
+	}
+
+	notes, webs, err := server.publicNotesWithWebs(ctx, author.profile.UserID, feedSize, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	body, err := render(server.authorFeed(author, format, notes, webs))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Data(http.StatusOK, contentType, body)
+}
+
+func (server *Server) authorFeed(author publicAuthor, format string, notes []db.Note, webs map[uuid.UUID][]db.Web) *feed.Feed {
+	profile := author.profile
+	name := profile.DisplayName
+	if name == "" {
+		name = profile.Handle
+	}
+	authorURL := server.config.FrontURL + "/u/" + profile.Handle
+
+	f := &feed.Feed{
+		Title:       "Notes of " + name,
+		Description: profile.Bio,
+		Link:        authorURL,
+		SelfLink:    server.config.PublicURL + "/u/" + profile.Handle + "/feed." + format,
+		Author:      feed.Person{Name: name, URI: authorURL},
+		Updated:     author.lastModified(),
+		Entries:     make([]feed.Entry, len(notes)),
+	}
+	for i, note := range notes {
+		entry := feed.Entry{
+			ID:        "urn:uuid:" + note.ID.String(),
+			Title:     note.Title,
+			Link:      server.config.PublicURL + "/public_notes/" + note.ID.String(),
+			Summary:   noteExcerpt(markdown.PlainText(note.Content), excerptLength),
+			Content:   note.Content,
+			Published: note.CreatedAt,
+			Updated:   note.UpdatedAt,
+		}
+		for _, web := range webs[note.ID] {
+			entry.Related = append(entry.Related, feed.Link{Href: web.Url, Title: web.Title})
+		}
+		f.Entries[i] = entry
+	}
+	return f
+}
+
+// noteExcerpt is the start of the text on one line, cut at a word when it is longer than max characters
+func noteExcerpt(content string, max int) string {
+	excerpt := strings.Join(strings.Fields(content), " ")
+	runes := []rune(excerpt)
+	if len(runes) <= max {
+		return excerpt
+	}
+
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
@@ -0,0 +1,365 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/feed"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuthorPageAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	note := randomPublicNote(t, user.ID)
+	web := randomWeb(t, user.ID)
+	state := db.GetPublicNotesStateRow{Count: 1, LastUpdatedAt: time.Now().Truncate(time.Second)}
+	author := publicAuthor{profile: profile, state: state}
+
+	testCases := []struct {
+		name          string
+		query         string
+		header        http.Header
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPublicNotesByUserId(gomock.Any(), gomock.Eq(db.ListPublicNotesByUserIdParams{UserID: user.ID, Limit: 10, Offset: 0})).
+					Times(1).
+					Return([]db.Note{note}, nil)
+				store.EXPECT().
+					ListWebByNoteIds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListWebByNoteIdsRow{{ID: web.ID, UserID: web.UserID, Url: web.Url, Title: web.Title, NoteID: note.ID}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, authorPageCacheControl, recorder.Header().Get("Cache-Control"))
+				require.Equal(t, author.etag("page:1:10"), recorder.Header().Get("ETag"))
+				require.Equal(t, state.LastUpdatedAt.UTC().Format(http.TimeFormat), recorder.Header().Get("Last-Modified"))
+				require.NotContains(t, recorder.Body.String(), user.Email)
+
+				var res authorPageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, profile.Handle, res.Profile.Handle)
+				require.Equal(t, int64(1), res.NoteCount)
+				require.Len(t, res.Notes, 1)
+				require.Equal(t, note.ID, res.Notes[0].ID)
+				require.Empty(t, res.Notes[0].Tags)
+				require.Len(t, res.Notes[0].Webs, 1)
+				require.Equal(t, web.Url, res.Notes[0].Webs[0].URL)
+			},
+		},
+		{
+			name:  "SecondPage",
+			query: "?page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPublicNotesByUserId(gomock.Any(), gomock.Eq(db.ListPublicNotesByUserIdParams{UserID: user.ID, Limit: 5, Offset: 5})).
+					Times(1).
+					Return([]db.Note{}, nil)
+				store.EXPECT().
+					ListWebByNoteIds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListWebByNoteIdsRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, author.etag("page:2:5"), recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:   "NotModified",
+			header: http.Header{"If-None-Match": {`"other", ` + author.etag("page:1:10")}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPublicNotesByUserId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotModified, recorder.Code)
+				require.Empty(t, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:   "NotModifiedSince",
+			header: http.Header{"If-Modified-Since": {state.LastUpdatedAt.UTC().Format(http.TimeFormat)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPublicNotesByUserId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotModified, recorder.Code)
+			},
+		},
+		{
+			name: "ModifiedSince",
+			header: http.Header{
+				"If-Modified-Since": {state.LastUpdatedAt.Add(-time.Minute).UTC().Format(http.TimeFormat)},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPublicNotesByUserId(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Note{note}, nil)
+				store.EXPECT().
+					ListWebByNoteIds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListWebByNoteIdsRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: "?page_size=100",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListPublicNotesByUserId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetPublicProfileByHandle(gomock.Any(), gomock.Eq(profile.Handle)).
+				AnyTimes().
+				Return(profile, nil)
+			store.EXPECT().
+				GetPublicNotesState(gomock.Any(), gomock.Eq(user.ID)).
+				AnyTimes().
+				Return(state, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/u/"+profile.Handle+tc.query, nil)
+			require.NoError(t, err)
+			for key, values := range tc.header {
+				request.Header[key] = values
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetAuthorPageNoteRemoved(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	profile.UpdatedAt = time.Now().Add(-time.Hour)
+	cachedAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+
+	// the latest public note was deleted after the reader got the page, the one left is older than its copy
+	profile.PublicNotesRemovedAt = cachedAt.Add(30 * time.Second)
+	state := db.GetPublicNotesStateRow{Count: 1, LastUpdatedAt: cachedAt.Add(-10 * time.Minute)}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetPublicProfileByHandle(gomock.Any(), gomock.Eq(profile.Handle)).
+		Times(1).
+		Return(profile, nil)
+	store.EXPECT().
+		GetPublicNotesState(gomock.Any(), gomock.Eq(user.ID)).
+		Times(1).
+		Return(state, nil)
+	store.EXPECT().
+		ListPublicNotesByUserId(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Note{randomPublicNote(t, user.ID)}, nil)
+	store.EXPECT().
+		ListWebByNoteIds(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ListWebByNoteIdsRow{}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/u/"+profile.Handle, nil)
+	require.NoError(t, err)
+	request.Header.Set("If-Modified-Since", cachedAt.UTC().Format(http.TimeFormat))
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, profile.PublicNotesRemovedAt.UTC().Format(http.TimeFormat), recorder.Header().Get("Last-Modified"))
+}
+
+func TestGetAuthorPageNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetPublicProfileByHandle(gomock.Any(), gomock.Eq("nobody")).
+		Times(1).
+		Return(db.Profile{}, sql.ErrNoRows)
+	store.EXPECT().
+		GetPublicNotesState(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/u/nobody/feed.atom", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetAuthorFeedAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	note := randomPublicNote(t, user.ID)
+	note.CreatedAt = time.Now().Add(-time.Hour).Truncate(time.Second)
+	note.UpdatedAt = time.Now().Truncate(time.Second)
+	web := randomWeb(t, user.ID)
+	state := db.GetPublicNotesStateRow{Count: 1, LastUpdatedAt: note.UpdatedAt}
+
+	testCases := []struct {
+		name          string
+		format        string
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Atom",
+			format: "atom",
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, feed.AtomContentType, recorder.Header().Get("Content-Type"))
+
+				var doc struct {
+					ID     string `xml:"id"`
+					Author struct {
+						Name string `xml:"name"`
+					} `xml:"author"`
+					Entries []struct {
+						ID    string `xml:"id"`
+						Links []struct {
+							Rel  string `xml:"rel,attr"`
+							Href string `xml:"href,attr"`
+						} `xml:"link"`
+					} `xml:"entry"`
+				}
+				require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &doc))
+				require.Equal(t, server.config.PublicURL+"/u/"+profile.Handle+"/feed.atom", doc.ID)
+				require.Equal(t, profile.DisplayName, doc.Author.Name)
+				require.Len(t, doc.Entries, 1)
+				require.Equal(t, "urn:uuid:"+note.ID.String(), doc.Entries[0].ID)
+				require.Equal(t, server.config.PublicURL+"/public_notes/"+note.ID.String(), doc.Entries[0].Links[0].Href)
+				require.Equal(t, "related", doc.Entries[0].Links[1].Rel)
+				require.Equal(t, web.Url, doc.Entries[0].Links[1].Href)
+			},
+		},
+		{
+			name:   "RSS",
+			format: "rss",
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, feed.RSSContentType, recorder.Header().Get("Content-Type"))
+
+				var doc struct {
+					Channel struct {
+						Items []struct {
+							Title     string `xml:"title"`
+							PubDate   string `xml:"pubDate"`
+							Enclosure struct {
+								URL string `xml:"url,attr"`
+							} `xml:"enclosure"`
+						} `xml:"item"`
+					} `xml:"channel"`
+				}
+				require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &doc))
+				require.Len(t, doc.Channel.Items, 1)
+				require.Equal(t, note.Title, doc.Channel.Items[0].Title)
+				require.Equal(t, note.CreatedAt.UTC().Format(time.RFC1123Z), doc.Channel.Items[0].PubDate)
+				require.Equal(t, web.Url, doc.Channel.Items[0].Enclosure.URL)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetPublicProfileByHandle(gomock.Any(), gomock.Eq(profile.Handle)).
+				Times(2).
+				Return(profile, nil)
+			store.EXPECT().
+				GetPublicNotesState(gomock.Any(), gomock.Eq(user.ID)).
+				Times(2).
+				Return(state, nil)
+			// the second request is answered from the validators alone
+			store.EXPECT().
+				ListPublicNotesByUserId(gomock.Any(), gomock.Eq(db.ListPublicNotesByUserIdParams{UserID: user.ID, Limit: feedSize, Offset: 0})).
+				Times(1).
+				Return([]db.Note{note}, nil)
+			store.EXPECT().
+				ListWebByNoteIds(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.ListWebByNoteIdsRow{{ID: web.ID, UserID: web.UserID, Url: web.Url, Title: web.Title, NoteID: note.ID}}, nil)
+
+			server := newTestServer(t, store)
+			path := "/u/" + strings.ToUpper(profile.Handle) + "/feed." + tc.format
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, feedCacheControl, recorder.Header().Get("Cache-Control"))
+			tc.checkResponse(t, server, recorder)
+
+			etag := recorder.Header().Get("ETag")
+			require.NotEqual(t, publicAuthor{profile: profile, state: state}.etag("page:1:10"), etag)
+
+			recorder = httptest.NewRecorder()
+			request, err = http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			request.Header.Set("If-None-Match", etag)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusNotModified, recorder.Code)
+		})
+	}
+}
+
+func TestNoteExcerpt(t *testing.T) {
+	require.Equal(t, "a b c", noteExcerpt("a\n\n b  c", 10))
+	require.Equal(t, "hello…", noteExcerpt("hello world", 8))
+	require.Equal(t, "日本語…", noteExcerpt("日本語のノート", 3))
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+)
+
+var errEmailAlreadyUsed = errors.New("email is already used")
+
+type changeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Sends a confirmation link to the new address and a notice to the current one.
+// The email of the user doesn't change until the link is opened.
+// @Param request body api.changeEmailRequest true "query params"
+// @Success 200 {} {}
+// @Router /users/me/email [put]
+// @Tags user
+// @Security AccessToken
+func (server *Server) changeEmail(ctx *gin.Context) {
+	var req changeEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if req.NewEmail == user.Email {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("new email is the same as the current one")))
+		return
+	}
+
+	if server.abortIfEmailRecentlyDeleted(ctx, req.NewEmail) {
+		return
+	}
+
+	// checked again on confirmation, someone can register the address in between
+	_, err = server.store.GetUserByEmail(ctx, req.NewEmail)
+	if err == nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(errEmailAlreadyUsed))
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	token, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateEmailChangeToken(ctx, db.CreateEmailChangeTokenParams{
+		TokenHash: util.HashSecretToken(token),
+		UserID:    user.ID,
+		NewEmail:  req.NewEmail,
+		ExpiresAt: time.Now().Add(server.config.EmailChangeTokenDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	mailArg := server.mailClient.EmailChangeMailContent(req.NewEmail, token)
+	err = server.mailClient.Send(mailArg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	noticeArg := server.mailClient.EmailChangeNoticeMailContent(user.Email, req.NewEmail)
+	err = server.mailClient.Send(noticeArg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+type confirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Sets the email with the token of the link sent by PUT /users/me/email.
+// @Param request body api.confirmEmailChangeRequest true "query params"
+// @Success 200 {object} api.userResponse
+// @Router /email/confirm [post]
+// @Tags user
+func (server *Server) confirmEmailChange(ctx *gin.Context) {
+	var req confirmEmailChangeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.TxChangeEmail(ctx, db.TxChangeEmailParams{
+		TokenHash: util.HashSecretToken(req.Token),
+	})
+	if err != nil {
+		switch err {
+		case db.ErrInvalidEmailChangeToken:
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		case db.ErrEmailAlreadyUsed:
+			ctx.JSON(http.StatusForbidden, errorResponse(errEmailAlreadyUsed))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
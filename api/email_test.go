@@ -0,0 +1,311 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/mail"
+	mockmail "github.com/inkclip/backend/mail/mock"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeEmailAPI(t *testing.T) {
+	user, password := randomUser(t)
+	newEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore, mailClient *mockmail.MockClient)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"new_email": newEmail, "password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+
+				var tokenHash string
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, newEmail, arg.NewEmail)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Minute)
+						tokenHash = arg.TokenHash
+						return db.EmailChangeToken{TokenHash: arg.TokenHash, UserID: arg.UserID, NewEmail: arg.NewEmail}, nil
+					})
+
+				mailClient.EXPECT().
+					EmailChangeMailContent(gomock.Eq(newEmail), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(recipient string, token string) mail.SendContent {
+						// the mail has the token, the database only its hash
+						require.Equal(t, tokenHash, util.HashSecretToken(token))
+						return mail.SendContent{Recipient: recipient}
+					})
+				mailClient.EXPECT().
+					EmailChangeNoticeMailContent(gomock.Eq(user.Email), gomock.Eq(newEmail)).
+					Times(1).
+					Return(mail.SendContent{Recipient: user.Email})
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(2).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"new_email": newEmail, "password": util.RandomString(8)},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "EmailAlreadyUsed",
+			body: gin.H{"new_email": newEmail, "password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(newEmail)).
+					Times(1).
+					Return(db.User{Email: newEmail}, nil)
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "SameEmail",
+			body: gin.H{"new_email": user.Email, "password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateEmailChangeToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{"new_email": "invalid", "password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"new_email": newEmail, "password": password},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailClient := mockmail.NewMockClient(ctrl)
+			tc.buildStubs(store, mailClient)
+
+			server := newTestServer(t, store)
+			server.config.EmailChangeTokenDuration = time.Hour
+			server.mailClient = mailClient
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/me/email", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConfirmEmailChangeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	token, err := util.NewSecretToken()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxChangeEmail(gomock.Any(), gomock.Eq(db.TxChangeEmailParams{TokenHash: util.HashSecretToken(token)})).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxChangeEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrInvalidEmailChangeToken)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "EmailTakenMeanwhile",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxChangeEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, db.ErrEmailAlreadyUsed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NoToken",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxChangeEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"token": token},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxChangeEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/email/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
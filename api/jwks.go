@@ -0,0 +1,28 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inkclip/backend/token"
+)
+
+var errNoPublicKeys = errors.New("tokens are signed with a shared secret, there are no public keys")
+
+// Serves the public keys tokens are verified with, so that other services can verify them.
+// Only when tokens are signed with TOKEN_SIGNING_KEY_FILE.
+// @Success 200 {object} token.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+// @Tags user
+func (server *Server) getJWKS(ctx *gin.Context) {
+	publisher, ok := server.tokenMaker.(token.KeyPublisher)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, errorResponse(errNoPublicKeys))
+		return
+	}
+
+	// keys are only replaced on restart, verifiers can cache them for a while
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, publisher.KeySet())
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	"github.com/inkclip/backend/token"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAsymmetricMaker(t *testing.T) token.Maker {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	maker, err := token.NewAsymmetricMaker(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil)
+	require.NoError(t, err)
+	return maker
+}
+
+func TestGetJWKSAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupServer   func(t *testing.T, server *Server)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupServer: func(t *testing.T, server *Server) {
+				server.tokenMaker = newTestAsymmetricMaker(t)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res token.JSONWebKeySet
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.Keys, 1)
+				require.Equal(t, "OKP", res.Keys[0].Kty)
+				require.Equal(t, "EdDSA", res.Keys[0].Alg)
+				require.NotEmpty(t, res.Keys[0].Kid)
+			},
+		},
+		{
+			name:        "SharedSecret",
+			setupServer: func(t *testing.T, server *Server) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			tc.setupServer(t, server)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAuthMiddlewareAsymmetricToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	hs256Maker := server.tokenMaker
+	server.tokenMaker = newTestAsymmetricMaker(t)
+
+	user, _ := randomUser(t)
+	server.router.GET("/asymmetric", authMiddleware(server.tokenMaker, store), func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	for _, tc := range []struct {
+		maker token.Maker
+		code  int
+	}{
+		{maker: server.tokenMaker, code: http.StatusOK},
+		{maker: hs256Maker, code: http.StatusUnauthorized},
+	} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/asymmetric", nil)
+		require.NoError(t, err)
+
+		addAuthorization(t, request, tc.maker, authorizationTypeBearer, user.ID, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, tc.code, recorder.Code)
+	}
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/util"
+)
+
+// dummyPasswordHash is checked when the email is unknown, so that it takes as long as a wrong password
+const dummyPasswordHash = "$2a$10$sCw0GR.oToRHk8Vf37ouFOEOsouAOfqq2sggSgb7l8oa43ZKeX2U2"
+
+var (
+	// errInvalidCredentials is the same for an unknown email and a wrong password, it doesn't tell which accounts exist
+	errInvalidCredentials = errors.New("email or password is incorrect")
+	errLoginLocked        = errors.New("too many failed logins, try again later")
+)
+
+func emailThrottleKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// loginLockedUntil returns the latest lock of the keys, zero when none of them is locked
+func (server *Server) loginLockedUntil(ctx *gin.Context, keys ...string) (time.Time, error) {
+	throttles, err := server.store.ListLoginThrottles(ctx, keys)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var lockedUntil time.Time
+	for _, throttle := range throttles {
+		if throttle.LockedUntil.Valid && throttle.LockedUntil.Time.After(lockedUntil) {
+			lockedUntil = throttle.LockedUntil.Time
+		}
+	}
+	if !lockedUntil.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return lockedUntil, nil
+}
+
+// abortLoginLocked responds 429 with the seconds until the lock ends in Retry-After
+func abortLoginLocked(ctx *gin.Context, lockedUntil time.Time) {
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	ctx.JSON(http.StatusTooManyRequests, errorResponse(errLoginLocked))
+}
+
+// lockoutDuration doubles the first lockout with every failure past the limit, up to the max
+func (server *Server) lockoutDuration(failures int32, maxFailures int) time.Duration {
+	over := int(failures) - maxFailures
+	duration := server.config.LoginLockoutDuration
+	for i := 0; i < over && duration < server.config.LoginMaxLockoutDuration; i++ {
+		duration *= 2
+	}
+	if duration > server.config.LoginMaxLockoutDuration {
+		duration = server.config.LoginMaxLockoutDuration
+	}
+	return duration
+}
+
+// recordLoginFailure counts the failure for the key and locks it once it reaches maxFailures.
+// It returns until when the key is locked and whether this failure started the lockout.
+func (server *Server) recordLoginFailure(ctx *gin.Context, key string, maxFailures int) (time.Time, bool, error) {
+	throttle, err := server.store.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		Key:         key,
+		WindowStart: time.Now().Add(-server.config.LoginMaxLockoutDuration),
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if int(throttle.Failures) < maxFailures {
+		return time.Time{}, false, nil
+	}
+
+	lockedUntil := time.Now().Add(server.lockoutDuration(throttle.Failures, maxFailures))
+	err = server.store.LockLogin(ctx, db.LockLoginParams{
+		Key:         key,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+	})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return lockedUntil, int(throttle.Failures) == maxFailures, nil
+}
+
+// recordFailedLogin records a failed login for the email and the client ip.
+// The owner of the account, when there is one, is told by mail the first time the email gets locked.
+func (server *Server) recordFailedLogin(ctx *gin.Context, email string, user *db.User) error {
+	lockedUntil, firstLockout, err := server.recordLoginFailure(ctx, emailThrottleKey(email), server.config.LoginMaxFailures)
+	if err != nil {
+		return err
+	}
+	_, _, err = server.recordLoginFailure(ctx, ipThrottleKey(ctx.ClientIP()), server.config.LoginMaxFailuresPerIP)
+	if err != nil {
+		return err
+	}
+
+	if firstLockout && user != nil {
+		// Send logs its own errors, and a failure mustn't change the response for existing accounts
+		_ = server.mailClient.Send(server.mailClient.LoginLockoutMailContent(user.Email, lockedUntil))
+	}
+	return nil
+}
+
+// failLogin records a failed login with recordFailedLogin and responds 401
+func (server *Server) failLogin(ctx *gin.Context, email string, user *db.User) {
+	if err := server.recordFailedLogin(ctx, email, user); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidCredentials))
+}
+
+// checkLoginPassword is true when the password is the one of the user, a nil user is checked against dummyPasswordHash
+func checkLoginPassword(password string, user *db.User) bool {
+	if user == nil {
+		_ = util.CheckPassword(password, dummyPasswordHash)
+		return false
+	}
+	return util.CheckPassword(password, user.HashedPassword) == nil
+}
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/mail"
+	mockmail "github.com/inkclip/backend/mail/mock"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+// testClientIP is the address the login requests are sent from
+const testClientIP = "192.0.2.1"
+
+func TestLoginThrottleAPI(t *testing.T) {
+	user, password := randomUser(t)
+	emailKey := emailThrottleKey(user.Email)
+	ipKey := ipThrottleKey(testClientIP)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore, mailClient *mockmail.MockClient)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "EmailLocked",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ListLoginThrottles(gomock.Any(), gomock.Eq([]string{emailKey, ipKey})).
+					Times(1).
+					Return([]db.LoginThrottle{{
+						Key:         emailKey,
+						Failures:    5,
+						LockedUntil: sql.NullTime{Time: time.Now().Add(90 * time.Second), Valid: true},
+					}}, nil)
+				// not even the right password gets through
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+				retryAfter, err := strconv.Atoi(recorder.Header().Get("Retry-After"))
+				require.NoError(t, err)
+				require.InDelta(t, 90, retryAfter, 1)
+			},
+		},
+		{
+			name: "IPLocked",
+			body: gin.H{"email": util.RandomEmail(), "password": password},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ListLoginThrottles(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginThrottle{{
+						Key:         ipKey,
+						Failures:    20,
+						LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+					}}, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "LockExpired",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ListLoginThrottles(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoginThrottle{{
+						Key:         emailKey,
+						Failures:    5,
+						LockedUntil: sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true},
+					}}, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Eq(emailKey)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "FailureStartsLockout",
+			body: gin.H{"email": user.Email, "password": util.RandomString(8)},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.RecordLoginFailureParams) (db.LoginThrottle, error) {
+						require.Equal(t, emailKey, arg.Key)
+						require.WithinDuration(t, time.Now().Add(-time.Hour), arg.WindowStart, time.Second)
+						return db.LoginThrottle{Key: arg.Key, Failures: 5}, nil
+					})
+				store.EXPECT().
+					LockLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.LockLoginParams) error {
+						require.Equal(t, emailKey, arg.Key)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.LockedUntil.Time, time.Second)
+						return nil
+					})
+				mailClient.EXPECT().
+					LoginLockoutMailContent(gomock.Eq(user.Email), gomock.Any()).
+					Times(1).
+					Return(mail.SendContent{Recipient: user.Email})
+				mailClient.EXPECT().
+					Send(gomock.Eq(mail.SendContent{Recipient: user.Email})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "FailureExtendsLockout",
+			body: gin.H{"email": user.Email, "password": util.RandomString(8)},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginThrottle{Key: emailKey, Failures: 7}, nil)
+				store.EXPECT().
+					LockLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.LockLoginParams) error {
+						require.WithinDuration(t, time.Now().Add(4*time.Minute), arg.LockedUntil.Time, time.Second)
+						return nil
+					})
+				// the owner was told when the lockout started
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownEmailLockout",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginThrottle{Key: emailKey, Failures: 5}, nil)
+				store.EXPECT().
+					LockLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "IPFailureStartsLockout",
+			body: gin.H{"email": user.Email, "password": util.RandomString(8)},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ interface{}, arg db.RecordLoginFailureParams) (db.LoginThrottle, error) {
+						if arg.Key == ipKey {
+							return db.LoginThrottle{Key: arg.Key, Failures: 20}, nil
+						}
+						return db.LoginThrottle{Key: arg.Key, Failures: 1}, nil
+					})
+				store.EXPECT().
+					LockLogin(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.LockLoginParams) error {
+						require.Equal(t, ipKey, arg.Key)
+						return nil
+					})
+				// the lock isn't about the account
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailClient := mockmail.NewMockClient(ctrl)
+			tc.buildStubs(store, mailClient)
+
+			server := newTestServer(t, store)
+			server.mailClient = mailClient
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.RemoteAddr = testClientIP + ":1234"
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLockoutDuration(t *testing.T) {
+	server := newTestServer(t, nil)
+	server.config.LoginLockoutDuration = time.Minute
+	server.config.LoginMaxLockoutDuration = time.Hour
+
+	testCases := []struct {
+		failures int32
+		duration time.Duration
+	}{
+		{failures: 5, duration: time.Minute},
+		{failures: 6, duration: 2 * time.Minute},
+		{failures: 10, duration: 32 * time.Minute},
+		{failures: 11, duration: time.Hour},
+		{failures: 1000, duration: time.Hour},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.duration, server.lockoutDuration(tc.failures, 5), "%d failures", tc.failures)
+	}
+}
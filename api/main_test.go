@@ -1,12 +1,16 @@
 package api
 
 import (
+	"database/sql"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inkclip/backend/blob"
 	"github.com/inkclip/backend/config"
+	mockdb "github.com/inkclip/backend/db/mock"
 	db "github.com/inkclip/backend/db/sqlc"
 	"github.com/inkclip/backend/mail"
 	"github.com/inkclip/backend/util"
@@ -15,14 +19,52 @@ import (
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := config.Config{
-		TokenSecretKey:      util.RandomString(32),
-		AccessTokenDuration: time.Minute,
-		FrontURL:            util.RandomURL(),
+		TokenSecretKey:          util.RandomString(32),
+		AccessTokenDuration:     time.Minute,
+		RefreshTokenDuration:    time.Hour,
+		FrontURL:                util.RandomURL(),
+		PublicURL:               util.RandomURL(),
+		LoginMaxFailures:        5,
+		LoginMaxFailuresPerIP:   20,
+		LoginLockoutDuration:    time.Minute,
+		LoginMaxLockoutDuration: time.Hour,
 	}
 
 	mailClient := mail.NewMailClient(config)
 
-	server, err := NewServer(config, store, mailClient)
+	// Tokens are checked against password_changed_at, the role and the block on every authenticated request.
+	// Tests that care register their own expectation before calling newTestServer, which gomock matches first.
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().
+			GetUserAuthorization(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.GetUserAuthorizationRow{Role: roleUser}, nil)
+		// registration checks the email against deleted users
+		mockStore.EXPECT().
+			GetUserTombstone(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.UserTombstone{}, sql.ErrNoRows)
+		// logins are throttled, by default nothing is locked and failures stay under the limits
+		mockStore.EXPECT().
+			ListLoginThrottles(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return([]db.LoginThrottle{}, nil)
+		mockStore.EXPECT().
+			RecordLoginFailure(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.LoginThrottle{Failures: 1}, nil)
+		mockStore.EXPECT().
+			DeleteLoginThrottle(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(nil)
+		// mails to an address are limited, by default none was sent recently
+		mockStore.EXPECT().
+			ClaimMailSend(gomock.Any(), gomock.Any()).
+			AnyTimes().
+			Return(db.MailThrottle{}, nil)
+	}
+
+	server, err := NewServer(config, store, mailClient, blob.NewLocalStore(t.TempDir()))
 	require.NoError(t, err)
 
 	return server
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,7 +10,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/inkclip/backend/config"
+	db "github.com/inkclip/backend/db/sqlc"
 	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
 	"go.uber.org/zap"
 )
 
@@ -17,9 +20,33 @@ const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	// authorizationScopesKey is only set for personal access tokens, a login session isn't limited by scopes
+	authorizationScopesKey = "authorization_scopes"
+	// authorizationRoleKey is the role of the authenticated user, checked by roleMiddleware
+	authorizationRoleKey = "authorization_role"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+const (
+	roleUser  = "user"
+	roleAdmin = "admin"
+)
+
+var (
+	errTokenBeforePasswordChange  = errors.New("token was issued before the password was changed")
+	errInsufficientScope          = errors.New("personal access token doesn't have the scope required by this route")
+	errPersonalAccessTokenRefused = errors.New("personal access tokens can't be used for this route")
+	errAccountBlocked             = errors.New("account is blocked")
+	errSessionBlocked             = errors.New("session is blocked")
+	errInsufficientRole           = errors.New("the role of the authenticated user doesn't allow this route")
+)
+
+// issuedBeforePasswordChange tells whether the token must be rejected since the password changed after it was issued.
+// password_changed_at is set with the clock tokens are issued with, the tokens handed out by the change come after it.
+func issuedBeforePasswordChange(payload *token.Payload, passwordChangedAt time.Time) bool {
+	return payload.IssuedAt.Before(passwordChangedAt)
+}
+
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -43,17 +70,135 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		}
 
 		accessToken := fields[1]
-		payload, err := tokenMaker.VerifyToken(accessToken)
+		var payload *token.Payload
+		if isPersonalAccessToken(accessToken) {
+			pat, ok := verifyPersonalAccessToken(ctx, store, accessToken)
+			if !ok {
+				return
+			}
+			payload = &token.Payload{
+				ID:        pat.ID,
+				UserID:    pat.UserID,
+				IssuedAt:  pat.CreatedAt,
+				ExpiresAt: pat.ExpiresAt.Time,
+			}
+			ctx.Set(authorizationScopesKey, pat.Scopes)
+		} else {
+			var err error
+			payload, err = tokenMaker.VerifyToken(accessToken)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+		}
+
+		// personal access tokens have no session, their zero session id matches none
+		authorization, err := store.GetUserAuthorization(ctx, db.GetUserAuthorizationParams{
+			ID:        payload.UserID,
+			SessionID: payload.SessionID,
+		})
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if issuedBeforePasswordChange(payload, authorization.PasswordChangedAt) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errTokenBeforePasswordChange))
+			return
+		}
+		if authorization.BlockedAt.Valid {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errAccountBlocked))
+			return
+		}
+		if authorization.SessionBlocked {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errSessionBlocked))
 			return
 		}
 
 		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(authorizationRoleKey, authorization.Role)
+		ctx.Next()
+	}
+}
+
+// verifyPersonalAccessToken looks the token up and records that it was used.
+// It aborts the request and returns false when the token can't be used.
+func verifyPersonalAccessToken(ctx *gin.Context, store db.Store, accessToken string) (db.PersonalAccessToken, bool) {
+	pat, err := store.GetPersonalAccessTokenByHash(ctx, util.HashSecretToken(accessToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrInvalidToken))
+			return pat, false
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return pat, false
+	}
+
+	if pat.ExpiresAt.Valid && time.Now().After(pat.ExpiresAt.Time) {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrExpiredToken))
+		return pat, false
+	}
+
+	if err := store.TouchPersonalAccessToken(ctx, pat.ID); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return pat, false
+	}
+	return pat, true
+}
+
+// hasScope tells whether the request is allowed to do what the scope covers
+func hasScope(ctx *gin.Context, scope string) bool {
+	scopes, ok := ctx.Get(authorizationScopesKey)
+	if !ok {
+		return true
+	}
+	for _, s := range scopes.([]string) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeMiddleware lets personal access tokens through only when they have the scope, it must come after authMiddleware
+func scopeMiddleware(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !hasScope(ctx, scope) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errInsufficientScope))
+			return
+		}
 		ctx.Next()
 	}
 }
 
+// sessionOnlyMiddleware refuses personal access tokens, for routes that manage the account itself
+func sessionOnlyMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get(authorizationScopesKey); ok {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errPersonalAccessTokenRefused))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// roleMiddleware lets through only users with one of the roles, it must come after authMiddleware
+func roleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role := ctx.GetString(authorizationRoleKey)
+		for _, r := range roles {
+			if r == role {
+				ctx.Next()
+				return
+			}
+		}
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errInsufficientRole))
+	}
+}
+
 func jsonMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Content-Type", "application/json")
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +19,8 @@ type createNoteRequest struct {
 	Content  string   `json:"content" binding:"required,max=10000"`
 	IsPublic *bool    `json:"is_public" binding:"required"`
 	WebIDs   []string `json:"web_ids" binding:"min=1,max=5,dive,uuid"`
+	// tags that don't exist yet are created
+	Tags []string `json:"tags" binding:"max=10,dive,max=30"`
 }
 
 type noteResponse struct {
@@ -27,9 +31,15 @@ type noteResponse struct {
 	CreatedAt time.Time     `json:"created_at"`
 	IsPublic  bool          `json:"is_public"`
 	Webs      []webResponse `json:"webs"`
+	Tags      []string      `json:"tags"`
+	// Version is bumped by every update
+	Version   int32     `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ETag is what getNote sends in the ETag header, send it back in If-Match to update the note
+	ETag string `json:"etag"`
 }
 
-func newNoteResponse(note db.Note, webs []db.Web) noteResponse {
+func newNoteResponse(note db.Note, webs []db.Web, tags []db.Tag) noteResponse {
 	webResponses := make([]webResponse, len(webs))
 	for i := range webs {
 		webResponses[i] = newWebResponse(webs[i])
@@ -42,9 +52,68 @@ func newNoteResponse(note db.Note, webs []db.Web) noteResponse {
 		CreatedAt: note.CreatedAt,
 		IsPublic:  note.IsPublic,
 		Webs:      webResponses,
+		Tags:      tagNames(tags),
+		Version:   note.Version,
+		UpdatedAt: note.UpdatedAt,
+		ETag:      noteETag(note),
 	}
 }
 
+func noteETag(note db.Note) string {
+	return strconv.Quote(strconv.Itoa(int(note.Version)))
+}
+
+// ifMatchVersion reads the note version from the If-Match header.
+// It isn't valid when the header is missing or "*", and is 0, which never matches, when the header can't be read.
+func ifMatchVersion(ctx *gin.Context) sql.NullInt32 {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return sql.NullInt32{}
+	}
+
+	// proxies that compress responses turn the ETag into a weak one
+	tag := strings.TrimPrefix(header, "W/")
+	value, err := strconv.Unquote(tag)
+	if err != nil || !strings.HasPrefix(tag, `"`) {
+		return sql.NullInt32{Int32: 0, Valid: true}
+	}
+	version, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return sql.NullInt32{Int32: 0, Valid: true}
+	}
+	return sql.NullInt32{Int32: int32(version), Valid: true}
+}
+
+type noteVersionMismatchResponse struct {
+	Error string `json:"error"`
+	// Version is the current version of the note
+	Version int32  `json:"version"`
+	ETag    string `json:"etag"`
+}
+
+// abortNoteUpdate writes the error response for an error returned by TxUpdateNote
+func (server *Server) abortNoteUpdate(ctx *gin.Context, id uuid.UUID, err error) {
+	if err == db.ErrNoteVersionMismatch {
+		note, getErr := server.store.GetNote(ctx, id)
+		if getErr != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(getErr))
+			return
+		}
+		ctx.Header("ETag", noteETag(note))
+		ctx.JSON(http.StatusPreconditionFailed, noteVersionMismatchResponse{
+			Error:   err.Error(),
+			Version: note.Version,
+			ETag:    noteETag(note),
+		})
+		return
+	}
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
 // @Param request body api.createNoteRequest true "query params"
 // @Success 200 {object} api.noteResponse
 // @Router /notes [post]
@@ -71,7 +140,8 @@ func (server *Server) createNote(ctx *gin.Context) {
 			Content:  req.Content,
 			IsPublic: *req.IsPublic,
 		},
-		WebIds: webIds,
+		WebIds:   webIds,
+		TagNames: normalizeTagNames(req.Tags),
 	}
 
 	txNote, err := server.store.TxCreateNote(ctx, arg)
@@ -80,7 +150,8 @@ func (server *Server) createNote(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newNoteResponse(txNote.Note, txNote.Webs))
+	ctx.Header("ETag", noteETag(txNote.Note))
+	ctx.JSON(http.StatusOK, newNoteResponse(txNote.Note, txNote.Webs, txNote.Tags))
 }
 
 type getNoteRequest struct {
@@ -123,12 +194,21 @@ func (server *Server) getNote(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newNoteResponse(note, webs))
+	tags, err := server.store.ListTagsByNoteId(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("ETag", noteETag(note))
+	ctx.JSON(http.StatusOK, newNoteResponse(note, webs, tags))
 }
 
 type listNoteRequest struct {
 	PageID   int32 `json:"page_id" form:"page_id" binding:"required,min=1"`
 	PageSize int32 `json:"page_size" form:"page_size" binding:"required,min=5,max=10"`
+	// only notes that have all of these tags
+	Tags []string `json:"tags" form:"tags" binding:"max=10,dive,max=30"`
 }
 
 type listNoteResponse struct {
@@ -151,6 +231,7 @@ func (server *Server) listNote(ctx *gin.Context) {
 
 	arg := db.ListNotesByUserIdParams{
 		UserID: authPayload.UserID,
+		Tags:   normalizeTagNames(req.Tags),
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
@@ -171,23 +252,29 @@ func (server *Server) listNote(ctx *gin.Context) {
 		return
 	}
 
+	tagRows, err := server.store.ListTagsByNoteIds(ctx, noteIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	webs := websByNoteID(webRows)
 	resNotes := make([]noteResponse, len(notes))
 	for i, note := range notes {
-		var websFiltterByNote []db.Web
-		for _, row := range webRows {
+		var tagsFilterByNote []db.Tag
+		for _, row := range tagRows {
 			if row.NoteID == note.ID {
-				websFiltterByNote = append(websFiltterByNote, db.Web{
-					ID:           row.ID,
-					UserID:       row.UserID,
-					Url:          row.Url,
-					Title:        row.Title,
-					ThumbnailUrl: row.ThumbnailUrl,
-					Html:         row.Html,
-					CreatedAt:    row.CreatedAt,
+				tagsFilterByNote = append(tagsFilterByNote, db.Tag{
+					ID:        row.ID,
+					UserID:    row.UserID,
+					Name:      row.Name,
+					NoteCount: row.NoteCount,
+					WebCount:  row.WebCount,
+					CreatedAt: row.CreatedAt,
 				})
 			}
 		}
-		resNotes[i] = newNoteResponse(note, websFiltterByNote)
+		resNotes[i] = newNoteResponse(note, webs[note.ID], tagsFilterByNote)
 	}
 
 	res := listNoteResponse{
@@ -197,6 +284,33 @@ func (server *Server) listNote(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// websByNoteID groups the webs of ListWebByNoteIds by their note
+func websByNoteID(rows []db.ListWebByNoteIdsRow) map[uuid.UUID][]db.Web {
+	webs := make(map[uuid.UUID][]db.Web)
+	for _, row := range rows {
+		webs[row.NoteID] = append(webs[row.NoteID], db.Web{
+			ID:              row.ID,
+			UserID:          row.UserID,
+			Url:             row.Url,
+			Title:           row.Title,
+			ThumbnailUrl:    row.ThumbnailUrl,
+			Html:            row.Html,
+			Status:          row.Status,
+			ErrorReason:     row.ErrorReason,
+			ArticleText:     row.ArticleText,
+			ArticleHtml:     row.ArticleHtml,
+			ArticleMarkdown: row.ArticleMarkdown,
+			Author:          row.Author,
+			SiteName:        row.SiteName,
+			Description:     row.Description,
+			PublishedAt:     row.PublishedAt,
+			ReadingMinutes:  row.ReadingMinutes,
+			CreatedAt:       row.CreatedAt,
+		})
+	}
+	return webs
+}
+
 type deleteNoteRequest struct {
 	ID string `uri:"id" binding:"required,uuid"`
 }
@@ -248,11 +362,16 @@ type putNoteRequest struct {
 	Content  string   `form:"content" binding:"required"`
 	WebIDs   []string `json:"web_ids" binding:"min=1,max=5,dive,uuid"`
 	IsPublic *bool    `json:"is_public" binding:"required"`
+	// replaces the tags of the note, tags that don't exist yet are created
+	Tags []string `json:"tags" binding:"max=10,dive,max=30"`
 }
 
+// The note is only updated when If-Match is its current ETag, or when If-Match is not sent.
 // @Param id path string true "Web ID"
+// @Param If-Match header string false "ETag of the note"
 // @Param request body api.putNoteRequest true "query params"
 // @Success 200 {object} api.noteResponse
+// @Failure 412 {object} api.noteVersionMismatchResponse
 // @Router /notes/{id} [put]
 // @Tags note
 // @Security AccessToken
@@ -290,59 +409,78 @@ func (server *Server) putNote(ctx *gin.Context) {
 	}
 	updateNoteArg := db.TxUpdateNoteParams{
 		UpdateNoteParams: db.UpdateNoteParams{
-			ID:       id,
-			Title:    req.Title,
-			Content:  req.Content,
-			IsPublic: *req.IsPublic,
+			ID:              id,
+			Title:           req.Title,
+			Content:         req.Content,
+			IsPublic:        *req.IsPublic,
+			ExpectedVersion: ifMatchVersion(ctx),
 		},
-		WebIds: webIDs,
+		WebIds:   webIDs,
+		TagNames: normalizeTagNames(req.Tags),
+		AuthorID: authPayload.UserID,
 	}
 	result, err := server.store.TxUpdateNote(ctx, updateNoteArg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		server.abortNoteUpdate(ctx, id, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newNoteResponse(result.Note, result.Webs))
+	ctx.Header("ETag", noteETag(result.Note))
+	ctx.JSON(http.StatusOK, newNoteResponse(result.Note, result.Webs, result.Tags))
 }
 
 type getPublicNoteRequest struct {
 	ID string `uri:"id" binding:"required,uuid"`
 }
 
+// The note as json, or a page with the note and the tags of link previews when Accept prefers text/html.
 // @Param id path string true "Note ID"
+// @Produce html,json
 // @Success 200 {object} api.noteResponse
 // @Router /public_notes/{id} [get]
 // @Tags note
 func (server *Server) getPublicNote(ctx *gin.Context) {
+	// caches must keep the html and the json apart
+	ctx.Header("Vary", "Accept")
+	html := wantsHTML(ctx)
+
 	var req getPublicNoteRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondPublicNoteError(ctx, html, http.StatusBadRequest, err)
 		return
 	}
 
 	id, _ := uuid.Parse(req.ID)
 	note, err := server.store.GetNote(ctx, id)
+	if err == nil && !note.IsPublic {
+		err = errNoteNotPublic
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		if err == sql.ErrNoRows || err == errNoteNotPublic {
+			respondPublicNoteError(ctx, html, http.StatusNotFound, err)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondPublicNoteError(ctx, html, http.StatusInternalServerError, err)
 		return
 	}
 
-	if !note.IsPublic {
-		err := errors.New("note is not public")
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	webs, err := server.store.ListWebByNoteId(ctx, id)
+	if err != nil {
+		respondPublicNoteError(ctx, html, http.StatusInternalServerError, err)
 		return
 	}
 
-	webs, err := server.store.ListWebByNoteId(ctx, id)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if html {
+		page, err := server.newPublicNotePage(ctx, note, webs)
+		if err != nil {
+			respondPublicNoteError(ctx, html, http.StatusInternalServerError, err)
+			return
+		}
+		ctx.Header("Cache-Control", publicNoteCacheControl)
+		renderHTML(ctx, http.StatusOK, "public_note", page)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newNoteResponse(note, webs))
+	// tags are how the owner organises notes, they aren't shown publicly
+	ctx.JSON(http.StatusOK, newNoteResponse(note, webs, nil))
 }
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/diff"
+	"github.com/inkclip/backend/token"
+)
+
+type noteRevisionResponse struct {
+	Revision  int32       `json:"revision" binding:"required"`
+	AuthorID  uuid.UUID   `json:"author_id" binding:"required"`
+	Title     string      `json:"title" binding:"required"`
+	Content   string      `json:"content" binding:"required"`
+	IsPublic  bool        `json:"is_public" binding:"required"`
+	WebIDs    []uuid.UUID `json:"web_ids" binding:"required"`
+	CreatedAt time.Time   `json:"created_at" binding:"required"`
+}
+
+func newNoteRevisionResponse(revision db.NoteRevision) noteRevisionResponse {
+	webIDs := revision.WebIds
+	if webIDs == nil {
+		webIDs = []uuid.UUID{}
+	}
+	return noteRevisionResponse{
+		Revision:  revision.Revision,
+		AuthorID:  revision.AuthorID,
+		Title:     revision.Title,
+		Content:   revision.Content,
+		IsPublic:  revision.IsPublic,
+		WebIDs:    webIDs,
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+type noteRevisionURIRequest struct {
+	ID       string `uri:"id" binding:"required,uuid"`
+	Revision int32  `uri:"revision" binding:"required,min=1"`
+}
+
+// getOwnNote writes the error response and returns false when the note can't be used by the authenticated user
+func (server *Server) getOwnNote(ctx *gin.Context, id uuid.UUID) (db.Note, bool) {
+	note, err := server.store.GetNote(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return note, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return note, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if note.UserID != authPayload.UserID {
+		err := errors.New("note doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return note, false
+	}
+	return note, true
+}
+
+// getNoteRevisionOrAbort writes the error response and returns false when the revision doesn't exist
+func (server *Server) getNoteRevisionOrAbort(ctx *gin.Context, noteID uuid.UUID, revision int32) (db.NoteRevision, bool) {
+	ret, err := server.store.GetNoteRevision(ctx, db.GetNoteRevisionParams{
+		NoteID:   noteID,
+		Revision: revision,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return ret, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return ret, false
+	}
+	return ret, true
+}
+
+type listNoteRevisionRequest struct {
+	PageID   int32 `json:"page_id" form:"page_id" binding:"required,min=1"`
+	PageSize int32 `json:"page_size" form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type listNoteRevisionResponse struct {
+	Revisions []noteRevisionResponse `json:"revisions"`
+}
+
+// Newest revisions come first.
+// @Param id path string true "Note ID"
+// @Param request query api.listNoteRevisionRequest true "query params"
+// @Success 200 {object} api.listNoteRevisionResponse
+// @Router /notes/{id}/revisions [get]
+// @Tags note
+// @Security AccessToken
+func (server *Server) listNoteRevision(ctx *gin.Context) {
+	var uri getNoteRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req listNoteRevisionRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+	if _, ok := server.getOwnNote(ctx, id); !ok {
+		return
+	}
+
+	revisions, err := server.store.ListNoteRevisions(ctx, db.ListNoteRevisionsParams{
+		NoteID: id,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listNoteRevisionResponse{
+		Revisions: make([]noteRevisionResponse, len(revisions)),
+	}
+	for i, revision := range revisions {
+		res.Revisions[i] = newNoteRevisionResponse(revision)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// @Param id path string true "Note ID"
+// @Param revision path int true "Revision"
+// @Success 200 {object} api.noteRevisionResponse
+// @Router /notes/{id}/revisions/{revision} [get]
+// @Tags note
+// @Security AccessToken
+func (server *Server) getNoteRevision(ctx *gin.Context) {
+	var uri noteRevisionURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+	if _, ok := server.getOwnNote(ctx, id); !ok {
+		return
+	}
+
+	revision, ok := server.getNoteRevisionOrAbort(ctx, id, uri.Revision)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newNoteRevisionResponse(revision))
+}
+
+type diffNoteRequest struct {
+	From int32 `json:"from" form:"from" binding:"required,min=1"`
+	To   int32 `json:"to" form:"to" binding:"required,min=1"`
+}
+
+type diffNoteResponse struct {
+	From        int32       `json:"from" binding:"required"`
+	To          int32       `json:"to" binding:"required"`
+	Title       []diff.Line `json:"title" binding:"required"`
+	Content     []diff.Line `json:"content" binding:"required"`
+	AddedWebs   []uuid.UUID `json:"added_webs" binding:"required"`
+	RemovedWebs []uuid.UUID `json:"removed_webs" binding:"required"`
+	// IsPublic is set only when the visibility changed, to the value of the newer revision
+	IsPublic *bool `json:"is_public,omitempty"`
+}
+
+// webIDsDiff returns the ids that are only in to and the ids that are only in from
+func webIDsDiff(from []uuid.UUID, to []uuid.UUID) ([]uuid.UUID, []uuid.UUID) {
+	inFrom := map[uuid.UUID]bool{}
+	for _, id := range from {
+		inFrom[id] = true
+	}
+	inTo := map[uuid.UUID]bool{}
+	for _, id := range to {
+		inTo[id] = true
+	}
+
+	added := []uuid.UUID{}
+	for _, id := range to {
+		if !inFrom[id] {
+			added = append(added, id)
+		}
+	}
+	removed := []uuid.UUID{}
+	for _, id := range from {
+		if !inTo[id] {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
+
+// Lines of the title and the content are compared, to may be older than from.
+// @Param id path string true "Note ID"
+// @Param request query api.diffNoteRequest true "query params"
+// @Success 200 {object} api.diffNoteResponse
+// @Router /notes/{id}/diff [get]
+// @Tags note
+// @Security AccessToken
+func (server *Server) diffNote(ctx *gin.Context) {
+	var uri getNoteRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req diffNoteRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+	if _, ok := server.getOwnNote(ctx, id); !ok {
+		return
+	}
+
+	from, ok := server.getNoteRevisionOrAbort(ctx, id, req.From)
+	if !ok {
+		return
+	}
+	to, ok := server.getNoteRevisionOrAbort(ctx, id, req.To)
+	if !ok {
+		return
+	}
+
+	added, removed := webIDsDiff(from.WebIds, to.WebIds)
+	res := diffNoteResponse{
+		From:        from.Revision,
+		To:          to.Revision,
+		Title:       diff.Lines(from.Title, to.Title),
+		Content:     diff.Lines(from.Content, to.Content),
+		AddedWebs:   added,
+		RemovedWebs: removed,
+	}
+	if from.IsPublic != to.IsPublic {
+		res.IsPublic = &to.IsPublic
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// The note gets the title, content, visibility and webs of the revision, and a new revision is recorded.
+// Webs deleted since then are left out, tags are not part of revisions and stay as they are.
+// Like putNote, the update is refused with 412 when If-Match is not the current ETag of the note.
+// @Param id path string true "Note ID"
+// @Param revision path int true "Revision"
+// @Param If-Match header string false "ETag of the note"
+// @Success 200 {object} api.noteResponse
+// @Failure 412 {object} api.noteVersionMismatchResponse
+// @Router /notes/{id}/revisions/{revision}/restore [post]
+// @Tags note
+// @Security AccessToken
+func (server *Server) restoreNoteRevision(ctx *gin.Context) {
+	var uri noteRevisionURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+	if _, ok := server.getOwnNote(ctx, id); !ok {
+		return
+	}
+
+	revision, ok := server.getNoteRevisionOrAbort(ctx, id, uri.Revision)
+	if !ok {
+		return
+	}
+
+	webIDs := []uuid.UUID{}
+	for _, webID := range revision.WebIds {
+		_, err := server.store.GetWeb(ctx, webID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		webIDs = append(webIDs, webID)
+	}
+
+	tags, err := server.store.ListTagsByNoteId(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.TxUpdateNote(ctx, db.TxUpdateNoteParams{
+		UpdateNoteParams: db.UpdateNoteParams{
+			ID:              id,
+			Title:           revision.Title,
+			Content:         revision.Content,
+			IsPublic:        revision.IsPublic,
+			ExpectedVersion: ifMatchVersion(ctx),
+		},
+		WebIds:   webIDs,
+		TagNames: normalizeTagNames(tagNames(tags)),
+		AuthorID: authPayload.UserID,
+	})
+	if err != nil {
+		server.abortNoteUpdate(ctx, id, err)
+		return
+	}
+
+	ctx.Header("ETag", noteETag(result.Note))
+	ctx.JSON(http.StatusOK, newNoteResponse(result.Note, result.Webs, result.Tags))
+}
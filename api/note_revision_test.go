@@ -0,0 +1,538 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/diff"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomNoteRevision(t *testing.T, note db.Note, revision int32, webs []db.Web) db.NoteRevision {
+	webIDs := make([]uuid.UUID, len(webs))
+	for i, web := range webs {
+		webIDs[i] = web.ID
+	}
+	return db.NoteRevision{
+		ID:        util.RandomInt(1, 1000),
+		NoteID:    note.ID,
+		Revision:  revision,
+		AuthorID:  note.UserID,
+		Title:     util.RandomString(6),
+		Content:   util.RandomString(100),
+		IsPublic:  note.IsPublic,
+		WebIds:    webIDs,
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+}
+
+func TestListNoteRevisionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	note := randomNote(t, user.ID)
+	revisions := []db.NoteRevision{
+		randomNoteRevision(t, note, 2, nil),
+		randomNoteRevision(t, note, 1, nil),
+	}
+
+	testCases := []struct {
+		name          string
+		noteID        string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			noteID: note.ID.String(),
+			query:  "page_id=2&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListNoteRevisions(gomock.Any(), gomock.Eq(db.ListNoteRevisionsParams{
+						NoteID: note.ID,
+						Limit:  5,
+						Offset: 5,
+					})).
+					Times(1).
+					Return(revisions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var got listNoteRevisionResponse
+				require.NoError(t, json.Unmarshal(data, &got))
+				require.Len(t, got.Revisions, len(revisions))
+				for i, revision := range revisions {
+					require.Equal(t, revision.Revision, got.Revisions[i].Revision)
+					require.Equal(t, revision.Title, got.Revisions[i].Title)
+					require.Equal(t, revision.Content, got.Revisions[i].Content)
+					require.Equal(t, revision.AuthorID, got.Revisions[i].AuthorID)
+					require.Empty(t, got.Revisions[i].WebIDs)
+				}
+			},
+		},
+		{
+			name:   "InvalidPageSize",
+			noteID: note.ID.String(),
+			query:  "page_id=1&page_size=100",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			noteID: note.ID.String(),
+			query:  "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(db.Note{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "RequestFromAnotherUser",
+			noteID: note.ID.String(),
+			query:  "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				user2, _ := randomUser(t)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListNoteRevisions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			noteID: note.ID.String(),
+			query:  "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListNoteRevisions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.NoteRevision{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/notes/%s/revisions?%s", tc.noteID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetNoteRevisionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	note := randomNote(t, user.ID)
+	revision := randomNoteRevision(t, note, 1, []db.Web{randomWeb(t, user.ID)})
+
+	testCases := []struct {
+		name          string
+		url           string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			url:  fmt.Sprintf("/notes/%s/revisions/1", note.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Eq(db.GetNoteRevisionParams{
+						NoteID:   note.ID,
+						Revision: 1,
+					})).
+					Times(1).
+					Return(revision, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var got noteRevisionResponse
+				require.NoError(t, json.Unmarshal(data, &got))
+				require.Equal(t, newNoteRevisionResponse(revision).Title, got.Title)
+				require.Equal(t, revision.Content, got.Content)
+				require.Equal(t, revision.WebIds, got.WebIDs)
+				require.WithinDuration(t, revision.CreatedAt, got.CreatedAt, time.Second)
+			},
+		},
+		{
+			name: "InvalidRevision",
+			url:  fmt.Sprintf("/notes/%s/revisions/0", note.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "RevisionNotFound",
+			url:  fmt.Sprintf("/notes/%s/revisions/3", note.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.NoteRevision{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDiffNoteAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	note := randomNote(t, user.ID)
+	web1 := randomWeb(t, user.ID)
+	web2 := randomWeb(t, user.ID)
+	web3 := randomWeb(t, user.ID)
+
+	from := randomNoteRevision(t, note, 1, []db.Web{web1, web2})
+	from.Title = "title"
+	from.Content = "a\nb\nc"
+	to := randomNoteRevision(t, note, 2, []db.Web{web2, web3})
+	to.Title = "title"
+	to.Content = "a\nx\nc"
+	to.IsPublic = true
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "from=1&to=2",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Eq(db.GetNoteRevisionParams{NoteID: note.ID, Revision: 1})).
+					Times(1).
+					Return(from, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Eq(db.GetNoteRevisionParams{NoteID: note.ID, Revision: 2})).
+					Times(1).
+					Return(to, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				var got diffNoteResponse
+				require.NoError(t, json.Unmarshal(data, &got))
+				require.Equal(t, int32(1), got.From)
+				require.Equal(t, int32(2), got.To)
+				require.Equal(t, []diff.Line{{Op: diff.Equal, Text: "title"}}, got.Title)
+				require.Equal(t, []diff.Line{
+					{Op: diff.Equal, Text: "a"},
+					{Op: diff.Delete, Text: "b"},
+					{Op: diff.Insert, Text: "x"},
+					{Op: diff.Equal, Text: "c"},
+				}, got.Content)
+				require.Equal(t, []uuid.UUID{web3.ID}, got.AddedWebs)
+				require.Equal(t, []uuid.UUID{web1.ID}, got.RemovedWebs)
+				require.NotNil(t, got.IsPublic)
+				require.True(t, *got.IsPublic)
+			},
+		},
+		{
+			name:  "MissingTo",
+			query: "from=1",
+			buildStubs: func(store *mockdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "RevisionNotFound",
+			query: "from=1&to=9",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Eq(db.GetNoteRevisionParams{NoteID: note.ID, Revision: 1})).
+					Times(1).
+					Return(from, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Eq(db.GetNoteRevisionParams{NoteID: note.ID, Revision: 9})).
+					Times(1).
+					Return(db.NoteRevision{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/notes/%s/diff?%s", note.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRestoreNoteRevisionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	note := randomNote(t, user.ID)
+	kept := randomWeb(t, user.ID)
+	deleted := randomWeb(t, user.ID)
+	revision := randomNoteRevision(t, note, 1, []db.Web{kept, deleted})
+	tags := []db.Tag{randomTag(t, user.ID)}
+
+	restored := note
+	restored.Title = revision.Title
+	restored.Content = revision.Content
+	result := db.TxUpdateNoteResult{
+		Note: restored,
+		Webs: []db.Web{kept},
+		Tags: tags,
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Eq(db.GetNoteRevisionParams{NoteID: note.ID, Revision: 1})).
+					Times(1).
+					Return(revision, nil)
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(kept.ID)).
+					Times(1).
+					Return(kept, nil)
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(deleted.ID)).
+					Times(1).
+					Return(db.Web{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListTagsByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(tags, nil)
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Eq(db.TxUpdateNoteParams{
+						UpdateNoteParams: db.UpdateNoteParams{
+							ID:       note.ID,
+							Title:    revision.Title,
+							Content:  revision.Content,
+							IsPublic: revision.IsPublic,
+						},
+						WebIds:   []uuid.UUID{kept.ID},
+						TagNames: []string{tags[0].Name},
+						AuthorID: user.ID,
+					})).
+					Times(1).
+					Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchNote(t, recorder.Body, restored, []db.Web{kept}, tags)
+			},
+		},
+		{
+			name: "RequestFromAnotherUser",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				user2, _ := randomUser(t)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ErrGetWebDB",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					GetNoteRevision(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(revision, nil)
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Web{}, sql.ErrConnDone)
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/notes/%s/revisions/1/restore", note.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
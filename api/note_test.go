@@ -34,9 +34,11 @@ func TestCeateNote(t *testing.T) {
 		webIds[i] = webs[i].ID
 		bodyWebIds[i] = webs[i].ID.String()
 	}
+	tags := []db.Tag{randomTag(t, user.ID), randomTag(t, user.ID)}
 	result := db.TxCreateNoteResult{
 		Note: note,
 		Webs: webs,
+		Tags: tags,
 	}
 
 	testCases := []struct {
@@ -53,6 +55,8 @@ func TestCeateNote(t *testing.T) {
 				"content":   note.Content,
 				"web_ids":   bodyWebIds,
 				"is_public": note.IsPublic,
+				// blank and duplicated names are dropped
+				"tags": []string{" " + tags[0].Name + " ", tags[1].Name, tags[0].Name, " "},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
@@ -65,7 +69,8 @@ func TestCeateNote(t *testing.T) {
 						Content:  note.Content,
 						IsPublic: note.IsPublic,
 					},
-					WebIds: webIds,
+					WebIds:   webIds,
+					TagNames: []string{tags[0].Name, tags[1].Name},
 				}
 				store.EXPECT().
 					TxCreateNote(gomock.Any(), gomock.Eq(arg)).
@@ -75,7 +80,7 @@ func TestCeateNote(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchNote(t, recorder.Body, note, webs)
+				requireBodyMatchNote(t, recorder.Body, note, webs, tags)
 			},
 		},
 		{
@@ -237,7 +242,26 @@ func TestGetPublicNote(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchNote(t, recorder.Body, note, webs)
+				requireBodyMatchNote(t, recorder.Body, note, webs, nil)
+			},
+		},
+		{
+			name:   "NotPublic",
+			noteID: note.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				private := note
+				private.IsPublic = false
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(private, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errNoteNotPublic.Error())
 			},
 		},
 		{
@@ -282,6 +306,7 @@ func TestGetPublicNote(t *testing.T) {
 			url := fmt.Sprintf("/public_notes/%s", tc.noteID)
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
+			request.Header.Set("Accept", "application/json")
 
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
@@ -319,9 +344,21 @@ func TestListNote(t *testing.T) {
 		}
 	}
 
+	tagRows := make([]db.ListTagsByNoteIdsRow, noteN)
+	for i, note := range notes {
+		tag := randomTag(t, user.ID)
+		tagRows[i] = db.ListTagsByNoteIdsRow{
+			ID:     tag.ID,
+			UserID: tag.UserID,
+			Name:   tag.Name,
+			NoteID: note.ID,
+		}
+	}
+
 	type Query struct {
 		pageID   int
 		pageSize int
+		tags     []string
 	}
 
 	testCases := []struct {
@@ -356,12 +393,49 @@ func TestListNote(t *testing.T) {
 					ListWebByNoteIds(gomock.Any(), gomock.InAnyOrder(noteIDs)).
 					Times(1).
 					Return(webRows, nil)
+				store.EXPECT().
+					ListTagsByNoteIds(gomock.Any(), gomock.InAnyOrder(noteIDs)).
+					Times(1).
+					Return(tagRows, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchNotes(t, recorder.Body, notes, webs)
 			},
 		},
+		{
+			name: "FilterByTags",
+			query: Query{
+				pageID:   1,
+				pageSize: noteN,
+				tags:     []string{"go", "db", "go"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListNotesByUserIdParams{
+					UserID: user.ID,
+					Tags:   []string{"go", "db"},
+					Limit:  int32(noteN),
+					Offset: 0,
+				}
+				store.EXPECT().ListNotesByUserId(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Note{}, nil)
+				store.EXPECT().
+					ListWebByNoteIds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListWebByNoteIdsRow{}, nil)
+				store.EXPECT().
+					ListTagsByNoteIds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListTagsByNoteIdsRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
 		{
 			name: "Unauthorized",
 			query: Query{
@@ -498,6 +572,9 @@ func TestListNote(t *testing.T) {
 			q := request.URL.Query()
 			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
 			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			for _, tag := range tc.query.tags {
+				q.Add("tags", tag)
+			}
 			request.URL.RawQuery = q.Encode()
 
 			tc.setupAuth(t, request, server.tokenMaker)
@@ -523,6 +600,7 @@ func requireBodyMatchNotes(t *testing.T, body *bytes.Buffer, notes []db.Note, we
 		require.NotEmpty(t, noteRes.Title)
 		require.NotEmpty(t, noteRes.Content)
 		require.Equal(t, len(webs)/len(notes), len(noteRes.Webs))
+		require.Len(t, noteRes.Tags, 1)
 	}
 }
 
@@ -706,14 +784,17 @@ func TestPutNote(t *testing.T) {
 		webIds[i] = webs[i].ID
 		bodyWebIds[i] = webs[i].ID.String()
 	}
+	tags := []db.Tag{randomTag(t, user.ID)}
 	result := db.TxUpdateNoteResult{
 		Note: note,
 		Webs: webs,
+		Tags: tags,
 	}
 
 	testCases := []struct {
 		name          string
 		noteID        string
+		ifMatch       string
 		body          gin.H
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
@@ -727,6 +808,7 @@ func TestPutNote(t *testing.T) {
 				"content":   note.Content,
 				"web_ids":   bodyWebIds,
 				"is_public": note.IsPublic,
+				"tags":      []string{tags[0].Name},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
@@ -744,7 +826,9 @@ func TestPutNote(t *testing.T) {
 							Title:   note.Title,
 							Content: note.Content,
 						},
-						WebIds: webIds,
+						WebIds:   webIds,
+						TagNames: []string{tags[0].Name},
+						AuthorID: user.ID,
 					})).
 					Times(1).
 					Return(result, nil)
@@ -752,12 +836,148 @@ func TestPutNote(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchNote(t, recorder.Body, note, webs)
+				requireBodyMatchNote(t, recorder.Body, note, webs, tags)
+			},
+		},
+		{
+			name:    "IfMatch",
+			noteID:  note.ID.String(),
+			ifMatch: `"3"`,
+			body: gin.H{
+				"title":     note.Title,
+				"content":   note.Content,
+				"web_ids":   bodyWebIds,
+				"is_public": note.IsPublic,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+
+				updated := result
+				updated.Note.Version = 4
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Eq(db.TxUpdateNoteParams{
+						UpdateNoteParams: db.UpdateNoteParams{
+							ID:              note.ID,
+							Title:           note.Title,
+							Content:         note.Content,
+							ExpectedVersion: sql.NullInt32{Int32: 3, Valid: true},
+						},
+						WebIds:   webIds,
+						AuthorID: user.ID,
+					})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"4"`, recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:    "WeakIfMatch",
+			noteID:  note.ID.String(),
+			ifMatch: `W/"3"`,
+			body: gin.H{
+				"title":     note.Title,
+				"content":   note.Content,
+				"web_ids":   bodyWebIds,
+				"is_public": note.IsPublic,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TxUpdateNoteParams) (db.TxUpdateNoteResult, error) {
+						require.Equal(t, sql.NullInt32{Int32: 3, Valid: true}, arg.UpdateNoteParams.ExpectedVersion)
+						return result, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "VersionMismatch",
+			noteID:  note.ID.String(),
+			ifMatch: `"2"`,
+			body: gin.H{
+				"title":     note.Title,
+				"content":   note.Content,
+				"web_ids":   bodyWebIds,
+				"is_public": note.IsPublic,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				current := note
+				current.Version = 3
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(2).
+					Return(current, nil)
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TxUpdateNoteResult{}, db.ErrNoteVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+
+				var res noteVersionMismatchResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, int32(3), res.Version)
+				require.Equal(t, `"3"`, res.ETag)
+			},
+		},
+		{
+			name:    "MalformedIfMatch",
+			noteID:  note.ID.String(),
+			ifMatch: "3",
+			body: gin.H{
+				"title":     note.Title,
+				"content":   note.Content,
+				"web_ids":   bodyWebIds,
+				"is_public": note.IsPublic,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(2).
+					Return(note, nil)
+				store.EXPECT().
+					TxUpdateNote(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TxUpdateNoteParams) (db.TxUpdateNoteResult, error) {
+						require.Equal(t, sql.NullInt32{Int32: 0, Valid: true}, arg.UpdateNoteParams.ExpectedVersion)
+						return db.TxUpdateNoteResult{}, db.ErrNoteVersionMismatch
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
 			},
 		},
 	}
 
 	for i := range testCases {
+
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
@@ -780,6 +1000,9 @@ func TestPutNote(t *testing.T) {
 			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
 			require.NoError(t, err)
 
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
 			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
@@ -787,7 +1010,7 @@ func TestPutNote(t *testing.T) {
 	}
 }
 
-func requireBodyMatchNote(t *testing.T, body *bytes.Buffer, note db.Note, webs []db.Web) {
+func requireBodyMatchNote(t *testing.T, body *bytes.Buffer, note db.Note, webs []db.Web, tags []db.Tag) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
@@ -805,6 +1028,7 @@ func requireBodyMatchNote(t *testing.T, body *bytes.Buffer, note db.Note, webs [
 		require.NotEmpty(t, web)
 		require.Equal(t, res.UserID, web.UserID)
 	}
+	require.Equal(t, tagNames(tags), res.Tags)
 }
 
 func randomNote(t *testing.T, userID uuid.UUID) db.Note {
@@ -817,6 +1041,7 @@ func randomNote(t *testing.T, userID uuid.UUID) db.Note {
 		Title:    util.RandomString(6),
 		Content:  util.RandomString(100),
 		IsPublic: false,
+		Version:  1,
 	}
 }
 
@@ -834,6 +1059,7 @@ func TestGetNote(t *testing.T) {
 	for i := 0; i < n; i++ {
 		webs[i] = randomWeb(t, user.ID)
 	}
+	tags := []db.Tag{randomTag(t, user.ID)}
 
 	testCases := []struct {
 		name          string
@@ -857,10 +1083,15 @@ func TestGetNote(t *testing.T) {
 					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
 					Times(1).
 					Return(webs, nil)
+				store.EXPECT().
+					ListTagsByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(tags, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchNote(t, recorder.Body, note, webs)
+				require.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+				requireBodyMatchNote(t, recorder.Body, note, webs, tags)
 			},
 		},
 		{
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+)
+
+const (
+	grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+	deviceStatusPending  = "pending"
+	deviceStatusDenied   = "denied"
+	deviceStatusConsumed = "consumed"
+
+	// slowDownIncrease is added to the interval of a client that polls too fast, RFC 8628 section 3.5
+	slowDownIncrease = 5 * time.Second
+)
+
+// error codes of RFC 6749 section 5.2 and RFC 8628 section 3.5
+const (
+	oauthErrorInvalidClient        = "invalid_client"
+	oauthErrorInvalidGrant         = "invalid_grant"
+	oauthErrorUnsupportedGrantType = "unsupported_grant_type"
+	oauthErrorAuthorizationPending = "authorization_pending"
+	oauthErrorSlowDown             = "slow_down"
+	oauthErrorAccessDenied         = "access_denied"
+	oauthErrorExpiredToken         = "expired_token"
+)
+
+var errInvalidUserCode = errors.New("user code is unknown, expired or already answered")
+
+// oauthErrorResponse is the error body OAuth clients expect instead of errorResponse
+func oauthErrorResponse(code string, description string) gin.H {
+	return gin.H{
+		"error":             code,
+		"error_description": description,
+	}
+}
+
+type deviceCodeRequest struct {
+	ClientID string `json:"client_id" form:"client_id" binding:"required"`
+}
+
+type deviceCodeResponse struct {
+	DeviceCode string `json:"device_code" binding:"required"`
+	// UserCode is shown to the user, who types it on the verification page
+	UserCode                string `json:"user_code" binding:"required"`
+	VerificationURI         string `json:"verification_uri" binding:"required"`
+	VerificationURIComplete string `json:"verification_uri_complete" binding:"required"`
+	// seconds
+	ExpiresIn int64 `json:"expires_in" binding:"required"`
+	// seconds to wait between two polls of POST /oauth/token
+	Interval int64 `json:"interval" binding:"required"`
+}
+
+// Starts the device authorization flow of RFC 8628 for the extension.
+// The body can be form encoded as the RFC expects, or JSON.
+// @Param request body api.deviceCodeRequest true "query params"
+// @Success 200 {object} api.deviceCodeResponse
+// @Router /oauth/device/code [post]
+// @Tags oauth
+func (server *Server) createDeviceCode(ctx *gin.Context) {
+	var req deviceCodeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.ClientID != server.config.OAuthClientID {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(oauthErrorInvalidClient, "unknown client"))
+		return
+	}
+
+	deviceCode, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	userCode, err := util.NewUserCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateDeviceAuthorization(ctx, db.CreateDeviceAuthorizationParams{
+		DeviceCodeHash:  util.HashSecretToken(deviceCode),
+		UserCode:        util.NormalizeUserCode(userCode),
+		ClientID:        req.ClientID,
+		IntervalSeconds: int32(server.config.DeviceCodeInterval.Seconds()),
+		ExpiresAt:       time.Now().Add(server.config.DeviceCodeDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	verificationURI := server.config.FrontURL + "/device"
+	ctx.JSON(http.StatusOK, deviceCodeResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?" + url.Values{"user_code": {userCode}}.Encode(),
+		ExpiresIn:               int64(server.config.DeviceCodeDuration.Seconds()),
+		Interval:                int64(server.config.DeviceCodeInterval.Seconds()),
+	})
+}
+
+type answerDeviceCodeRequest struct {
+	UserCode string `json:"user_code" binding:"required"`
+}
+
+// Lets the device that shows the user code log in as the authenticated user.
+// @Param request body api.answerDeviceCodeRequest true "query params"
+// @Success 200 {} {}
+// @Router /oauth/device/approve [post]
+// @Tags oauth
+// @Security AccessToken
+func (server *Server) approveDeviceCode(ctx *gin.Context) {
+	var req answerDeviceCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	_, err := server.store.ApproveDeviceAuthorization(ctx, db.ApproveDeviceAuthorizationParams{
+		UserCode: util.NormalizeUserCode(req.UserCode),
+		UserID:   uuid.NullUUID{UUID: authPayload.UserID, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errInvalidUserCode))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// Refuses the device that shows the user code, its next poll gets access_denied.
+// @Param request body api.answerDeviceCodeRequest true "query params"
+// @Success 200 {} {}
+// @Router /oauth/device/deny [post]
+// @Tags oauth
+// @Security AccessToken
+func (server *Server) denyDeviceCode(ctx *gin.Context) {
+	var req answerDeviceCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	_, err := server.store.DenyDeviceAuthorization(ctx, db.DenyDeviceAuthorizationParams{
+		UserCode: util.NormalizeUserCode(req.UserCode),
+		UserID:   uuid.NullUUID{UUID: authPayload.UserID, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errInvalidUserCode))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+type oauthTokenRequest struct {
+	GrantType  string `json:"grant_type" form:"grant_type" binding:"required"`
+	DeviceCode string `json:"device_code" form:"device_code" binding:"required"`
+	ClientID   string `json:"client_id" form:"client_id" binding:"required"`
+}
+
+// oauthTokenResponse is the login response with the fields RFC 6749 section 5.1 requires
+type oauthTokenResponse struct {
+	loginUserRedirectResponse
+	TokenType string `json:"token_type" binding:"required"`
+	// seconds until the access token expires
+	ExpiresIn int64 `json:"expires_in" binding:"required"`
+}
+
+// Polled by the extension with the device code until the user answers.
+// Errors follow RFC 8628: authorization_pending, slow_down, access_denied and expired_token.
+// @Param request body api.oauthTokenRequest true "query params"
+// @Success 200 {object} api.oauthTokenResponse
+// @Router /oauth/token [post]
+// @Tags oauth
+func (server *Server) oauthToken(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var req oauthTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.GrantType != grantTypeDeviceCode {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorUnsupportedGrantType, "only the device code grant is supported"))
+		return
+	}
+	if req.ClientID != server.config.OAuthClientID {
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(oauthErrorInvalidClient, "unknown client"))
+		return
+	}
+
+	deviceCodeHash := util.HashSecretToken(req.DeviceCode)
+	authorization, err := server.store.GetDeviceAuthorization(ctx, deviceCodeHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, "unknown device code"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if authorization.ClientID != req.ClientID || authorization.Status == deviceStatusConsumed {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, "device code was issued to another client or already used"))
+		return
+	}
+	if time.Now().After(authorization.ExpiresAt) {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorExpiredToken, "device code has expired"))
+		return
+	}
+
+	interval := time.Duration(authorization.IntervalSeconds) * time.Second
+	tooFast := authorization.LastPolledAt.Valid && time.Since(authorization.LastPolledAt.Time) < interval
+	if tooFast {
+		interval += slowDownIncrease
+	}
+	err = server.store.UpdateDeviceAuthorizationPoll(ctx, db.UpdateDeviceAuthorizationPollParams{
+		DeviceCodeHash:  deviceCodeHash,
+		IntervalSeconds: int32(interval.Seconds()),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if tooFast {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorSlowDown, "polling too fast, the interval is increased by 5 seconds"))
+		return
+	}
+
+	switch authorization.Status {
+	case deviceStatusPending:
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorAuthorizationPending, "the user hasn't answered yet"))
+		return
+	case deviceStatusDenied:
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorAccessDenied, "the user denied the device"))
+		return
+	}
+
+	// two polls can see the approval at the same time, only one of them gets the tokens
+	authorization, err = server.store.ConsumeDeviceAuthorization(ctx, deviceCodeHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, "device code was already used"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, authorization.UserID.UUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorInvalidGrant, "the user who approved the device is gone"))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.BlockedAt.Valid {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(oauthErrorAccessDenied, "the account is blocked"))
+		return
+	}
+
+	rsp, err := server.createLoginSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oauthTokenResponse{
+		loginUserRedirectResponse: rsp,
+		TokenType:                 "Bearer",
+		ExpiresIn:                 int64(time.Until(rsp.AccessTokenExpiresAt).Seconds()),
+	})
+}
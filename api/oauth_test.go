@@ -0,0 +1,447 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testOAuthClientID = "inkclip-extension"
+
+func newOAuthTestServer(t *testing.T, store *mockdb.MockStore) *Server {
+	server := newTestServer(t, store)
+	server.config.OAuthClientID = testOAuthClientID
+	server.config.DeviceCodeDuration = 10 * time.Minute
+	server.config.DeviceCodeInterval = 5 * time.Second
+	return server
+}
+
+func requireOAuthError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) {
+	require.Equal(t, status, recorder.Code)
+
+	var res map[string]string
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Equal(t, code, res["error"])
+}
+
+func TestCreateDeviceCodeAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		clientID      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			clientID: testOAuthClientID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateDeviceAuthorization(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateDeviceAuthorizationParams) (db.DeviceAuthorization, error) {
+						require.Equal(t, testOAuthClientID, arg.ClientID)
+						require.Len(t, arg.UserCode, 8)
+						require.Equal(t, int32(5), arg.IntervalSeconds)
+						return db.DeviceAuthorization{DeviceCodeHash: arg.DeviceCodeHash}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res deviceCodeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.DeviceCode)
+				require.Regexp(t, "^[A-Z]{4}-[A-Z]{4}$", res.UserCode)
+				require.True(t, strings.HasSuffix(res.VerificationURI, "/device"))
+				require.Contains(t, res.VerificationURIComplete, res.UserCode)
+				require.Equal(t, int64(600), res.ExpiresIn)
+				require.Equal(t, int64(5), res.Interval)
+			},
+		},
+		{
+			name:     "UnknownClient",
+			clientID: "unknown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateDeviceAuthorization(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, oauthErrorInvalidClient)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newOAuthTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			// the RFC has the client send a form
+			body := url.Values{"client_id": {tc.clientID}}.Encode()
+			request, err := http.NewRequest(http.MethodPost, "/oauth/device/code", strings.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveDeviceCodeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		path          string
+		userCode      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Approve",
+			path:     "/oauth/device/approve",
+			userCode: "bcdf-ghjk",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveDeviceAuthorization(gomock.Any(), gomock.Eq(db.ApproveDeviceAuthorizationParams{
+						UserCode: "BCDFGHJK",
+						UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+					})).
+					Times(1).
+					Return(db.DeviceAuthorization{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Deny",
+			path:     "/oauth/device/deny",
+			userCode: "BCDF-GHJK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DenyDeviceAuthorization(gomock.Any(), gomock.Eq(db.DenyDeviceAuthorizationParams{
+						UserCode: "BCDFGHJK",
+						UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+					})).
+					Times(1).
+					Return(db.DeviceAuthorization{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownCode",
+			path:     "/oauth/device/approve",
+			userCode: "BCDF-GHJK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveDeviceAuthorization(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.DeviceAuthorization{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newOAuthTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"user_code": tc.userCode})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOAuthTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	deviceCode, err := util.NewSecretToken()
+	require.NoError(t, err)
+	deviceCodeHash := util.HashSecretToken(deviceCode)
+
+	authorization := func(status string) db.DeviceAuthorization {
+		return db.DeviceAuthorization{
+			DeviceCodeHash:  deviceCodeHash,
+			UserCode:        "BCDFGHJK",
+			ClientID:        testOAuthClientID,
+			Status:          status,
+			UserID:          uuid.NullUUID{UUID: user.ID, Valid: status != deviceStatusPending},
+			IntervalSeconds: 5,
+			LastPolledAt:    sql.NullTime{Time: time.Now().Add(-10 * time.Second), Valid: true},
+			ExpiresAt:       time.Now().Add(time.Minute),
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(authorization("approved"), nil)
+				store.EXPECT().
+					UpdateDeviceAuthorizationPoll(gomock.Any(), gomock.Eq(db.UpdateDeviceAuthorizationPollParams{
+						DeviceCodeHash:  deviceCodeHash,
+						IntervalSeconds: 5,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ConsumeDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(authorization(deviceStatusConsumed), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, UserID: arg.UserID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+				var res oauthTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.AccessToken)
+				require.NotEmpty(t, res.RefreshToken)
+				require.Equal(t, "Bearer", res.TokenType)
+				require.Equal(t, user.ID, res.User.ID)
+			},
+		},
+		{
+			name: "Pending",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(authorization(deviceStatusPending), nil)
+				store.EXPECT().
+					UpdateDeviceAuthorizationPoll(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ConsumeDeviceAuthorization(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorAuthorizationPending)
+			},
+		},
+		{
+			name: "SlowDown",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				polled := authorization(deviceStatusPending)
+				polled.LastPolledAt = sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true}
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(polled, nil)
+				store.EXPECT().
+					UpdateDeviceAuthorizationPoll(gomock.Any(), gomock.Eq(db.UpdateDeviceAuthorizationPollParams{
+						DeviceCodeHash:  deviceCodeHash,
+						IntervalSeconds: 10,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorSlowDown)
+			},
+		},
+		{
+			name: "Denied",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(authorization(deviceStatusDenied), nil)
+				store.EXPECT().
+					UpdateDeviceAuthorizationPoll(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorAccessDenied)
+			},
+		},
+		{
+			name: "Expired",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := authorization("approved")
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorExpiredToken)
+			},
+		},
+		{
+			name: "AlreadyUsed",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(authorization(deviceStatusConsumed), nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "ConsumedByAnotherPoll",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(authorization("approved"), nil)
+				store.EXPECT().
+					UpdateDeviceAuthorizationPoll(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ConsumeDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(db.DeviceAuthorization{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "UnknownDeviceCode",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Eq(deviceCodeHash)).
+					Times(1).
+					Return(db.DeviceAuthorization{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorInvalidGrant)
+			},
+		},
+		{
+			name: "UnsupportedGrantType",
+			body: url.Values{"grant_type": {"password"}, "device_code": {deviceCode}, "client_id": {testOAuthClientID}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusBadRequest, oauthErrorUnsupportedGrantType)
+			},
+		},
+		{
+			name: "UnknownClient",
+			body: url.Values{"grant_type": {grantTypeDeviceCode}, "device_code": {deviceCode}, "client_id": {"unknown"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetDeviceAuthorization(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireOAuthError(t, recorder, http.StatusUnauthorized, oauthErrorInvalidClient)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newOAuthTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(tc.body.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,371 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/oidc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/lib/pq"
+)
+
+// oidcStateDuration is how long the user has to log in at the provider
+const oidcStateDuration = 10 * time.Minute
+
+var (
+	errOIDCNotConfigured     = errors.New("sign in with an external provider is not configured")
+	errInvalidOIDCState      = errors.New("state is unknown, expired or already used")
+	errOIDCEmailNotVerified  = errors.New("the provider didn't give a verified email")
+	errIdentityNotLinked     = errors.New("an account already uses this email, log in with the password and link the provider from the settings")
+	errIdentityAlreadyLinked = errors.New("this identity is linked to another account")
+)
+
+type oidcAuthorizationResponse struct {
+	// AuthorizationURL is where to send the browser to log in at the provider
+	AuthorizationURL string `json:"authorization_url" binding:"required"`
+}
+
+type oidcCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+type identityResponse struct {
+	ID        uuid.UUID `json:"id" binding:"required"`
+	Issuer    string    `json:"issuer" binding:"required"`
+	Subject   string    `json:"subject" binding:"required"`
+	Email     string    `json:"email" binding:"required"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
+}
+
+func newIdentityResponse(identity db.UserIdentity) identityResponse {
+	return identityResponse{
+		ID:        identity.ID,
+		Issuer:    identity.Issuer,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+}
+
+// startOIDC stores what the callback needs and responds the authorization url.
+// userID is set when a logged in user links an identity.
+func (server *Server) startOIDC(ctx *gin.Context, userID uuid.NullUUID) {
+	if server.oidcProvider == nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(errOIDCNotConfigured))
+		return
+	}
+
+	state, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	nonce, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	codeVerifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authorizationURL, err := server.oidcProvider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(codeVerifier))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateOidcState(ctx, db.CreateOidcStateParams{
+		StateHash:    util.HashSecretToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(oidcStateDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, oidcAuthorizationResponse{AuthorizationURL: authorizationURL})
+}
+
+// finishOIDC uses up the state, then exchanges the code and verifies the ID token it gives.
+// The state must have been started by userID. It writes the error response and returns false when the login can't go on.
+func (server *Server) finishOIDC(ctx *gin.Context, req oidcCallbackRequest, userID uuid.NullUUID) (*oidc.IDToken, bool) {
+	if server.oidcProvider == nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(errOIDCNotConfigured))
+		return nil, false
+	}
+
+	state, err := server.store.UseOidcState(ctx, util.HashSecretToken(req.State))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidOIDCState))
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return nil, false
+	}
+	// a login state can't link, and a link state can't log in or link to someone else
+	if state.UserID != userID {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidOIDCState))
+		return nil, false
+	}
+
+	rawIDToken, err := server.oidcProvider.Exchange(ctx, req.Code, state.CodeVerifier)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, false
+	}
+
+	idToken, err := server.oidcProvider.VerifyIDToken(ctx, rawIDToken, state.Nonce)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return nil, false
+	}
+	return idToken, true
+}
+
+// Starts a login at the OpenID Connect provider.
+// The front keeps the state to check it against the one the provider redirects with, then posts the code to POST /oidc/callback.
+// @Success 200 {object} api.oidcAuthorizationResponse
+// @Router /oidc/login [get]
+// @Tags user
+func (server *Server) oidcLogin(ctx *gin.Context) {
+	server.startOIDC(ctx, uuid.NullUUID{})
+}
+
+// Logs in with the code the provider redirected with.
+// An identity seen for the first time registers a user with the verified email of the provider,
+// unless a user already has the email, who has to link the identity from the settings instead.
+// @Param request body api.oidcCallbackRequest true "query params"
+// @Success 200 {object} api.loginUserRedirectResponse
+// @Success 202 {object} api.loginUserChallengeResponse
+// @Router /oidc/callback [post]
+// @Tags user
+func (server *Server) oidcCallback(ctx *gin.Context) {
+	var req oidcCallbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	idToken, ok := server.finishOIDC(ctx, req, uuid.NullUUID{})
+	if !ok {
+		return
+	}
+
+	identity, err := server.store.GetUserIdentityBySubject(ctx, db.GetUserIdentityBySubjectParams{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+	})
+	if err == nil {
+		user, err := server.store.GetUser(ctx, identity.UserID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		server.respondLogin(ctx, user)
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if idToken.Email == "" || !idToken.EmailVerified {
+		ctx.JSON(http.StatusForbidden, errorResponse(errOIDCEmailNotVerified))
+		return
+	}
+
+	// linking by email would hand the account to whoever controls the email at the provider
+	_, err = server.store.GetUserByEmail(ctx, idToken.Email)
+	if err == nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(errIdentityNotLinked))
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if server.abortIfEmailRecentlyDeleted(ctx, idToken.Email) {
+		return
+	}
+
+	// nobody knows the password, it can be set with POST /password/forgot
+	password, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	hashedPassword, err := util.HashPassword(password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.TxCreateUserWithIdentity(ctx, db.TxCreateUserWithIdentityParams{
+		Email:          idToken.Email,
+		HashedPassword: hashedPassword,
+		Issuer:         idToken.Issuer,
+		Subject:        idToken.Subject,
+	})
+	if err != nil {
+		switch err {
+		case db.ErrEmailAlreadyUsed:
+			ctx.JSON(http.StatusForbidden, errorResponse(errIdentityNotLinked))
+			return
+		case db.ErrIdentityAlreadyLinked:
+			ctx.JSON(http.StatusForbidden, errorResponse(errIdentityAlreadyLinked))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.respondLogin(ctx, user)
+}
+
+// Starts linking an identity at the OpenID Connect provider to the authenticated user.
+// The front posts the code the provider redirects with to POST /users/me/identities.
+// @Success 200 {object} api.oidcAuthorizationResponse
+// @Router /users/me/identities/link [post]
+// @Tags user
+// @Security AccessToken
+func (server *Server) startLinkIdentity(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	server.startOIDC(ctx, uuid.NullUUID{UUID: authPayload.UserID, Valid: true})
+}
+
+// Links the identity of the code the provider redirected with, it can log in as the user afterwards.
+// @Param request body api.oidcCallbackRequest true "query params"
+// @Success 200 {object} api.identityResponse
+// @Router /users/me/identities [post]
+// @Tags user
+// @Security AccessToken
+func (server *Server) linkIdentity(ctx *gin.Context) {
+	var req oidcCallbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	idToken, ok := server.finishOIDC(ctx, req, uuid.NullUUID{UUID: authPayload.UserID, Valid: true})
+	if !ok {
+		return
+	}
+
+	identity, err := server.store.GetUserIdentityBySubject(ctx, db.GetUserIdentityBySubjectParams{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+	})
+	if err == nil {
+		if identity.UserID != authPayload.UserID {
+			ctx.JSON(http.StatusForbidden, errorResponse(errIdentityAlreadyLinked))
+			return
+		}
+		ctx.JSON(http.StatusOK, newIdentityResponse(identity))
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	identity, err = server.store.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		UserID:  authPayload.UserID,
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   idToken.Email,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(errIdentityAlreadyLinked))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newIdentityResponse(identity))
+}
+
+type listIdentityResponse struct {
+	Identities []identityResponse `json:"identities"`
+}
+
+// @Success 200 {object} api.listIdentityResponse
+// @Router /users/me/identities [get]
+// @Tags user
+// @Security AccessToken
+func (server *Server) listIdentity(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	identities, err := server.store.ListUserIdentitiesByUserId(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listIdentityResponse{
+		Identities: make([]identityResponse, len(identities)),
+	}
+	for i, identity := range identities {
+		res.Identities[i] = newIdentityResponse(identity)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+type unlinkIdentityRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// @Param id path string true "Identity ID"
+// @Success 200 {object} api.identityResponse
+// @Router /users/me/identities/{id} [delete]
+// @Tags user
+// @Security AccessToken
+func (server *Server) unlinkIdentity(ctx *gin.Context) {
+	var req unlinkIdentityRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(req.ID)
+	identity, err := server.store.GetUserIdentity(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if identity.UserID != authPayload.UserID {
+		err := errors.New("identity doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteUserIdentity(ctx, identity.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newIdentityResponse(identity))
+}
@@ -0,0 +1,521 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/oidc"
+	"github.com/inkclip/backend/oidc/oidctest"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newOIDCTestServer(t *testing.T, store *mockdb.MockStore) (*Server, *oidctest.Provider) {
+	stub := oidctest.NewProvider(util.RandomString(10), util.RandomString(20))
+	t.Cleanup(stub.Close)
+
+	server := newTestServer(t, store)
+	server.oidcProvider = oidc.NewProvider(oidc.Config{
+		Issuer:       stub.Issuer(),
+		ClientID:     stub.ClientID,
+		ClientSecret: stub.ClientSecret,
+		RedirectURL:  util.RandomURL() + "/oidc/callback",
+	}, nil)
+	return server, stub
+}
+
+// startOIDCAtStub starts the flow, logs in at the stand-in provider and returns what it redirected with.
+// UseOidcState gives back the state that was stored.
+func startOIDCAtStub(t *testing.T, server *Server, store *mockdb.MockStore, stub *oidctest.Provider, method string, path string, userID uuid.UUID) gin.H {
+	var stored db.CreateOidcStateParams
+	store.EXPECT().
+		CreateOidcState(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, arg db.CreateOidcStateParams) (db.OidcState, error) {
+			stored = arg
+			return db.OidcState{StateHash: arg.StateHash}, nil
+		})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(method, path, nil)
+	require.NoError(t, err)
+	if userID != uuid.Nil {
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, userID, time.Minute)
+	}
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res oidcAuthorizationResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+
+	code, state, err := stub.Authorize(res.AuthorizationURL)
+	require.NoError(t, err)
+	require.Equal(t, stored.StateHash, util.HashSecretToken(state))
+
+	store.EXPECT().
+		UseOidcState(gomock.Any(), gomock.Eq(stored.StateHash)).
+		Times(1).
+		Return(db.OidcState{
+			StateHash:    stored.StateHash,
+			Nonce:        stored.Nonce,
+			CodeVerifier: stored.CodeVerifier,
+			UserID:       stored.UserID,
+		}, nil)
+
+	return gin.H{"code": code, "state": state}
+}
+
+func TestOIDCCallbackAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	subject := util.RandomString(12)
+
+	testCases := []struct {
+		name          string
+		oidcUser      oidctest.User
+		startPath     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "LinkedIdentity",
+			oidcUser: oidctest.User{Subject: subject, Email: user.Email, EmailVerified: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.GetUserIdentityBySubjectParams) (db.UserIdentity, error) {
+						require.Equal(t, subject, arg.Subject)
+						return db.UserIdentity{ID: uuid.New(), UserID: user.ID, Issuer: arg.Issuer, Subject: arg.Subject}, nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, UserID: arg.UserID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res loginUserRedirectResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.AccessToken)
+				require.Equal(t, user.ID, res.User.ID)
+			},
+		},
+		{
+			name:     "LinkedIdentityWithTwoFactor",
+			oidcUser: oidctest.User{Subject: subject},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{UserID: user.ID}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{UserID: user.ID, EnabledAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil)
+				store.EXPECT().
+					CreateLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginChallenge{ExpiresAt: time.Now().Add(time.Minute)}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:     "NewUser",
+			oidcUser: oidctest.User{Subject: subject, Email: user.Email, EmailVerified: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+					TxCreateUserWithIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TxCreateUserWithIdentityParams) (db.User, error) {
+						require.Equal(t, user.Email, arg.Email)
+						require.Equal(t, subject, arg.Subject)
+						require.NotEmpty(t, arg.HashedPassword)
+						return user, nil
+					})
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, UserID: arg.UserID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "EmailOfAnotherAccount",
+			oidcUser: oidctest.User{Subject: subject, Email: user.Email, EmailVerified: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					TxCreateUserWithIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "EmailNotVerified",
+			oidcUser: oidctest.User{Subject: subject, Email: user.Email, EmailVerified: false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().
+					TxCreateUserWithIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server, stub := newOIDCTestServer(t, store)
+			stub.SetNextUser(tc.oidcUser)
+
+			body := startOIDCAtStub(t, server, store, stub, http.MethodGet, "/oidc/login", uuid.Nil)
+			tc.buildStubs(store)
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/oidc/callback", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestOIDCCallbackInvalidState(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Unknown",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseOidcState(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OidcState{}, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "StartedForLinking",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UseOidcState(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OidcState{UserID: uuid.NullUUID{UUID: user.ID, Valid: true}}, nil)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+				GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+				Times(0)
+
+			server, _ := newOIDCTestServer(t, store)
+
+			data, err := json.Marshal(gin.H{"code": "code", "state": "state"})
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/oidc/callback", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+func TestOIDCNotConfigured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateOidcState(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	server := newTestServer(t, store)
+	require.Nil(t, server.oidcProvider)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/oidc/login", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestLinkIdentityAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	subject := util.RandomString(12)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateUserIdentityParams) (db.UserIdentity, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, subject, arg.Subject)
+						return db.UserIdentity{ID: uuid.New(), UserID: arg.UserID, Issuer: arg.Issuer, Subject: arg.Subject, Email: arg.Email}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res identityResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, subject, res.Subject)
+			},
+		},
+		{
+			name: "LinkedToAnotherUser",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentityBySubject(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserIdentity{ID: uuid.New(), UserID: uuid.New()}, nil)
+				store.EXPECT().
+					CreateUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server, stub := newOIDCTestServer(t, store)
+			stub.SetNextUser(oidctest.User{Subject: subject, Email: user.Email, EmailVerified: true})
+
+			body := startOIDCAtStub(t, server, store, stub, http.MethodPost, "/users/me/identities/link", user.ID)
+			tc.buildStubs(store)
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodPost, "/users/me/identities", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLinkIdentityStateOfAnotherUser(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server, stub := newOIDCTestServer(t, store)
+	stub.SetNextUser(oidctest.User{Subject: util.RandomString(12)})
+
+	body := startOIDCAtStub(t, server, store, stub, http.MethodPost, "/users/me/identities/link", other.ID)
+	store.EXPECT().
+		CreateUserIdentity(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/users/me/identities", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestUnlinkIdentityAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	identity := db.UserIdentity{
+		ID:      uuid.New(),
+		UserID:  user.ID,
+		Issuer:  util.RandomURL(),
+		Subject: util.RandomString(12),
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Eq(identity.ID)).
+					Times(1).
+					Return(identity, nil)
+				store.EXPECT().
+					DeleteUserIdentity(gomock.Any(), gomock.Eq(identity.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Eq(identity.ID)).
+					Times(1).
+					Return(db.UserIdentity{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "IdentityOfAnotherUser",
+			buildStubs: func(store *mockdb.MockStore) {
+				other := identity
+				other.UserID = uuid.New()
+				store.EXPECT().
+					GetUserIdentity(gomock.Any(), gomock.Eq(identity.ID)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					DeleteUserIdentity(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/me/identities/%s", identity.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// Sends a link to reset the password.
+// The response is the same whether the email belongs to a user or not, the mail is sent after it.
+// Only one link a PASSWORD_RESET_INTERVAL is sent to an address, the requests in between are 200 without a mail.
+// @Param request body api.forgotPasswordRequest true "query params"
+// @Success 200 {} {}
+// @Router /password/forgot [post]
+// @Tags user
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the limit is kept for any address, one without an account mustn't answer faster
+	_, err := server.store.ClaimMailSend(ctx, db.ClaimMailSendParams{
+		Key:        passwordResetThrottleKey(req.Email),
+		SentBefore: time.Now().Add(-server.config.PasswordResetInterval),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, gin.H{})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.runInBackground(func(bgCtx context.Context) error {
+		return server.sendPasswordReset(bgCtx, req.Email)
+	})
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+func passwordResetThrottleKey(email string) string {
+	return "password_reset:" + strings.ToLower(email)
+}
+
+// sendPasswordReset stores a new reset token of the user of the email and mails its link, nothing is sent when there is no such user
+func (server *Server) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := server.store.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	token, err := util.NewSecretToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		TokenHash: util.HashSecretToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(server.config.PasswordResetTokenDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	mailArg := server.mailClient.PasswordResetMailContent(user.Email, token)
+	return server.mailClient.Send(mailArg)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// Sets the new password with the token of the link sent by /password/forgot.
+// Every session of the user is logged out.
+// @Param request body api.resetPasswordRequest true "query params"
+// @Success 200 {object} api.userResponse
+// @Router /password/reset [post]
+// @Tags user
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.TxResetPassword(ctx, db.TxResetPasswordParams{
+		TokenHash:      util.HashSecretToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		if err == db.ErrInvalidPasswordResetToken {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
+// Every session of the user is logged out, including the one making the request.
+// A new session is started for the caller, tokens issued before stop working.
+// @Param request body api.changePasswordRequest true "query params"
+// @Success 200 {object} api.loginUserRedirectResponse
+// @Router /users/me/password [put]
+// @Tags user
+// @Security AccessToken
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// not 401, the access token itself is fine
+	if err := util.CheckPassword(req.CurrentPassword, user.HashedPassword); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err = server.store.TxChangePassword(ctx, db.TxChangePasswordParams{
+		UserID:         user.ID,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.createLoginSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
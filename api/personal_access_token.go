@@ -0,0 +1,198 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+)
+
+// personalAccessTokenPrefix tells personal access tokens apart from JWTs in the authorization header
+const personalAccessTokenPrefix = "inkclip_pat_"
+
+const (
+	scopeWebsRead   = "webs:read"
+	scopeWebsWrite  = "webs:write"
+	scopeNotesRead  = "notes:read"
+	scopeNotesWrite = "notes:write"
+	scopeTagsRead   = "tags:read"
+	scopeTagsWrite  = "tags:write"
+)
+
+func isPersonalAccessToken(accessToken string) bool {
+	return strings.HasPrefix(accessToken, personalAccessTokenPrefix)
+}
+
+type personalAccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id" binding:"required"`
+	Name       string     `json:"name" binding:"required"`
+	Scopes     []string   `json:"scopes" binding:"required"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	// never expires when empty
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" binding:"required"`
+}
+
+func newPersonalAccessTokenResponse(pat db.PersonalAccessToken) personalAccessTokenResponse {
+	res := personalAccessTokenResponse{
+		ID:        pat.ID,
+		Name:      pat.Name,
+		Scopes:    pat.Scopes,
+		CreatedAt: pat.CreatedAt,
+	}
+	if pat.LastUsedAt.Valid {
+		res.LastUsedAt = &pat.LastUsedAt.Time
+	}
+	if pat.ExpiresAt.Valid {
+		res.ExpiresAt = &pat.ExpiresAt.Time
+	}
+	return res
+}
+
+type createPersonalAccessTokenRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=webs:read webs:write notes:read notes:write tags:read tags:write"`
+	// RFC3339, the token never expires when empty
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type createPersonalAccessTokenResponse struct {
+	// Token is only shown here, it is stored hashed
+	Token               string                      `json:"token" binding:"required"`
+	PersonalAccessToken personalAccessTokenResponse `json:"personal_access_token" binding:"required"`
+}
+
+// Creates a token that is sent as "Bearer <token>" like an access token, but only for the routes its scopes allow.
+// It can't manage the account, and it is deleted when the password changes.
+// @Param request body api.createPersonalAccessTokenRequest true "query params"
+// @Success 200 {object} api.createPersonalAccessTokenResponse
+// @Router /users/me/tokens [post]
+// @Tags user
+// @Security AccessToken
+func (server *Server) createPersonalAccessToken(ctx *gin.Context) {
+	var req createPersonalAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			err := errors.New("expires_at must be in the future")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		expiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	secret, err := util.NewSecretToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	accessToken := personalAccessTokenPrefix + secret
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	pat, err := server.store.CreatePersonalAccessToken(ctx, db.CreatePersonalAccessTokenParams{
+		UserID:    authPayload.UserID,
+		Name:      req.Name,
+		TokenHash: util.HashSecretToken(accessToken),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createPersonalAccessTokenResponse{
+		Token:               accessToken,
+		PersonalAccessToken: newPersonalAccessTokenResponse(pat),
+	})
+}
+
+type listPersonalAccessTokenResponse struct {
+	PersonalAccessTokens []personalAccessTokenResponse `json:"personal_access_tokens"`
+}
+
+// Lists the personal access tokens, newest first. Expired ones are kept until they are revoked.
+// @Success 200 {object} api.listPersonalAccessTokenResponse
+// @Router /users/me/tokens [get]
+// @Tags user
+// @Security AccessToken
+func (server *Server) listPersonalAccessToken(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	pats, err := server.store.ListPersonalAccessTokensByUserId(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listPersonalAccessTokenResponse{
+		PersonalAccessTokens: make([]personalAccessTokenResponse, len(pats)),
+	}
+	for i, pat := range pats {
+		res.PersonalAccessTokens[i] = newPersonalAccessTokenResponse(pat)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+type revokePersonalAccessTokenRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// @Param id path string true "Personal access token ID"
+// @Success 200 {object} api.personalAccessTokenResponse
+// @Router /users/me/tokens/{id} [delete]
+// @Tags user
+// @Security AccessToken
+func (server *Server) revokePersonalAccessToken(ctx *gin.Context) {
+	var req revokePersonalAccessTokenRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(req.ID)
+	pat, err := server.store.GetPersonalAccessToken(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if pat.UserID != authPayload.UserID {
+		err := errors.New("personal access token doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeletePersonalAccessToken(ctx, pat.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPersonalAccessTokenResponse(pat))
+}
@@ -0,0 +1,433 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPersonalAccessToken(t *testing.T, userID uuid.UUID, scopes ...string) (db.PersonalAccessToken, string) {
+	secret, err := util.NewSecretToken()
+	require.NoError(t, err)
+	accessToken := personalAccessTokenPrefix + secret
+
+	return db.PersonalAccessToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      util.RandomString(8),
+		TokenHash: util.HashSecretToken(accessToken),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}, accessToken
+}
+
+func TestCreatePersonalAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":   "extension",
+				"scopes": []string{scopeWebsWrite, scopeTagsRead, scopeWebsWrite},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, []string{scopeWebsWrite, scopeTagsRead}, arg.Scopes)
+						require.False(t, arg.ExpiresAt.Valid)
+						return db.PersonalAccessToken{
+							ID:        uuid.New(),
+							UserID:    arg.UserID,
+							Name:      arg.Name,
+							TokenHash: arg.TokenHash,
+							Scopes:    arg.Scopes,
+							CreatedAt: time.Now(),
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res createPersonalAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.True(t, isPersonalAccessToken(res.Token))
+				require.Equal(t, "extension", res.PersonalAccessToken.Name)
+				require.Nil(t, res.PersonalAccessToken.ExpiresAt)
+			},
+		},
+		{
+			name: "WithExpiry",
+			body: gin.H{
+				"name":       "script",
+				"scopes":     []string{scopeNotesRead},
+				"expires_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+						require.True(t, arg.ExpiresAt.Valid)
+						return db.PersonalAccessToken{ID: uuid.New(), UserID: arg.UserID, Scopes: arg.Scopes, ExpiresAt: arg.ExpiresAt}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res createPersonalAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotNil(t, res.PersonalAccessToken.ExpiresAt)
+			},
+		},
+		{
+			name: "UnknownScope",
+			body: gin.H{
+				"name":   "extension",
+				"scopes": []string{"users:write"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoScope",
+			body: gin.H{
+				"name":   "extension",
+				"scopes": []string{},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryInThePast",
+			body: gin.H{
+				"name":       "extension",
+				"scopes":     []string{scopeWebsRead},
+				"expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreatePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/tokens", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPersonalAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	pat, _ := randomPersonalAccessToken(t, user.ID, scopeWebsRead)
+	pat.LastUsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListPersonalAccessTokensByUserId(gomock.Any(), gomock.Eq(user.ID)).
+		Times(1).
+		Return([]db.PersonalAccessToken{pat}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/users/me/tokens", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var res listPersonalAccessTokenResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	require.Len(t, res.PersonalAccessTokens, 1)
+	require.Equal(t, pat.ID, res.PersonalAccessTokens[0].ID)
+	require.NotNil(t, res.PersonalAccessTokens[0].LastUsedAt)
+	require.NotContains(t, recorder.Body.String(), pat.TokenHash)
+}
+
+func TestRevokePersonalAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	pat, _ := randomPersonalAccessToken(t, user.ID, scopeWebsRead)
+
+	testCases := []struct {
+		name          string
+		tokenID       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			tokenID: pat.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(pat, nil)
+				store.EXPECT().
+					DeletePersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "InvalidID",
+			tokenID: "invalid",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "NotFound",
+			tokenID: pat.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(db.PersonalAccessToken{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "TokenOfAnotherUser",
+			tokenID: pat.ID.String(),
+			buildStubs: func(store *mockdb.MockStore) {
+				other, _ := randomPersonalAccessToken(t, uuid.New(), scopeWebsRead)
+				store.EXPECT().
+					GetPersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					DeletePersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/users/me/tokens/%s", tc.tokenID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPersonalAccessTokenAuth(t *testing.T) {
+	user, _ := randomUser(t)
+	pat, accessToken := randomPersonalAccessToken(t, user.ID, scopeWebsRead)
+
+	testCases := []struct {
+		name          string
+		path          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			path: "/pat/webs",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(pat.TokenHash)).
+					Times(1).
+					Return(pat, nil)
+				store.EXPECT().
+					TouchPersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, user.ID.String(), recorder.Body.String())
+			},
+		},
+		{
+			name: "MissingScope",
+			path: "/pat/notes",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(pat.TokenHash)).
+					Times(1).
+					Return(pat, nil)
+				store.EXPECT().
+					TouchPersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountRoute",
+			path: "/pat/account",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(pat.TokenHash)).
+					Times(1).
+					Return(pat, nil)
+				store.EXPECT().
+					TouchPersonalAccessToken(gomock.Any(), gomock.Eq(pat.ID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			path: "/pat/webs",
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := pat
+				expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(pat.TokenHash)).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					TouchPersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Revoked",
+			path: "/pat/webs",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPersonalAccessTokenByHash(gomock.Any(), gomock.Eq(pat.TokenHash)).
+					Times(1).
+					Return(db.PersonalAccessToken{}, sql.ErrNoRows)
+				store.EXPECT().
+					TouchPersonalAccessToken(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			handler := func(ctx *gin.Context) {
+				ctx.String(http.StatusOK, ctx.MustGet(authorizationPayloadKey).(*token.Payload).UserID.String())
+			}
+			auth := authMiddleware(server.tokenMaker, server.store)
+			server.router.GET("/pat/webs", auth, scopeMiddleware(scopeWebsRead), handler)
+			server.router.GET("/pat/notes", auth, scopeMiddleware(scopeNotesRead), handler)
+			server.router.GET("/pat/account", auth, sessionOnlyMiddleware(), handler)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
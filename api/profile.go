@@ -0,0 +1,327 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/avatar"
+	"github.com/inkclip/backend/blob"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/lib/pq"
+)
+
+// avatarKeyPrefix is the folder of the avatars in the blob store, they are served at /avatars/:name
+const avatarKeyPrefix = "avatars/"
+
+var handleRe = regexp.MustCompile(`^[a-z0-9_]{3,30}$`)
+
+// reservedHandles would read as pages of the app, or of its staff, in a url
+var reservedHandles = map[string]bool{
+	"about": true, "admin": true, "administrator": true, "api": true, "app": true,
+	"auth": true, "avatars": true, "help": true, "inkclip": true, "login": true,
+	"logout": true, "me": true, "moderator": true, "notes": true, "null": true,
+	"oauth": true, "privacy": true, "profiles": true, "public": true, "register": true,
+	"root": true, "search": true, "settings": true, "signup": true, "staff": true,
+	"support": true, "system": true, "tags": true, "terms": true, "undefined": true,
+	"users": true, "webs": true, "www": true,
+}
+
+var (
+	errInvalidHandle  = errors.New("handle must be 3 to 30 lowercase letters, digits or underscores")
+	errReservedHandle = errors.New("handle is reserved")
+	errHandleTaken    = errors.New("handle is already taken")
+	errNoProfile      = errors.New("create a profile first")
+	errAvatarTooLarge = fmt.Errorf("avatar must be at most %d bytes", avatar.MaxUploadSize)
+)
+
+// normalizeHandle lowercases the handle, handles are unique regardless of case
+func normalizeHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimSpace(handle))
+	if !handleRe.MatchString(handle) {
+		return "", errInvalidHandle
+	}
+	if reservedHandles[handle] {
+		return "", errReservedHandle
+	}
+	return handle, nil
+}
+
+// profileResponse is public, it never carries the email of the user
+type profileResponse struct {
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	// empty when there is no avatar
+	AvatarURL string    `json:"avatar_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newProfileResponse(profile db.Profile) profileResponse {
+	res := profileResponse{
+		Handle:      profile.Handle,
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		UpdatedAt:   profile.UpdatedAt,
+	}
+	if profile.AvatarKey != "" {
+		res.AvatarURL = "/" + profile.AvatarKey
+	}
+	return res
+}
+
+// @Success 200 {object} api.profileResponse
+// @Router /users/me/profile [get]
+// @Tags profile
+// @Security AccessToken
+func (server *Server) getMyProfile(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	profile, err := server.store.GetProfile(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNoProfile))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+type putProfileRequest struct {
+	Handle      string `json:"handle" binding:"required"`
+	DisplayName string `json:"display_name" binding:"max=50"`
+	Bio         string `json:"bio" binding:"max=300"`
+}
+
+// Creates the profile or replaces it, the avatar is kept.
+// @Param request body api.putProfileRequest true "query params"
+// @Success 200 {object} api.profileResponse
+// @Router /users/me/profile [put]
+// @Tags profile
+// @Security AccessToken
+func (server *Server) putProfile(ctx *gin.Context) {
+	var req putProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	handle, err := normalizeHandle(req.Handle)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	profile, err := server.store.UpsertProfile(ctx, db.UpsertProfileParams{
+		UserID:      authPayload.UserID,
+		Handle:      handle,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Bio:         strings.TrimSpace(req.Bio),
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(errHandleTaken))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+// Takes a png, jpeg or gif in the avatar field of a multipart form, it is cropped to a square and resized.
+// @Accept multipart/form-data
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} api.profileResponse
+// @Router /users/me/profile/avatar [put]
+// @Tags profile
+// @Security AccessToken
+func (server *Server) putAvatar(ctx *gin.Context) {
+	// room for the multipart headers around the file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, avatar.MaxUploadSize+1<<10)
+	file, err := ctx.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(errAvatarTooLarge))
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if file.Size > avatar.MaxUploadSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(errAvatarTooLarge))
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	profile, err := server.store.GetProfile(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNoProfile))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	resized, err := avatar.Resize(data)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// a new key for every upload, so the old one can be cached forever
+	key := avatarKeyPrefix + uuid.NewString() + ".png"
+	if err := server.blobStore.Put(ctx, key, resized); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	updated, err := server.store.UpdateProfileAvatar(ctx, db.UpdateProfileAvatarParams{
+		UserID:    profile.UserID,
+		AvatarKey: key,
+	})
+	if err != nil {
+		_ = server.blobStore.Delete(ctx, key)
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.deleteAvatarBlob(ctx, profile.AvatarKey)
+
+	ctx.JSON(http.StatusOK, newProfileResponse(updated))
+}
+
+// @Success 200 {object} api.profileResponse
+// @Router /users/me/profile/avatar [delete]
+// @Tags profile
+// @Security AccessToken
+func (server *Server) deleteAvatar(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	profile, err := server.store.GetProfile(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errNoProfile))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	updated, err := server.store.UpdateProfileAvatar(ctx, db.UpdateProfileAvatarParams{
+		UserID:    profile.UserID,
+		AvatarKey: "",
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	server.deleteAvatarBlob(ctx, profile.AvatarKey)
+
+	ctx.JSON(http.StatusOK, newProfileResponse(updated))
+}
+
+// deleteAvatarBlob removes an avatar nothing points to anymore.
+// A failure only leaves an orphan file behind, so it is recorded on the context instead of failing the request.
+func (server *Server) deleteAvatarBlob(ctx *gin.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := server.blobStore.Delete(ctx, key); err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+type handleRequest struct {
+	Handle string `uri:"handle" binding:"required"`
+}
+
+// The profile of a user, found by its handle without logging in.
+// @Param handle path string true "Handle"
+// @Success 200 {object} api.profileResponse
+// @Router /profiles/{handle} [get]
+// @Tags profile
+func (server *Server) getPublicProfile(ctx *gin.Context) {
+	var req handleRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	profile, err := server.store.GetPublicProfileByHandle(ctx, strings.ToLower(req.Handle))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newProfileResponse(profile))
+}
+
+type avatarRequest struct {
+	Name string `uri:"name" binding:"required"`
+}
+
+// @Param name path string true "Avatar file name"
+// @Produce png
+// @Success 200 {file} binary
+// @Router /avatars/{name} [get]
+// @Tags profile
+func (server *Server) getAvatar(ctx *gin.Context) {
+	var req avatarRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	key := avatarKeyPrefix + req.Name
+	if !blob.ValidKey(key) {
+		ctx.JSON(http.StatusNotFound, errorResponse(blob.ErrNotFound))
+		return
+	}
+
+	data, err := server.blobStore.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// keys change with every upload
+	ctx.Header("Cache-Control", "public, max-age=31536000, immutable")
+	ctx.Header("Content-Type", "image/png")
+	ctx.Data(http.StatusOK, "image/png", data)
+}
@@ -0,0 +1,459 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inkclip/backend/avatar"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func randomProfile(t *testing.T, user db.User) db.Profile {
+	return db.Profile{
+		UserID:      user.ID,
+		Handle:      strings.ToLower(util.RandomString(8)),
+		DisplayName: util.RandomName(),
+		Bio:         util.RandomString(20),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+func TestNormalizeHandle(t *testing.T) {
+	handle, err := normalizeHandle(" Ink_Fan42 ")
+	require.NoError(t, err)
+	require.Equal(t, "ink_fan42", handle)
+
+	for _, invalid := range []string{"ab", strings.Repeat("a", 31), "ink-fan", "ink fan", "inkfän"} {
+		_, err := normalizeHandle(invalid)
+		require.ErrorIs(t, err, errInvalidHandle, invalid)
+	}
+
+	_, err = normalizeHandle("Admin")
+	require.ErrorIs(t, err, errReservedHandle)
+}
+
+func TestPutProfileAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		auth          bool
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"handle": strings.ToUpper(profile.Handle), "display_name": " " + profile.DisplayName + " ", "bio": profile.Bio},
+			auth: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertProfile(gomock.Any(), gomock.Eq(db.UpsertProfileParams{
+						UserID:      user.ID,
+						Handle:      profile.Handle,
+						DisplayName: profile.DisplayName,
+						Bio:         profile.Bio,
+					})).
+					Times(1).
+					Return(profile, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res profileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, profile.Handle, res.Handle)
+				require.Equal(t, profile.DisplayName, res.DisplayName)
+				require.Empty(t, res.AvatarURL)
+			},
+		},
+		{
+			name: "InvalidHandle",
+			body: gin.H{"handle": "a b"},
+			auth: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ReservedHandle",
+			body: gin.H{"handle": "support"},
+			auth: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errReservedHandle.Error())
+			},
+		},
+		{
+			name: "BioTooLong",
+			body: gin.H{"handle": profile.Handle, "bio": strings.Repeat("a", 301)},
+			auth: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "HandleTaken",
+			body: gin.H{"handle": profile.Handle},
+			auth: true,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertProfile(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Profile{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errHandleTaken.Error())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{"handle": profile.Handle},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/me/profile", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			if tc.auth {
+				addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			}
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetPublicProfileAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	profile.AvatarKey = avatarKeyPrefix + "a.png"
+
+	testCases := []struct {
+		name          string
+		handle        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			handle: strings.ToUpper(profile.Handle),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPublicProfileByHandle(gomock.Any(), gomock.Eq(profile.Handle)).
+					Times(1).
+					Return(profile, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), user.Email)
+				require.NotContains(t, recorder.Body.String(), user.ID.String())
+
+				var res profileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Equal(t, profile.Handle, res.Handle)
+				require.Equal(t, "/avatars/a.png", res.AvatarURL)
+			},
+		},
+		{
+			name:   "NotFound",
+			handle: profile.Handle,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPublicProfileByHandle(gomock.Any(), gomock.Eq(profile.Handle)).
+					Times(1).
+					Return(db.Profile{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/profiles/"+tc.handle, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// newAvatarRequest is a multipart upload of data in the avatar field
+func newAvatarRequest(t *testing.T, data []byte) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("avatar", "avatar.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	request, err := http.NewRequest(http.MethodPut, "/users/me/profile/avatar", &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", w.FormDataContentType())
+	return request
+}
+
+func randomPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 32))))
+	return buf.Bytes()
+}
+
+func TestPutAvatarAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	profile.AvatarKey = avatarKeyPrefix + "old.png"
+
+	testCases := []struct {
+		name          string
+		data          []byte
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			data: randomPNG(t),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(profile, nil)
+				store.EXPECT().
+					UpdateProfileAvatar(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateProfileAvatarParams) (db.Profile, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.True(t, strings.HasPrefix(arg.AvatarKey, avatarKeyPrefix))
+						updated := profile
+						updated.AvatarKey = arg.AvatarKey
+						return updated, nil
+					})
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res profileResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				data, err := server.blobStore.Get(context.Background(), strings.TrimPrefix(res.AvatarURL, "/"))
+				require.NoError(t, err)
+
+				cfg, err := png.DecodeConfig(bytes.NewReader(data))
+				require.NoError(t, err)
+				require.Equal(t, avatar.Size, cfg.Width)
+				require.Equal(t, avatar.Size, cfg.Height)
+
+				_, err = server.blobStore.Get(context.Background(), profile.AvatarKey)
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "NoProfile",
+			data: randomPNG(t),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Profile{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpdateProfileAvatar(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAnImage",
+			data: []byte("<svg></svg>"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(profile, nil)
+				store.EXPECT().
+					UpdateProfileAvatar(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), avatar.ErrUnsupportedImage.Error())
+			},
+		},
+		{
+			name: "TooLarge",
+			data: make([]byte, avatar.MaxUploadSize+1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			require.NoError(t, server.blobStore.Put(context.Background(), profile.AvatarKey, randomPNG(t)))
+			recorder := httptest.NewRecorder()
+
+			request := newAvatarRequest(t, tc.data)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestDeleteAvatarAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	profile.AvatarKey = avatarKeyPrefix + "old.png"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetProfile(gomock.Any(), gomock.Eq(user.ID)).
+		Times(1).
+		Return(profile, nil)
+	updated := profile
+	updated.AvatarKey = ""
+	store.EXPECT().
+		UpdateProfileAvatar(gomock.Any(), gomock.Eq(db.UpdateProfileAvatarParams{UserID: user.ID, AvatarKey: ""})).
+		Times(1).
+		Return(updated, nil)
+
+	server := newTestServer(t, store)
+	require.NoError(t, server.blobStore.Put(context.Background(), profile.AvatarKey, randomPNG(t)))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, "/users/me/profile/avatar", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	_, err = server.blobStore.Get(context.Background(), profile.AvatarKey)
+	require.Error(t, err)
+}
+
+func TestGetAvatarAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	data := randomPNG(t)
+	require.NoError(t, server.blobStore.Put(context.Background(), avatarKeyPrefix+"a.png", data))
+
+	testCases := []struct {
+		name string
+		path string
+		code int
+	}{
+		{name: "OK", path: "/avatars/a.png", code: http.StatusOK},
+		{name: "NotFound", path: "/avatars/b.png", code: http.StatusNotFound},
+		{name: "InvalidName", path: "/avatars/..a.png", code: http.StatusNotFound},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.code, recorder.Code)
+			if tc.code == http.StatusOK {
+				require.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+				require.Equal(t, data, recorder.Body.Bytes())
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"embed"
+	"errors"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/markdown"
+)
+
+const (
+	siteName = "inkclip"
+	// publicNoteCacheControl lets shared links be crawled without reaching the database every time
+	publicNoteCacheControl = "public, max-age=60"
+	// publicNoteCSP allows nothing but the inline style of the page and images, the note is sanitized but this is the backstop
+	publicNoteCSP = "default-src 'none'; img-src https: http: data:; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+	// descriptionLength is the most characters of the note in the description of the page
+	descriptionLength = 160
+)
+
+var errNoteNotPublic = errors.New("note is not public")
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+type publicNoteWeb struct {
+	URL          string
+	Title        string
+	SiteName     string
+	ThumbnailURL string
+}
+
+// publicNotePage is what templates/public_note.html shows
+type publicNotePage struct {
+	SiteName    string
+	Title       string
+	Description string
+	// URL is the canonical url of the page
+	URL string
+	// Image is the preview of the page, the thumbnail of the first linked web
+	Image   string
+	Content template.HTML
+	// Author, AuthorURL and FeedURL are empty when the owner has no profile
+	Author        string
+	AuthorURL     string
+	FeedURL       string
+	PublishedAt   string
+	UpdatedAt     string
+	PublishedDate string
+	Webs          []publicNoteWeb
+}
+
+// wantsHTML is true when the Accept header prefers text/html to json, as browsers and most link preview crawlers do.
+// json stays the default, for no Accept, */* and ties, since api clients got it before the page existed.
+func wantsHTML(ctx *gin.Context) bool {
+	htmlQuality, jsonQuality := 0.0, 0.0
+	for _, accepted := range strings.Split(ctx.GetHeader("Accept"), ",") {
+		mediaType, quality := parseAcceptedType(accepted)
+		switch mediaType {
+		case gin.MIMEHTML:
+			htmlQuality = math.Max(htmlQuality, quality)
+		case gin.MIMEJSON, "application/*", "*/*":
+			jsonQuality = math.Max(jsonQuality, quality)
+		}
+	}
+	return htmlQuality > jsonQuality
+}
+
+// parseAcceptedType returns the media type of an entry of Accept and its q, 1 when there is none
+func parseAcceptedType(accepted string) (string, float64) {
+	params := strings.Split(accepted, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+	quality := 1.0
+	for _, param := range params[1:] {
+		name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || strings.TrimSpace(name) != "q" {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			q = 0
+		}
+		quality = q
+	}
+	return mediaType, quality
+}
+
+// absoluteHTTPURL is true for the urls a crawler can fetch
+func absoluteHTTPURL(raw string) bool {
+	return strings.HasPrefix(raw, "https://") || strings.HasPrefix(raw, "http://")
+}
+
+func (server *Server) newPublicNotePage(ctx *gin.Context, note db.Note, webs []db.Web) (publicNotePage, error) {
+	content, err := markdown.ToHTML(note.Content)
+	if err != nil {
+		return publicNotePage{}, err
+	}
+
+	page := publicNotePage{
+		SiteName:      siteName,
+		Title:         note.Title,
+		Description:   noteExcerpt(markdown.PlainText(note.Content), descriptionLength),
+		URL:           server.config.PublicURL + "/public_notes/" + note.ID.String(),
+		Content:       template.HTML(content), // #nosec G203 -- markdown.ToHTML sanitizes it with bluemonday
+		PublishedAt:   note.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:     note.UpdatedAt.UTC().Format(time.RFC3339),
+		PublishedDate: note.CreatedAt.UTC().Format("January 2, 2006"),
+		Webs:          make([]publicNoteWeb, len(webs)),
+	}
+
+	for i, web := range webs {
+		thumbnailURL := ""
+		if absoluteHTTPURL(web.ThumbnailUrl) {
+			thumbnailURL = web.ThumbnailUrl
+		}
+		page.Webs[i] = publicNoteWeb{
+			URL:          web.Url,
+			Title:        web.Title,
+			SiteName:     web.SiteName,
+			ThumbnailURL: thumbnailURL,
+		}
+	}
+	if len(page.Webs) > 0 {
+		page.Image = page.Webs[0].ThumbnailURL
+	}
+
+	profile, err := server.store.GetPublicProfile(ctx, note.UserID)
+	if err != nil {
+		// without a profile, or with the one of a blocked user, the note is shown without an author
+		if err == sql.ErrNoRows {
+			return page, nil
+		}
+		return page, err
+	}
+	page.Author = profile.DisplayName
+	if page.Author == "" {
+		page.Author = profile.Handle
+	}
+	page.AuthorURL = server.config.FrontURL + "/u/" + profile.Handle
+	page.FeedURL = server.config.PublicURL + "/u/" + profile.Handle + "/feed.atom"
+	return page, nil
+}
+
+// publicErrorPage is what the public_error template shows, never the error itself which may be internal
+type publicErrorPage struct {
+	SiteName string
+	Title    string
+	Message  string
+}
+
+// respondPublicNoteError responds the error as a page when the client asked for html, as json otherwise
+func respondPublicNoteError(ctx *gin.Context, html bool, status int, err error) {
+	if !html {
+		ctx.JSON(status, errorResponse(err))
+		return
+	}
+
+	page := publicErrorPage{SiteName: siteName, Title: http.StatusText(status)}
+	switch status {
+	case http.StatusNotFound:
+		page.Title = "Note not found"
+		page.Message = "The note doesn't exist or isn't public."
+	case http.StatusBadRequest:
+		page.Message = "The link to the note is invalid."
+	default:
+		page.Message = "The note can't be shown right now, please try again later."
+	}
+	renderHTML(ctx, status, "public_error", page)
+}
+
+// renderHTML executes the template with the headers of every public page
+func renderHTML(ctx *gin.Context, status int, name string, data interface{}) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		_ = ctx.Error(err)
+		ctx.Data(http.StatusInternalServerError, "text/plain; charset=utf-8", []byte(http.StatusText(http.StatusInternalServerError)))
+		return
+	}
+
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Header("Content-Security-Policy", publicNoteCSP)
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
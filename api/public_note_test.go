@@ -0,0 +1,303 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPublicNoteHTML(t *testing.T) {
+	user, _ := randomUser(t)
+	profile := randomProfile(t, user)
+	note := randomPublicNote(t, user.ID)
+	note.Title = `Fish "&" <chips>`
+	note.Content = "# Heading\n\nThe **best** chips in town.\n\n<script>alert(1)</script>"
+	note.CreatedAt = time.Date(2022, 12, 1, 9, 30, 0, 0, time.UTC)
+	note.UpdatedAt = note.CreatedAt
+
+	web := randomWeb(t, user.ID)
+	web.ThumbnailUrl = "https://example.com/thumb.jpg"
+	noThumbnail := randomWeb(t, user.ID)
+	noThumbnail.ThumbnailUrl = ""
+
+	testCases := []struct {
+		name          string
+		noteID        string
+		accept        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{web, noThumbnail}, nil)
+				store.EXPECT().
+					GetPublicProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(profile, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t, "Accept", recorder.Header().Get("Vary"))
+				require.Equal(t, publicNoteCSP, recorder.Header().Get("Content-Security-Policy"))
+				require.Equal(t, publicNoteCacheControl, recorder.Header().Get("Cache-Control"))
+
+				body := recorder.Body.String()
+				noteURL := server.config.PublicURL + "/public_notes/" + note.ID.String()
+				require.Contains(t, body, `<title>Fish &#34;&amp;&#34; &lt;chips&gt; - inkclip</title>`)
+				require.Contains(t, body, `<meta property="og:title" content="Fish &#34;&amp;&#34; &lt;chips&gt;">`)
+				require.Contains(t, body, `<meta property="og:description" content="Heading The best chips in town.">`)
+				require.Contains(t, body, `<meta property="og:url" content="`+noteURL+`">`)
+				require.Contains(t, body, `<meta property="og:image" content="https://example.com/thumb.jpg">`)
+				require.Contains(t, body, `<meta name="twitter:card" content="summary_large_image">`)
+				require.Contains(t, body, `<meta property="article:published_time" content="2022-12-01T09:30:00Z">`)
+				require.Contains(t, body, `<h1>Heading</h1>`)
+				require.Contains(t, body, `<strong>best</strong>`)
+				require.NotContains(t, body, "<script>")
+				require.Contains(t, body, `href="`+server.config.FrontURL+`/u/`+profile.Handle+`"`)
+				require.Contains(t, body, `href="`+server.config.PublicURL+`/u/`+profile.Handle+`/feed.atom"`)
+				require.Contains(t, body, noThumbnail.Url)
+				require.NotContains(t, body, user.Email)
+			},
+		},
+		{
+			name:   "NoProfileNoThumbnail",
+			accept: "text/html",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{noThumbnail, web}, nil)
+				store.EXPECT().
+					GetPublicProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Profile{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				body := recorder.Body.String()
+				require.Contains(t, body, `<meta name="twitter:card" content="summary">`)
+				require.NotContains(t, body, "og:image")
+				require.NotContains(t, body, "feed.atom")
+			},
+		},
+		{
+			name:   "JSON",
+			accept: "application/json",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{web}, nil)
+				store.EXPECT().
+					GetPublicProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+				requireBodyMatchNote(t, recorder.Body, note, []db.Web{web}, nil)
+			},
+		},
+		{
+			name:   "NoAccept",
+			accept: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{web}, nil)
+				store.EXPECT().
+					GetPublicProfile(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+				requireBodyMatchNote(t, recorder.Body, note, []db.Web{web}, nil)
+			},
+		},
+		{
+			name:   "AnyType",
+			accept: "*/*",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{web}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			},
+		},
+		{
+			name:   "HTMLNotPreferred",
+			accept: "application/json, text/html;q=0.5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{web}, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			},
+		},
+		{
+			name:   "NotPublic",
+			accept: "text/html",
+			buildStubs: func(store *mockdb.MockStore) {
+				private := note
+				private.IsPublic = false
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(private, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "Note not found")
+				require.NotContains(t, recorder.Body.String(), note.Title)
+			},
+		},
+		{
+			name:   "InvalidID",
+			noteID: "not-a-uuid",
+			accept: "text/html",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "The link to the note is invalid.")
+			},
+		},
+		{
+			name:   "InternalError",
+			accept: "text/html",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(db.Note{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "Internal Server Error")
+				require.NotContains(t, recorder.Body.String(), sql.ErrConnDone.Error())
+			},
+		},
+		{
+			name:   "PageError",
+			accept: "text/html",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(note, nil)
+				store.EXPECT().
+					ListWebByNoteId(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return([]db.Web{web}, nil)
+				store.EXPECT().
+					GetPublicProfile(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.Profile{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.NotContains(t, recorder.Body.String(), note.Title)
+			},
+		},
+		{
+			name:   "InternalErrorJSON",
+			accept: "application/json",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetNote(gomock.Any(), gomock.Eq(note.ID)).
+					Times(1).
+					Return(db.Note{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			noteID := tc.noteID
+			if noteID == "" {
+				noteID = note.ID.String()
+			}
+			request, err := http.NewRequest(http.MethodGet, "/public_notes/"+noteID, nil)
+			require.NoError(t, err)
+			if tc.accept != "" {
+				request.Header.Set("Accept", tc.accept)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
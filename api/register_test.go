@@ -0,0 +1,395 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/mail"
+	mockmail "github.com/inkclip/backend/mail/mock"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTemporaryUser(t *testing.T, createdAt time.Time) db.TemporaryUser {
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	return db.TemporaryUser{
+		Email:          util.RandomEmail(),
+		HashedPassword: hashedPassword,
+		Token:          util.RandomString(36),
+		ExpiresAt:      createdAt.Add(temporaryUserDuration),
+		CreatedAt:      createdAt,
+	}
+}
+
+func TestResendVerificationAPI(t *testing.T) {
+	tmpUser := randomTemporaryUser(t, time.Now().Add(-time.Hour))
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore, mailClient *mockmail.MockClient)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": tmpUser.Email},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ClaimMailSend(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ClaimMailSendParams) (db.MailThrottle, error) {
+						require.Equal(t, verificationThrottleKey(tmpUser.Email), arg.Key)
+						require.WithinDuration(t, time.Now().Add(-time.Minute), arg.SentBefore, time.Second)
+						return db.MailThrottle{Key: arg.Key}, nil
+					})
+				store.EXPECT().
+					GetLatestTemporaryUserByEmail(gomock.Any(), gomock.Eq(tmpUser.Email)).
+					Times(1).
+					Return(tmpUser, nil)
+
+				var token string
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTemporaryUserParams) (db.TemporaryUser, error) {
+						require.Equal(t, tmpUser.Email, arg.Email)
+						require.Equal(t, tmpUser.HashedPassword, arg.HashedPassword)
+						require.NotEqual(t, tmpUser.Token, arg.Token)
+						require.WithinDuration(t, time.Now().Add(temporaryUserDuration), arg.ExpiresAt, time.Minute)
+						token = arg.Token
+						return db.TemporaryUser{Email: arg.Email, Token: arg.Token}, nil
+					})
+
+				mailClient.EXPECT().
+					VertifyMailContent(gomock.Eq(tmpUser.Email), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(recipient string, got string) mail.SendContent {
+						require.Equal(t, token, got)
+						return mail.SendContent{Recipient: recipient}
+					})
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SentRecently",
+			body: gin.H{"email": tmpUser.Email},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ClaimMailSend(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MailThrottle{}, sql.ErrNoRows)
+				// the answer doesn't depend on a pending registration
+				store.EXPECT().
+					GetLatestTemporaryUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "SendError",
+			body: gin.H{"email": tmpUser.Email},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetLatestTemporaryUserByEmail(gomock.Any(), gomock.Eq(tmpUser.Email)).
+					Times(1).
+					Return(tmpUser, nil)
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TemporaryUser{Email: tmpUser.Email}, nil)
+				mailClient.EXPECT().
+					VertifyMailContent(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(mail.SendContent{})
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(1).
+					Return(errors.New("smtp down"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// the mail goes out after the response, the same one as without a registration
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"email": tmpUser.Email},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ClaimMailSend(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MailThrottle{}, sql.ErrConnDone)
+				store.EXPECT().
+					GetLatestTemporaryUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NoPendingRegistration",
+			body: gin.H{"email": util.RandomEmail()},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetLatestTemporaryUserByEmail(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TemporaryUser{}, sql.ErrNoRows)
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidEmail",
+			body: gin.H{"email": "invalid"},
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					GetLatestTemporaryUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailClient := mockmail.NewMockClient(ctrl)
+			tc.buildStubs(store, mailClient)
+
+			server := newTestServer(t, store)
+			server.config.RegisterResendInterval = time.Minute
+			server.mailClient = mailClient
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/register/resend", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			server.background.Wait()
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRegisterAPI(t *testing.T) {
+	email := util.RandomEmail()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, mailClient *mockmail.MockClient)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ClaimMailSend(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ClaimMailSendParams) (db.MailThrottle, error) {
+						// the key resend is throttled with, registering again doesn't get around it
+						require.Equal(t, verificationThrottleKey(email), arg.Key)
+						return db.MailThrottle{Key: arg.Key}, nil
+					})
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTemporaryUserParams) (db.TemporaryUser, error) {
+						return db.TemporaryUser{Email: arg.Email, Token: arg.Token}, nil
+					})
+				mailClient.EXPECT().
+					VertifyMailContent(gomock.Eq(email), gomock.Any()).
+					Times(1).
+					Return(mail.SendContent{Recipient: email})
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SentRecently",
+			buildStubs: func(store *mockdb.MockStore, mailClient *mockmail.MockClient) {
+				store.EXPECT().
+					ClaimMailSend(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MailThrottle{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateTemporaryUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				mailClient.EXPECT().
+					Send(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.Equal(t, "60", recorder.Header().Get("Retry-After"))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			mailClient := mockmail.NewMockClient(ctrl)
+			tc.buildStubs(store, mailClient)
+
+			server := newTestServer(t, store)
+			server.config.RegisterResendInterval = time.Minute
+			server.mailClient = mailClient
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": email, "password": util.RandomString(8)})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/register", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyAPI(t *testing.T) {
+	tmpUser := randomTemporaryUser(t, time.Now())
+	expiredTmpUser := randomTemporaryUser(t, time.Now().Add(-2*temporaryUserDuration))
+	user := db.User{Email: tmpUser.Email, HashedPassword: tmpUser.HashedPassword}
+
+	testCases := []struct {
+		name          string
+		tmpUser       db.TemporaryUser
+		buildStubs    func(store *mockdb.MockStore, tmpUser db.TemporaryUser)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			tmpUser: tmpUser,
+			buildStubs: func(store *mockdb.MockStore, tmpUser db.TemporaryUser) {
+				store.EXPECT().
+					GetTemporaryUserByEmailAndToken(gomock.Any(), gomock.Eq(db.GetTemporaryUserByEmailAndTokenParams{
+						Email: tmpUser.Email,
+						Token: tmpUser.Token,
+					})).
+					Times(1).
+					Return(tmpUser, nil)
+				store.EXPECT().
+					TxVerifyUser(gomock.Any(), gomock.Eq(db.CreateUserParams{
+						Email:          tmpUser.Email,
+						HashedPassword: tmpUser.HashedPassword,
+					})).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name:    "AlreadyUsed",
+			tmpUser: tmpUser,
+			buildStubs: func(store *mockdb.MockStore, tmpUser db.TemporaryUser) {
+				store.EXPECT().
+					GetTemporaryUserByEmailAndToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TemporaryUser{}, sql.ErrNoRows)
+				store.EXPECT().
+					TxVerifyUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:    "Expired",
+			tmpUser: expiredTmpUser,
+			buildStubs: func(store *mockdb.MockStore, tmpUser db.TemporaryUser) {
+				store.EXPECT().
+					GetTemporaryUserByEmailAndToken(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(tmpUser, nil)
+				store.EXPECT().
+					TxVerifyUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.tmpUser)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"email": tc.tmpUser.Email, "token": tc.tmpUser.Token})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/verify", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"html"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+)
+
+const (
+	searchTypeNote = "note"
+	searchTypeWeb  = "web"
+
+	// private use characters never show up in real text, so they can't be confused with the content
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+var headlineOptions = `StartSel=` + highlightStart + `, StopSel=` + highlightStop +
+	`, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" ... "`
+
+type searchRequest struct {
+	Query string `json:"q" form:"q" binding:"required,max=200"`
+	// note or web, both when empty
+	Type     string `json:"type" form:"type" binding:"omitempty,oneof=note web"`
+	IsPublic *bool  `json:"is_public" form:"is_public"`
+	// RFC3339, inclusive
+	From *time.Time `json:"from" form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	// RFC3339, exclusive
+	To *time.Time `json:"to" form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// only webs from this domain or its subdomains
+	Domain   string `json:"domain" form:"domain" binding:"omitempty,hostname_rfc1123"`
+	PageID   int32  `json:"page_id" form:"page_id" binding:"required,min=1"`
+	PageSize int32  `json:"page_size" form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type searchResultResponse struct {
+	Type     string    `json:"type" binding:"required"`
+	ID       uuid.UUID `json:"id" binding:"required"`
+	Title    string    `json:"title" binding:"required"`
+	URL      string    `json:"url,omitempty"`
+	IsPublic bool      `json:"is_public" binding:"required"`
+	// html escaped text, matches are wrapped in <mark>
+	Snippet   string    `json:"snippet" binding:"required"`
+	Rank      float32   `json:"rank" binding:"required"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
+}
+
+type searchResponse struct {
+	Results []searchResultResponse `json:"results"`
+}
+
+func newSearchResultResponse(row db.SearchRow) searchResultResponse {
+	return searchResultResponse{
+		Type:      row.Type,
+		ID:        row.ID,
+		Title:     row.Title,
+		URL:       row.Url,
+		IsPublic:  row.IsPublic,
+		Snippet:   highlight(row.Snippet),
+		Rank:      row.Rank,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+// highlight escapes the headline built by postgres and turns its markers into <mark>
+func highlight(headline string) string {
+	escaped := html.EscapeString(headline)
+	escaped = strings.ReplaceAll(escaped, highlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, highlightStop, "</mark>")
+}
+
+// Webs are never public, and notes have no domain.
+// A personal access token needs notes:read and webs:read for the types searched.
+// @Param request query api.searchRequest true "query params"
+// @Success 200 {object} api.searchResponse
+// @Router /search [get]
+// @Tags search
+// @Security AccessToken
+func (server *Server) search(ctx *gin.Context) {
+	var req searchRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.From != nil && req.To != nil && !req.From.Before(*req.To) {
+		err := errors.New("from must be before to")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.SearchParams{
+		Query:           req.Query,
+		HeadlineOptions: headlineOptions,
+		UserID:          authPayload.UserID,
+		IncludeNotes:    req.Type != searchTypeWeb && req.Domain == "",
+		IncludeWebs:     req.Type != searchTypeNote,
+		PageLimit:       req.PageSize,
+		PageOffset:      (req.PageID - 1) * req.PageSize,
+	}
+	if req.IsPublic != nil {
+		arg.IsPublic = sql.NullBool{Bool: *req.IsPublic, Valid: true}
+	}
+	if req.From != nil {
+		arg.CreatedFrom = sql.NullTime{Time: *req.From, Valid: true}
+	}
+	if req.To != nil {
+		arg.CreatedTo = sql.NullTime{Time: *req.To, Valid: true}
+	}
+	if req.Domain != "" {
+		arg.Domain = sql.NullString{String: req.Domain, Valid: true}
+	}
+
+	// a personal access token needs the read scope of every type searched
+	if (arg.IncludeNotes && !hasScope(ctx, scopeNotesRead)) || (arg.IncludeWebs && !hasScope(ctx, scopeWebsRead)) {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInsufficientScope))
+		return
+	}
+
+	rows, err := server.store.Search(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := searchResponse{
+		Results: make([]searchResultResponse, len(rows)),
+	}
+	for i, row := range rows {
+		res.Results[i] = newSearchResultResponse(row)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
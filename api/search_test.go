@@ -0,0 +1,286 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	rows := []db.SearchRow{
+		randomSearchRow(t, searchTypeNote),
+		randomSearchRow(t, searchTypeWeb),
+	}
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: url.Values{"q": {"golang"}, "page_id": {"2"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchParams{
+					Query:           "golang",
+					HeadlineOptions: headlineOptions,
+					UserID:          user.ID,
+					IncludeNotes:    true,
+					IncludeWebs:     true,
+					PageLimit:       5,
+					PageOffset:      5,
+				}
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchSearch(t, recorder.Body, rows)
+			},
+		},
+		{
+			name: "Filters",
+			query: url.Values{
+				"q":         {"golang"},
+				"type":      {"note"},
+				"is_public": {"true"},
+				"from":      {from.Format(time.RFC3339)},
+				"to":        {to.Format(time.RFC3339)},
+				"page_id":   {"1"},
+				"page_size": {"5"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchParams{
+					Query:           "golang",
+					HeadlineOptions: headlineOptions,
+					UserID:          user.ID,
+					IncludeNotes:    true,
+					IncludeWebs:     false,
+					IsPublic:        sql.NullBool{Bool: true, Valid: true},
+					CreatedFrom:     sql.NullTime{Time: from, Valid: true},
+					CreatedTo:       sql.NullTime{Time: to, Valid: true},
+					PageLimit:       5,
+					PageOffset:      0,
+				}
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.SearchRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchSearch(t, recorder.Body, []db.SearchRow{})
+			},
+		},
+		{
+			name:  "DomainOnlyMatchesWebs",
+			query: url.Values{"q": {"golang"}, "domain": {"example.com"}, "page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SearchParams{
+					Query:           "golang",
+					HeadlineOptions: headlineOptions,
+					UserID:          user.ID,
+					IncludeNotes:    false,
+					IncludeWebs:     true,
+					Domain:          sql.NullString{String: "example.com", Valid: true},
+					PageLimit:       5,
+					PageOffset:      0,
+				}
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.SearchRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Unauthorized",
+			query: url.Values{"q": {"golang"}, "page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingQuery",
+			query: url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidType",
+			query: url.Values{"q": {"golang"}, "type": {"user"}, "page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidDomain",
+			query: url.Values{"q": {"golang"}, "domain": {"https://example.com/"}, "page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDateRange",
+			query: url.Values{
+				"q":         {"golang"},
+				"from":      {to.Format(time.RFC3339)},
+				"to":        {from.Format(time.RFC3339)},
+				"page_id":   {"1"},
+				"page_size": {"5"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "DBError",
+			query: url.Values{"q": {"golang"}, "page_id": {"1"}, "page_size": {"5"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					Search(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.SearchRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/search?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	headline := "a <b>" + highlightStart + "go" + highlightStop + "</b> & " + highlightStart + "rust" + highlightStop
+	require.Equal(t, "a &lt;b&gt;<mark>go</mark>&lt;/b&gt; &amp; <mark>rust</mark>", highlight(headline))
+}
+
+func randomSearchRow(t *testing.T, searchType string) db.SearchRow {
+	id, err := uuid.NewRandom()
+	require.NoError(t, err)
+	row := db.SearchRow{
+		Type:      searchType,
+		ID:        id,
+		Title:     util.RandomName(),
+		Snippet:   "about " + highlightStart + "golang" + highlightStop,
+		Rank:      0.5,
+		CreatedAt: time.Now(),
+	}
+	if searchType == searchTypeWeb {
+		row.Url = util.RandomURL()
+	}
+	return row
+}
+
+func requireBodyMatchSearch(t *testing.T, body *bytes.Buffer, rows []db.SearchRow) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var got searchResponse
+	err = json.Unmarshal(data, &got)
+	require.NoError(t, err)
+	require.Len(t, got.Results, len(rows))
+	for i, row := range rows {
+		require.Equal(t, row.Type, got.Results[i].Type)
+		require.Equal(t, row.ID, got.Results[i].ID)
+		require.Equal(t, row.Title, got.Results[i].Title)
+		require.Equal(t, row.Url, got.Results[i].URL)
+		require.Equal(t, "about <mark>golang</mark>", got.Results[i].Snippet)
+	}
+}
@@ -1,30 +1,52 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/inkclip/backend/blob"
 	"github.com/inkclip/backend/config"
 	db "github.com/inkclip/backend/db/sqlc"
 	docs "github.com/inkclip/backend/docs"
 	"github.com/inkclip/backend/mail"
+	"github.com/inkclip/backend/oidc"
 	"github.com/inkclip/backend/token"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
 )
 
+const (
+	readHeaderTimeout = 10 * time.Second
+	// shutdownTimeout is how long the requests in flight get once the server is stopped
+	shutdownTimeout = 10 * time.Second
+	// backgroundTimeout is how long work that was moved off a request, like sending a mail, may take
+	backgroundTimeout = 30 * time.Second
+)
+
 type Server struct {
 	config     config.Config
 	store      db.Store
 	tokenMaker token.Maker
 	mailClient mail.Client
-	router     *gin.Engine
+	blobStore  blob.Store
+	// oidcProvider is nil when OIDC_ISSUER isn't set
+	oidcProvider *oidc.Provider
+	router       *gin.Engine
+	// background counts the work still running after its response, see runInBackground
+	background sync.WaitGroup
 }
 
-func NewServer(config config.Config, store db.Store, mailClient mail.Client) (*Server, error) {
-	tokenMaker, err := token.NewJWTMaker(config.TokenSecretKey)
+func NewServer(config config.Config, store db.Store, mailClient mail.Client, blobStore blob.Store) (*Server, error) {
+	tokenMaker, err := newTokenMaker(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
@@ -34,6 +56,15 @@ func NewServer(config config.Config, store db.Store, mailClient mail.Client) (*S
 		store:      store,
 		tokenMaker: tokenMaker,
 		mailClient: mailClient,
+		blobStore:  blobStore,
+	}
+	if config.OIDCIssuer != "" {
+		server.oidcProvider = oidc.NewProvider(oidc.Config{
+			Issuer:       config.OIDCIssuer,
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			RedirectURL:  config.OIDCRedirectURL,
+		}, nil)
 	}
 
 	server.setupRouter()
@@ -41,6 +72,51 @@ func NewServer(config config.Config, store db.Store, mailClient mail.Client) (*S
 	return server, nil
 }
 
+// newTokenMaker makes tokens of TOKEN_FORMAT signed with the key of TOKEN_SIGNING_KEY_FILE, or with TOKEN_SECRET_KEY when there is none
+func newTokenMaker(config config.Config) (token.Maker, error) {
+	switch config.TokenFormat {
+	case "", "jwt":
+	case "paseto":
+		if config.TokenVerificationKeyFiles != "" {
+			return nil, errors.New("TOKEN_VERIFICATION_KEY_FILES isn't supported with TOKEN_FORMAT paseto")
+		}
+		if config.TokenSigningKeyFile == "" {
+			return token.NewPasetoLocalMaker(config.TokenSecretKey)
+		}
+		signingKey, err := os.ReadFile(config.TokenSigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		return token.NewPasetoPublicMaker(signingKey)
+	default:
+		return nil, fmt.Errorf("unknown token format %q", config.TokenFormat)
+	}
+
+	if config.TokenSigningKeyFile == "" {
+		return token.NewJWTMaker(config.TokenSecretKey)
+	}
+
+	signingKey, err := os.ReadFile(config.TokenSigningKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var verificationKeys [][]byte
+	for _, file := range strings.Split(config.TokenVerificationKeyFiles, ",") {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		key, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	return token.NewAsymmetricMaker(signingKey, verificationKeys)
+}
+
 func (server *Server) setupRouter() {
 	// TODO: serverに持たせる
 	logger, err := zap.NewProduction()
@@ -56,29 +132,98 @@ func (server *Server) setupRouter() {
 	docs.SwaggerInfo.BasePath = "/"
 
 	router.POST("/register", server.register)
+	router.POST("/register/resend", server.resendVerification)
 	router.POST("/verify", server.verify)
 
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
+	router.POST("/users/login/2fa", server.loginTwoFactor)
 	router.POST("/users/renew_access", server.renewAccessToken)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	router.POST("/password/forgot", server.forgotPassword)
+	router.POST("/password/reset", server.resetPassword)
+	router.POST("/email/confirm", server.confirmEmailChange)
 
-	authRoutes.GET("/users/me", server.getMe)
-	authRoutes.GET("/users/:id", server.getUser)
-	authRoutes.GET("/users", server.listUser)
+	router.POST("/oauth/device/code", server.createDeviceCode)
+	router.POST("/oauth/token", server.oauthToken)
 
-	authRoutes.POST("/webs", server.createWeb)
-	authRoutes.GET("/webs/:id", server.getWeb)
-	authRoutes.GET("/webs", server.listWeb)
-	authRoutes.DELETE("/webs/:id", server.deleteWeb)
+	router.GET("/.well-known/jwks.json", server.getJWKS)
+
+	router.GET("/oidc/login", server.oidcLogin)
+	router.POST("/oidc/callback", server.oidcCallback)
+
+	authRoutes := router.Group("/", authMiddleware(server.tokenMaker, server.store))
+	// personal access tokens only reach the routes below that name a scope
+	accountRoutes := authRoutes.Group("/", sessionOnlyMiddleware())
+
+	accountRoutes.GET("/users/me", server.getMe)
+	accountRoutes.DELETE("/users/me", server.deleteUser)
+	accountRoutes.GET("/users/me/export", server.exportUser)
+	accountRoutes.PUT("/users/me/password", server.changePassword)
+	accountRoutes.PUT("/users/me/email", server.changeEmail)
+	accountRoutes.POST("/users/me/2fa", server.setupTwoFactor)
+	accountRoutes.POST("/users/me/2fa/confirm", server.confirmTwoFactor)
+	accountRoutes.DELETE("/users/me/2fa", server.disableTwoFactor)
+	accountRoutes.POST("/users/logout", server.logoutUser)
+	accountRoutes.GET("/users/me/profile", server.getMyProfile)
+	accountRoutes.PUT("/users/me/profile", server.putProfile)
+	accountRoutes.PUT("/users/me/profile/avatar", server.putAvatar)
+	accountRoutes.DELETE("/users/me/profile/avatar", server.deleteAvatar)
+	accountRoutes.GET("/users/me/sessions", server.listSession)
+	accountRoutes.DELETE("/users/me/sessions", server.revokeAllSessions)
+	accountRoutes.DELETE("/users/me/sessions/:id", server.revokeSession)
+	accountRoutes.POST("/users/me/tokens", server.createPersonalAccessToken)
+	accountRoutes.GET("/users/me/tokens", server.listPersonalAccessToken)
+	accountRoutes.DELETE("/users/me/tokens/:id", server.revokePersonalAccessToken)
+	accountRoutes.POST("/users/me/identities/link", server.startLinkIdentity)
+	accountRoutes.POST("/users/me/identities", server.linkIdentity)
+	accountRoutes.GET("/users/me/identities", server.listIdentity)
+	accountRoutes.DELETE("/users/me/identities/:id", server.unlinkIdentity)
+	accountRoutes.POST("/oauth/device/approve", server.approveDeviceCode)
+	accountRoutes.POST("/oauth/device/deny", server.denyDeviceCode)
+	accountRoutes.GET("/users/:id", server.getUser)
+
+	adminRoutes := accountRoutes.Group("/admin", roleMiddleware(roleAdmin))
+	adminRoutes.GET("/users", server.listAdminUser)
+	adminRoutes.GET("/users/:id", server.getAdminUser)
+	adminRoutes.PUT("/users/:id/role", server.updateAdminUserRole)
+	adminRoutes.POST("/users/:id/block", server.blockAdminUser)
+	adminRoutes.POST("/users/:id/unblock", server.unblockAdminUser)
+	adminRoutes.DELETE("/users/:id/sessions", server.revokeAdminUserSessions)
+	adminRoutes.GET("/stats", server.getAdminStats)
+
+	authRoutes.POST("/webs", scopeMiddleware(scopeWebsWrite), server.createWeb)
+	authRoutes.GET("/webs/:id", scopeMiddleware(scopeWebsRead), server.getWeb)
+	authRoutes.POST("/webs/:id/refetch", scopeMiddleware(scopeWebsWrite), server.refetchWeb)
+	authRoutes.PUT("/webs/:id/tags", scopeMiddleware(scopeWebsWrite), server.putWebTags)
+	authRoutes.GET("/webs", scopeMiddleware(scopeWebsRead), server.listWeb)
+	authRoutes.DELETE("/webs/:id", scopeMiddleware(scopeWebsWrite), server.deleteWeb)
+
+	authRoutes.POST("/notes", scopeMiddleware(scopeNotesWrite), server.createNote)
+	authRoutes.GET("/notes/:id", scopeMiddleware(scopeNotesRead), server.getNote)
+	authRoutes.GET("/notes", scopeMiddleware(scopeNotesRead), server.listNote)
+	authRoutes.DELETE("/notes/:id", scopeMiddleware(scopeNotesWrite), server.deleteNote)
+	authRoutes.PUT("/notes/:id", scopeMiddleware(scopeNotesWrite), server.putNote)
+	authRoutes.GET("/notes/:id/revisions", scopeMiddleware(scopeNotesRead), server.listNoteRevision)
+	authRoutes.GET("/notes/:id/revisions/:revision", scopeMiddleware(scopeNotesRead), server.getNoteRevision)
+	authRoutes.POST("/notes/:id/revisions/:revision/restore", scopeMiddleware(scopeNotesWrite), server.restoreNoteRevision)
+	authRoutes.GET("/notes/:id/diff", scopeMiddleware(scopeNotesRead), server.diffNote)
+
+	authRoutes.GET("/tags", scopeMiddleware(scopeTagsRead), server.listTag)
+	authRoutes.POST("/tags", scopeMiddleware(scopeTagsWrite), server.createTag)
+	authRoutes.PUT("/tags/:id", scopeMiddleware(scopeTagsWrite), server.renameTag)
+	authRoutes.POST("/tags/:id/merge", scopeMiddleware(scopeTagsWrite), server.mergeTag)
+	authRoutes.DELETE("/tags/:id", scopeMiddleware(scopeTagsWrite), server.deleteTag)
+
+	// the scope depends on the types searched, it is checked by the handler
+	authRoutes.GET("/search", server.search)
 
-	authRoutes.POST("/notes", server.createNote)
-	authRoutes.GET("/notes/:id", server.getNote)
-	authRoutes.GET("/notes", server.listNote)
-	authRoutes.DELETE("/notes/:id", server.deleteNote)
-	authRoutes.PUT("/notes/:id", server.putNote)
 	router.GET("/public_notes/:id", server.getPublicNote)
+	router.GET("/profiles/:handle", server.getPublicProfile)
+	router.GET("/avatars/:name", server.getAvatar)
+	router.GET("/u/:handle", server.getAuthorPage)
+	router.GET("/u/:handle/feed.atom", server.getAuthorAtomFeed)
+	router.GET("/u/:handle/feed.rss", server.getAuthorRSSFeed)
 
 	// TODO: only env is dev
 	if server.config.Env == "dev" {
@@ -88,8 +233,42 @@ func (server *Server) setupRouter() {
 	server.router = router
 }
 
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+// Start serves on the address until ctx is cancelled, then lets the requests in flight finish for up to shutdownTimeout
+func (server *Server) Start(ctx context.Context, address string) error {
+	httpServer := &http.Server{
+		Addr:              address,
+		Handler:           server.router,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		shutdown <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	err := <-shutdown
+	server.background.Wait()
+	return err
+}
+
+// runInBackground runs fn once the response can go, so that how long fn takes doesn't show in it.
+// fn gets its own context since the one of the request ends with the response.
+func (server *Server) runInBackground(fn func(ctx context.Context) error) {
+	server.background.Add(1)
+	go func() {
+		defer server.background.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), backgroundTimeout)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			log.Println("background work failed: ", err)
+		}
+	}()
 }
 
 func errorResponse(err error) gin.H {
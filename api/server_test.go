@@ -0,0 +1,67 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/inkclip/backend/config"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenMaker(t *testing.T) {
+	secretKey := util.RandomString(32)
+
+	testCases := []struct {
+		name   string
+		config config.Config
+		check  func(t *testing.T, maker token.Maker, err error)
+	}{
+		{
+			name:   "JWT",
+			config: config.Config{TokenSecretKey: secretKey, TokenFormat: "jwt"},
+			check: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.JWTMaker{}, maker)
+			},
+		},
+		{
+			name:   "PasetoLocal",
+			config: config.Config{TokenSecretKey: secretKey, TokenFormat: "paseto"},
+			check: func(t *testing.T, maker token.Maker, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &token.PasetoLocalMaker{}, maker)
+			},
+		},
+		{
+			name: "PasetoVerificationKeys",
+			config: config.Config{
+				TokenSecretKey:            secretKey,
+				TokenFormat:               "paseto",
+				TokenSigningKeyFile:       "signing.pem",
+				TokenVerificationKeyFiles: "previous.pem",
+			},
+			check: func(t *testing.T, maker token.Maker, err error) {
+				// refused before any file is read, previous keys would be ignored otherwise
+				require.EqualError(t, err, "TOKEN_VERIFICATION_KEY_FILES isn't supported with TOKEN_FORMAT paseto")
+				require.Nil(t, maker)
+			},
+		},
+		{
+			name:   "UnknownFormat",
+			config: config.Config{TokenSecretKey: secretKey, TokenFormat: "macaroon"},
+			check: func(t *testing.T, maker token.Maker, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := newTokenMaker(tc.config)
+			tc.check(t, maker, err)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+)
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id" binding:"required"`
+	UserAgent string    `json:"user_agent" binding:"required"`
+	ClientIp  string    `json:"client_ip" binding:"required"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
+	// Current is set on the session of the token making the request
+	Current bool `json:"current" binding:"required"`
+}
+
+func newSessionResponse(session db.Session, current uuid.UUID) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+		Current:   session.ID == current,
+	}
+}
+
+// Blocks the session of the access token, neither it nor its refresh tokens can be used anymore.
+// @Success 200 {} {}
+// @Router /users/logout [post]
+// @Tags user
+// @Security AccessToken
+func (server *Server) logoutUser(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if _, ok := server.blockOwnSession(ctx, authPayload.SessionID); !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+type listSessionResponse struct {
+	Sessions []sessionResponse `json:"sessions"`
+}
+
+// Lists the sessions that are neither blocked nor expired, newest first.
+// @Success 200 {object} api.listSessionResponse
+// @Router /users/me/sessions [get]
+// @Tags user
+// @Security AccessToken
+func (server *Server) listSession(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	sessions, err := server.store.ListActiveSessionsByUserId(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listSessionResponse{
+		Sessions: make([]sessionResponse, len(sessions)),
+	}
+	for i, session := range sessions {
+		res.Sessions[i] = newSessionResponse(session, authPayload.SessionID)
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+type revokeSessionRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// @Param id path string true "Session ID"
+// @Success 200 {object} api.sessionResponse
+// @Router /users/me/sessions/{id} [delete]
+// @Tags user
+// @Security AccessToken
+func (server *Server) revokeSession(ctx *gin.Context) {
+	var req revokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(req.ID)
+	session, ok := server.blockOwnSession(ctx, id)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	ctx.JSON(http.StatusOK, newSessionResponse(session, authPayload.SessionID))
+}
+
+// Logs out everywhere, including the session making the request.
+// @Success 200 {} {}
+// @Router /users/me/sessions [delete]
+// @Tags user
+// @Security AccessToken
+func (server *Server) revokeAllSessions(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if err := server.store.BlockSessionsByUserId(ctx, authPayload.UserID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// blockOwnSession blocks the session with every session renewed from the same login.
+// It writes the error response and returns false when the session can't be blocked by the authenticated user
+func (server *Server) blockOwnSession(ctx *gin.Context, id uuid.UUID) (db.Session, bool) {
+	session, err := server.store.GetSession(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return session, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return session, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if session.UserID != authPayload.UserID {
+		err := errors.New("session doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return session, false
+	}
+
+	// renewals replace the session, the whole family is the device
+	err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return session, false
+	}
+	session.IsBlocked = true
+	return session, true
+}
@@ -0,0 +1,280 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/lib/pq"
+)
+
+type tagResponse struct {
+	ID        uuid.UUID `json:"id" binding:"required"`
+	Name      string    `json:"name" binding:"required"`
+	NoteCount int32     `json:"note_count" binding:"required"`
+	WebCount  int32     `json:"web_count" binding:"required"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
+}
+
+func newTagResponse(tag db.Tag) tagResponse {
+	return tagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		NoteCount: tag.NoteCount,
+		WebCount:  tag.WebCount,
+		CreatedAt: tag.CreatedAt,
+	}
+}
+
+// tagNames is how tags are shown on notes and webs
+func tagNames(tags []db.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+// normalizeTagNames trims the names and drops empty and duplicated ones
+func normalizeTagNames(names []string) []string {
+	var ret []string
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		ret = append(ret, name)
+	}
+	return ret
+}
+
+type listTagResponse struct {
+	Tags []tagResponse `json:"tags"`
+}
+
+// @Success 200 {object} api.listTagResponse
+// @Router /tags [get]
+// @Tags tag
+// @Security AccessToken
+func (server *Server) listTag(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	tags, err := server.store.ListTagsByUserId(ctx, authPayload.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := listTagResponse{
+		Tags: make([]tagResponse, len(tags)),
+	}
+	for i, tag := range tags {
+		res.Tags[i] = newTagResponse(tag)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type createTagRequest struct {
+	Name string `json:"name" binding:"required,max=30"`
+}
+
+// @Param request body api.createTagRequest true "query params"
+// @Success 200 {object} api.tagResponse
+// @Router /tags [post]
+// @Tags tag
+// @Security AccessToken
+func (server *Server) createTag(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var req createTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		err := errors.New("name must not be blank")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	tag, err := server.store.CreateTag(ctx, db.CreateTagParams{
+		UserID: authPayload.UserID,
+		Name:   name,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTagResponse(tag))
+}
+
+type tagURIRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// getOwnTag writes the error response and returns false when the tag can't be used by the authenticated user
+func (server *Server) getOwnTag(ctx *gin.Context, id uuid.UUID) (db.Tag, bool) {
+	tag, err := server.store.GetTag(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return tag, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return tag, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if tag.UserID != authPayload.UserID {
+		err := errors.New("tag doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return tag, false
+	}
+	return tag, true
+}
+
+type renameTagRequest struct {
+	Name string `json:"name" binding:"required,max=30"`
+}
+
+// Use merge to rename a tag to the name of another one.
+// @Param id path string true "Tag ID"
+// @Param request body api.renameTagRequest true "query params"
+// @Success 200 {object} api.tagResponse
+// @Router /tags/{id} [put]
+// @Tags tag
+// @Security AccessToken
+func (server *Server) renameTag(ctx *gin.Context) {
+	var uri tagURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req renameTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		err := errors.New("name must not be blank")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+	if _, ok := server.getOwnTag(ctx, id); !ok {
+		return
+	}
+
+	tag, err := server.store.UpdateTagName(ctx, db.UpdateTagNameParams{
+		ID:   id,
+		Name: name,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTagResponse(tag))
+}
+
+type mergeTagRequest struct {
+	TargetID string `json:"target_id" binding:"required,uuid"`
+}
+
+// Moves the notes and webs of the tag to the target tag and deletes the tag.
+// @Param id path string true "Tag ID"
+// @Param request body api.mergeTagRequest true "query params"
+// @Success 200 {object} api.tagResponse
+// @Router /tags/{id}/merge [post]
+// @Tags tag
+// @Security AccessToken
+func (server *Server) mergeTag(ctx *gin.Context) {
+	var uri tagURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req mergeTagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	sourceID, _ := uuid.Parse(uri.ID)
+	targetID, _ := uuid.Parse(req.TargetID)
+	if sourceID == targetID {
+		err := errors.New("cannot merge a tag into itself")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.getOwnTag(ctx, sourceID); !ok {
+		return
+	}
+	if _, ok := server.getOwnTag(ctx, targetID); !ok {
+		return
+	}
+
+	tag, err := server.store.TxMergeTags(ctx, db.TxMergeTagsParams{
+		SourceID: sourceID,
+		TargetID: targetID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTagResponse(tag))
+}
+
+// @Param id path string true "Tag ID"
+// @Success 200 {} {}
+// @Router /tags/{id} [delete]
+// @Tags tag
+// @Security AccessToken
+func (server *Server) deleteTag(ctx *gin.Context) {
+	var uri tagURIRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+	if _, ok := server.getOwnTag(ctx, id); !ok {
+		return
+	}
+
+	if err := server.store.DeleteTag(ctx, id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
@@ -0,0 +1,401 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/totp"
+	"github.com/inkclip/backend/util"
+)
+
+const (
+	// twoFactorIssuer is the name authenticator apps show next to the code
+	twoFactorIssuer   = "inkclip"
+	recoveryCodeCount = 10
+	// maxLoginChallengeAttempts bounds how many codes can be tried with one challenge
+	maxLoginChallengeAttempts = 5
+)
+
+var (
+	errInvalidTwoFactorCode  = errors.New("invalid two factor code")
+	errInvalidLoginChallenge = errors.New("login challenge is invalid or expired")
+)
+
+// verifyTwoFactorCode accepts a totp code or an unused recovery code, both can only be used once
+func (server *Server) verifyTwoFactorCode(ctx *gin.Context, twoFactor db.UserTwoFactor, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		step, ok := totp.Validate(twoFactor.TotpSecret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		_, err := server.store.UseTotpStep(ctx, db.UseTotpStepParams{
+			UserID:       twoFactor.UserID,
+			LastUsedStep: step,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		CodeHash: util.HashSecretToken(util.NormalizeRecoveryCode(code)),
+		UserID:   twoFactor.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// getEnabledTwoFactor returns the 2fa settings of the user, or false after responding 404 when 2fa is off
+func (server *Server) getEnabledTwoFactor(ctx *gin.Context, userID uuid.UUID) (db.UserTwoFactor, bool) {
+	twoFactor, err := server.store.GetUserTwoFactor(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("two factor authentication is not enabled")))
+			return twoFactor, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return twoFactor, false
+	}
+	if !twoFactor.EnabledAt.Valid {
+		ctx.JSON(http.StatusNotFound, errorResponse(errors.New("two factor authentication is not enabled")))
+		return twoFactor, false
+	}
+	return twoFactor, true
+}
+
+type setupTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type setupTwoFactorResponse struct {
+	Secret string `json:"secret" binding:"required"`
+	// OtpauthURI is meant to be shown as a QR code
+	OtpauthURI string `json:"otpauth_uri" binding:"required"`
+}
+
+// Starts the enrollment with a new secret, 2fa is enabled once POST /users/me/2fa/confirm gets a first code.
+// Calling it again before confirming replaces the secret.
+// @Param request body api.setupTwoFactorRequest true "query params"
+// @Success 200 {object} api.setupTwoFactorResponse
+// @Router /users/me/2fa [post]
+// @Tags user
+// @Security AccessToken
+func (server *Server) setupTwoFactor(ctx *gin.Context) {
+	var req setupTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	user, err := server.store.GetUser(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if err := util.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.UpsertUserTwoFactor(ctx, db.UpsertUserTwoFactorParams{
+		UserID:     user.ID,
+		TotpSecret: secret,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(errors.New("two factor authentication is already enabled")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, setupTwoFactorResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(twoFactorIssuer, user.Email, secret),
+	})
+}
+
+type confirmTwoFactorRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type confirmTwoFactorResponse struct {
+	// RecoveryCodes are only shown here, each one can replace a code once
+	RecoveryCodes []string `json:"recovery_codes" binding:"required"`
+}
+
+// Enables 2fa with a first code from the authenticator app.
+// @Param request body api.confirmTwoFactorRequest true "query params"
+// @Success 200 {object} api.confirmTwoFactorResponse
+// @Router /users/me/2fa/confirm [post]
+// @Tags user
+// @Security AccessToken
+func (server *Server) confirmTwoFactor(ctx *gin.Context) {
+	var req confirmTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	twoFactor, err := server.store.GetUserTwoFactor(ctx, authPayload.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(db.ErrTwoFactorNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if twoFactor.EnabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrTwoFactorNotPending))
+		return
+	}
+
+	step, ok := totp.Validate(twoFactor.TotpSecret, strings.TrimSpace(req.Code), time.Now())
+	if !ok {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInvalidTwoFactorCode))
+		return
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = util.NewRecoveryCode()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		hashes[i] = util.HashSecretToken(util.NormalizeRecoveryCode(codes[i]))
+	}
+
+	_, err = server.store.TxEnableTwoFactor(ctx, db.TxEnableTwoFactorParams{
+		UserID:             twoFactor.UserID,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		if err == db.ErrTwoFactorNotPending {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmTwoFactorResponse{RecoveryCodes: codes})
+}
+
+type disableTwoFactorRequest struct {
+	// Code is a code from the authenticator app or a recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+// @Param request body api.disableTwoFactorRequest true "query params"
+// @Success 200 {} {}
+// @Router /users/me/2fa [delete]
+// @Tags user
+// @Security AccessToken
+func (server *Server) disableTwoFactor(ctx *gin.Context) {
+	var req disableTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	twoFactor, ok := server.getEnabledTwoFactor(ctx, authPayload.UserID)
+	if !ok {
+		return
+	}
+
+	ok, err := server.verifyTwoFactorCode(ctx, twoFactor, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInvalidTwoFactorCode))
+		return
+	}
+
+	err = server.store.TxDisableTwoFactor(ctx, db.TxDisableTwoFactorParams{UserID: twoFactor.UserID})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+type loginUserChallengeResponse struct {
+	TwoFactorRequired  bool      `json:"two_factor_required" binding:"required"`
+	ChallengeToken     string    `json:"challenge_token" binding:"required"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at" binding:"required"`
+}
+
+// createLoginChallenge is what POST /users/login responds instead of tokens when the user has 2fa on
+func (server *Server) createLoginChallenge(ctx *gin.Context, user db.User) (loginUserChallengeResponse, error) {
+	var rsp loginUserChallengeResponse
+
+	challengeToken, err := util.NewSecretToken()
+	if err != nil {
+		return rsp, err
+	}
+
+	challenge, err := server.store.CreateLoginChallenge(ctx, db.CreateLoginChallengeParams{
+		TokenHash: util.HashSecretToken(challengeToken),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(server.config.TwoFactorChallengeDuration),
+	})
+	if err != nil {
+		return rsp, err
+	}
+
+	rsp = loginUserChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: challenge.ExpiresAt,
+	}
+	return rsp, nil
+}
+
+type loginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code is a code from the authenticator app or a recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+// Exchanges the challenge returned by POST /users/login and a code for tokens.
+// @Param request body api.loginTwoFactorRequest true "query params"
+// @Success 200 {object} api.loginUserRedirectResponse
+// @Router /users/login/2fa [post]
+// @Tags user
+func (server *Server) loginTwoFactor(ctx *gin.Context) {
+	var req loginTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the attempt is taken before the code is checked, so that concurrent requests can't try more codes than allowed
+	challengeHash := util.HashSecretToken(req.ChallengeToken)
+	challenge, err := server.store.IncrementLoginChallengeAttempts(ctx, db.IncrementLoginChallengeAttemptsParams{
+		TokenHash:   challengeHash,
+		MaxAttempts: maxLoginChallengeAttempts,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidLoginChallenge))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, challenge.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// wrong codes count against the email like wrong passwords, a new challenge doesn't start them over
+	emailKey := emailThrottleKey(user.Email)
+	lockedUntil, err := server.loginLockedUntil(ctx, emailKey)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !lockedUntil.IsZero() {
+		abortLoginLocked(ctx, lockedUntil)
+		return
+	}
+
+	twoFactor, err := server.store.GetUserTwoFactor(ctx, challenge.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidLoginChallenge))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	// 2fa was turned off since the challenge was handed out
+	if !twoFactor.EnabledAt.Valid {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidLoginChallenge))
+		return
+	}
+
+	ok, err := server.verifyTwoFactorCode(ctx, twoFactor, req.Code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !ok {
+		if err := server.recordFailedLogin(ctx, user.Email, &user); err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidTwoFactorCode))
+		return
+	}
+
+	_, err = server.store.UseLoginChallenge(ctx, challengeHash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidLoginChallenge))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if user.BlockedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountBlocked))
+		return
+	}
+
+	if err := server.store.DeleteLoginThrottle(ctx, emailKey); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.createLoginSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,695 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/mail"
+	mockmail "github.com/inkclip/backend/mail/mock"
+	"github.com/inkclip/backend/token"
+	"github.com/inkclip/backend/totp"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTwoFactor(t *testing.T, userID uuid.UUID, enabled bool) db.UserTwoFactor {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	twoFactor := db.UserTwoFactor{
+		UserID:     userID,
+		TotpSecret: secret,
+	}
+	if enabled {
+		twoFactor.EnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	return twoFactor
+}
+
+func currentTotpCode(t *testing.T, twoFactor db.UserTwoFactor) string {
+	code, err := totp.Code(twoFactor.TotpSecret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func serveTwoFactorRequest(t *testing.T, server *Server, method string, path string, body gin.H, setupAuth func(t *testing.T, request *http.Request, tokenMaker token.Maker)) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(method, path, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	setupAuth(t, request, server.tokenMaker)
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestSetupTwoFactorAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpsertUserTwoFactor(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UpsertUserTwoFactorParams) (db.UserTwoFactor, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						return db.UserTwoFactor{UserID: arg.UserID, TotpSecret: arg.TotpSecret}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res setupTwoFactorResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.Secret)
+
+				uri, err := url.Parse(res.OtpauthURI)
+				require.NoError(t, err)
+				require.Equal(t, "otpauth", uri.Scheme)
+				require.Equal(t, res.Secret, uri.Query().Get("secret"))
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"password": util.RandomString(8)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpsertUserTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			body: gin.H{"password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpsertUserTwoFactor(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := serveTwoFactorRequest(t, server, http.MethodPost, "/users/me/2fa", tc.body, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			})
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConfirmTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	pending := randomTwoFactor(t, user.ID, false)
+	enabled := randomTwoFactor(t, user.ID, true)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": currentTotpCode(t, pending)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					TxEnableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TxEnableTwoFactorParams) (db.UserTwoFactor, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Len(t, arg.RecoveryCodeHashes, recoveryCodeCount)
+						return enabled, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res confirmTwoFactorResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.Len(t, res.RecoveryCodes, recoveryCodeCount)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: gin.H{"code": "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					TxEnableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotEnrolled",
+			body: gin.H{"code": "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			body: gin.H{"code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					TxEnableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := serveTwoFactorRequest(t, server, http.MethodPost, "/users/me/2fa/confirm", tc.body, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			})
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDisableTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	enabled := randomTwoFactor(t, user.ID, true)
+	recoveryCode, err := util.NewRecoveryCode()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UseTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.UseTotpStepParams) (db.UserTwoFactor, error) {
+						require.Equal(t, totp.Step(time.Now()), arg.LastUsedStep)
+						return enabled, nil
+					})
+				store.EXPECT().
+					TxDisableTwoFactor(gomock.Any(), gomock.Eq(db.TxDisableTwoFactorParams{UserID: user.ID})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: gin.H{"code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Eq(db.UseRecoveryCodeParams{
+						CodeHash: util.HashSecretToken(util.NormalizeRecoveryCode(recoveryCode)),
+						UserID:   user.ID,
+					})).
+					Times(1).
+					Return(db.RecoveryCode{}, nil)
+				store.EXPECT().
+					TxDisableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ReplayedCode",
+			body: gin.H{"code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UseTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+				store.EXPECT().
+					TxDisableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: gin.H{"code": recoveryCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UseRecoveryCode(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.RecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().
+					TxDisableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "NotEnabled",
+			body: gin.H{"code": "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(randomTwoFactor(t, user.ID, false), nil)
+				store.EXPECT().
+					TxDisableTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := serveTwoFactorRequest(t, server, http.MethodDelete, "/users/me/2fa", tc.body, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			})
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginTwoFactorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	enabled := randomTwoFactor(t, user.ID, true)
+	challengeToken, err := util.NewSecretToken()
+	require.NoError(t, err)
+	challengeHash := util.HashSecretToken(challengeToken)
+	challenge := db.LoginChallenge{
+		TokenHash: challengeHash,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(5 * time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"challenge_token": challengeToken, "code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IncrementLoginChallengeAttempts(gomock.Any(), gomock.Eq(db.IncrementLoginChallengeAttemptsParams{
+						TokenHash:   challengeHash,
+						MaxAttempts: maxLoginChallengeAttempts,
+					})).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UseTotpStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(enabled, nil)
+				store.EXPECT().
+					UseLoginChallenge(gomock.Any(), gomock.Eq(challengeHash)).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Eq(emailThrottleKey(user.Email))).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, UserID: arg.UserID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res loginUserRedirectResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.AccessToken)
+			},
+		},
+		{
+			name: "WrongCode",
+			body: gin.H{"challenge_token": challengeToken, "code": "000000"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IncrementLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(enabled, nil)
+				// the wrong code counts against the email and the ip like a wrong password
+				store.EXPECT().
+					RecordLoginFailure(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(db.LoginThrottle{Failures: 1}, nil)
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UseLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidChallenge",
+			body: gin.H{"challenge_token": challengeToken, "code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				// unknown, expired, used and out of attempts are all no row
+				store.EXPECT().
+					IncrementLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.LoginChallenge{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "EmailLocked",
+			body: gin.H{"challenge_token": challengeToken, "code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IncrementLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ListLoginThrottles(gomock.Any(), gomock.Eq([]string{emailThrottleKey(user.Email)})).
+					Times(1).
+					Return([]db.LoginThrottle{{
+						Key:         emailThrottleKey(user.Email),
+						Failures:    5,
+						LockedUntil: sql.NullTime{Time: time.Now().Add(time.Minute), Valid: true},
+					}}, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				require.NotEmpty(t, recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name: "TwoFactorDisabledMeanwhile",
+			body: gin.H{"challenge_token": challengeToken, "code": currentTotpCode(t, enabled)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					IncrementLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(challenge, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := serveTwoFactorRequest(t, server, http.MethodPost, "/users/login/2fa", tc.body, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {})
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestLoginTwoFactorNewChallenges logs in with the right password again after every wrong code,
+// the new challenges mustn't give more tries than the email throttle allows
+func TestLoginTwoFactorNewChallenges(t *testing.T) {
+	user, password := randomUser(t)
+	enabled := randomTwoFactor(t, user.ID, true)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	mailClient := mockmail.NewMockClient(ctrl)
+
+	throttles := map[string]db.LoginThrottle{}
+	store.EXPECT().
+		ListLoginThrottles(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, keys []string) ([]db.LoginThrottle, error) {
+			var found []db.LoginThrottle
+			for _, key := range keys {
+				if throttle, ok := throttles[key]; ok {
+					found = append(found, throttle)
+				}
+			}
+			return found, nil
+		})
+	store.EXPECT().
+		RecordLoginFailure(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, arg db.RecordLoginFailureParams) (db.LoginThrottle, error) {
+			throttle := throttles[arg.Key]
+			throttle.Key = arg.Key
+			throttle.Failures++
+			throttles[arg.Key] = throttle
+			return throttle, nil
+		})
+	store.EXPECT().
+		LockLogin(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, arg db.LockLoginParams) error {
+			throttle := throttles[arg.Key]
+			throttle.LockedUntil = arg.LockedUntil
+			throttles[arg.Key] = throttle
+			return nil
+		})
+	store.EXPECT().
+		DeleteLoginThrottle(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, key string) error {
+			delete(throttles, key)
+			return nil
+		})
+
+	store.EXPECT().
+		GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+		AnyTimes().
+		Return(user, nil)
+	store.EXPECT().
+		GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+		AnyTimes().
+		Return(enabled, nil)
+	store.EXPECT().
+		CreateLoginChallenge(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, arg db.CreateLoginChallengeParams) (db.LoginChallenge, error) {
+			return db.LoginChallenge{TokenHash: arg.TokenHash, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+		})
+	// every challenge is fresh, only its first attempt is ever taken
+	store.EXPECT().
+		IncrementLoginChallengeAttempts(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, arg db.IncrementLoginChallengeAttemptsParams) (db.LoginChallenge, error) {
+			return db.LoginChallenge{TokenHash: arg.TokenHash, UserID: user.ID, Attempts: 1}, nil
+		})
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.ID)).
+		AnyTimes().
+		Return(user, nil)
+	store.EXPECT().
+		UseRecoveryCode(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.RecoveryCode{}, sql.ErrNoRows)
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	mailClient.EXPECT().
+		LoginLockoutMailContent(gomock.Eq(user.Email), gomock.Any()).
+		Times(1).
+		Return(mail.SendContent{})
+	mailClient.EXPECT().
+		Send(gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	server := newTestServer(t, store)
+	server.mailClient = mailClient
+
+	for i := 0; i < server.config.LoginMaxFailures; i++ {
+		recorder := serveTwoFactorRequest(t, server, http.MethodPost, "/users/login", gin.H{"email": user.Email, "password": password}, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {})
+		require.Equal(t, http.StatusAccepted, recorder.Code)
+
+		var challenge loginUserChallengeResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &challenge))
+
+		recorder = serveTwoFactorRequest(t, server, http.MethodPost, "/users/login/2fa", gin.H{"challenge_token": challenge.ChallengeToken, "code": "wrong-code"}, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {})
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+
+	// the right password doesn't get a new challenge once the wrong codes locked the email
+	recorder := serveTwoFactorRequest(t, server, http.MethodPost, "/users/login", gin.H{"email": user.Email, "password": password}, func(t *testing.T, request *http.Request, tokenMaker token.Maker) {})
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
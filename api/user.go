@@ -1,9 +1,14 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,13 +19,18 @@ import (
 	"github.com/lib/pq"
 )
 
+// temporaryUserDuration is how long the link sent on registration works
+const temporaryUserDuration = 24 * time.Hour
+
 type registerRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 }
 
+// Sends the address a verification link, at most one every REGISTER_RESEND_INTERVAL together with POST /register/resend.
 // @Param request body api.registerRequest true "query params"
 // @Success 200 {} {}
+// @Failure 429 {} {}
 // @Router /register [post]
 // @Tags user
 func (server *Server) register(ctx *gin.Context) {
@@ -30,6 +40,13 @@ func (server *Server) register(ctx *gin.Context) {
 		return
 	}
 
+	if server.abortIfEmailRecentlyDeleted(ctx, req.Email) {
+		return
+	}
+	if server.abortIfVerificationSentRecently(ctx, req.Email) {
+		return
+	}
+
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -41,7 +58,7 @@ func (server *Server) register(ctx *gin.Context) {
 		Email:          req.Email,
 		HashedPassword: hashedPassword,
 		Token:          token.String(),
-		ExpiresAt:      time.Now().Add(24 * time.Hour),
+		ExpiresAt:      time.Now().Add(temporaryUserDuration),
 	}
 	tmpUser, err := server.store.CreateTemporaryUser(ctx, arg)
 	if err != nil {
@@ -89,20 +106,29 @@ func (server *Server) verify(ctx *gin.Context) {
 	}
 	tmpUser, err := server.store.GetTemporaryUserByEmailAndToken(ctx, getTmpUserArg)
 	if err != nil {
+		// the link was already used or swept after expiring
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 	if tmpUser.ExpiresAt.Before(time.Now()) {
+		err := errors.New("verification link has expired")
 		ctx.JSON(http.StatusForbidden, errorResponse(err))
 		return
 	}
+	if server.abortIfEmailRecentlyDeleted(ctx, tmpUser.Email) {
+		return
+	}
 
 	arg := db.CreateUserParams{
 		Email:          tmpUser.Email,
 		HashedPassword: tmpUser.HashedPassword,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	user, err := server.store.TxVerifyUser(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -120,39 +146,95 @@ func (server *Server) verify(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, rsp)
 }
 
-// TODO: パスワード変更
-// type resetPasswordRequest struct {
-// 	Email string `json:"email" form:"email" binding:"required"`
-// }
-
-// // @Param request body api.changePasswordRequest true "query params"
-// // @Success 200 {} {}
-// // @Router /reset_password [post]
-// // @Tags user
-// func (server *Server) resetPassword(ctx *gin.Context) {
-// 	var req resetPasswordRequest
-// 	if err := ctx.ShouldBindJSON(&req); err != nil {
-// 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-// 		return
-// 	}
-
-// 	user, err := server.store.GetUserByEmail(ctx, req.Email)
-// 	if err != nil {
-// 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-// 		return
-// 	}
-
-// 	ctx.JSON(http.StatusOK, gin.H{})
-// }
+type resendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// Sends a new verification link for a pending registration, links sent before keep working until they expire.
+// The response is the same whether a registration is pending or not, the link is sent after it
+// and 429 comes for any address that was given to this or POST /register too recently.
+// @Param request body api.resendVerificationRequest true "query params"
+// @Success 200 {} {}
+// @Failure 429 {} {}
+// @Router /register/resend [post]
+// @Tags user
+func (server *Server) resendVerification(ctx *gin.Context) {
+	var req resendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.abortIfVerificationSentRecently(ctx, req.Email) {
+		return
+	}
+
+	server.runInBackground(func(bgCtx context.Context) error {
+		return server.resendVerificationMail(bgCtx, req.Email)
+	})
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// resendVerificationMail mails a new link of the latest pending registration of the email, nothing is sent when there is none
+func (server *Server) resendVerificationMail(ctx context.Context, email string) error {
+	latest, err := server.store.GetLatestTemporaryUserByEmail(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	tmpUser, err := server.store.CreateTemporaryUser(ctx, db.CreateTemporaryUserParams{
+		Email:          latest.Email,
+		HashedPassword: latest.HashedPassword,
+		Token:          uuid.New().String(),
+		ExpiresAt:      time.Now().Add(temporaryUserDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	mailArg := server.mailClient.VertifyMailContent(tmpUser.Email, tmpUser.Token)
+	return server.mailClient.Send(mailArg)
+}
+
+func verificationThrottleKey(email string) string {
+	return "verification:" + strings.ToLower(email)
+}
+
+// abortIfVerificationSentRecently claims the next verification link of the address, pending or not,
+// and responds 429 when one was sent within REGISTER_RESEND_INTERVAL
+func (server *Server) abortIfVerificationSentRecently(ctx *gin.Context, email string) bool {
+	_, err := server.store.ClaimMailSend(ctx, db.ClaimMailSendParams{
+		Key:        verificationThrottleKey(email),
+		SentBefore: time.Now().Add(-server.config.RegisterResendInterval),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// when the last link went out isn't read back, the whole interval is the longest to wait
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(server.config.RegisterResendInterval.Seconds()))))
+			err := errors.New("a verification link was sent recently")
+			ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+			return true
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return true
+	}
+	return false
+}
 
 type createUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 }
 
+// userResponse is what a user may see of an account, never the password hash
 type userResponse struct {
-	ID                uuid.UUID `json:"id" binding:"required"`
-	Email             string    `json:"email" binding:"required"`
+	ID    uuid.UUID `json:"id" binding:"required"`
+	Email string    `json:"email" binding:"required"`
+	// user or admin
+	Role              string    `json:"role" binding:"required"`
 	PasswordChangedAt time.Time `json:"password_changed_at" binding:"required"`
 	CreatedAt         time.Time `json:"created_at" binding:"required"`
 }
@@ -161,6 +243,7 @@ func newUserResponse(user db.User) userResponse {
 	return userResponse{
 		ID:                user.ID,
 		Email:             user.Email,
+		Role:              user.Role,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -177,6 +260,10 @@ func (server *Server) createUser(ctx *gin.Context) {
 		return
 	}
 
+	if server.abortIfEmailRecentlyDeleted(ctx, req.Email) {
+		return
+	}
+
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -208,10 +295,15 @@ func (server *Server) createUser(ctx *gin.Context) {
 
 // UUID support: https://github.com/gin-gonic/gin/pull/3045
 type getUserRequest struct {
-	ID string `uri:"id" binding:"required"`
+	ID string `uri:"id" binding:"required,uuid"`
 }
 
-// TODO: delete
+// Users can only get their own account, admins any.
+// @Param id path string true "User ID"
+// @Success 200 {object} api.userResponse
+// @Router /users/{id} [get]
+// @Tags user
+// @Security AccessToken
 func (server *Server) getUser(ctx *gin.Context) {
 	var req getUserRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
@@ -219,9 +311,11 @@ func (server *Server) getUser(ctx *gin.Context) {
 		return
 	}
 
-	id, err := uuid.Parse(req.ID)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	id, _ := uuid.Parse(req.ID)
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if id != authPayload.UserID && ctx.GetString(authorizationRoleKey) != roleAdmin {
+		err := errors.New("user isn't the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
@@ -235,34 +329,7 @@ func (server *Server) getUser(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, user)
-}
-
-type listUserRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-}
-
-// TODO: delete
-func (server *Server) listUser(ctx *gin.Context) {
-	var req listUserRequest
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	arg := db.ListUsersParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
-	}
-
-	users, err := server.store.ListUsers(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	ctx.JSON(http.StatusOK, users)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
 type loginUserRequest struct {
@@ -279,8 +346,14 @@ type loginUserRedirectResponse struct {
 	User                  userResponse `json:"user" binding:"required"`
 }
 
+// When the user has 2fa on, the response is 202 with a challenge to send to POST /users/login/2fa along with a code.
+// An unknown email and a wrong password are both 401. Too many failures for the email or from the client ip lock
+// the login, it is 429 with Retry-After until the lock ends.
 // @Param request body api.loginUserRequest true "query params"
 // @Success 200 {object} api.loginUserRedirectResponse
+// @Success 202 {object} api.loginUserChallengeResponse
+// @Failure 401 {} {}
+// @Failure 429 {} {}
 // @Router /users/login [post]
 // @Tags user
 func (server *Server) loginUser(ctx *gin.Context) {
@@ -290,55 +363,115 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	emailKey := emailThrottleKey(req.Email)
+	lockedUntil, err := server.loginLockedUntil(ctx, emailKey, ipThrottleKey(ctx.ClientIP()))
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !lockedUntil.IsZero() {
+		abortLoginLocked(ctx, lockedUntil)
+		return
+	}
+
+	var user *db.User
+	found, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err == nil {
+		user = &found
+	}
+
+	if !checkLoginPassword(req.Password, user) {
+		server.failLogin(ctx, req.Email, user)
+		return
+	}
+
+	server.respondLogin(ctx, *user)
+}
+
+// respondLogin finishes a login once the user is identified:
+// 403 when the user is blocked, 202 with a challenge when 2fa is on, otherwise 200 with the tokens of a new session
+func (server *Server) respondLogin(ctx *gin.Context, user db.User) {
+	if user.BlockedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountBlocked))
+		return
+	}
+
+	twoFactor, err := server.store.GetUserTwoFactor(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if err == nil && twoFactor.EnabledAt.Valid {
+		challenge, err := server.createLoginChallenge(ctx, user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 			return
 		}
+		ctx.JSON(http.StatusAccepted, challenge)
+		return
+	}
+
+	// the email starts over only once the login is complete, so that new challenges don't reset wrong 2fa codes.
+	// The ip keeps counting since one account of its own would reset it.
+	if err := server.store.DeleteLoginThrottle(ctx, emailThrottleKey(user.Email)); err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	rsp, err := server.createLoginSession(ctx, user)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// createLoginSession starts a new session family for the user and issues its tokens
+func (server *Server) createLoginSession(ctx *gin.Context, user db.User) (loginUserRedirectResponse, error) {
+	var rsp loginUserRedirectResponse
+
+	sessionID, err := uuid.NewRandom()
+	if err != nil {
+		return rsp, err
+	}
 
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.ID,
+		sessionID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return rsp, err
 	}
 
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.ID,
+		sessionID,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return rsp, err
 	}
 
 	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
+		ID:           sessionID,
 		UserID:       user.ID,
 		RefreshToken: refreshToken,
 		UserAgent:    ctx.Request.UserAgent(),
 		ClientIp:     ctx.ClientIP(),
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiresAt,
+		FamilyID:     sessionID,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return rsp, err
 	}
 
-	rsp := loginUserRedirectResponse{
+	rsp = loginUserRedirectResponse{
 		SessionID:             session.ID,
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiresAt,
@@ -346,7 +479,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		RefreshTokenExpiresAt: refreshPayload.ExpiresAt,
 		User:                  newUserResponse(user),
 	}
-	ctx.JSON(http.StatusOK, rsp)
+	return rsp, nil
 }
 
 type renewAccessTokenRequest struct {
@@ -354,10 +487,15 @@ type renewAccessTokenRequest struct {
 }
 
 type renewAccessTokenResponse struct {
-	AccessToken          string    `json:"access_token"`
-	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	SessionID             uuid.UUID `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
+// Every renewal replaces the refresh token, the one sent can't be used again.
+// Sending a replaced refresh token blocks every session issued since the login.
 // @Param request body api.renewAccessTokenRequest true "query params"
 // @Success 200 {object} api.renewAccessTokenResponse
 // @Router /users/renew_access [post]
@@ -375,7 +513,7 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	session, err := server.store.GetSession(ctx, refreshPayload.ID)
+	session, err := server.store.GetSession(ctx, refreshPayload.SessionID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
@@ -385,12 +523,6 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
-	if session.IsBlocked {
-		err := fmt.Errorf("session is blocked")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
 	if session.UserID != refreshPayload.UserID {
 		err := fmt.Errorf("incorrect session user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
@@ -403,14 +535,58 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	if session.RotatedAt.Valid {
+		server.abortRefreshTokenReuse(ctx, session)
+		return
+	}
+
+	if session.IsBlocked {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errSessionBlocked))
+		return
+	}
+
 	if time.Now().After(session.ExpiresAt) {
 		err := fmt.Errorf("expired session")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
+	authorization, err := server.store.GetUserAuthorization(ctx, db.GetUserAuthorizationParams{
+		ID:        session.UserID,
+		SessionID: session.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if issuedBeforePasswordChange(refreshPayload, authorization.PasswordChangedAt) {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errTokenBeforePasswordChange))
+		return
+	}
+	if authorization.BlockedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountBlocked))
+		return
+	}
+
+	newSessionID, err := uuid.NewRandom()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	refreshToken, newRefreshPayload, err := server.tokenMaker.CreateToken(
+		session.UserID,
+		newSessionID,
+		server.config.RefreshTokenDuration,
+	)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
-		refreshPayload.UserID,
+		session.UserID,
+		newSessionID,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -418,13 +594,47 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	newSession, err := server.store.TxRotateSession(ctx, db.TxRotateSessionParams{
+		SessionID: session.ID,
+		NewSession: db.CreateSessionParams{
+			ID:           newSessionID,
+			RefreshToken: refreshToken,
+			UserAgent:    ctx.Request.UserAgent(),
+			ClientIp:     ctx.ClientIP(),
+			IsBlocked:    false,
+			ExpiresAt:    newRefreshPayload.ExpiresAt,
+		},
+	})
+	if err != nil {
+		if err == db.ErrSessionRotated {
+			// another request renewed with the same refresh token in the meantime
+			server.abortRefreshTokenReuse(ctx, session)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	rsp := renewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessPayload.ExpiresAt,
+		SessionID:             newSession.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: newRefreshPayload.ExpiresAt,
 	}
 	ctx.JSON(http.StatusOK, rsp)
 }
 
+// abortRefreshTokenReuse blocks the whole family of the session since its refresh token may have been stolen
+func (server *Server) abortRefreshTokenReuse(ctx *gin.Context, session db.Session) {
+	if err := server.store.BlockSessionFamily(ctx, session.FamilyID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	err := fmt.Errorf("refresh token has already been used")
+	ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+}
+
 // @Success 200 {object} api.userResponse
 // @Router /users/me [get]
 // @Tags user
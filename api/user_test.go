@@ -47,7 +47,44 @@ func TestGetUserAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchUser(t, recorder.Body, user)
+				requireBodyMatchPostUser(t, recorder.Body, user)
+				require.NotContains(t, recorder.Body.String(), "hashed_password")
+			},
+		},
+		{
+			name:   "AnotherUser",
+			userID: user.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, uuid.New(), time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "AdminGetsAnotherUser",
+			userID: user.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, uuid.New(), time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserAuthorization(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetUserAuthorizationRow{Role: roleAdmin}, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostUser(t, recorder.Body, user)
 			},
 		},
 		{
@@ -287,32 +324,507 @@ func randomUser(t *testing.T) (user db.User, password string) {
 		ID:             id,
 		Email:          util.RandomEmail(),
 		HashedPassword: hashedPassword,
+		Role:           roleUser,
 	}
 	return
 }
 
-func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User) {
+func requireBodyMatchPostUser(t *testing.T, body *bytes.Buffer, user db.User) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	require.NoError(t, err)
-
 	var gotUser db.User
 	err = json.Unmarshal(data, &gotUser)
 
 	require.NoError(t, err)
 	require.Equal(t, user.Email, gotUser.Email)
-	require.NotEmpty(t, gotUser.HashedPassword)
+	require.Empty(t, gotUser.HashedPassword)
 }
 
-func requireBodyMatchPostUser(t *testing.T, body *bytes.Buffer, user db.User) {
-	data, err := io.ReadAll(body)
+func TestLoginUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, UserID: arg.UserID}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res loginUserRedirectResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEmpty(t, res.AccessToken)
+				require.NotEmpty(t, res.RefreshToken)
+			},
+		},
+		{
+			name: "TwoFactorPending",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				// an enrollment that was never confirmed doesn't count
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{UserID: user.ID}, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "TwoFactorEnabled",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Eq(user.ID)).
+					Times(1).
+					Return(db.UserTwoFactor{UserID: user.ID, EnabledAt: sql.NullTime{Time: time.Now(), Valid: true}}, nil)
+				store.EXPECT().
+					CreateLoginChallenge(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateLoginChallengeParams) (db.LoginChallenge, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						return db.LoginChallenge{TokenHash: arg.TokenHash, UserID: arg.UserID, ExpiresAt: arg.ExpiresAt}, nil
+					})
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res loginUserChallengeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.True(t, res.TwoFactorRequired)
+				require.NotEmpty(t, res.ChallengeToken)
+				require.NotContains(t, recorder.Body.String(), "access_token")
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{"email": user.Email, "password": util.RandomString(8)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserTwoFactor(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: gin.H{"email": user.Email, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// the same as a wrong password
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), errInvalidCredentials.Error())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.TwoFactorChallengeDuration = 5 * time.Minute
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		updateSession func(session *db.Session)
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					TxRotateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TxRotateSessionParams) (db.Session, error) {
+						require.Equal(t, session.ID, arg.SessionID)
+						require.NotEqual(t, session.ID, arg.NewSession.ID)
+						require.NotEqual(t, session.RefreshToken, arg.NewSession.RefreshToken)
+						return db.Session{
+							ID:           arg.NewSession.ID,
+							UserID:       session.UserID,
+							RefreshToken: arg.NewSession.RefreshToken,
+							ExpiresAt:    arg.NewSession.ExpiresAt,
+							FamilyID:     session.FamilyID,
+							ParentID:     uuid.NullUUID{UUID: session.ID, Valid: true},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res renewAccessTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+				require.NotEqual(t, session.ID, res.SessionID)
+
+				refreshPayload, err := tokenMaker.VerifyToken(res.RefreshToken)
+				require.NoError(t, err)
+				require.Equal(t, res.SessionID, refreshPayload.SessionID)
+				require.Equal(t, user.ID, refreshPayload.UserID)
+
+				accessPayload, err := tokenMaker.VerifyToken(res.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, res.SessionID, accessPayload.SessionID)
+			},
+		},
+		{
+			name: "ReusedRefreshToken",
+			updateSession: func(session *db.Session) {
+				session.RotatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					TxRotateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentlyRotated",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					TxRotateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, db.ErrSessionRotated)
+				store.EXPECT().
+					BlockSessionFamily(gomock.Any(), gomock.Eq(session.FamilyID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			updateSession: func(session *db.Session) {
+				session.IsBlocked = true
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					TxRotateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MismatchedRefreshToken",
+			updateSession: func(session *db.Session) {
+				session.RefreshToken = util.RandomString(32)
+			},
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					TxRotateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, tokenMaker token.Maker, session db.Session) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			sessionID := uuid.New()
+			refreshToken, payload, err := server.tokenMaker.CreateToken(user.ID, sessionID, time.Hour)
+			require.NoError(t, err)
+			session := db.Session{
+				ID:           sessionID,
+				UserID:       user.ID,
+				RefreshToken: refreshToken,
+				ExpiresAt:    payload.ExpiresAt,
+				FamilyID:     sessionID,
+			}
+			if tc.updateSession != nil {
+				tc.updateSession(&session)
+			}
+			tc.buildStubs(store, session)
+
+			data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/renew_access", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder, server.tokenMaker, session)
+		})
+	}
+}
+
+func TestRenewAccessTokenAfterPasswordChange(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sessionID := uuid.New()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUserAuthorization(gomock.Any(), gomock.Eq(db.GetUserAuthorizationParams{ID: user.ID, SessionID: sessionID})).
+		Times(1).
+		Return(db.GetUserAuthorizationRow{PasswordChangedAt: time.Now().Add(time.Minute), Role: roleUser}, nil)
+	server := newTestServer(t, store)
+
+	refreshToken, payload, err := server.tokenMaker.CreateToken(user.ID, sessionID, time.Hour)
+	require.NoError(t, err)
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Eq(sessionID)).
+		Times(1).
+		Return(db.Session{
+			ID:           sessionID,
+			UserID:       user.ID,
+			RefreshToken: refreshToken,
+			ExpiresAt:    payload.ExpiresAt,
+			FamilyID:     sessionID,
+		}, nil)
+	store.EXPECT().
+		TxRotateSession(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
 	require.NoError(t, err)
 
-	var gotUser db.User
-	err = json.Unmarshal(data, &gotUser)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/users/renew_access", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+// memorySessions keeps sessions for the store mock so that renewals can be chained
+type memorySessions struct {
+	sessions map[uuid.UUID]db.Session
+}
+
+func (m *memorySessions) expect(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetSession(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, id uuid.UUID) (db.Session, error) {
+			session, ok := m.sessions[id]
+			if !ok {
+				return db.Session{}, sql.ErrNoRows
+			}
+			return session, nil
+		})
+	store.EXPECT().
+		TxRotateSession(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, arg db.TxRotateSessionParams) (db.Session, error) {
+			rotated := m.sessions[arg.SessionID]
+			if rotated.RotatedAt.Valid {
+				return db.Session{}, db.ErrSessionRotated
+			}
+			rotated.RotatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			m.sessions[rotated.ID] = rotated
+
+			session := arg.NewSession
+			m.sessions[session.ID] = db.Session{
+				ID:           session.ID,
+				UserID:       rotated.UserID,
+				RefreshToken: session.RefreshToken,
+				ExpiresAt:    session.ExpiresAt,
+				FamilyID:     rotated.FamilyID,
+				ParentID:     uuid.NullUUID{UUID: rotated.ID, Valid: true},
+			}
+			return m.sessions[session.ID], nil
+		})
+	store.EXPECT().
+		BlockSessionFamily(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ interface{}, familyID uuid.UUID) error {
+			for id, session := range m.sessions {
+				if session.FamilyID == familyID {
+					session.IsBlocked = true
+					m.sessions[id] = session
+				}
+			}
+			return nil
+		})
+}
+
+func TestRenewAccessTokenRotationChain(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
 
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	sessionID := uuid.New()
+	refreshToken, payload, err := server.tokenMaker.CreateToken(user.ID, sessionID, time.Hour)
 	require.NoError(t, err)
-	require.Equal(t, user.Email, gotUser.Email)
-	require.Empty(t, gotUser.HashedPassword)
+	m := &memorySessions{sessions: map[uuid.UUID]db.Session{
+		sessionID: {
+			ID:           sessionID,
+			UserID:       user.ID,
+			RefreshToken: refreshToken,
+			ExpiresAt:    payload.ExpiresAt,
+			FamilyID:     sessionID,
+		},
+	}}
+	m.expect(store)
+
+	renew := func(refreshToken string) (*httptest.ResponseRecorder, renewAccessTokenResponse) {
+		data, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+		require.NoError(t, err)
+		request, err := http.NewRequest(http.MethodPost, "/users/renew_access", bytes.NewReader(data))
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		var res renewAccessTokenResponse
+		if recorder.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+		}
+		return recorder, res
+	}
+
+	// every renewal hands out the next refresh token of the chain
+	tokens := []string{refreshToken}
+	for i := 0; i < 3; i++ {
+		recorder, res := renew(tokens[len(tokens)-1])
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, sessionID, m.sessions[res.SessionID].FamilyID)
+		tokens = append(tokens, res.RefreshToken)
+	}
+	require.Len(t, m.sessions, 4)
+
+	// an old refresh token shows up again
+	recorder, _ := renew(tokens[1])
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	for _, session := range m.sessions {
+		require.True(t, session.IsBlocked)
+	}
+
+	// so the latest one doesn't work anymore either
+	recorder, _ = renew(tokens[len(tokens)-1])
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
 }
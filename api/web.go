@@ -3,13 +3,9 @@ package api
 import (
 	"database/sql"
 	"errors"
-	"io"
-	"log"
 	"net/http"
-	"strings"
 	"time"
 
-	"github.com/dyatlov/go-opengraph/opengraph"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	db "github.com/inkclip/backend/db/sqlc"
@@ -19,6 +15,8 @@ import (
 
 type createWebRequest struct {
 	URL string `json:"url" binding:"required,url"`
+	// tags that don't exist yet are created
+	Tags []string `json:"tags" binding:"max=10,dive,max=30"`
 }
 
 // omitempty 空の場合はレスポンスに含めない
@@ -29,19 +27,45 @@ type webResponse struct {
 	Title        string    `json:"title" binding:"required"`
 	ThumbnailURL string    `json:"thumbnail_url,omitempty" binding:"required"`
 	HTML         string    `json:"html" binding:"required"`
-	CreatedAt    time.Time `json:"created_at" binding:"required"`
+	Status       string    `json:"status" binding:"required"`
+	ErrorReason  string    `json:"error_reason,omitempty"`
+	// readable article extracted from the page, empty until the web is clipped
+	Description     string     `json:"description"`
+	Author          string     `json:"author"`
+	SiteName        string     `json:"site_name"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	ReadingMinutes  int32      `json:"reading_minutes"`
+	ArticleText     string     `json:"article_text"`
+	ArticleHTML     string     `json:"article_html"`
+	ArticleMarkdown string     `json:"article_markdown"`
+	// only set by the /webs endpoints
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at" binding:"required"`
 }
 
 func newWebResponse(web db.Web) webResponse {
-	return webResponse{
-		ID:           web.ID,
-		UserID:       web.UserID,
-		URL:          web.Url,
-		Title:        web.Title,
-		ThumbnailURL: web.ThumbnailUrl,
-		HTML:         web.Html,
-		CreatedAt:    web.CreatedAt,
+	res := webResponse{
+		ID:              web.ID,
+		UserID:          web.UserID,
+		URL:             web.Url,
+		Title:           web.Title,
+		ThumbnailURL:    web.ThumbnailUrl,
+		HTML:            web.Html,
+		Status:          web.Status,
+		ErrorReason:     web.ErrorReason,
+		Description:     web.Description,
+		Author:          web.Author,
+		SiteName:        web.SiteName,
+		ReadingMinutes:  web.ReadingMinutes,
+		ArticleText:     web.ArticleText,
+		ArticleHTML:     web.ArticleHtml,
+		ArticleMarkdown: web.ArticleMarkdown,
+		CreatedAt:       web.CreatedAt,
 	}
+	if web.PublishedAt.Valid {
+		res.PublishedAt = &web.PublishedAt.Time
+	}
+	return res
 }
 
 // @Param request body api.createWebRequest true "query params"
@@ -58,74 +82,98 @@ func (server *Server) createWeb(ctx *gin.Context) {
 		return
 	}
 
-	res, err := http.Get(req.URL)
+	arg := db.TxCreateWebParams{
+		CreateWebParams: db.CreateWebParams{
+			UserID: authPayload.UserID,
+			Url:    req.URL,
+			// replaced by the page title once the worker has fetched it
+			Title:        req.URL,
+			ThumbnailUrl: "",
+			Html:         "",
+			Status:       db.WebStatusPending,
+		},
+		MaxAttempts: server.config.WebJobMaxAttempts,
+		TagNames:    normalizeTagNames(req.Tags),
+	}
+
+	result, err := server.store.TxCreateWeb(ctx, arg)
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
-	defer func() {
-		if err := res.Body.Close(); err != nil {
-			log.Fatal("Error closing resp:", err)
-		}
-	}()
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	res := newWebResponse(result.Web)
+	res.Tags = tagNames(result.Tags)
+	ctx.JSON(http.StatusOK, res)
+}
+
+type getWebRequest struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+// @Param id path string true "Web ID"
+// @Success 200 {object} api.webResponse
+// @Router /webs/{id} [get]
+// @Tags web
+// @Security AccessToken
+func (server *Server) getWeb(ctx *gin.Context) {
+	var req getWebRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	og := opengraph.NewOpenGraph()
-	err = og.ProcessHTML(strings.NewReader(string(body)))
+	id, _ := uuid.Parse(req.ID)
+
+	web, err := server.store.GetWeb(ctx, id)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	var thumbnailURL string
-	if len(og.Images) != 0 {
-		thumbnailURL = og.Images[0].URL
-	}
-
-	arg := db.CreateWebParams{
-		UserID:       authPayload.UserID,
-		Url:          req.URL,
-		Title:        og.Title,
-		ThumbnailUrl: thumbnailURL,
-		Html:         string(body),
-	}
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	if arg.Title == "" {
-		arg.Title = req.URL
+	if web.UserID != authPayload.UserID {
+		err := errors.New("web doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
 	}
 
-	web, err := server.store.CreateWeb(ctx, arg)
+	tags, err := server.store.ListTagsByWebId(ctx, id)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newWebResponse(web))
+	res := newWebResponse(web)
+	res.Tags = tagNames(tags)
+	ctx.JSON(http.StatusOK, res)
 }
 
-type getWebRequest struct {
+type refetchWebRequest struct {
 	ID string `uri:"id" binding:"required,uuid"`
 }
 
 // @Param id path string true "Web ID"
 // @Success 200 {object} api.webResponse
-// @Router /webs/{id} [get]
+// @Router /webs/{id}/refetch [post]
 // @Tags web
 // @Security AccessToken
-func (server *Server) getWeb(ctx *gin.Context) {
-	var req getWebRequest
+func (server *Server) refetchWeb(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var req refetchWebRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
@@ -142,21 +190,90 @@ func (server *Server) getWeb(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if web.UserID != authPayload.UserID {
+		err := errors.New("web doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+	if web.Status == db.WebStatusPending {
+		err := errors.New("web is already being fetched")
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.TxRefetchWeb(ctx, db.TxRefetchWebParams{
+		WebID:       id,
+		MaxAttempts: server.config.WebJobMaxAttempts,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
+	ctx.JSON(http.StatusOK, newWebResponse(result.Web))
+}
+
+type putWebTagsRequest struct {
+	// replaces the tags of the web, tags that don't exist yet are created
+	Tags []string `json:"tags" binding:"max=10,dive,max=30"`
+}
+
+// @Param id path string true "Web ID"
+// @Param request body api.putWebTagsRequest true "query params"
+// @Success 200 {object} api.webResponse
+// @Router /webs/{id}/tags [put]
+// @Tags web
+// @Security AccessToken
+func (server *Server) putWebTags(ctx *gin.Context) {
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
+	var uri getWebRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	var req putWebTagsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	id, _ := uuid.Parse(uri.ID)
+
+	web, err := server.store.GetWeb(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 	if web.UserID != authPayload.UserID {
 		err := errors.New("web doesn't belong to the authenticated user")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newWebResponse(web))
+	tags, err := server.store.TxSetWebTags(ctx, db.TxSetWebTagsParams{
+		Web:      web,
+		TagNames: normalizeTagNames(req.Tags),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	res := newWebResponse(web)
+	res.Tags = tagNames(tags)
+	ctx.JSON(http.StatusOK, res)
 }
 
 type listWebRequest struct {
 	PageID   int32 `json:"page_id" form:"page_id" binding:"required,min=1"`
 	PageSize int32 `json:"page_size" form:"page_size" binding:"required,min=5,max=10"`
+	// only webs that have all of these tags
+	Tags []string `json:"tags" form:"tags" binding:"max=10,dive,max=30"`
 }
 
 type listWebResponse struct {
@@ -179,6 +296,7 @@ func (server *Server) listWeb(ctx *gin.Context) {
 
 	arg := db.ListWebsByUserIdParams{
 		UserID: authPayload.UserID,
+		Tags:   normalizeTagNames(req.Tags),
 		Limit:  req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
@@ -189,9 +307,27 @@ func (server *Server) listWeb(ctx *gin.Context) {
 		return
 	}
 
+	webIDs := make([]uuid.UUID, len(webs))
+	for i := range webs {
+		webIDs[i] = webs[i].ID
+	}
+
+	tagRows, err := server.store.ListTagsByWebIds(ctx, webIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
 	resWebs := []webResponse{}
 	for _, web := range webs {
-		resWebs = append(resWebs, newWebResponse(web))
+		res := newWebResponse(web)
+		res.Tags = []string{}
+		for _, row := range tagRows {
+			if row.WebID == web.ID {
+				res.Tags = append(res.Tags, row.Name)
+			}
+		}
+		resWebs = append(resWebs, res)
 	}
 
 	res := listWebResponse{
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -18,14 +19,13 @@ import (
 	db "github.com/inkclip/backend/db/sqlc"
 	"github.com/inkclip/backend/token"
 	"github.com/inkclip/backend/util"
-	"github.com/jarcoal/httpmock"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCreateWebAPI(t *testing.T) {
 	user, _ := randomUser(t)
-	web := randomWeb(t, user.ID)
+	web := randomPendingWeb(t, user.ID)
 
 	testCases := []struct {
 		name          string
@@ -43,24 +43,18 @@ func TestCreateWebAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				httpmock.RegisterResponder("GET", web.Url,
-					httpmock.NewStringResponder(
-						http.StatusOK,
-						web.Html,
-					),
-				)
-
-				arg := db.CreateWebParams{
-					UserID:       web.UserID,
-					Url:          web.Url,
-					Title:        web.Title,
-					ThumbnailUrl: web.ThumbnailUrl,
-					Html:         web.Html,
+				arg := db.TxCreateWebParams{
+					CreateWebParams: db.CreateWebParams{
+						UserID: web.UserID,
+						Url:    web.Url,
+						Title:  web.Url,
+						Status: db.WebStatusPending,
+					},
 				}
 				store.EXPECT().
-					CreateWeb(gomock.Any(), gomock.Eq(arg)).
+					TxCreateWeb(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(web, nil)
+					Return(db.TxCreateWebResult{Web: web}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -68,122 +62,108 @@ func TestCreateWebAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "Unauthorized",
+			name: "WithTags",
 			body: gin.H{
-				"url": web.Url,
+				"url":  web.Url,
+				"tags": []string{"go", " go "},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TxCreateWebParams{
+					CreateWebParams: db.CreateWebParams{
+						UserID: web.UserID,
+						Url:    web.Url,
+						Title:  web.Url,
+						Status: db.WebStatusPending,
+					},
+					TagNames: []string{"go"},
+				}
+				tag := randomTag(t, user.ID)
+				tag.Name = "go"
+				store.EXPECT().
+					TxCreateWeb(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TxCreateWebResult{Web: web, Tags: []db.Tag{tag}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got webResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, web.ID, got.ID)
+				require.Equal(t, []string{"go"}, got.Tags)
 			},
 		},
 		{
-			name: "ErrDB",
+			name: "TooLongTag",
 			body: gin.H{
-				"url": web.Url,
+				"url":  web.Url,
+				"tags": []string{strings.Repeat("a", 31)},
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				httpmock.RegisterResponder("GET", web.Url,
-					httpmock.NewStringResponder(
-						http.StatusOK,
-						web.Html,
-					),
-				)
-
-				arg := db.CreateWebParams{
-					UserID:       web.UserID,
-					Url:          web.Url,
-					Title:        web.Title,
-					ThumbnailUrl: web.ThumbnailUrl,
-					Html:         web.Html,
-				}
 				store.EXPECT().
-					CreateWeb(gomock.Any(), gomock.Eq(arg)).
-					Times(1).
-					Return(db.Web{}, sql.ErrConnDone)
+					TxCreateWeb(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name: "CannotGetOGP",
+			name: "Unauthorized",
 			body: gin.H{
 				"url": web.Url,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				httpmock.RegisterResponder("GET", web.Url,
-					httpmock.NewStringResponder(
-						http.StatusOK,
-						"",
-					),
-				)
-
-				arg := db.CreateWebParams{
-					UserID:       web.UserID,
-					Url:          web.Url,
-					Title:        web.Url,
-					ThumbnailUrl: "",
-				}
-				expectWeb := db.Web{
-					ID:           web.ID,
-					UserID:       web.UserID,
-					Url:          web.Url,
-					Title:        web.Url,
-					ThumbnailUrl: "",
-				}
 				store.EXPECT().
-					CreateWeb(gomock.Any(), gomock.Eq(arg)).
-					Times(1).
-					Return(expectWeb, nil)
+					TxCreateWeb(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusOK, recorder.Code)
-				matchWeb := db.Web{
-					ID:           web.ID,
-					UserID:       web.UserID,
-					Url:          web.Url,
-					Title:        web.Url,
-					ThumbnailUrl: "",
-				}
-				requireBodyMatchWeb(t, recorder.Body, matchWeb)
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
 		{
-			name: "InvalidURL",
+			name: "ErrDB",
 			body: gin.H{
-				"url": "invalid url",
+				"url": web.Url,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxCreateWeb(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TxCreateWebResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
-			name: "URLRequestFailed",
+			name: "InvalidURL",
 			body: gin.H{
-				"url": web.Url,
+				"url": "invalid url",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TxCreateWeb(gomock.Any(), gomock.Any()).
+					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
@@ -195,24 +175,10 @@ func TestCreateWebAPI(t *testing.T) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				httpmock.RegisterResponder("GET", web.Url,
-					httpmock.NewStringResponder(
-						http.StatusOK,
-						web.Html,
-					),
-				)
-
-				arg := db.CreateWebParams{
-					UserID:       web.UserID,
-					Url:          web.Url,
-					Title:        web.Title,
-					ThumbnailUrl: web.ThumbnailUrl,
-					Html:         web.Html,
-				}
 				store.EXPECT().
-					CreateWeb(gomock.Any(), gomock.Eq(arg)).
+					TxCreateWeb(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Web{}, &pq.Error{Code: "23505"})
+					Return(db.TxCreateWebResult{}, &pq.Error{Code: "23505"})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusForbidden, recorder.Code)
@@ -224,9 +190,6 @@ func TestCreateWebAPI(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			httpmock.Activate()
-			defer httpmock.DeactivateAndReset()
-
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
@@ -250,9 +213,159 @@ func TestCreateWebAPI(t *testing.T) {
 	}
 }
 
+func TestRefetchWebAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	web := randomWeb(t, user.ID)
+	web.Status = db.WebStatusFailed
+	web.ErrorReason = "cannot fetch url"
+
+	testCases := []struct {
+		name          string
+		webID         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			webID: web.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				pending := web
+				pending.Status = db.WebStatusPending
+				pending.ErrorReason = ""
+				store.EXPECT().
+					TxRefetchWeb(gomock.Any(), gomock.Eq(db.TxRefetchWebParams{WebID: web.ID})).
+					Times(1).
+					Return(db.TxRefetchWebResult{Web: pending}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got webResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.WebStatusPending, got.Status)
+				require.Empty(t, got.ErrorReason)
+			},
+		},
+		{
+			name:  "AlreadyPending",
+			webID: web.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				pending := web
+				pending.Status = db.WebStatusPending
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(pending, nil)
+
+				store.EXPECT().
+					TxRefetchWeb(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:  "NotFound",
+			webID: web.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(db.Web{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "RequestFromUnauthorizedUser",
+			webID: web.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				user2, _ := randomUser(t)
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(randomWeb(t, user2.ID), nil)
+
+				store.EXPECT().
+					TxRefetchWeb(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "ErrTx",
+			webID: web.ID.String(),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				store.EXPECT().
+					TxRefetchWeb(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TxRefetchWebResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/webs/%v/refetch", tc.webID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestGetWebAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	web := randomWeb(t, user.ID)
+	tags := []db.Tag{randomTag(t, user.ID)}
 
 	testCases := []struct {
 		name          string
@@ -272,6 +385,10 @@ func TestGetWebAPI(t *testing.T) {
 					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
 					Times(1).
 					Return(web, nil)
+				store.EXPECT().
+					ListTagsByWebId(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(tags, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -387,9 +504,21 @@ func TestListWebAPI(t *testing.T) {
 		webs[i] = randomWeb(t, user.ID)
 	}
 
+	tagRows := make([]db.ListTagsByWebIdsRow, n)
+	for i, web := range webs {
+		tag := randomTag(t, user.ID)
+		tagRows[i] = db.ListTagsByWebIdsRow{
+			ID:     tag.ID,
+			UserID: tag.UserID,
+			Name:   tag.Name,
+			WebID:  web.ID,
+		}
+	}
+
 	type Query struct {
 		pageID   int
 		pageSize int
+		tags     []string
 	}
 
 	testCases := []struct {
@@ -418,12 +547,47 @@ func TestListWebAPI(t *testing.T) {
 					ListWebsByUserId(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(webs, nil)
+				store.EXPECT().
+					ListTagsByWebIds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(tagRows, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 				requireBodyMatchWebs(t, recorder.Body, webs)
 			},
 		},
+		{
+			name: "FilterByTags",
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+				tags:     []string{"go"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListWebsByUserIdParams{
+					UserID: user.ID,
+					Tags:   []string{"go"},
+					Limit:  int32(n),
+					Offset: 0,
+				}
+				store.EXPECT().
+					ListWebsByUserId(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(webs[:1], nil)
+				store.EXPECT().
+					ListTagsByWebIds(gomock.Any(), gomock.Eq([]uuid.UUID{webs[0].ID})).
+					Times(1).
+					Return(tagRows[:1], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchWebs(t, recorder.Body, webs[:1])
+			},
+		},
 		// {
 		// 	name: "InvalidPageID",
 		// 	query: Query{
@@ -529,6 +693,9 @@ func TestListWebAPI(t *testing.T) {
 			q := request.URL.Query()
 			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
 			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			for _, tag := range tc.query.tags {
+				q.Add("tags", tag)
+			}
 			request.URL.RawQuery = q.Encode()
 
 			tc.setupAuth(t, request, server.tokenMaker)
@@ -714,9 +881,29 @@ func randomWeb(t *testing.T, userID uuid.UUID) db.Web {
 			title,
 			thumbnailURL,
 		),
+		ArticleText:     "Clipped article",
+		ArticleHtml:     "<p>Clipped article</p>",
+		ArticleMarkdown: "Clipped article",
+		SiteName:        util.RandomName(),
+		ReadingMinutes:  1,
+		Status:          db.WebStatusDone,
 	}
 }
 
+func randomPendingWeb(t *testing.T, userID uuid.UUID) db.Web {
+	web := randomWeb(t, userID)
+	web.Title = web.Url
+	web.ThumbnailUrl = ""
+	web.Html = ""
+	web.ArticleText = ""
+	web.ArticleHtml = ""
+	web.ArticleMarkdown = ""
+	web.SiteName = ""
+	web.ReadingMinutes = 0
+	web.Status = db.WebStatusPending
+	return web
+}
+
 func requireBodyMatchWeb(t *testing.T, body *bytes.Buffer, web db.Web) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -729,6 +916,11 @@ func requireBodyMatchWeb(t *testing.T, body *bytes.Buffer, web db.Web) {
 	require.Equal(t, web.Url, gotWeb.Url)
 	require.Equal(t, web.Title, gotWeb.Title)
 	require.Equal(t, web.ThumbnailUrl, gotWeb.ThumbnailUrl)
+	require.Equal(t, web.Status, gotWeb.Status)
+	require.Equal(t, web.ArticleHtml, gotWeb.ArticleHtml)
+	require.Equal(t, web.ArticleMarkdown, gotWeb.ArticleMarkdown)
+	require.Equal(t, web.SiteName, gotWeb.SiteName)
+	require.Equal(t, web.ReadingMinutes, gotWeb.ReadingMinutes)
 }
 
 func requireBodyMatchWebs(t *testing.T, body *bytes.Buffer, webs []db.Web) {
@@ -740,3 +932,146 @@ func requireBodyMatchWebs(t *testing.T, body *bytes.Buffer, webs []db.Web) {
 	require.NoError(t, err)
 	require.Equal(t, len(got.Webs), len(webs))
 }
+
+func TestPutWebTagsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	web := randomWeb(t, user.ID)
+	tag := randomTag(t, user.ID)
+
+	testCases := []struct {
+		name          string
+		webID         string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			webID: web.ID.String(),
+			body:  gin.H{"tags": []string{tag.Name}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+				store.EXPECT().
+					TxSetWebTags(gomock.Any(), gomock.Eq(db.TxSetWebTagsParams{Web: web, TagNames: []string{tag.Name}})).
+					Times(1).
+					Return([]db.Tag{tag}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got webResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, web.ID, got.ID)
+				require.Equal(t, []string{tag.Name}, got.Tags)
+			},
+		},
+		{
+			name:  "ClearTags",
+			webID: web.ID.String(),
+			body:  gin.H{"tags": []string{}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+				store.EXPECT().
+					TxSetWebTags(gomock.Any(), gomock.Eq(db.TxSetWebTagsParams{Web: web})).
+					Times(1).
+					Return([]db.Tag{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "OtherUsersWeb",
+			webID: web.ID.String(),
+			body:  gin.H{"tags": []string{tag.Name}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, uuid.New(), time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+				store.EXPECT().
+					TxSetWebTags(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "NotFound",
+			webID: web.ID.String(),
+			body:  gin.H{"tags": []string{tag.Name}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(db.Web{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "TooManyTags",
+			webID: web.ID.String(),
+			body:  gin.H{"tags": []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.ID, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/webs/%s/tags", tc.webID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package article
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Article is the readable part of a web page
+type Article struct {
+	Title       string
+	SiteName    string
+	Author      string
+	Description string
+	// PublishedAt is zero when the page doesn't tell
+	PublishedAt time.Time
+	// Text is the plain text of the article, paragraphs separated by a blank line
+	Text string
+	// HTML is a sanitized html fragment of the article
+	HTML     string
+	Markdown string
+	// ReadingMinutes is the estimated time to read Text
+	ReadingMinutes int32
+}
+
+// Extract finds the main content of an html page.
+// pageURL is used to resolve relative links and images.
+func Extract(body []byte, pageURL string) (*Article, error) {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse html: %w", err)
+	}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse page url: %w", err)
+	}
+	if href := baseHref(doc); href != "" {
+		if ref, err := url.Parse(href); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	meta := readMeta(doc)
+
+	prune(doc)
+	content := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, node := range findContent(doc) {
+		for _, child := range sanitizeNode(node, base) {
+			content.AppendChild(child)
+		}
+	}
+
+	var buf bytes.Buffer
+	for child := content.FirstChild; child != nil; child = child.NextSibling {
+		if err := html.Render(&buf, child); err != nil {
+			return nil, fmt.Errorf("cannot render html: %w", err)
+		}
+	}
+
+	text := plainText(content)
+
+	return &Article{
+		Title:          meta.title,
+		SiteName:       meta.siteName,
+		Author:         meta.author,
+		Description:    meta.description,
+		PublishedAt:    meta.publishedAt,
+		Text:           text,
+		HTML:           buf.String(),
+		Markdown:       markdown(content),
+		ReadingMinutes: readingMinutes(text),
+	}, nil
+}
+
+const (
+	// words per minute for space separated languages
+	wordsPerMinute = 200
+	// characters per minute for Japanese, Chinese and Korean
+	cjkCharsPerMinute = 500
+)
+
+func readingMinutes(text string) int32 {
+	var words, cjk int
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		case unicode.IsSpace(r):
+			inWord = false
+		}
+	}
+	if words == 0 && cjk == 0 {
+		return 0
+	}
+
+	minutes := float64(words)/wordsPerMinute + float64(cjk)/cjkCharsPerMinute
+	return int32(math.Max(1, math.Ceil(minutes)))
+}
+
+func baseHref(doc *html.Node) string {
+	var href string
+	walk(doc, func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Base {
+			href = attr(n, "href")
+			return false
+		}
+		return href == ""
+	})
+	return href
+}
+
+// walk visits n and its descendants in document order.
+// Returning false from fn skips the children of that node.
+func walk(n *html.Node, fn func(*html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func innerText(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(n *html.Node) bool {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		return true
+	})
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
@@ -0,0 +1,118 @@
+package article
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Fallback title</title>
+	<meta property="og:title" content="How to clip the web" />
+	<meta property="og:site_name" content="Example Blog" />
+	<meta name="author" content="Jane Doe" />
+	<meta name="description" content="Keep what you read." />
+	<meta property="article:published_time" content="2022-12-01T19:00:00+09:00" />
+	<style>body { color: red; }</style>
+</head>
+<body>
+	<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+	<div class="sidebar"><p>Subscribe to our newsletter, it is the best one around here.</p></div>
+	<article class="post">
+		<h1>How to clip the web</h1>
+		<p>Reading on the web is great, but pages change and disappear, so it is worth keeping a copy.</p>
+		<p>A clip keeps the <strong>text</strong>, the <em>links</em> and <a href="/images">the images</a>, without the ads.</p>
+		<img data-src="/img/clip.png" src="data:image/gif;base64,R0lGOD" alt="clip" />
+		<ul><li>first point</li><li>second point</li></ul>
+		<pre>go run main.go</pre>
+		<p onclick="alert(1)">Scripts <script>alert(1)</script>never survive, and neither do <a href="javascript:alert(1)">bad links</a>.</p>
+	</article>
+	<footer>Copyright</footer>
+</body>
+</html>`
+
+func TestExtract(t *testing.T) {
+	got, err := Extract([]byte(testPage), "https://example.com/posts/clip")
+	require.NoError(t, err)
+
+	require.Equal(t, "How to clip the web", got.Title)
+	require.Equal(t, "Example Blog", got.SiteName)
+	require.Equal(t, "Jane Doe", got.Author)
+	require.Equal(t, "Keep what you read.", got.Description)
+	require.Equal(t, time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC), got.PublishedAt)
+	require.Equal(t, int32(1), got.ReadingMinutes)
+
+	require.Contains(t, got.HTML, `<h1>How to clip the web</h1>`)
+	require.Contains(t, got.HTML, `<a href="https://example.com/images" rel="nofollow noopener noreferrer">the images</a>`)
+	require.Contains(t, got.HTML, `<img src="https://example.com/img/clip.png" alt="clip"/>`)
+	require.Contains(t, got.HTML, `<pre>go run main.go</pre>`)
+	for _, removed := range []string{"<script", "onclick", "javascript:", "newsletter", "Copyright", "About", "<style"} {
+		require.NotContains(t, got.HTML, removed)
+	}
+
+	require.Contains(t, got.Markdown, "# How to clip the web")
+	require.Contains(t, got.Markdown, "A clip keeps the **text**, the *links* and [the images](https://example.com/images), without the ads.")
+	require.Contains(t, got.Markdown, "![clip](https://example.com/img/clip.png)")
+	require.Contains(t, got.Markdown, "- first point\n- second point")
+	require.Contains(t, got.Markdown, "```\ngo run main.go\n```")
+
+	require.True(t, strings.HasPrefix(got.Text, "How to clip the web\n\nReading on the web is great"))
+	require.Contains(t, got.Text, "A clip keeps the text, the links and the images, without the ads.")
+	require.Contains(t, got.Text, "Scripts never survive, and neither do bad links.")
+	require.NotContains(t, got.Text, "*")
+}
+
+func TestExtractFallbacks(t *testing.T) {
+	page := `<html><head><base href="https://cdn.example.com/blog/"><title>Only a title</title></head>
+		<body><time datetime="2021-05-04">May 4</time><a rel="author" href="/me">John</a>
+		<p>short</p><img src="cover.png"></body></html>`
+
+	got, err := Extract([]byte(page), "https://example.com/post")
+	require.NoError(t, err)
+	require.Equal(t, "Only a title", got.Title)
+	require.Equal(t, "John", got.Author)
+	require.Equal(t, time.Date(2021, 5, 4, 0, 0, 0, 0, time.UTC), got.PublishedAt)
+	require.Contains(t, got.HTML, `<img src="https://cdn.example.com/blog/cover.png"/>`)
+}
+
+func TestExtractEmpty(t *testing.T) {
+	got, err := Extract([]byte(""), "https://example.com")
+	require.NoError(t, err)
+	require.Empty(t, got.Title)
+	require.Empty(t, got.Text)
+	require.Empty(t, got.HTML)
+	require.Zero(t, got.ReadingMinutes)
+	require.True(t, got.PublishedAt.IsZero())
+}
+
+func TestReadingMinutes(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want int32
+	}{
+		{name: "Empty", text: "", want: 0},
+		{name: "Short", text: "a few words", want: 1},
+		{name: "Words", text: strings.Repeat("word ", 401), want: 3},
+		{name: "Japanese", text: strings.Repeat("あ", 1000), want: 2},
+		{name: "Mixed", text: strings.Repeat("word ", 200) + strings.Repeat("字", 500), want: 2},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, readingMinutes(tc.text))
+		})
+	}
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	got, err := Extract([]byte(`<body><article><p>Use *stars* and _underscores_ in [brackets], they are plain text here.</p></article></body>`), "https://example.com")
+	require.NoError(t, err)
+	require.Equal(t, `Use \*stars\* and \_underscores\_ in \[brackets\], they are plain text here.`, got.Markdown)
+	require.Equal(t, "Use *stars* and _underscores_ in [brackets], they are plain text here.", got.Text)
+}
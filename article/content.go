@@ -0,0 +1,261 @@
+package article
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// patterns borrowed from Mozilla's Readability
+var (
+	unlikelyRe = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	maybeRe    = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveRe = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeRe = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// elements that never hold article content
+var removedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Button:   true,
+	atom.Input:    true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Nav:      true,
+	atom.Footer:   true,
+	atom.Aside:    true,
+	atom.Svg:      true,
+	atom.Canvas:   true,
+	atom.Template: true,
+	atom.Link:     true,
+	atom.Meta:     true,
+}
+
+const minParagraphLength = 25
+
+// prune removes boilerplate before scoring
+func prune(doc *html.Node) {
+	var removed []*html.Node
+	walk(doc, func(n *html.Node) bool {
+		if n.Type == html.CommentNode {
+			removed = append(removed, n)
+			return false
+		}
+		if n.Type != html.ElementNode {
+			return true
+		}
+		if removedElements[n.DataAtom] || isHidden(n) || isUnlikely(n) {
+			removed = append(removed, n)
+			return false
+		}
+		return true
+	})
+	for _, n := range removed {
+		if n.Parent != nil {
+			n.Parent.RemoveChild(n)
+		}
+	}
+}
+
+func isHidden(n *html.Node) bool {
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	return hasAttr(n, "hidden") ||
+		attr(n, "aria-hidden") == "true" ||
+		strings.Contains(style, "display:none") ||
+		strings.Contains(style, "visibility:hidden")
+}
+
+func isUnlikely(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Article, atom.Main, atom.A:
+		return false
+	}
+	match := attr(n, "class") + " " + attr(n, "id")
+	if strings.TrimSpace(match) == "" {
+		return false
+	}
+	return unlikelyRe.MatchString(match) && !maybeRe.MatchString(match) && !hasAncestor(n, atom.Article)
+}
+
+// findContent returns the nodes that make up the article, in document order
+func findContent(doc *html.Node) []*html.Node {
+	scores := map[*html.Node]float64{}
+	var candidates []*html.Node
+
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Td, atom.Blockquote:
+		default:
+			return true
+		}
+
+		text := innerText(n)
+		length := utf8.RuneCountInString(text)
+		if length < minParagraphLength {
+			return false
+		}
+
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "、")+strings.Count(text, "，"))
+		score += math.Min(float64(length)/100, 3)
+
+		addScore(n.Parent, score)
+		if n.Parent != nil {
+			addScore(n.Parent.Parent, score/2)
+		}
+		return false
+	})
+
+	var top *html.Node
+	var topScore float64
+	for _, n := range candidates {
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > topScore {
+			top = n
+			topScore = scores[n]
+		}
+	}
+
+	if top == nil {
+		if body := findFirst(doc, atom.Body); body != nil {
+			return []*html.Node{body}
+		}
+		return []*html.Node{doc}
+	}
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+
+	// siblings often hold the rest of the article, e.g. when paragraphs are split into several divs
+	threshold := math.Max(10, topScore*0.2)
+	var content []*html.Node
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		if sibling == top {
+			content = append(content, sibling)
+			continue
+		}
+		if score, ok := scores[sibling]; ok && score >= threshold {
+			content = append(content, sibling)
+			continue
+		}
+		if sibling.DataAtom == atom.P {
+			text := innerText(sibling)
+			length := utf8.RuneCountInString(text)
+			density := linkDensity(sibling)
+			if (length > 80 && density < 0.25) ||
+				(length > 0 && density == 0 && (strings.HasSuffix(text, ".") || strings.HasSuffix(text, "。"))) {
+				content = append(content, sibling)
+			}
+		}
+	}
+	return content
+}
+
+func initialScore(n *html.Node) float64 {
+	score := classWeight(n)
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score += 10
+	case atom.Div:
+		score += 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score += 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score -= 3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score -= 5
+	}
+	return score
+}
+
+func classWeight(n *html.Node) float64 {
+	var weight float64
+	for _, v := range []string{attr(n, "class"), attr(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if negativeRe.MatchString(v) {
+			weight -= 25
+		}
+		if positiveRe.MatchString(v) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of the text of n that sits inside links
+func linkDensity(n *html.Node) float64 {
+	length := utf8.RuneCountInString(innerText(n))
+	if length == 0 {
+		return 0
+	}
+	var linkLength int
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.ElementNode && c.DataAtom == atom.A {
+			linkLength += utf8.RuneCountInString(innerText(c))
+			return false
+		}
+		return true
+	})
+	return float64(linkLength) / float64(length)
+}
+
+func findFirst(n *html.Node, a atom.Atom) *html.Node {
+	var found *html.Node
+	walk(n, func(c *html.Node) bool {
+		if found != nil {
+			return false
+		}
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			found = c
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func hasAncestor(n *html.Node, a atom.Atom) bool {
+	for p := n.Parent; p != nil; p = p.Parent {
+		if p.Type == html.ElementNode && p.DataAtom == a {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package article
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// renderer turns a sanitized tree into markdown, or into plain text when plain is set
+type renderer struct {
+	plain bool
+}
+
+func markdown(root *html.Node) string {
+	r := renderer{}
+	return strings.Join(r.blocks(root), "\n\n")
+}
+
+func plainText(root *html.Node) string {
+	r := renderer{plain: true}
+	return strings.Join(r.blocks(root), "\n\n")
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+)
+
+func isBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	switch n.DataAtom {
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Li, atom.Blockquote, atom.Pre, atom.Hr,
+		atom.Table, atom.Figure, atom.Figcaption, atom.Div:
+		return true
+	}
+	return false
+}
+
+// blocks renders the children of n as a list of paragraphs
+func (r renderer) blocks(n *html.Node) []string {
+	var ret []string
+	var inline strings.Builder
+
+	flush := func() {
+		if text := strings.TrimSpace(inline.String()); text != "" {
+			ret = append(ret, text)
+		}
+		inline.Reset()
+	}
+	add := func(block string) {
+		if strings.TrimSpace(block) != "" {
+			ret = append(ret, block)
+		}
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if !isBlock(c) {
+			inline.WriteString(r.inline(c))
+			continue
+		}
+		flush()
+
+		switch c.DataAtom {
+		case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+			text := strings.TrimSpace(r.inlineChildren(c))
+			if text != "" && !r.plain {
+				level := int(c.Data[1] - '0')
+				text = strings.Repeat("#", level) + " " + text
+			}
+			add(text)
+		case atom.Ul, atom.Ol:
+			add(r.list(c, ""))
+		case atom.Blockquote:
+			inner := r.blocks(c)
+			if r.plain {
+				ret = append(ret, inner...)
+				continue
+			}
+			lines := strings.Split(strings.Join(inner, "\n\n"), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			add(strings.Join(lines, "\n"))
+		case atom.Pre:
+			code := strings.Trim(rawText(c), "\n")
+			if !r.plain {
+				code = "```\n" + code + "\n```"
+			}
+			add(code)
+		case atom.Hr:
+			if !r.plain {
+				ret = append(ret, "---")
+			}
+		case atom.Table:
+			add(r.table(c))
+		case atom.Li:
+			text := strings.TrimSpace(r.inlineChildren(c))
+			if text != "" && !r.plain {
+				text = "- " + text
+			}
+			add(text)
+		default:
+			ret = append(ret, r.blocks(c)...)
+		}
+	}
+	flush()
+
+	return ret
+}
+
+func (r renderer) inlineChildren(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(r.inline(c))
+	}
+	return sb.String()
+}
+
+func (r renderer) inline(n *html.Node) string {
+	if n.Type == html.TextNode {
+		text := collapseSpaces(n.Data)
+		if !r.plain {
+			text = markdownEscaper.Replace(text)
+		}
+		return text
+	}
+	if n.Type != html.ElementNode {
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		if r.plain {
+			return "\n"
+		}
+		return "  \n"
+	case atom.Img:
+		if r.plain {
+			return ""
+		}
+		return fmt.Sprintf("![%s](%s)", markdownEscaper.Replace(attr(n, "alt")), attr(n, "src"))
+	case atom.Code:
+		if r.plain {
+			return rawText(n)
+		}
+		return "`" + strings.ReplaceAll(rawText(n), "`", "'") + "`"
+	}
+
+	text := r.inlineChildren(n)
+	if r.plain {
+		if isBlock(n) {
+			return " " + text + " "
+		}
+		return text
+	}
+
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	switch n.DataAtom {
+	case atom.A:
+		return fmt.Sprintf("[%s](%s)", trimmed, attr(n, "href"))
+	case atom.Strong, atom.B:
+		return surround(text, "**")
+	case atom.Em, atom.I:
+		return surround(text, "*")
+	}
+	if isBlock(n) {
+		return " " + text + " "
+	}
+	return text
+}
+
+func (r renderer) list(n *html.Node, indent string) string {
+	var lines []string
+	i := 0
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.DataAtom != atom.Li {
+			continue
+		}
+		i++
+
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", i)
+		}
+		if r.plain {
+			marker = ""
+		}
+
+		var text strings.Builder
+		var nested []string
+		for c := li.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Ul || c.DataAtom == atom.Ol) {
+				nested = append(nested, r.list(c, indent+strings.Repeat(" ", len(marker))))
+				continue
+			}
+			text.WriteString(r.inline(c))
+		}
+
+		if item := strings.TrimSpace(collapseSpaces(text.String())); item != "" {
+			lines = append(lines, indent+marker+item)
+		}
+		for _, block := range nested {
+			if block != "" {
+				lines = append(lines, block)
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r renderer) table(n *html.Node) string {
+	var rows [][]string
+	walk(n, func(c *html.Node) bool {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Tr {
+			return true
+		}
+		var cells []string
+		for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+				text := strings.TrimSpace(r.inlineChildren(cell))
+				if !r.plain {
+					text = strings.ReplaceAll(text, "|", `\|`)
+				}
+				cells = append(cells, text)
+			}
+		}
+		if len(cells) != 0 {
+			rows = append(rows, cells)
+		}
+		return false
+	})
+	if len(rows) == 0 {
+		return ""
+	}
+
+	if r.plain {
+		lines := make([]string, len(rows))
+		for i, row := range rows {
+			lines[i] = strings.Join(row, "\t")
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	line := func(row []string) string {
+		cells := make([]string, columns)
+		copy(cells, row)
+		return "| " + strings.Join(cells, " | ") + " |"
+	}
+
+	lines := []string{line(rows[0]), line(strings.Split(strings.Repeat("---,", columns-1)+"---", ","))}
+	for _, row := range rows[1:] {
+		lines = append(lines, line(row))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// surround wraps the text in marker while keeping surrounding spaces outside of it
+func surround(text string, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+func rawText(n *html.Node) string {
+	var sb strings.Builder
+	walk(n, func(c *html.Node) bool {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+		if c.Type == html.ElementNode && c.DataAtom == atom.Br {
+			sb.WriteString("\n")
+		}
+		return true
+	})
+	return sb.String()
+}
+
+func collapseSpaces(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			if !space {
+				sb.WriteByte(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
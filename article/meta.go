@@ -0,0 +1,146 @@
+package article
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+type pageMeta struct {
+	title       string
+	siteName    string
+	author      string
+	description string
+	publishedAt time.Time
+}
+
+// keys are checked in order, the first non empty value wins
+var (
+	titleKeys       = []string{"og:title", "twitter:title"}
+	siteNameKeys    = []string{"og:site_name", "application-name"}
+	authorKeys      = []string{"author", "article:author", "dc.creator", "byl", "twitter:creator"}
+	descriptionKeys = []string{"description", "og:description", "twitter:description", "dc.description"}
+	publishedKeys   = []string{
+		"article:published_time",
+		"og:published_time",
+		"datepublished",
+		"date",
+		"pubdate",
+		"publishdate",
+		"dc.date",
+		"dc.date.issued",
+		"sailthru.date",
+	}
+)
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+func readMeta(doc *html.Node) pageMeta {
+	values := map[string]string{}
+	var documentTitle, timeDatetime, relAuthor string
+
+	walk(doc, func(n *html.Node) bool {
+		if n.Type != html.ElementNode {
+			return true
+		}
+		switch n.DataAtom {
+		case atom.Meta:
+			content := strings.TrimSpace(attr(n, "content"))
+			if content == "" {
+				return true
+			}
+			for _, key := range []string{attr(n, "property"), attr(n, "name"), attr(n, "itemprop")} {
+				key = strings.ToLower(strings.TrimSpace(key))
+				if key != "" && values[key] == "" {
+					values[key] = content
+				}
+			}
+		case atom.Title:
+			if documentTitle == "" {
+				documentTitle = innerText(n)
+			}
+		case atom.Time:
+			if timeDatetime == "" {
+				timeDatetime = attr(n, "datetime")
+			}
+		case atom.A:
+			if relAuthor == "" && strings.EqualFold(attr(n, "rel"), "author") {
+				relAuthor = innerText(n)
+			}
+		}
+		return true
+	})
+
+	meta := pageMeta{
+		title:       first(values, titleKeys),
+		siteName:    first(values, siteNameKeys),
+		description: first(values, descriptionKeys),
+	}
+	if meta.title == "" {
+		meta.title = documentTitle
+	}
+
+	// article:author is often a profile url rather than a name
+	for _, key := range authorKeys {
+		if v := values[key]; v != "" && !strings.HasPrefix(v, "http") {
+			meta.author = v
+			break
+		}
+	}
+	if meta.author == "" {
+		meta.author = relAuthor
+	}
+
+	for _, v := range append(collect(values, publishedKeys), timeDatetime) {
+		if t, ok := parseTime(v); ok {
+			meta.publishedAt = t
+			break
+		}
+	}
+
+	return meta
+}
+
+func first(values map[string]string, keys []string) string {
+	for _, key := range keys {
+		if v := values[key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func collect(values map[string]string, keys []string) []string {
+	ret := []string{}
+	for _, key := range keys {
+		if v := values[key]; v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func parseTime(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
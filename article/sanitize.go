@@ -0,0 +1,154 @@
+package article
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// allowedElements are kept in the sanitized html, every other element is unwrapped
+var allowedElements = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Br:         true,
+	atom.Hr:         true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Li:         true,
+	atom.Blockquote: true,
+	atom.Pre:        true,
+	atom.Code:       true,
+	atom.Em:         true,
+	atom.I:          true,
+	atom.Strong:     true,
+	atom.B:          true,
+	atom.A:          true,
+	atom.Img:        true,
+	atom.Figure:     true,
+	atom.Figcaption: true,
+	atom.Table:      true,
+	atom.Thead:      true,
+	atom.Tbody:      true,
+	atom.Tr:         true,
+	atom.Th:         true,
+	atom.Td:         true,
+}
+
+// voidElements may be kept without any text inside
+var voidElements = map[atom.Atom]bool{
+	atom.Br:  true,
+	atom.Hr:  true,
+	atom.Img: true,
+}
+
+// sanitize returns a cleaned copy of the children of n.
+// Only allowed elements and safe attributes survive, urls are made absolute.
+func sanitize(n *html.Node, base *url.URL) []*html.Node {
+	var ret []*html.Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		ret = append(ret, sanitizeNode(child, base)...)
+	}
+	return ret
+}
+
+func sanitizeNode(n *html.Node, base *url.URL) []*html.Node {
+	switch n.Type {
+	case html.TextNode:
+		return []*html.Node{{Type: html.TextNode, Data: n.Data}}
+	case html.DocumentNode:
+		return sanitize(n, base)
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	if removedElements[n.DataAtom] {
+		return nil
+	}
+	if !allowedElements[n.DataAtom] {
+		return sanitize(n, base)
+	}
+
+	clean := &html.Node{Type: html.ElementNode, Data: n.DataAtom.String(), DataAtom: n.DataAtom}
+	switch n.DataAtom {
+	case atom.A:
+		href, ok := safeURL(attr(n, "href"), base, "http", "https", "mailto")
+		if !ok {
+			return sanitize(n, base)
+		}
+		clean.Attr = []html.Attribute{
+			{Key: "href", Val: href},
+			{Key: "rel", Val: "nofollow noopener noreferrer"},
+		}
+	case atom.Img:
+		src, ok := safeURL(imageSource(n), base, "http", "https")
+		if !ok {
+			return nil
+		}
+		clean.Attr = []html.Attribute{{Key: "src", Val: src}}
+		if alt := attr(n, "alt"); alt != "" {
+			clean.Attr = append(clean.Attr, html.Attribute{Key: "alt", Val: alt})
+		}
+	}
+
+	for _, child := range sanitize(n, base) {
+		clean.AppendChild(child)
+	}
+
+	if !voidElements[n.DataAtom] && isEmpty(clean) {
+		return nil
+	}
+	return []*html.Node{clean}
+}
+
+// imageSource prefers lazy loading attributes since src is often a placeholder then
+func imageSource(n *html.Node) string {
+	for _, key := range []string{"data-src", "data-original", "src"} {
+		if v := attr(n, key); v != "" && !strings.HasPrefix(v, "data:") {
+			return v
+		}
+	}
+	return ""
+}
+
+func safeURL(raw string, base *url.URL, schemes ...string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	u := base.ResolveReference(ref)
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return u.String(), true
+		}
+	}
+	return "", false
+}
+
+func isEmpty(n *html.Node) bool {
+	empty := true
+	walk(n, func(c *html.Node) bool {
+		if !empty {
+			return false
+		}
+		if c.Type == html.TextNode && strings.TrimSpace(c.Data) != "" {
+			empty = false
+		}
+		if c.Type == html.ElementNode && c.DataAtom == atom.Img {
+			empty = false
+		}
+		return true
+	})
+	return empty
+}
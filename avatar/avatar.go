@@ -0,0 +1,113 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+)
+
+const (
+	// Size is the width and the height of every avatar
+	Size = 256
+	// MaxUploadSize is the largest file accepted for an avatar
+	MaxUploadSize = 5 << 20
+	// maxPixels bounds the decoded image, a small file can declare a huge one
+	maxPixels = 4096 * 4096
+)
+
+var (
+	ErrUnsupportedImage = errors.New("avatar must be a png, jpeg or gif image")
+	ErrImageTooLarge    = errors.New("avatar image is too large")
+)
+
+// Resize crops the middle square of the image and scales it to Size x Size, encoded as png.
+// Only the first frame of an animated gif is kept.
+func Resize(data []byte) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scale(squareRGBA(src), Size)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// squareRGBA copies the middle square of img, the longer side is cut evenly on both ends
+func squareRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	min := image.Point{
+		X: b.Min.X + (b.Dx()-side)/2,
+		Y: b.Min.Y + (b.Dy()-side)/2,
+	}
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, min, draw.Src)
+	return square
+}
+
+// scale resizes the square src to size x size.
+// Every pixel is the average of the source pixels it covers, so shrinking doesn't alias,
+// and it is the nearest source pixel when growing.
+func scale(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	side := src.Bounds().Dx()
+
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, size, side)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, size, side)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4 : y*dst.Stride+x*4+4]
+			d[0] = uint8(r / n)
+			d[1] = uint8(g / n)
+			d[2] = uint8(b / n)
+			d[3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// span is the range of source pixels under the destination pixel i, never empty
+func span(i, size, side int) (int, int) {
+	start := i * side / size
+	end := (i + 1) * side / size
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
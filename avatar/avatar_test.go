@@ -0,0 +1,69 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestResize(t *testing.T) {
+	// a wide image whose middle square is red, the sides are blue
+	img := image.NewRGBA(image.Rect(0, 0, 900, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 900; x++ {
+			c := color.RGBA{B: 255, A: 255}
+			if x >= 300 && x < 600 {
+				c = color.RGBA{R: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	data, err := Resize(encodePNG(t, img))
+	require.NoError(t, err)
+
+	resized, format, err := image.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "png", format)
+	require.Equal(t, image.Rect(0, 0, Size, Size), resized.Bounds())
+
+	for _, p := range []image.Point{{0, 0}, {Size - 1, Size - 1}, {Size / 2, Size / 2}} {
+		r, g, b, a := resized.At(p.X, p.Y).RGBA()
+		require.Equal(t, [4]uint32{0xffff, 0, 0, 0xffff}, [4]uint32{r, g, b, a}, p)
+	}
+}
+
+func TestResizeSmallJPEG(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 20, 40))
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+
+	data, err := Resize(buf.Bytes())
+	require.NoError(t, err)
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, Size, cfg.Width)
+	require.Equal(t, Size, cfg.Height)
+}
+
+func TestResizeInvalid(t *testing.T) {
+	_, err := Resize([]byte("not an image"))
+	require.ErrorIs(t, err, ErrUnsupportedImage)
+
+	// only the header is read to refuse it, the pixels are never decoded
+	huge := encodePNG(t, image.NewGray(image.Rect(0, 0, 5000, 5000)))
+	_, err = Resize(huge)
+	require.ErrorIs(t, err, ErrImageTooLarge)
+}
@@ -0,0 +1,116 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/inkclip/backend/config"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("blob key is invalid")
+)
+
+// keySegmentRe is one part of a key between slashes, keys never climb out of the store
+var keySegmentRe = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// Store keeps uploaded files, such as the avatars of users, by key
+type Store interface {
+	// Put writes data at key, replacing what was there
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns ErrNotFound when nothing is at key
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete does nothing when nothing is at key
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore returns the store of BLOB_STORE
+func NewStore(config config.Config) (Store, error) {
+	switch config.BlobStore {
+	case "", "local":
+		return NewLocalStore(config.BlobDir), nil
+	}
+	return nil, fmt.Errorf("unknown blob store %q", config.BlobStore)
+}
+
+// ValidKey is true for slash separated keys whose parts are letters, digits, dots, dashes and underscores
+func ValidKey(key string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		if !keySegmentRe.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// LocalStore keeps every blob in a file under its directory
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) Store {
+	return &LocalStore{dir: dir}
+}
+
+func (store *LocalStore) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(store.dir, filepath.FromSlash(key)), nil
+}
+
+func (store *LocalStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// written aside and renamed, so that a reader never sees half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (store *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (store *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package blob
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inkclip/backend/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "avatars/a.png")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Put(ctx, "avatars/a.png", []byte("first")))
+	require.NoError(t, store.Put(ctx, "avatars/a.png", []byte("second")))
+
+	data, err := store.Get(ctx, "avatars/a.png")
+	require.NoError(t, err)
+	require.Equal(t, []byte("second"), data)
+
+	require.NoError(t, store.Delete(ctx, "avatars/a.png"))
+	require.NoError(t, store.Delete(ctx, "avatars/a.png"))
+
+	_, err = store.Get(ctx, "avatars/a.png")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestValidKey(t *testing.T) {
+	for _, key := range []string{"a.png", "avatars/a-b_c.png"} {
+		require.True(t, ValidKey(key), key)
+	}
+	for _, key := range []string{"", "../a.png", "avatars/../../a", "/a.png", "a//b", ".hidden", "a\\b"} {
+		require.False(t, ValidKey(key), key)
+	}
+
+	store := NewLocalStore(t.TempDir())
+	require.ErrorIs(t, store.Put(context.Background(), "../escape", []byte("x")), ErrInvalidKey)
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore(config.Config{BlobStore: "local", BlobDir: t.TempDir()})
+	require.NoError(t, err)
+	require.IsType(t, &LocalStore{}, store)
+
+	_, err = NewStore(config.Config{BlobStore: "s3"})
+	require.Error(t, err)
+}
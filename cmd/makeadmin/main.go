@@ -0,0 +1,48 @@
+// Command makeadmin gives the admin role to the user of an email, the first admin can't be made through the api.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"os"
+
+	"github.com/inkclip/backend/config"
+	db "github.com/inkclip/backend/db/sqlc"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: makeadmin <email>")
+	}
+	email := os.Args[1]
+
+	config, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+
+	store := db.NewStore(conn)
+
+	user, err := store.GetUserByEmail(context.Background(), email)
+	if err != nil {
+		log.Fatal("cannot get user: ", err)
+	}
+
+	_, err = store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{
+		ID:   user.ID,
+		Role: "admin",
+	})
+	if err != nil {
+		log.Fatal("cannot update role: ", err)
+	}
+
+	log.Printf("%s is an admin", email)
+}
@@ -0,0 +1,42 @@
+// Command reextract refreshes the readable article of every clipped web from its stored html.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/inkclip/backend/config"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/worker"
+	"go.uber.org/zap"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	config, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db: ", err)
+	}
+
+	store := db.NewStore(conn)
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal("cannot create logger: ", err)
+	}
+	defer logger.Sync()
+
+	updated, err := worker.Reextract(context.Background(), store, logger)
+	if err != nil {
+		log.Fatal("cannot reextract articles: ", err)
+	}
+
+	log.Printf("reextracted %d webs", updated)
+}
@@ -23,6 +23,61 @@ type Config struct {
 	MailUsername         string        `mapstructure:"MAIL_USERNAME"`
 	MailPassword         string        `mapstructure:"MAIL_PASSWORD"`
 	FrontURL             string        `mapstructure:"FRONT_URL"`
+	FetchTimeout         time.Duration `mapstructure:"FETCH_TIMEOUT"`
+	FetchMaxBodySize     int64         `mapstructure:"FETCH_MAX_BODY_SIZE"`
+	FetchMaxRedirects    int           `mapstructure:"FETCH_MAX_REDIRECTS"`
+	WorkerConcurrency    int           `mapstructure:"WORKER_CONCURRENCY"`
+	WorkerPollInterval   time.Duration `mapstructure:"WORKER_POLL_INTERVAL"`
+	WebJobMaxAttempts    int32         `mapstructure:"WEB_JOB_MAX_ATTEMPTS"`
+	// PasswordResetTokenDuration is how long a link sent by POST /password/forgot works
+	PasswordResetTokenDuration time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	// EmailChangeTokenDuration is how long a link sent by PUT /users/me/email works
+	EmailChangeTokenDuration time.Duration `mapstructure:"EMAIL_CHANGE_TOKEN_DURATION"`
+	// AccountDeletionGracePeriod is how long the email of a deleted user can't be registered again
+	AccountDeletionGracePeriod time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	// RegisterResendInterval is how long POST /register and POST /register/resend wait before sending another link to the same address
+	RegisterResendInterval time.Duration `mapstructure:"REGISTER_RESEND_INTERVAL"`
+	// SweepInterval is how often expired registrations are purged
+	SweepInterval time.Duration `mapstructure:"SWEEP_INTERVAL"`
+	// TwoFactorChallengeDuration is how long the challenge returned by POST /users/login can be exchanged when 2fa is on
+	TwoFactorChallengeDuration time.Duration `mapstructure:"TWO_FACTOR_CHALLENGE_DURATION"`
+	// OAuthClientID is the client_id the extension sends in the device authorization flow
+	OAuthClientID string `mapstructure:"OAUTH_CLIENT_ID"`
+	// DeviceCodeDuration is how long the code returned by POST /oauth/device/code can be approved and exchanged
+	DeviceCodeDuration time.Duration `mapstructure:"DEVICE_CODE_DURATION"`
+	// DeviceCodeInterval is how long the extension must wait between two polls of POST /oauth/token
+	DeviceCodeInterval time.Duration `mapstructure:"DEVICE_CODE_INTERVAL"`
+	// OIDCIssuer is the issuer identifier of the OpenID Connect provider to sign in with, the sign in is off when empty
+	OIDCIssuer string `mapstructure:"OIDC_ISSUER"`
+	// OIDCClientID is the client registered at the OpenID Connect provider
+	OIDCClientID string `mapstructure:"OIDC_CLIENT_ID"`
+	// OIDCClientSecret is the secret of OIDCClientID
+	OIDCClientSecret string `mapstructure:"OIDC_CLIENT_SECRET"`
+	// OIDCRedirectURL is the front page the provider sends the browser back to, it posts the code to POST /oidc/callback
+	OIDCRedirectURL string `mapstructure:"OIDC_REDIRECT_URL"`
+	// TokenSigningKeyFile is a PEM Ed25519 or RSA private key tokens are signed with, TOKEN_SECRET_KEY signs HS256 tokens when it's empty
+	TokenSigningKeyFile string `mapstructure:"TOKEN_SIGNING_KEY_FILE"`
+	// TokenVerificationKeyFiles are comma separated PEM public keys of previous signing keys, their tokens are accepted until they expire
+	TokenVerificationKeyFiles string `mapstructure:"TOKEN_VERIFICATION_KEY_FILES"`
+	// TokenFormat is jwt or paseto, PASETO v4.public is signed with TOKEN_SIGNING_KEY_FILE and v4.local is encrypted with TOKEN_SECRET_KEY when there is none.
+	// Previous keys are only accepted with jwt, TOKEN_VERIFICATION_KEY_FILES must be empty with paseto
+	TokenFormat string `mapstructure:"TOKEN_FORMAT"`
+	// LoginMaxFailures is how many failed logins an email gets before it is locked
+	LoginMaxFailures int `mapstructure:"LOGIN_MAX_FAILURES"`
+	// LoginMaxFailuresPerIP is how many failed logins a client ip gets, over all emails, before it is locked
+	LoginMaxFailuresPerIP int `mapstructure:"LOGIN_MAX_FAILURES_PER_IP"`
+	// LoginLockoutDuration is the first lockout, it doubles with every further failure
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	// LoginMaxLockoutDuration caps the lockout, failures older than it are forgotten
+	LoginMaxLockoutDuration time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT_DURATION"`
+	// BlobStore is where uploaded files such as avatars are kept, only local is supported for now
+	BlobStore string `mapstructure:"BLOB_STORE"`
+	// BlobDir is the directory of the local blob store
+	BlobDir string `mapstructure:"BLOB_DIR"`
+	// PublicURL is where this server is reached from outside, feeds link to it
+	PublicURL string `mapstructure:"PUBLIC_URL"`
+	// PasswordResetInterval is how long POST /password/forgot waits before sending another link to the same address
+	PasswordResetInterval time.Duration `mapstructure:"PASSWORD_RESET_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -43,6 +98,36 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetDefault("MAIL_USERNAME", "")
 	viper.SetDefault("MAIL_PASSWORD", "")
 	viper.SetDefault("FRONT_URL", "")
+	viper.SetDefault("FETCH_TIMEOUT", "10s")
+	viper.SetDefault("FETCH_MAX_BODY_SIZE", 5<<20)
+	viper.SetDefault("FETCH_MAX_REDIRECTS", 5)
+	viper.SetDefault("WORKER_CONCURRENCY", 2)
+	viper.SetDefault("WORKER_POLL_INTERVAL", "2s")
+	viper.SetDefault("WEB_JOB_MAX_ATTEMPTS", 5)
+	viper.SetDefault("PASSWORD_RESET_TOKEN_DURATION", "1h")
+	viper.SetDefault("EMAIL_CHANGE_TOKEN_DURATION", "24h")
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_PERIOD", "720h")
+	viper.SetDefault("REGISTER_RESEND_INTERVAL", "1m")
+	viper.SetDefault("SWEEP_INTERVAL", "1h")
+	viper.SetDefault("TWO_FACTOR_CHALLENGE_DURATION", "5m")
+	viper.SetDefault("OAUTH_CLIENT_ID", "inkclip-extension")
+	viper.SetDefault("DEVICE_CODE_DURATION", "10m")
+	viper.SetDefault("DEVICE_CODE_INTERVAL", "5s")
+	viper.SetDefault("OIDC_ISSUER", "")
+	viper.SetDefault("OIDC_CLIENT_ID", "")
+	viper.SetDefault("OIDC_CLIENT_SECRET", "")
+	viper.SetDefault("OIDC_REDIRECT_URL", "")
+	viper.SetDefault("TOKEN_SIGNING_KEY_FILE", "")
+	viper.SetDefault("TOKEN_VERIFICATION_KEY_FILES", "")
+	viper.SetDefault("TOKEN_FORMAT", "jwt")
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_MAX_FAILURES_PER_IP", 20)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "1m")
+	viper.SetDefault("LOGIN_MAX_LOCKOUT_DURATION", "1h")
+	viper.SetDefault("BLOB_STORE", "local")
+	viper.SetDefault("BLOB_DIR", "data/blobs")
+	viper.SetDefault("PUBLIC_URL", "http://localhost:8080")
+	viper.SetDefault("PASSWORD_RESET_INTERVAL", "1m")
 
 	viper.AutomaticEnv()
 
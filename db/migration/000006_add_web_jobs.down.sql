@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS web_jobs;
+
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "error_reason";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "status";
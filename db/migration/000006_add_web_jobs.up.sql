@@ -0,0 +1,19 @@
+ALTER TABLE "webs" ADD COLUMN "status" varchar NOT NULL DEFAULT 'done';
+ALTER TABLE "webs" ADD COLUMN "error_reason" varchar NOT NULL DEFAULT '';
+
+CREATE TABLE "web_jobs" (
+  "id" bigserial PRIMARY KEY,
+  "web_id" uuid NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "max_attempts" integer NOT NULL,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "web_jobs" ("web_id");
+
+CREATE INDEX ON "web_jobs" ("run_at");
+
+ALTER TABLE "web_jobs" ADD FOREIGN KEY ("web_id") REFERENCES "webs" ("id") ON DELETE CASCADE;
@@ -0,0 +1,8 @@
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "reading_minutes";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "published_at";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "description";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "site_name";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "author";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "article_markdown";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "article_html";
+ALTER TABLE IF EXISTS "webs" DROP COLUMN IF EXISTS "article_text";
@@ -0,0 +1,8 @@
+ALTER TABLE "webs" ADD COLUMN "article_text" varchar NOT NULL DEFAULT '';
+ALTER TABLE "webs" ADD COLUMN "article_html" varchar NOT NULL DEFAULT '';
+ALTER TABLE "webs" ADD COLUMN "article_markdown" varchar NOT NULL DEFAULT '';
+ALTER TABLE "webs" ADD COLUMN "author" varchar NOT NULL DEFAULT '';
+ALTER TABLE "webs" ADD COLUMN "site_name" varchar NOT NULL DEFAULT '';
+ALTER TABLE "webs" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+ALTER TABLE "webs" ADD COLUMN "published_at" timestamptz;
+ALTER TABLE "webs" ADD COLUMN "reading_minutes" integer NOT NULL DEFAULT 0;
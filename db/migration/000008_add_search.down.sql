@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS web_search_document_update ON webs;
+DROP FUNCTION IF EXISTS web_search_document_trigger;
+DROP TRIGGER IF EXISTS note_search_document_update ON notes;
+DROP FUNCTION IF EXISTS note_search_document_trigger;
+DROP TABLE IF EXISTS web_search_documents;
+DROP TABLE IF EXISTS note_search_documents;
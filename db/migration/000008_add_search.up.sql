@@ -0,0 +1,59 @@
+-- search documents live beside notes and webs so that SELECT * doesn't carry them around
+CREATE TABLE "note_search_documents" (
+  "note_id" uuid PRIMARY KEY,
+  "document" tsvector NOT NULL
+);
+
+CREATE INDEX ON "note_search_documents" USING GIN ("document");
+
+ALTER TABLE "note_search_documents" ADD FOREIGN KEY ("note_id") REFERENCES "notes" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "web_search_documents" (
+  "web_id" uuid PRIMARY KEY,
+  "document" tsvector NOT NULL
+);
+
+CREATE INDEX ON "web_search_documents" USING GIN ("document");
+
+ALTER TABLE "web_search_documents" ADD FOREIGN KEY ("web_id") REFERENCES "webs" ("id") ON DELETE CASCADE;
+
+-- 'simple' doesn't stem, so it works the same for every language
+CREATE FUNCTION note_search_document_trigger() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO note_search_documents (note_id, document)
+  VALUES (
+    NEW.id,
+    setweight(to_tsvector('simple', NEW.title), 'A') || setweight(to_tsvector('simple', NEW.content), 'B')
+  )
+  ON CONFLICT (note_id) DO UPDATE SET document = EXCLUDED.document;
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER note_search_document_update
+AFTER INSERT OR UPDATE OF title, content ON notes
+FOR EACH ROW EXECUTE FUNCTION note_search_document_trigger();
+
+CREATE FUNCTION web_search_document_trigger() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO web_search_documents (web_id, document)
+  VALUES (
+    NEW.id,
+    setweight(to_tsvector('simple', NEW.title), 'A') || setweight(to_tsvector('simple', NEW.article_text), 'B')
+  )
+  ON CONFLICT (web_id) DO UPDATE SET document = EXCLUDED.document;
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER web_search_document_update
+AFTER INSERT OR UPDATE OF title, article_text ON webs
+FOR EACH ROW EXECUTE FUNCTION web_search_document_trigger();
+
+INSERT INTO note_search_documents (note_id, document)
+SELECT id, setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', content), 'B')
+FROM notes;
+
+INSERT INTO web_search_documents (web_id, document)
+SELECT id, setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', article_text), 'B')
+FROM webs;
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS web_tag_count_update ON web_tags;
+DROP FUNCTION IF EXISTS web_tag_count_trigger;
+DROP TRIGGER IF EXISTS note_tag_count_update ON note_tags;
+DROP FUNCTION IF EXISTS note_tag_count_trigger;
+DROP TABLE IF EXISTS web_tags;
+DROP TABLE IF EXISTS note_tags;
+DROP TABLE IF EXISTS tags;
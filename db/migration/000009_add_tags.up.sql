@@ -0,0 +1,67 @@
+CREATE TABLE "tags" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid()),
+  "user_id" uuid NOT NULL,
+  "name" varchar NOT NULL,
+  "note_count" integer NOT NULL DEFAULT 0,
+  "web_count" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "tags" ("user_id", "name");
+
+ALTER TABLE "tags" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "note_tags" (
+  "note_id" uuid NOT NULL,
+  "tag_id" uuid NOT NULL,
+  PRIMARY KEY ("note_id", "tag_id")
+);
+
+CREATE INDEX ON "note_tags" ("tag_id");
+
+ALTER TABLE "note_tags" ADD FOREIGN KEY ("note_id") REFERENCES "notes" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "note_tags" ADD FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "web_tags" (
+  "web_id" uuid NOT NULL,
+  "tag_id" uuid NOT NULL,
+  PRIMARY KEY ("web_id", "tag_id")
+);
+
+CREATE INDEX ON "web_tags" ("tag_id");
+
+ALTER TABLE "web_tags" ADD FOREIGN KEY ("web_id") REFERENCES "webs" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "web_tags" ADD FOREIGN KEY ("tag_id") REFERENCES "tags" ("id") ON DELETE CASCADE;
+
+-- counts are kept on tags so the sidebar doesn't have to aggregate
+CREATE FUNCTION note_tag_count_trigger() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    UPDATE tags SET note_count = note_count + 1 WHERE id = NEW.tag_id;
+  ELSE
+    UPDATE tags SET note_count = note_count - 1 WHERE id = OLD.tag_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER note_tag_count_update
+AFTER INSERT OR DELETE ON note_tags
+FOR EACH ROW EXECUTE FUNCTION note_tag_count_trigger();
+
+CREATE FUNCTION web_tag_count_trigger() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    UPDATE tags SET web_count = web_count + 1 WHERE id = NEW.tag_id;
+  ELSE
+    UPDATE tags SET web_count = web_count - 1 WHERE id = OLD.tag_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER web_tag_count_update
+AFTER INSERT OR DELETE ON web_tags
+FOR EACH ROW EXECUTE FUNCTION web_tag_count_trigger();
@@ -0,0 +1,30 @@
+CREATE TABLE "note_revisions" (
+  "id" bigserial PRIMARY KEY,
+  "note_id" uuid NOT NULL,
+  "revision" integer NOT NULL,
+  "author_id" uuid NOT NULL,
+  "title" varchar NOT NULL,
+  "content" varchar NOT NULL,
+  "is_public" boolean NOT NULL,
+  "web_ids" uuid[] NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "note_revisions" ("note_id", "revision");
+
+ALTER TABLE "note_revisions" ADD FOREIGN KEY ("note_id") REFERENCES "notes" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "note_revisions" ADD FOREIGN KEY ("author_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- existing notes start their history from what they look like now
+INSERT INTO note_revisions (note_id, revision, author_id, title, content, is_public, web_ids, created_at)
+SELECT
+  notes.id,
+  1,
+  notes.user_id,
+  notes.title,
+  notes.content,
+  notes.is_public,
+  coalesce((SELECT array_agg(note_webs.web_id) FROM note_webs WHERE note_webs.note_id = notes.id), '{}'),
+  notes.created_at
+FROM notes;
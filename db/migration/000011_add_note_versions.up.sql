@@ -0,0 +1,6 @@
+-- version is bumped by every update, clients send it back in If-Match
+ALTER TABLE "notes" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
+
+ALTER TABLE "notes" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+
+UPDATE notes SET updated_at = created_at;
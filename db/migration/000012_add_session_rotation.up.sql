@@ -0,0 +1,17 @@
+-- every renewal replaces the session with a new one of the same family,
+-- the family of a login is blocked as a whole when a replaced refresh token comes back
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+
+UPDATE sessions SET family_id = id;
+
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+ALTER TABLE "sessions" ADD COLUMN "parent_id" uuid;
+
+ALTER TABLE "sessions" ADD COLUMN "rotated_at" timestamptz;
+
+CREATE INDEX ON "sessions" ("family_id");
+
+CREATE INDEX ON "sessions" ("user_id");
+
+ALTER TABLE "sessions" ADD FOREIGN KEY ("parent_id") REFERENCES "sessions" ("id") ON DELETE SET NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE "password_reset_tokens" (
+  "token_hash" varchar PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "password_reset_tokens" ("user_id");
+
+ALTER TABLE "password_reset_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
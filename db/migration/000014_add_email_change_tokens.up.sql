@@ -0,0 +1,12 @@
+CREATE TABLE "email_change_tokens" (
+  "token_hash" varchar PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "new_email" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "email_change_tokens" ("user_id");
+
+ALTER TABLE "email_change_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
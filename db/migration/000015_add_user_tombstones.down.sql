@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_tombstones;
+
+ALTER TABLE "webs" ADD CONSTRAINT "webs_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id");
@@ -0,0 +1,8 @@
+-- webs got a second foreign key to users in 000003, the first one has no ON DELETE CASCADE and keeps users with webs from being deleted
+ALTER TABLE "webs" DROP CONSTRAINT IF EXISTS "webs_user_id_fkey";
+
+-- emails of deleted users are kept hashed so that they can't be registered again right away
+CREATE TABLE "user_tombstones" (
+  "email_hash" varchar PRIMARY KEY,
+  "deleted_at" timestamptz NOT NULL DEFAULT (now())
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "temporary_users_expires_at_idx";
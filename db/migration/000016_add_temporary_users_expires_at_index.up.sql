@@ -0,0 +1,2 @@
+-- expired rows are swept periodically
+CREATE INDEX ON "temporary_users" ("expires_at");
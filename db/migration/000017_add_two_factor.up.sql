@@ -0,0 +1,35 @@
+-- enabled_at is NULL while the enrollment waits for its first code
+CREATE TABLE "user_two_factors" (
+  "user_id" uuid PRIMARY KEY,
+  "totp_secret" varchar NOT NULL,
+  "enabled_at" timestamptz,
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_two_factors" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "recovery_codes" (
+  "code_hash" varchar PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "recovery_codes" ("user_id");
+
+ALTER TABLE "recovery_codes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- handed out by POST /users/login when 2fa is on, exchanged for tokens at POST /users/login/2fa
+CREATE TABLE "login_challenges" (
+  "token_hash" varchar PRIMARY KEY,
+  "user_id" uuid NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "login_challenges" ("user_id");
+
+ALTER TABLE "login_challenges" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
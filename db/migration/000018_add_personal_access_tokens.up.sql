@@ -0,0 +1,17 @@
+-- long lived tokens for the browser extension and scripts, limited to their scopes
+CREATE TABLE "personal_access_tokens" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid()),
+  "user_id" uuid NOT NULL,
+  "name" varchar NOT NULL,
+  "token_hash" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "last_used_at" timestamptz,
+  "expires_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "personal_access_tokens" ("token_hash");
+
+CREATE INDEX ON "personal_access_tokens" ("user_id");
+
+ALTER TABLE "personal_access_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,17 @@
+-- RFC 8628, the extension polls with the device code while the user approves the user code from a logged in browser
+CREATE TABLE "device_authorizations" (
+  "device_code_hash" varchar PRIMARY KEY,
+  "user_code" varchar NOT NULL,
+  "client_id" varchar NOT NULL,
+  -- pending, approved, denied or consumed once exchanged for tokens
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "user_id" uuid,
+  "interval_seconds" integer NOT NULL,
+  "last_polled_at" timestamptz,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "device_authorizations" ("user_code");
+
+ALTER TABLE "device_authorizations" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
@@ -0,0 +1,30 @@
+-- accounts at external OpenID Connect providers that log in as the user
+CREATE TABLE "user_identities" (
+  "id" uuid PRIMARY KEY DEFAULT (gen_random_uuid()),
+  "user_id" uuid NOT NULL,
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  -- the email the provider had when the identity was linked
+  "email" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "user_identities" ("issuer", "subject");
+
+CREATE INDEX ON "user_identities" ("user_id");
+
+ALTER TABLE "user_identities" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+-- what the callback needs to finish a login started at the provider.
+-- user_id is set when a logged in user links an identity instead of logging in
+CREATE TABLE "oidc_states" (
+  "state_hash" varchar PRIMARY KEY,
+  "nonce" varchar NOT NULL,
+  "code_verifier" varchar NOT NULL,
+  "user_id" uuid,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "oidc_states" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
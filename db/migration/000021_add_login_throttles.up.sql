@@ -0,0 +1,8 @@
+-- failed logins counted per email and per client ip, whether the account exists or not
+CREATE TABLE "login_throttles" (
+  -- "email:<email>" or "ip:<address>"
+  "key" varchar PRIMARY KEY,
+  "failures" integer NOT NULL,
+  "last_failed_at" timestamptz NOT NULL,
+  "locked_until" timestamptz
+);
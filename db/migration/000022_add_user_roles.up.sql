@@ -0,0 +1,4 @@
+-- user or admin, admins reach the /admin routes
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'user';
+-- a blocked user can't log in and its tokens are refused
+ALTER TABLE "users" ADD COLUMN "blocked_at" timestamptz;
@@ -0,0 +1,14 @@
+-- what other people see of a user, found by its handle without the email
+CREATE TABLE "profiles" (
+  "user_id" uuid PRIMARY KEY,
+  -- lowercase, validated by the api
+  "handle" varchar UNIQUE NOT NULL,
+  "display_name" varchar NOT NULL DEFAULT '',
+  "bio" varchar NOT NULL DEFAULT '',
+  -- the key of the resized avatar in the blob store, empty when there is none
+  "avatar_key" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "profiles" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
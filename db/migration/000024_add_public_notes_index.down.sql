@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "notes_user_id_updated_at_idx";
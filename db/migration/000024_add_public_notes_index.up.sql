@@ -0,0 +1,2 @@
+-- author pages and feeds list the public notes of a user, latest update first
+CREATE INDEX ON "notes" ("user_id", "updated_at" DESC) WHERE "is_public";
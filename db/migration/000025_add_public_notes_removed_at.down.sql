@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS public_note_removed ON notes;
+DROP FUNCTION IF EXISTS public_note_removed_trigger;
+ALTER TABLE "profiles" DROP COLUMN IF EXISTS "public_notes_removed_at";
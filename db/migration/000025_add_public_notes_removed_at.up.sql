@@ -0,0 +1,15 @@
+-- author pages are validated with the latest update of the public notes, which doesn't move when one of them goes away
+ALTER TABLE "profiles" ADD COLUMN "public_notes_removed_at" timestamptz NOT NULL DEFAULT ('epoch');
+
+CREATE FUNCTION public_note_removed_trigger() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'DELETE' OR NOT NEW.is_public THEN
+    UPDATE profiles SET public_notes_removed_at = now() WHERE user_id = OLD.user_id;
+  END IF;
+  RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER public_note_removed
+AFTER DELETE OR UPDATE OF is_public ON notes
+FOR EACH ROW WHEN (OLD.is_public) EXECUTE FUNCTION public_note_removed_trigger();
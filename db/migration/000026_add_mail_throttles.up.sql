@@ -0,0 +1,6 @@
+-- the last mail of a kind sent to an address, whether it has an account or not
+CREATE TABLE "mail_throttles" (
+  -- "<kind>:<email>"
+  "key" varchar PRIMARY KEY,
+  "sent_at" timestamptz NOT NULL
+);
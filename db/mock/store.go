@@ -7,6 +7,7 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,431 +37,2089 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// ApproveDeviceAuthorization mocks base method.
+func (m *MockStore) ApproveDeviceAuthorization(arg0 context.Context, arg1 db.ApproveDeviceAuthorizationParams) (db.DeviceAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveDeviceAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.DeviceAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveDeviceAuthorization indicates an expected call of ApproveDeviceAuthorization.
+func (mr *MockStoreMockRecorder) ApproveDeviceAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveDeviceAuthorization", reflect.TypeOf((*MockStore)(nil).ApproveDeviceAuthorization), arg0, arg1)
+}
+
+// BlockSessionFamily mocks base method.
+func (m *MockStore) BlockSessionFamily(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockStoreMockRecorder) BlockSessionFamily(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockStore)(nil).BlockSessionFamily), arg0, arg1)
+}
+
+// BlockSessionsByUserId mocks base method.
+func (m *MockStore) BlockSessionsByUserId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionsByUserId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSessionsByUserId indicates an expected call of BlockSessionsByUserId.
+func (mr *MockStoreMockRecorder) BlockSessionsByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionsByUserId", reflect.TypeOf((*MockStore)(nil).BlockSessionsByUserId), arg0, arg1)
+}
+
+// ClaimMailSend mocks base method.
+func (m *MockStore) ClaimMailSend(arg0 context.Context, arg1 db.ClaimMailSendParams) (db.MailThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimMailSend", arg0, arg1)
+	ret0, _ := ret[0].(db.MailThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimMailSend indicates an expected call of ClaimMailSend.
+func (mr *MockStoreMockRecorder) ClaimMailSend(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimMailSend", reflect.TypeOf((*MockStore)(nil).ClaimMailSend), arg0, arg1)
+}
+
+// ClaimWebJob mocks base method.
+func (m *MockStore) ClaimWebJob(arg0 context.Context, arg1 time.Time) (db.WebJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebJob", arg0, arg1)
+	ret0, _ := ret[0].(db.WebJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebJob indicates an expected call of ClaimWebJob.
+func (mr *MockStoreMockRecorder) ClaimWebJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebJob", reflect.TypeOf((*MockStore)(nil).ClaimWebJob), arg0, arg1)
+}
+
+// ConsumeDeviceAuthorization mocks base method.
+func (m *MockStore) ConsumeDeviceAuthorization(arg0 context.Context, arg1 string) (db.DeviceAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeDeviceAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.DeviceAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeDeviceAuthorization indicates an expected call of ConsumeDeviceAuthorization.
+func (mr *MockStoreMockRecorder) ConsumeDeviceAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeDeviceAuthorization", reflect.TypeOf((*MockStore)(nil).ConsumeDeviceAuthorization), arg0, arg1)
+}
+
+// CreateDeviceAuthorization mocks base method.
+func (m *MockStore) CreateDeviceAuthorization(arg0 context.Context, arg1 db.CreateDeviceAuthorizationParams) (db.DeviceAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeviceAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.DeviceAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeviceAuthorization indicates an expected call of CreateDeviceAuthorization.
+func (mr *MockStoreMockRecorder) CreateDeviceAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeviceAuthorization", reflect.TypeOf((*MockStore)(nil).CreateDeviceAuthorization), arg0, arg1)
+}
+
+// CreateEmailChangeToken mocks base method.
+func (m *MockStore) CreateEmailChangeToken(arg0 context.Context, arg1 db.CreateEmailChangeTokenParams) (db.EmailChangeToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChangeToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChangeToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEmailChangeToken indicates an expected call of CreateEmailChangeToken.
+func (mr *MockStoreMockRecorder) CreateEmailChangeToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChangeToken", reflect.TypeOf((*MockStore)(nil).CreateEmailChangeToken), arg0, arg1)
+}
+
+// CreateLoginChallenge mocks base method.
+func (m *MockStore) CreateLoginChallenge(arg0 context.Context, arg1 db.CreateLoginChallengeParams) (db.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoginChallenge indicates an expected call of CreateLoginChallenge.
+func (mr *MockStoreMockRecorder) CreateLoginChallenge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoginChallenge", reflect.TypeOf((*MockStore)(nil).CreateLoginChallenge), arg0, arg1)
+}
+
 // CreateNote mocks base method.
 func (m *MockStore) CreateNote(arg0 context.Context, arg1 db.CreateNoteParams) (db.Note, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateNote", arg0, arg1)
-	ret0, _ := ret[0].(db.Note)
+	ret := m.ctrl.Call(m, "CreateNote", arg0, arg1)
+	ret0, _ := ret[0].(db.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNote indicates an expected call of CreateNote.
+func (mr *MockStoreMockRecorder) CreateNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockStore)(nil).CreateNote), arg0, arg1)
+}
+
+// CreateNoteRevision mocks base method.
+func (m *MockStore) CreateNoteRevision(arg0 context.Context, arg1 db.CreateNoteRevisionParams) (db.NoteRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNoteRevision", arg0, arg1)
+	ret0, _ := ret[0].(db.NoteRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNoteRevision indicates an expected call of CreateNoteRevision.
+func (mr *MockStoreMockRecorder) CreateNoteRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNoteRevision", reflect.TypeOf((*MockStore)(nil).CreateNoteRevision), arg0, arg1)
+}
+
+// CreateNoteTag mocks base method.
+func (m *MockStore) CreateNoteTag(arg0 context.Context, arg1 db.CreateNoteTagParams) (db.NoteTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNoteTag", arg0, arg1)
+	ret0, _ := ret[0].(db.NoteTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNoteTag indicates an expected call of CreateNoteTag.
+func (mr *MockStoreMockRecorder) CreateNoteTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNoteTag", reflect.TypeOf((*MockStore)(nil).CreateNoteTag), arg0, arg1)
+}
+
+// CreateNoteWeb mocks base method.
+func (m *MockStore) CreateNoteWeb(arg0 context.Context, arg1 db.CreateNoteWebParams) (db.NoteWeb, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNoteWeb", arg0, arg1)
+	ret0, _ := ret[0].(db.NoteWeb)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNoteWeb indicates an expected call of CreateNoteWeb.
+func (mr *MockStoreMockRecorder) CreateNoteWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNoteWeb", reflect.TypeOf((*MockStore)(nil).CreateNoteWeb), arg0, arg1)
+}
+
+// CreateOidcState mocks base method.
+func (m *MockStore) CreateOidcState(arg0 context.Context, arg1 db.CreateOidcStateParams) (db.OidcState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOidcState", arg0, arg1)
+	ret0, _ := ret[0].(db.OidcState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOidcState indicates an expected call of CreateOidcState.
+func (mr *MockStoreMockRecorder) CreateOidcState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOidcState", reflect.TypeOf((*MockStore)(nil).CreateOidcState), arg0, arg1)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockStore) CreatePasswordResetToken(arg0 context.Context, arg1 db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockStoreMockRecorder) CreatePasswordResetToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockStore)(nil).CreatePasswordResetToken), arg0, arg1)
+}
+
+// CreatePersonalAccessToken mocks base method.
+func (m *MockStore) CreatePersonalAccessToken(arg0 context.Context, arg1 db.CreatePersonalAccessTokenParams) (db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalAccessToken indicates an expected call of CreatePersonalAccessToken.
+func (mr *MockStoreMockRecorder) CreatePersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalAccessToken", reflect.TypeOf((*MockStore)(nil).CreatePersonalAccessToken), arg0, arg1)
+}
+
+// CreateRecoveryCode mocks base method.
+func (m *MockStore) CreateRecoveryCode(arg0 context.Context, arg1 db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRecoveryCode indicates an expected call of CreateRecoveryCode.
+func (mr *MockStoreMockRecorder) CreateRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCode", reflect.TypeOf((*MockStore)(nil).CreateRecoveryCode), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateTag mocks base method.
+func (m *MockStore) CreateTag(arg0 context.Context, arg1 db.CreateTagParams) (db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockStoreMockRecorder) CreateTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockStore)(nil).CreateTag), arg0, arg1)
+}
+
+// CreateTemporaryUser mocks base method.
+func (m *MockStore) CreateTemporaryUser(arg0 context.Context, arg1 db.CreateTemporaryUserParams) (db.TemporaryUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTemporaryUser", arg0, arg1)
+	ret0, _ := ret[0].(db.TemporaryUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTemporaryUser indicates an expected call of CreateTemporaryUser.
+func (mr *MockStoreMockRecorder) CreateTemporaryUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemporaryUser", reflect.TypeOf((*MockStore)(nil).CreateTemporaryUser), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateUserIdentity mocks base method.
+func (m *MockStore) CreateUserIdentity(arg0 context.Context, arg1 db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserIdentity indicates an expected call of CreateUserIdentity.
+func (mr *MockStoreMockRecorder) CreateUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserIdentity", reflect.TypeOf((*MockStore)(nil).CreateUserIdentity), arg0, arg1)
+}
+
+// CreateUserTombstone mocks base method.
+func (m *MockStore) CreateUserTombstone(arg0 context.Context, arg1 string) (db.UserTombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserTombstone", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTombstone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserTombstone indicates an expected call of CreateUserTombstone.
+func (mr *MockStoreMockRecorder) CreateUserTombstone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTombstone", reflect.TypeOf((*MockStore)(nil).CreateUserTombstone), arg0, arg1)
+}
+
+// CreateWeb mocks base method.
+func (m *MockStore) CreateWeb(arg0 context.Context, arg1 db.CreateWebParams) (db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWeb", arg0, arg1)
+	ret0, _ := ret[0].(db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWeb indicates an expected call of CreateWeb.
+func (mr *MockStoreMockRecorder) CreateWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWeb", reflect.TypeOf((*MockStore)(nil).CreateWeb), arg0, arg1)
+}
+
+// CreateWebJob mocks base method.
+func (m *MockStore) CreateWebJob(arg0 context.Context, arg1 db.CreateWebJobParams) (db.WebJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebJob", arg0, arg1)
+	ret0, _ := ret[0].(db.WebJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebJob indicates an expected call of CreateWebJob.
+func (mr *MockStoreMockRecorder) CreateWebJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebJob", reflect.TypeOf((*MockStore)(nil).CreateWebJob), arg0, arg1)
+}
+
+// CreateWebTag mocks base method.
+func (m *MockStore) CreateWebTag(arg0 context.Context, arg1 db.CreateWebTagParams) (db.WebTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebTag", arg0, arg1)
+	ret0, _ := ret[0].(db.WebTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebTag indicates an expected call of CreateWebTag.
+func (mr *MockStoreMockRecorder) CreateWebTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebTag", reflect.TypeOf((*MockStore)(nil).CreateWebTag), arg0, arg1)
+}
+
+// DeleteExpiredTemporaryUsers mocks base method.
+func (m *MockStore) DeleteExpiredTemporaryUsers(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredTemporaryUsers", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredTemporaryUsers indicates an expected call of DeleteExpiredTemporaryUsers.
+func (mr *MockStoreMockRecorder) DeleteExpiredTemporaryUsers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredTemporaryUsers", reflect.TypeOf((*MockStore)(nil).DeleteExpiredTemporaryUsers), arg0)
+}
+
+// DeleteLoginThrottle mocks base method.
+func (m *MockStore) DeleteLoginThrottle(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginThrottle", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteLoginThrottle indicates an expected call of DeleteLoginThrottle.
+func (mr *MockStoreMockRecorder) DeleteLoginThrottle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginThrottle", reflect.TypeOf((*MockStore)(nil).DeleteLoginThrottle), arg0, arg1)
+}
+
+// DeleteNote mocks base method.
+func (m *MockStore) DeleteNote(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNote", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNote indicates an expected call of DeleteNote.
+func (mr *MockStoreMockRecorder) DeleteNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockStore)(nil).DeleteNote), arg0, arg1)
+}
+
+// DeleteNoteTagsByNoteId mocks base method.
+func (m *MockStore) DeleteNoteTagsByNoteId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNoteTagsByNoteId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNoteTagsByNoteId indicates an expected call of DeleteNoteTagsByNoteId.
+func (mr *MockStoreMockRecorder) DeleteNoteTagsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteTagsByNoteId", reflect.TypeOf((*MockStore)(nil).DeleteNoteTagsByNoteId), arg0, arg1)
+}
+
+// DeleteNoteWeb mocks base method.
+func (m *MockStore) DeleteNoteWeb(arg0 context.Context, arg1 db.DeleteNoteWebParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNoteWeb", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNoteWeb indicates an expected call of DeleteNoteWeb.
+func (mr *MockStoreMockRecorder) DeleteNoteWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteWeb", reflect.TypeOf((*MockStore)(nil).DeleteNoteWeb), arg0, arg1)
+}
+
+// DeleteNoteWebsByNoteId mocks base method.
+func (m *MockStore) DeleteNoteWebsByNoteId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNoteWebsByNoteId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNoteWebsByNoteId indicates an expected call of DeleteNoteWebsByNoteId.
+func (mr *MockStoreMockRecorder) DeleteNoteWebsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteWebsByNoteId", reflect.TypeOf((*MockStore)(nil).DeleteNoteWebsByNoteId), arg0, arg1)
+}
+
+// DeletePersonalAccessToken mocks base method.
+func (m *MockStore) DeletePersonalAccessToken(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePersonalAccessToken indicates an expected call of DeletePersonalAccessToken.
+func (mr *MockStoreMockRecorder) DeletePersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePersonalAccessToken", reflect.TypeOf((*MockStore)(nil).DeletePersonalAccessToken), arg0, arg1)
+}
+
+// DeletePersonalAccessTokensByUserId mocks base method.
+func (m *MockStore) DeletePersonalAccessTokensByUserId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePersonalAccessTokensByUserId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePersonalAccessTokensByUserId indicates an expected call of DeletePersonalAccessTokensByUserId.
+func (mr *MockStoreMockRecorder) DeletePersonalAccessTokensByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePersonalAccessTokensByUserId", reflect.TypeOf((*MockStore)(nil).DeletePersonalAccessTokensByUserId), arg0, arg1)
+}
+
+// DeleteRecoveryCodesByUserId mocks base method.
+func (m *MockStore) DeleteRecoveryCodesByUserId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodesByUserId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodesByUserId indicates an expected call of DeleteRecoveryCodesByUserId.
+func (mr *MockStoreMockRecorder) DeleteRecoveryCodesByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodesByUserId", reflect.TypeOf((*MockStore)(nil).DeleteRecoveryCodesByUserId), arg0, arg1)
+}
+
+// DeleteTag mocks base method.
+func (m *MockStore) DeleteTag(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockStoreMockRecorder) DeleteTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockStore)(nil).DeleteTag), arg0, arg1)
+}
+
+// DeleteTemporaryUsersByEmail mocks base method.
+func (m *MockStore) DeleteTemporaryUsersByEmail(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTemporaryUsersByEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTemporaryUsersByEmail indicates an expected call of DeleteTemporaryUsersByEmail.
+func (mr *MockStoreMockRecorder) DeleteTemporaryUsersByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTemporaryUsersByEmail", reflect.TypeOf((*MockStore)(nil).DeleteTemporaryUsersByEmail), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteUserIdentity mocks base method.
+func (m *MockStore) DeleteUserIdentity(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserIdentity indicates an expected call of DeleteUserIdentity.
+func (mr *MockStoreMockRecorder) DeleteUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIdentity", reflect.TypeOf((*MockStore)(nil).DeleteUserIdentity), arg0, arg1)
+}
+
+// DeleteUserTwoFactor mocks base method.
+func (m *MockStore) DeleteUserTwoFactor(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTwoFactor indicates an expected call of DeleteUserTwoFactor.
+func (mr *MockStoreMockRecorder) DeleteUserTwoFactor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTwoFactor", reflect.TypeOf((*MockStore)(nil).DeleteUserTwoFactor), arg0, arg1)
+}
+
+// DeleteWeb mocks base method.
+func (m *MockStore) DeleteWeb(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWeb", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWeb indicates an expected call of DeleteWeb.
+func (mr *MockStoreMockRecorder) DeleteWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWeb", reflect.TypeOf((*MockStore)(nil).DeleteWeb), arg0, arg1)
+}
+
+// DeleteWebJob mocks base method.
+func (m *MockStore) DeleteWebJob(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebJob indicates an expected call of DeleteWebJob.
+func (mr *MockStoreMockRecorder) DeleteWebJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebJob", reflect.TypeOf((*MockStore)(nil).DeleteWebJob), arg0, arg1)
+}
+
+// DeleteWebTagsByWebId mocks base method.
+func (m *MockStore) DeleteWebTagsByWebId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebTagsByWebId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebTagsByWebId indicates an expected call of DeleteWebTagsByWebId.
+func (mr *MockStoreMockRecorder) DeleteWebTagsByWebId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebTagsByWebId", reflect.TypeOf((*MockStore)(nil).DeleteWebTagsByWebId), arg0, arg1)
+}
+
+// DenyDeviceAuthorization mocks base method.
+func (m *MockStore) DenyDeviceAuthorization(arg0 context.Context, arg1 db.DenyDeviceAuthorizationParams) (db.DeviceAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DenyDeviceAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.DeviceAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DenyDeviceAuthorization indicates an expected call of DenyDeviceAuthorization.
+func (mr *MockStoreMockRecorder) DenyDeviceAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenyDeviceAuthorization", reflect.TypeOf((*MockStore)(nil).DenyDeviceAuthorization), arg0, arg1)
+}
+
+// EnableUserTwoFactor mocks base method.
+func (m *MockStore) EnableUserTwoFactor(arg0 context.Context, arg1 db.EnableUserTwoFactorParams) (db.UserTwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableUserTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnableUserTwoFactor indicates an expected call of EnableUserTwoFactor.
+func (mr *MockStoreMockRecorder) EnableUserTwoFactor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableUserTwoFactor", reflect.TypeOf((*MockStore)(nil).EnableUserTwoFactor), arg0, arg1)
+}
+
+// GetDeviceAuthorization mocks base method.
+func (m *MockStore) GetDeviceAuthorization(arg0 context.Context, arg1 string) (db.DeviceAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.DeviceAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceAuthorization indicates an expected call of GetDeviceAuthorization.
+func (mr *MockStoreMockRecorder) GetDeviceAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceAuthorization", reflect.TypeOf((*MockStore)(nil).GetDeviceAuthorization), arg0, arg1)
+}
+
+// GetLatestTemporaryUserByEmail mocks base method.
+func (m *MockStore) GetLatestTemporaryUserByEmail(arg0 context.Context, arg1 string) (db.TemporaryUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestTemporaryUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.TemporaryUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestTemporaryUserByEmail indicates an expected call of GetLatestTemporaryUserByEmail.
+func (mr *MockStoreMockRecorder) GetLatestTemporaryUserByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestTemporaryUserByEmail", reflect.TypeOf((*MockStore)(nil).GetLatestTemporaryUserByEmail), arg0, arg1)
+}
+
+// GetNote mocks base method.
+func (m *MockStore) GetNote(arg0 context.Context, arg1 uuid.UUID) (db.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNote", arg0, arg1)
+	ret0, _ := ret[0].(db.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNote indicates an expected call of GetNote.
+func (mr *MockStoreMockRecorder) GetNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNote", reflect.TypeOf((*MockStore)(nil).GetNote), arg0, arg1)
+}
+
+// GetNoteRevision mocks base method.
+func (m *MockStore) GetNoteRevision(arg0 context.Context, arg1 db.GetNoteRevisionParams) (db.NoteRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoteRevision", arg0, arg1)
+	ret0, _ := ret[0].(db.NoteRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoteRevision indicates an expected call of GetNoteRevision.
+func (mr *MockStoreMockRecorder) GetNoteRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteRevision", reflect.TypeOf((*MockStore)(nil).GetNoteRevision), arg0, arg1)
+}
+
+// GetNoteWeb mocks base method.
+func (m *MockStore) GetNoteWeb(arg0 context.Context, arg1 db.GetNoteWebParams) (db.NoteWeb, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNoteWeb", arg0, arg1)
+	ret0, _ := ret[0].(db.NoteWeb)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNoteWeb indicates an expected call of GetNoteWeb.
+func (mr *MockStoreMockRecorder) GetNoteWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteWeb", reflect.TypeOf((*MockStore)(nil).GetNoteWeb), arg0, arg1)
+}
+
+// GetPersonalAccessToken mocks base method.
+func (m *MockStore) GetPersonalAccessToken(arg0 context.Context, arg1 uuid.UUID) (db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessToken indicates an expected call of GetPersonalAccessToken.
+func (mr *MockStoreMockRecorder) GetPersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessToken", reflect.TypeOf((*MockStore)(nil).GetPersonalAccessToken), arg0, arg1)
+}
+
+// GetPersonalAccessTokenByHash mocks base method.
+func (m *MockStore) GetPersonalAccessTokenByHash(arg0 context.Context, arg1 string) (db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokenByHash", arg0, arg1)
+	ret0, _ := ret[0].(db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessTokenByHash indicates an expected call of GetPersonalAccessTokenByHash.
+func (mr *MockStoreMockRecorder) GetPersonalAccessTokenByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokenByHash", reflect.TypeOf((*MockStore)(nil).GetPersonalAccessTokenByHash), arg0, arg1)
+}
+
+// GetProfile mocks base method.
+func (m *MockStore) GetProfile(arg0 context.Context, arg1 uuid.UUID) (db.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProfile indicates an expected call of GetProfile.
+func (mr *MockStoreMockRecorder) GetProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockStore)(nil).GetProfile), arg0, arg1)
+}
+
+// GetPublicNotesState mocks base method.
+func (m *MockStore) GetPublicNotesState(arg0 context.Context, arg1 uuid.UUID) (db.GetPublicNotesStateRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicNotesState", arg0, arg1)
+	ret0, _ := ret[0].(db.GetPublicNotesStateRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicNotesState indicates an expected call of GetPublicNotesState.
+func (mr *MockStoreMockRecorder) GetPublicNotesState(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicNotesState", reflect.TypeOf((*MockStore)(nil).GetPublicNotesState), arg0, arg1)
+}
+
+// GetPublicProfile mocks base method.
+func (m *MockStore) GetPublicProfile(arg0 context.Context, arg1 uuid.UUID) (db.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicProfile indicates an expected call of GetPublicProfile.
+func (mr *MockStoreMockRecorder) GetPublicProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicProfile", reflect.TypeOf((*MockStore)(nil).GetPublicProfile), arg0, arg1)
+}
+
+// GetPublicProfileByHandle mocks base method.
+func (m *MockStore) GetPublicProfileByHandle(arg0 context.Context, arg1 string) (db.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicProfileByHandle", arg0, arg1)
+	ret0, _ := ret[0].(db.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicProfileByHandle indicates an expected call of GetPublicProfileByHandle.
+func (mr *MockStoreMockRecorder) GetPublicProfileByHandle(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicProfileByHandle", reflect.TypeOf((*MockStore)(nil).GetPublicProfileByHandle), arg0, arg1)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetSystemStats mocks base method.
+func (m *MockStore) GetSystemStats(arg0 context.Context) (db.GetSystemStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSystemStats", arg0)
+	ret0, _ := ret[0].(db.GetSystemStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSystemStats indicates an expected call of GetSystemStats.
+func (mr *MockStoreMockRecorder) GetSystemStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemStats", reflect.TypeOf((*MockStore)(nil).GetSystemStats), arg0)
+}
+
+// GetTag mocks base method.
+func (m *MockStore) GetTag(arg0 context.Context, arg1 uuid.UUID) (db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTag", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTag indicates an expected call of GetTag.
+func (mr *MockStoreMockRecorder) GetTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTag", reflect.TypeOf((*MockStore)(nil).GetTag), arg0, arg1)
+}
+
+// GetTemporaryUserByEmailAndToken mocks base method.
+func (m *MockStore) GetTemporaryUserByEmailAndToken(arg0 context.Context, arg1 db.GetTemporaryUserByEmailAndTokenParams) (db.TemporaryUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemporaryUserByEmailAndToken", arg0, arg1)
+	ret0, _ := ret[0].(db.TemporaryUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemporaryUserByEmailAndToken indicates an expected call of GetTemporaryUserByEmailAndToken.
+func (mr *MockStoreMockRecorder) GetTemporaryUserByEmailAndToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemporaryUserByEmailAndToken", reflect.TypeOf((*MockStore)(nil).GetTemporaryUserByEmailAndToken), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 uuid.UUID) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// GetUserAuthorization mocks base method.
+func (m *MockStore) GetUserAuthorization(arg0 context.Context, arg1 db.GetUserAuthorizationParams) (db.GetUserAuthorizationRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(db.GetUserAuthorizationRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAuthorization indicates an expected call of GetUserAuthorization.
+func (mr *MockStoreMockRecorder) GetUserAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAuthorization", reflect.TypeOf((*MockStore)(nil).GetUserAuthorization), arg0, arg1)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+}
+
+// GetUserIdentity mocks base method.
+func (m *MockStore) GetUserIdentity(arg0 context.Context, arg1 uuid.UUID) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentity indicates an expected call of GetUserIdentity.
+func (mr *MockStoreMockRecorder) GetUserIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentity", reflect.TypeOf((*MockStore)(nil).GetUserIdentity), arg0, arg1)
+}
+
+// GetUserIdentityBySubject mocks base method.
+func (m *MockStore) GetUserIdentityBySubject(arg0 context.Context, arg1 db.GetUserIdentityBySubjectParams) (db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserIdentityBySubject", arg0, arg1)
+	ret0, _ := ret[0].(db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserIdentityBySubject indicates an expected call of GetUserIdentityBySubject.
+func (mr *MockStoreMockRecorder) GetUserIdentityBySubject(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIdentityBySubject", reflect.TypeOf((*MockStore)(nil).GetUserIdentityBySubject), arg0, arg1)
+}
+
+// GetUserTombstone mocks base method.
+func (m *MockStore) GetUserTombstone(arg0 context.Context, arg1 string) (db.UserTombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTombstone", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTombstone)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTombstone indicates an expected call of GetUserTombstone.
+func (mr *MockStoreMockRecorder) GetUserTombstone(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTombstone", reflect.TypeOf((*MockStore)(nil).GetUserTombstone), arg0, arg1)
+}
+
+// GetUserTwoFactor mocks base method.
+func (m *MockStore) GetUserTwoFactor(arg0 context.Context, arg1 uuid.UUID) (db.UserTwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTwoFactor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTwoFactor indicates an expected call of GetUserTwoFactor.
+func (mr *MockStoreMockRecorder) GetUserTwoFactor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTwoFactor", reflect.TypeOf((*MockStore)(nil).GetUserTwoFactor), arg0, arg1)
+}
+
+// GetWeb mocks base method.
+func (m *MockStore) GetWeb(arg0 context.Context, arg1 uuid.UUID) (db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWeb", arg0, arg1)
+	ret0, _ := ret[0].(db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWeb indicates an expected call of GetWeb.
+func (mr *MockStoreMockRecorder) GetWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWeb", reflect.TypeOf((*MockStore)(nil).GetWeb), arg0, arg1)
+}
+
+// GetWebJobByWebId mocks base method.
+func (m *MockStore) GetWebJobByWebId(arg0 context.Context, arg1 uuid.UUID) (db.WebJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebJobByWebId", arg0, arg1)
+	ret0, _ := ret[0].(db.WebJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebJobByWebId indicates an expected call of GetWebJobByWebId.
+func (mr *MockStoreMockRecorder) GetWebJobByWebId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebJobByWebId", reflect.TypeOf((*MockStore)(nil).GetWebJobByWebId), arg0, arg1)
+}
+
+// IncrementLoginChallengeAttempts mocks base method.
+func (m *MockStore) IncrementLoginChallengeAttempts(arg0 context.Context, arg1 db.IncrementLoginChallengeAttemptsParams) (db.LoginChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementLoginChallengeAttempts", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementLoginChallengeAttempts indicates an expected call of IncrementLoginChallengeAttempts.
+func (mr *MockStoreMockRecorder) IncrementLoginChallengeAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementLoginChallengeAttempts", reflect.TypeOf((*MockStore)(nil).IncrementLoginChallengeAttempts), arg0, arg1)
+}
+
+// InvalidateEmailChangeTokensByUserId mocks base method.
+func (m *MockStore) InvalidateEmailChangeTokensByUserId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateEmailChangeTokensByUserId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateEmailChangeTokensByUserId indicates an expected call of InvalidateEmailChangeTokensByUserId.
+func (mr *MockStoreMockRecorder) InvalidateEmailChangeTokensByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateEmailChangeTokensByUserId", reflect.TypeOf((*MockStore)(nil).InvalidateEmailChangeTokensByUserId), arg0, arg1)
+}
+
+// InvalidatePasswordResetTokensByUserId mocks base method.
+func (m *MockStore) InvalidatePasswordResetTokensByUserId(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidatePasswordResetTokensByUserId", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidatePasswordResetTokensByUserId indicates an expected call of InvalidatePasswordResetTokensByUserId.
+func (mr *MockStoreMockRecorder) InvalidatePasswordResetTokensByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidatePasswordResetTokensByUserId", reflect.TypeOf((*MockStore)(nil).InvalidatePasswordResetTokensByUserId), arg0, arg1)
+}
+
+// ListActiveSessionsByUserId mocks base method.
+func (m *MockStore) ListActiveSessionsByUserId(arg0 context.Context, arg1 uuid.UUID) ([]db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessionsByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessionsByUserId indicates an expected call of ListActiveSessionsByUserId.
+func (mr *MockStoreMockRecorder) ListActiveSessionsByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessionsByUserId", reflect.TypeOf((*MockStore)(nil).ListActiveSessionsByUserId), arg0, arg1)
+}
+
+// ListDoneWebsAfterId mocks base method.
+func (m *MockStore) ListDoneWebsAfterId(arg0 context.Context, arg1 db.ListDoneWebsAfterIdParams) ([]db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDoneWebsAfterId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDoneWebsAfterId indicates an expected call of ListDoneWebsAfterId.
+func (mr *MockStoreMockRecorder) ListDoneWebsAfterId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDoneWebsAfterId", reflect.TypeOf((*MockStore)(nil).ListDoneWebsAfterId), arg0, arg1)
+}
+
+// ListLoginThrottles mocks base method.
+func (m *MockStore) ListLoginThrottles(arg0 context.Context, arg1 []string) ([]db.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListLoginThrottles", arg0, arg1)
+	ret0, _ := ret[0].([]db.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListLoginThrottles indicates an expected call of ListLoginThrottles.
+func (mr *MockStoreMockRecorder) ListLoginThrottles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListLoginThrottles", reflect.TypeOf((*MockStore)(nil).ListLoginThrottles), arg0, arg1)
+}
+
+// ListNoteRevisions mocks base method.
+func (m *MockStore) ListNoteRevisions(arg0 context.Context, arg1 db.ListNoteRevisionsParams) ([]db.NoteRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNoteRevisions", arg0, arg1)
+	ret0, _ := ret[0].([]db.NoteRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNoteRevisions indicates an expected call of ListNoteRevisions.
+func (mr *MockStoreMockRecorder) ListNoteRevisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNoteRevisions", reflect.TypeOf((*MockStore)(nil).ListNoteRevisions), arg0, arg1)
+}
+
+// ListNoteWebsByNoteId mocks base method.
+func (m *MockStore) ListNoteWebsByNoteId(arg0 context.Context, arg1 uuid.UUID) ([]db.NoteWeb, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNoteWebsByNoteId", arg0, arg1)
+	ret0, _ := ret[0].([]db.NoteWeb)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNoteWebsByNoteId indicates an expected call of ListNoteWebsByNoteId.
+func (mr *MockStoreMockRecorder) ListNoteWebsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNoteWebsByNoteId", reflect.TypeOf((*MockStore)(nil).ListNoteWebsByNoteId), arg0, arg1)
+}
+
+// ListNoteWebsByNoteIds mocks base method.
+func (m *MockStore) ListNoteWebsByNoteIds(arg0 context.Context, arg1 []uuid.UUID) ([]db.NoteWeb, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNoteWebsByNoteIds", arg0, arg1)
+	ret0, _ := ret[0].([]db.NoteWeb)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNoteWebsByNoteIds indicates an expected call of ListNoteWebsByNoteIds.
+func (mr *MockStoreMockRecorder) ListNoteWebsByNoteIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNoteWebsByNoteIds", reflect.TypeOf((*MockStore)(nil).ListNoteWebsByNoteIds), arg0, arg1)
+}
+
+// ListNotesByUserId mocks base method.
+func (m *MockStore) ListNotesByUserId(arg0 context.Context, arg1 db.ListNotesByUserIdParams) ([]db.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotesByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotesByUserId indicates an expected call of ListNotesByUserId.
+func (mr *MockStoreMockRecorder) ListNotesByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotesByUserId", reflect.TypeOf((*MockStore)(nil).ListNotesByUserId), arg0, arg1)
+}
+
+// ListNotesByUserIdAfterId mocks base method.
+func (m *MockStore) ListNotesByUserIdAfterId(arg0 context.Context, arg1 db.ListNotesByUserIdAfterIdParams) ([]db.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotesByUserIdAfterId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotesByUserIdAfterId indicates an expected call of ListNotesByUserIdAfterId.
+func (mr *MockStoreMockRecorder) ListNotesByUserIdAfterId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotesByUserIdAfterId", reflect.TypeOf((*MockStore)(nil).ListNotesByUserIdAfterId), arg0, arg1)
+}
+
+// ListPersonalAccessTokensByUserId mocks base method.
+func (m *MockStore) ListPersonalAccessTokensByUserId(arg0 context.Context, arg1 uuid.UUID) ([]db.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPersonalAccessTokensByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPersonalAccessTokensByUserId indicates an expected call of ListPersonalAccessTokensByUserId.
+func (mr *MockStoreMockRecorder) ListPersonalAccessTokensByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPersonalAccessTokensByUserId", reflect.TypeOf((*MockStore)(nil).ListPersonalAccessTokensByUserId), arg0, arg1)
+}
+
+// ListPublicNotesByUserId mocks base method.
+func (m *MockStore) ListPublicNotesByUserId(arg0 context.Context, arg1 db.ListPublicNotesByUserIdParams) ([]db.Note, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPublicNotesByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Note)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPublicNotesByUserId indicates an expected call of ListPublicNotesByUserId.
+func (mr *MockStoreMockRecorder) ListPublicNotesByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPublicNotesByUserId", reflect.TypeOf((*MockStore)(nil).ListPublicNotesByUserId), arg0, arg1)
+}
+
+// ListTagsByNoteId mocks base method.
+func (m *MockStore) ListTagsByNoteId(arg0 context.Context, arg1 uuid.UUID) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByNoteId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByNoteId indicates an expected call of ListTagsByNoteId.
+func (mr *MockStoreMockRecorder) ListTagsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByNoteId", reflect.TypeOf((*MockStore)(nil).ListTagsByNoteId), arg0, arg1)
+}
+
+// ListTagsByNoteIds mocks base method.
+func (m *MockStore) ListTagsByNoteIds(arg0 context.Context, arg1 []uuid.UUID) ([]db.ListTagsByNoteIdsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByNoteIds", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTagsByNoteIdsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByNoteIds indicates an expected call of ListTagsByNoteIds.
+func (mr *MockStoreMockRecorder) ListTagsByNoteIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByNoteIds", reflect.TypeOf((*MockStore)(nil).ListTagsByNoteIds), arg0, arg1)
+}
+
+// ListTagsByUserId mocks base method.
+func (m *MockStore) ListTagsByUserId(arg0 context.Context, arg1 uuid.UUID) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByUserId indicates an expected call of ListTagsByUserId.
+func (mr *MockStoreMockRecorder) ListTagsByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByUserId", reflect.TypeOf((*MockStore)(nil).ListTagsByUserId), arg0, arg1)
+}
+
+// ListTagsByWebId mocks base method.
+func (m *MockStore) ListTagsByWebId(arg0 context.Context, arg1 uuid.UUID) ([]db.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByWebId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByWebId indicates an expected call of ListTagsByWebId.
+func (mr *MockStoreMockRecorder) ListTagsByWebId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByWebId", reflect.TypeOf((*MockStore)(nil).ListTagsByWebId), arg0, arg1)
+}
+
+// ListTagsByWebIds mocks base method.
+func (m *MockStore) ListTagsByWebIds(arg0 context.Context, arg1 []uuid.UUID) ([]db.ListTagsByWebIdsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTagsByWebIds", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListTagsByWebIdsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTagsByWebIds indicates an expected call of ListTagsByWebIds.
+func (mr *MockStoreMockRecorder) ListTagsByWebIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTagsByWebIds", reflect.TypeOf((*MockStore)(nil).ListTagsByWebIds), arg0, arg1)
+}
+
+// ListUserIdentitiesByUserId mocks base method.
+func (m *MockStore) ListUserIdentitiesByUserId(arg0 context.Context, arg1 uuid.UUID) ([]db.UserIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserIdentitiesByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.UserIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserIdentitiesByUserId indicates an expected call of ListUserIdentitiesByUserId.
+func (mr *MockStoreMockRecorder) ListUserIdentitiesByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserIdentitiesByUserId", reflect.TypeOf((*MockStore)(nil).ListUserIdentitiesByUserId), arg0, arg1)
+}
+
+// ListWebByNoteId mocks base method.
+func (m *MockStore) ListWebByNoteId(arg0 context.Context, arg1 uuid.UUID) ([]db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebByNoteId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebByNoteId indicates an expected call of ListWebByNoteId.
+func (mr *MockStoreMockRecorder) ListWebByNoteId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebByNoteId", reflect.TypeOf((*MockStore)(nil).ListWebByNoteId), arg0, arg1)
+}
+
+// ListWebByNoteIds mocks base method.
+func (m *MockStore) ListWebByNoteIds(arg0 context.Context, arg1 []uuid.UUID) ([]db.ListWebByNoteIdsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebByNoteIds", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListWebByNoteIdsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebByNoteIds indicates an expected call of ListWebByNoteIds.
+func (mr *MockStoreMockRecorder) ListWebByNoteIds(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebByNoteIds", reflect.TypeOf((*MockStore)(nil).ListWebByNoteIds), arg0, arg1)
+}
+
+// ListWebsByUserId mocks base method.
+func (m *MockStore) ListWebsByUserId(arg0 context.Context, arg1 db.ListWebsByUserIdParams) ([]db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebsByUserId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebsByUserId indicates an expected call of ListWebsByUserId.
+func (mr *MockStoreMockRecorder) ListWebsByUserId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebsByUserId", reflect.TypeOf((*MockStore)(nil).ListWebsByUserId), arg0, arg1)
+}
+
+// ListWebsByUserIdAfterId mocks base method.
+func (m *MockStore) ListWebsByUserIdAfterId(arg0 context.Context, arg1 db.ListWebsByUserIdAfterIdParams) ([]db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebsByUserIdAfterId", arg0, arg1)
+	ret0, _ := ret[0].([]db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebsByUserIdAfterId indicates an expected call of ListWebsByUserIdAfterId.
+func (mr *MockStoreMockRecorder) ListWebsByUserIdAfterId(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebsByUserIdAfterId", reflect.TypeOf((*MockStore)(nil).ListWebsByUserIdAfterId), arg0, arg1)
+}
+
+// LockLogin mocks base method.
+func (m *MockStore) LockLogin(arg0 context.Context, arg1 db.LockLoginParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockStoreMockRecorder) LockLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockStore)(nil).LockLogin), arg0, arg1)
+}
+
+// MergeNoteTags mocks base method.
+func (m *MockStore) MergeNoteTags(arg0 context.Context, arg1 db.MergeNoteTagsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeNoteTags", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeNoteTags indicates an expected call of MergeNoteTags.
+func (mr *MockStoreMockRecorder) MergeNoteTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeNoteTags", reflect.TypeOf((*MockStore)(nil).MergeNoteTags), arg0, arg1)
+}
+
+// MergeWebTags mocks base method.
+func (m *MockStore) MergeWebTags(arg0 context.Context, arg1 db.MergeWebTagsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeWebTags", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeWebTags indicates an expected call of MergeWebTags.
+func (mr *MockStoreMockRecorder) MergeWebTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeWebTags", reflect.TypeOf((*MockStore)(nil).MergeWebTags), arg0, arg1)
+}
+
+// RecordLoginFailure mocks base method.
+func (m *MockStore) RecordLoginFailure(arg0 context.Context, arg1 db.RecordLoginFailureParams) (db.LoginThrottle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginThrottle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockStoreMockRecorder) RecordLoginFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockStore)(nil).RecordLoginFailure), arg0, arg1)
+}
+
+// ResetWebJob mocks base method.
+func (m *MockStore) ResetWebJob(arg0 context.Context, arg1 db.ResetWebJobParams) (db.WebJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetWebJob", arg0, arg1)
+	ret0, _ := ret[0].(db.WebJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResetWebJob indicates an expected call of ResetWebJob.
+func (mr *MockStoreMockRecorder) ResetWebJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetWebJob", reflect.TypeOf((*MockStore)(nil).ResetWebJob), arg0, arg1)
+}
+
+// RetryWebJob mocks base method.
+func (m *MockStore) RetryWebJob(arg0 context.Context, arg1 db.RetryWebJobParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebJob", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetryWebJob indicates an expected call of RetryWebJob.
+func (mr *MockStoreMockRecorder) RetryWebJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebJob", reflect.TypeOf((*MockStore)(nil).RetryWebJob), arg0, arg1)
+}
+
+// RotateSession mocks base method.
+func (m *MockStore) RotateSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSession indicates an expected call of RotateSession.
+func (mr *MockStoreMockRecorder) RotateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSession", reflect.TypeOf((*MockStore)(nil).RotateSession), arg0, arg1)
+}
+
+// Search mocks base method.
+func (m *MockStore) Search(arg0 context.Context, arg1 db.SearchParams) ([]db.SearchRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", arg0, arg1)
+	ret0, _ := ret[0].([]db.SearchRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockStoreMockRecorder) Search(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockStore)(nil).Search), arg0, arg1)
+}
+
+// SearchUsers mocks base method.
+func (m *MockStore) SearchUsers(arg0 context.Context, arg1 db.SearchUsersParams) ([]db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", arg0, arg1)
+	ret0, _ := ret[0].([]db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockStoreMockRecorder) SearchUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockStore)(nil).SearchUsers), arg0, arg1)
+}
+
+// TouchPersonalAccessToken mocks base method.
+func (m *MockStore) TouchPersonalAccessToken(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchPersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchPersonalAccessToken indicates an expected call of TouchPersonalAccessToken.
+func (mr *MockStoreMockRecorder) TouchPersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchPersonalAccessToken", reflect.TypeOf((*MockStore)(nil).TouchPersonalAccessToken), arg0, arg1)
+}
+
+// TxBlockUser mocks base method.
+func (m *MockStore) TxBlockUser(arg0 context.Context, arg1 db.TxBlockUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxBlockUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxBlockUser indicates an expected call of TxBlockUser.
+func (mr *MockStoreMockRecorder) TxBlockUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxBlockUser", reflect.TypeOf((*MockStore)(nil).TxBlockUser), arg0, arg1)
+}
+
+// TxChangeEmail mocks base method.
+func (m *MockStore) TxChangeEmail(arg0 context.Context, arg1 db.TxChangeEmailParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxChangeEmail", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxChangeEmail indicates an expected call of TxChangeEmail.
+func (mr *MockStoreMockRecorder) TxChangeEmail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxChangeEmail", reflect.TypeOf((*MockStore)(nil).TxChangeEmail), arg0, arg1)
+}
+
+// TxChangePassword mocks base method.
+func (m *MockStore) TxChangePassword(arg0 context.Context, arg1 db.TxChangePasswordParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxChangePassword", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxChangePassword indicates an expected call of TxChangePassword.
+func (mr *MockStoreMockRecorder) TxChangePassword(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxChangePassword", reflect.TypeOf((*MockStore)(nil).TxChangePassword), arg0, arg1)
+}
+
+// TxCompleteWebJob mocks base method.
+func (m *MockStore) TxCompleteWebJob(arg0 context.Context, arg1 db.TxCompleteWebJobParams) (db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxCompleteWebJob", arg0, arg1)
+	ret0, _ := ret[0].(db.Web)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxCompleteWebJob indicates an expected call of TxCompleteWebJob.
+func (mr *MockStoreMockRecorder) TxCompleteWebJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxCompleteWebJob", reflect.TypeOf((*MockStore)(nil).TxCompleteWebJob), arg0, arg1)
+}
+
+// TxCreateNote mocks base method.
+func (m *MockStore) TxCreateNote(arg0 context.Context, arg1 db.TxCreateNoteParams) (db.TxCreateNoteResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxCreateNote", arg0, arg1)
+	ret0, _ := ret[0].(db.TxCreateNoteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxCreateNote indicates an expected call of TxCreateNote.
+func (mr *MockStoreMockRecorder) TxCreateNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxCreateNote", reflect.TypeOf((*MockStore)(nil).TxCreateNote), arg0, arg1)
+}
+
+// TxCreateUserWithIdentity mocks base method.
+func (m *MockStore) TxCreateUserWithIdentity(arg0 context.Context, arg1 db.TxCreateUserWithIdentityParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxCreateUserWithIdentity", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxCreateUserWithIdentity indicates an expected call of TxCreateUserWithIdentity.
+func (mr *MockStoreMockRecorder) TxCreateUserWithIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxCreateUserWithIdentity", reflect.TypeOf((*MockStore)(nil).TxCreateUserWithIdentity), arg0, arg1)
+}
+
+// TxCreateWeb mocks base method.
+func (m *MockStore) TxCreateWeb(arg0 context.Context, arg1 db.TxCreateWebParams) (db.TxCreateWebResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxCreateWeb", arg0, arg1)
+	ret0, _ := ret[0].(db.TxCreateWebResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxCreateWeb indicates an expected call of TxCreateWeb.
+func (mr *MockStoreMockRecorder) TxCreateWeb(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxCreateWeb", reflect.TypeOf((*MockStore)(nil).TxCreateWeb), arg0, arg1)
+}
+
+// TxDeleteNote mocks base method.
+func (m *MockStore) TxDeleteNote(arg0 context.Context, arg1 db.TxDeleteNoteParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxDeleteNote", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TxDeleteNote indicates an expected call of TxDeleteNote.
+func (mr *MockStoreMockRecorder) TxDeleteNote(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxDeleteNote", reflect.TypeOf((*MockStore)(nil).TxDeleteNote), arg0, arg1)
+}
+
+// TxDeleteUser mocks base method.
+func (m *MockStore) TxDeleteUser(arg0 context.Context, arg1 db.TxDeleteUserParams) (db.UserTombstone, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxDeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTombstone)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateNote indicates an expected call of CreateNote.
-func (mr *MockStoreMockRecorder) CreateNote(arg0, arg1 interface{}) *gomock.Call {
+// TxDeleteUser indicates an expected call of TxDeleteUser.
+func (mr *MockStoreMockRecorder) TxDeleteUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNote", reflect.TypeOf((*MockStore)(nil).CreateNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxDeleteUser", reflect.TypeOf((*MockStore)(nil).TxDeleteUser), arg0, arg1)
 }
 
-// CreateNoteWeb mocks base method.
-func (m *MockStore) CreateNoteWeb(arg0 context.Context, arg1 db.CreateNoteWebParams) (db.NoteWeb, error) {
+// TxDisableTwoFactor mocks base method.
+func (m *MockStore) TxDisableTwoFactor(arg0 context.Context, arg1 db.TxDisableTwoFactorParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateNoteWeb", arg0, arg1)
-	ret0, _ := ret[0].(db.NoteWeb)
+	ret := m.ctrl.Call(m, "TxDisableTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TxDisableTwoFactor indicates an expected call of TxDisableTwoFactor.
+func (mr *MockStoreMockRecorder) TxDisableTwoFactor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxDisableTwoFactor", reflect.TypeOf((*MockStore)(nil).TxDisableTwoFactor), arg0, arg1)
+}
+
+// TxEnableTwoFactor mocks base method.
+func (m *MockStore) TxEnableTwoFactor(arg0 context.Context, arg1 db.TxEnableTwoFactorParams) (db.UserTwoFactor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxEnableTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTwoFactor)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateNoteWeb indicates an expected call of CreateNoteWeb.
-func (mr *MockStoreMockRecorder) CreateNoteWeb(arg0, arg1 interface{}) *gomock.Call {
+// TxEnableTwoFactor indicates an expected call of TxEnableTwoFactor.
+func (mr *MockStoreMockRecorder) TxEnableTwoFactor(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNoteWeb", reflect.TypeOf((*MockStore)(nil).CreateNoteWeb), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxEnableTwoFactor", reflect.TypeOf((*MockStore)(nil).TxEnableTwoFactor), arg0, arg1)
 }
 
-// CreateSession mocks base method.
-func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) (db.Session, error) {
+// TxFailWebJob mocks base method.
+func (m *MockStore) TxFailWebJob(arg0 context.Context, arg1 db.TxFailWebJobParams) (db.Web, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "TxFailWebJob", arg0, arg1)
+	ret0, _ := ret[0].(db.Web)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession.
-func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+// TxFailWebJob indicates an expected call of TxFailWebJob.
+func (mr *MockStoreMockRecorder) TxFailWebJob(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxFailWebJob", reflect.TypeOf((*MockStore)(nil).TxFailWebJob), arg0, arg1)
 }
 
-// CreateTemporaryUser mocks base method.
-func (m *MockStore) CreateTemporaryUser(arg0 context.Context, arg1 db.CreateTemporaryUserParams) (db.TemporaryUser, error) {
+// TxMergeTags mocks base method.
+func (m *MockStore) TxMergeTags(arg0 context.Context, arg1 db.TxMergeTagsParams) (db.Tag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTemporaryUser", arg0, arg1)
-	ret0, _ := ret[0].(db.TemporaryUser)
+	ret := m.ctrl.Call(m, "TxMergeTags", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTemporaryUser indicates an expected call of CreateTemporaryUser.
-func (mr *MockStoreMockRecorder) CreateTemporaryUser(arg0, arg1 interface{}) *gomock.Call {
+// TxMergeTags indicates an expected call of TxMergeTags.
+func (mr *MockStoreMockRecorder) TxMergeTags(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTemporaryUser", reflect.TypeOf((*MockStore)(nil).CreateTemporaryUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxMergeTags", reflect.TypeOf((*MockStore)(nil).TxMergeTags), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// TxRefetchWeb mocks base method.
+func (m *MockStore) TxRefetchWeb(arg0 context.Context, arg1 db.TxRefetchWebParams) (db.TxRefetchWebResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "TxRefetchWeb", arg0, arg1)
+	ret0, _ := ret[0].(db.TxRefetchWebResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// TxRefetchWeb indicates an expected call of TxRefetchWeb.
+func (mr *MockStoreMockRecorder) TxRefetchWeb(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxRefetchWeb", reflect.TypeOf((*MockStore)(nil).TxRefetchWeb), arg0, arg1)
 }
 
-// CreateWeb mocks base method.
-func (m *MockStore) CreateWeb(arg0 context.Context, arg1 db.CreateWebParams) (db.Web, error) {
+// TxResetPassword mocks base method.
+func (m *MockStore) TxResetPassword(arg0 context.Context, arg1 db.TxResetPasswordParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateWeb", arg0, arg1)
-	ret0, _ := ret[0].(db.Web)
+	ret := m.ctrl.Call(m, "TxResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateWeb indicates an expected call of CreateWeb.
-func (mr *MockStoreMockRecorder) CreateWeb(arg0, arg1 interface{}) *gomock.Call {
+// TxResetPassword indicates an expected call of TxResetPassword.
+func (mr *MockStoreMockRecorder) TxResetPassword(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWeb", reflect.TypeOf((*MockStore)(nil).CreateWeb), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxResetPassword", reflect.TypeOf((*MockStore)(nil).TxResetPassword), arg0, arg1)
 }
 
-// DeleteNote mocks base method.
-func (m *MockStore) DeleteNote(arg0 context.Context, arg1 uuid.UUID) error {
+// TxRotateSession mocks base method.
+func (m *MockStore) TxRotateSession(arg0 context.Context, arg1 db.TxRotateSessionParams) (db.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNote", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "TxRotateSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteNote indicates an expected call of DeleteNote.
-func (mr *MockStoreMockRecorder) DeleteNote(arg0, arg1 interface{}) *gomock.Call {
+// TxRotateSession indicates an expected call of TxRotateSession.
+func (mr *MockStoreMockRecorder) TxRotateSession(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNote", reflect.TypeOf((*MockStore)(nil).DeleteNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxRotateSession", reflect.TypeOf((*MockStore)(nil).TxRotateSession), arg0, arg1)
 }
 
-// DeleteNoteWeb mocks base method.
-func (m *MockStore) DeleteNoteWeb(arg0 context.Context, arg1 db.DeleteNoteWebParams) error {
+// TxSetWebTags mocks base method.
+func (m *MockStore) TxSetWebTags(arg0 context.Context, arg1 db.TxSetWebTagsParams) ([]db.Tag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNoteWeb", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "TxSetWebTags", arg0, arg1)
+	ret0, _ := ret[0].([]db.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteNoteWeb indicates an expected call of DeleteNoteWeb.
-func (mr *MockStoreMockRecorder) DeleteNoteWeb(arg0, arg1 interface{}) *gomock.Call {
+// TxSetWebTags indicates an expected call of TxSetWebTags.
+func (mr *MockStoreMockRecorder) TxSetWebTags(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteWeb", reflect.TypeOf((*MockStore)(nil).DeleteNoteWeb), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxSetWebTags", reflect.TypeOf((*MockStore)(nil).TxSetWebTags), arg0, arg1)
 }
 
-// DeleteNoteWebsByNoteId mocks base method.
-func (m *MockStore) DeleteNoteWebsByNoteId(arg0 context.Context, arg1 uuid.UUID) error {
+// TxUpdateNote mocks base method.
+func (m *MockStore) TxUpdateNote(arg0 context.Context, arg1 db.TxUpdateNoteParams) (db.TxUpdateNoteResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteNoteWebsByNoteId", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "TxUpdateNote", arg0, arg1)
+	ret0, _ := ret[0].(db.TxUpdateNoteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteNoteWebsByNoteId indicates an expected call of DeleteNoteWebsByNoteId.
-func (mr *MockStoreMockRecorder) DeleteNoteWebsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+// TxUpdateNote indicates an expected call of TxUpdateNote.
+func (mr *MockStoreMockRecorder) TxUpdateNote(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNoteWebsByNoteId", reflect.TypeOf((*MockStore)(nil).DeleteNoteWebsByNoteId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxUpdateNote", reflect.TypeOf((*MockStore)(nil).TxUpdateNote), arg0, arg1)
 }
 
-// DeleteUser mocks base method.
-func (m *MockStore) DeleteUser(arg0 context.Context, arg1 uuid.UUID) error {
+// TxVerifyUser mocks base method.
+func (m *MockStore) TxVerifyUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "TxVerifyUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+// TxVerifyUser indicates an expected call of TxVerifyUser.
+func (mr *MockStoreMockRecorder) TxVerifyUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxVerifyUser", reflect.TypeOf((*MockStore)(nil).TxVerifyUser), arg0, arg1)
 }
 
-// DeleteWeb mocks base method.
-func (m *MockStore) DeleteWeb(arg0 context.Context, arg1 uuid.UUID) error {
+// UpdateDeviceAuthorizationPoll mocks base method.
+func (m *MockStore) UpdateDeviceAuthorizationPoll(arg0 context.Context, arg1 db.UpdateDeviceAuthorizationPollParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteWeb", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateDeviceAuthorizationPoll", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteWeb indicates an expected call of DeleteWeb.
-func (mr *MockStoreMockRecorder) DeleteWeb(arg0, arg1 interface{}) *gomock.Call {
+// UpdateDeviceAuthorizationPoll indicates an expected call of UpdateDeviceAuthorizationPoll.
+func (mr *MockStoreMockRecorder) UpdateDeviceAuthorizationPoll(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWeb", reflect.TypeOf((*MockStore)(nil).DeleteWeb), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDeviceAuthorizationPoll", reflect.TypeOf((*MockStore)(nil).UpdateDeviceAuthorizationPoll), arg0, arg1)
 }
 
-// GetNote mocks base method.
-func (m *MockStore) GetNote(arg0 context.Context, arg1 uuid.UUID) (db.Note, error) {
+// UpdateNote mocks base method.
+func (m *MockStore) UpdateNote(arg0 context.Context, arg1 db.UpdateNoteParams) (db.Note, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNote", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateNote", arg0, arg1)
 	ret0, _ := ret[0].(db.Note)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetNote indicates an expected call of GetNote.
-func (mr *MockStoreMockRecorder) GetNote(arg0, arg1 interface{}) *gomock.Call {
+// UpdateNote indicates an expected call of UpdateNote.
+func (mr *MockStoreMockRecorder) UpdateNote(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNote", reflect.TypeOf((*MockStore)(nil).GetNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockStore)(nil).UpdateNote), arg0, arg1)
 }
 
-// GetNoteWeb mocks base method.
-func (m *MockStore) GetNoteWeb(arg0 context.Context, arg1 db.GetNoteWebParams) (db.NoteWeb, error) {
+// UpdateProfileAvatar mocks base method.
+func (m *MockStore) UpdateProfileAvatar(arg0 context.Context, arg1 db.UpdateProfileAvatarParams) (db.Profile, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNoteWeb", arg0, arg1)
-	ret0, _ := ret[0].(db.NoteWeb)
+	ret := m.ctrl.Call(m, "UpdateProfileAvatar", arg0, arg1)
+	ret0, _ := ret[0].(db.Profile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetNoteWeb indicates an expected call of GetNoteWeb.
-func (mr *MockStoreMockRecorder) GetNoteWeb(arg0, arg1 interface{}) *gomock.Call {
+// UpdateProfileAvatar indicates an expected call of UpdateProfileAvatar.
+func (mr *MockStoreMockRecorder) UpdateProfileAvatar(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNoteWeb", reflect.TypeOf((*MockStore)(nil).GetNoteWeb), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfileAvatar", reflect.TypeOf((*MockStore)(nil).UpdateProfileAvatar), arg0, arg1)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// UpdateTagName mocks base method.
+func (m *MockStore) UpdateTagName(arg0 context.Context, arg1 db.UpdateTagNameParams) (db.Tag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "UpdateTagName", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// UpdateTagName indicates an expected call of UpdateTagName.
+func (mr *MockStoreMockRecorder) UpdateTagName(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTagName", reflect.TypeOf((*MockStore)(nil).UpdateTagName), arg0, arg1)
 }
 
-// GetTemporaryUserByEmailAndToken mocks base method.
-func (m *MockStore) GetTemporaryUserByEmailAndToken(arg0 context.Context, arg1 db.GetTemporaryUserByEmailAndTokenParams) (db.TemporaryUser, error) {
+// UpdateUser mocks base method.
+func (m *MockStore) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTemporaryUserByEmailAndToken", arg0, arg1)
-	ret0, _ := ret[0].(db.TemporaryUser)
+	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTemporaryUserByEmailAndToken indicates an expected call of GetTemporaryUserByEmailAndToken.
-func (mr *MockStoreMockRecorder) GetTemporaryUserByEmailAndToken(arg0, arg1 interface{}) *gomock.Call {
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemporaryUserByEmailAndToken", reflect.TypeOf((*MockStore)(nil).GetTemporaryUserByEmailAndToken), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 uuid.UUID) (db.User, error) {
+// UpdateUserBlockedAt mocks base method.
+func (m *MockStore) UpdateUserBlockedAt(arg0 context.Context, arg1 db.UpdateUserBlockedAtParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateUserBlockedAt", arg0, arg1)
 	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// UpdateUserBlockedAt indicates an expected call of UpdateUserBlockedAt.
+func (mr *MockStoreMockRecorder) UpdateUserBlockedAt(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBlockedAt", reflect.TypeOf((*MockStore)(nil).UpdateUserBlockedAt), arg0, arg1)
 }
 
-// GetUserByEmail mocks base method.
-func (m *MockStore) GetUserByEmail(arg0 context.Context, arg1 string) (db.User, error) {
+// UpdateUserPassword mocks base method.
+func (m *MockStore) UpdateUserPassword(arg0 context.Context, arg1 db.UpdateUserPasswordParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
 	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByEmail indicates an expected call of GetUserByEmail.
-func (mr *MockStoreMockRecorder) GetUserByEmail(arg0, arg1 interface{}) *gomock.Call {
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockStoreMockRecorder) UpdateUserPassword(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStore)(nil).GetUserByEmail), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockStore)(nil).UpdateUserPassword), arg0, arg1)
 }
 
-// GetWeb mocks base method.
-func (m *MockStore) GetWeb(arg0 context.Context, arg1 uuid.UUID) (db.Web, error) {
+// UpdateUserRole mocks base method.
+func (m *MockStore) UpdateUserRole(arg0 context.Context, arg1 db.UpdateUserRoleParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWeb", arg0, arg1)
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockStoreMockRecorder) UpdateUserRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockStore)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UpdateWebArticle mocks base method.
+func (m *MockStore) UpdateWebArticle(arg0 context.Context, arg1 db.UpdateWebArticleParams) (db.Web, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebArticle", arg0, arg1)
 	ret0, _ := ret[0].(db.Web)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetWeb indicates an expected call of GetWeb.
-func (mr *MockStoreMockRecorder) GetWeb(arg0, arg1 interface{}) *gomock.Call {
+// UpdateWebArticle indicates an expected call of UpdateWebArticle.
+func (mr *MockStoreMockRecorder) UpdateWebArticle(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWeb", reflect.TypeOf((*MockStore)(nil).GetWeb), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebArticle", reflect.TypeOf((*MockStore)(nil).UpdateWebArticle), arg0, arg1)
 }
 
-// ListNoteWebsByNoteId mocks base method.
-func (m *MockStore) ListNoteWebsByNoteId(arg0 context.Context, arg1 uuid.UUID) ([]db.NoteWeb, error) {
+// UpdateWebContent mocks base method.
+func (m *MockStore) UpdateWebContent(arg0 context.Context, arg1 db.UpdateWebContentParams) (db.Web, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListNoteWebsByNoteId", arg0, arg1)
-	ret0, _ := ret[0].([]db.NoteWeb)
+	ret := m.ctrl.Call(m, "UpdateWebContent", arg0, arg1)
+	ret0, _ := ret[0].(db.Web)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListNoteWebsByNoteId indicates an expected call of ListNoteWebsByNoteId.
-func (mr *MockStoreMockRecorder) ListNoteWebsByNoteId(arg0, arg1 interface{}) *gomock.Call {
+// UpdateWebContent indicates an expected call of UpdateWebContent.
+func (mr *MockStoreMockRecorder) UpdateWebContent(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNoteWebsByNoteId", reflect.TypeOf((*MockStore)(nil).ListNoteWebsByNoteId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebContent", reflect.TypeOf((*MockStore)(nil).UpdateWebContent), arg0, arg1)
 }
 
-// ListNotesByUserId mocks base method.
-func (m *MockStore) ListNotesByUserId(arg0 context.Context, arg1 db.ListNotesByUserIdParams) ([]db.Note, error) {
+// UpdateWebStatus mocks base method.
+func (m *MockStore) UpdateWebStatus(arg0 context.Context, arg1 db.UpdateWebStatusParams) (db.Web, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListNotesByUserId", arg0, arg1)
-	ret0, _ := ret[0].([]db.Note)
+	ret := m.ctrl.Call(m, "UpdateWebStatus", arg0, arg1)
+	ret0, _ := ret[0].(db.Web)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListNotesByUserId indicates an expected call of ListNotesByUserId.
-func (mr *MockStoreMockRecorder) ListNotesByUserId(arg0, arg1 interface{}) *gomock.Call {
+// UpdateWebStatus indicates an expected call of UpdateWebStatus.
+func (mr *MockStoreMockRecorder) UpdateWebStatus(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotesByUserId", reflect.TypeOf((*MockStore)(nil).ListNotesByUserId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebStatus", reflect.TypeOf((*MockStore)(nil).UpdateWebStatus), arg0, arg1)
 }
 
-// ListUsers mocks base method.
-func (m *MockStore) ListUsers(arg0 context.Context, arg1 db.ListUsersParams) ([]db.User, error) {
+// UpsertProfile mocks base method.
+func (m *MockStore) UpsertProfile(arg0 context.Context, arg1 db.UpsertProfileParams) (db.Profile, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
-	ret0, _ := ret[0].([]db.User)
+	ret := m.ctrl.Call(m, "UpsertProfile", arg0, arg1)
+	ret0, _ := ret[0].(db.Profile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListUsers indicates an expected call of ListUsers.
-func (mr *MockStoreMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+// UpsertProfile indicates an expected call of UpsertProfile.
+func (mr *MockStoreMockRecorder) UpsertProfile(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockStore)(nil).ListUsers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertProfile", reflect.TypeOf((*MockStore)(nil).UpsertProfile), arg0, arg1)
 }
 
-// ListWebByNoteId mocks base method.
-func (m *MockStore) ListWebByNoteId(arg0 context.Context, arg1 uuid.UUID) ([]db.Web, error) {
+// UpsertTag mocks base method.
+func (m *MockStore) UpsertTag(arg0 context.Context, arg1 db.UpsertTagParams) (db.Tag, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListWebByNoteId", arg0, arg1)
-	ret0, _ := ret[0].([]db.Web)
+	ret := m.ctrl.Call(m, "UpsertTag", arg0, arg1)
+	ret0, _ := ret[0].(db.Tag)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListWebByNoteId indicates an expected call of ListWebByNoteId.
-func (mr *MockStoreMockRecorder) ListWebByNoteId(arg0, arg1 interface{}) *gomock.Call {
+// UpsertTag indicates an expected call of UpsertTag.
+func (mr *MockStoreMockRecorder) UpsertTag(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebByNoteId", reflect.TypeOf((*MockStore)(nil).ListWebByNoteId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTag", reflect.TypeOf((*MockStore)(nil).UpsertTag), arg0, arg1)
 }
 
-// ListWebByNoteIds mocks base method.
-func (m *MockStore) ListWebByNoteIds(arg0 context.Context, arg1 []uuid.UUID) ([]db.ListWebByNoteIdsRow, error) {
+// UpsertUserTwoFactor mocks base method.
+func (m *MockStore) UpsertUserTwoFactor(arg0 context.Context, arg1 db.UpsertUserTwoFactorParams) (db.UserTwoFactor, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListWebByNoteIds", arg0, arg1)
-	ret0, _ := ret[0].([]db.ListWebByNoteIdsRow)
+	ret := m.ctrl.Call(m, "UpsertUserTwoFactor", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTwoFactor)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListWebByNoteIds indicates an expected call of ListWebByNoteIds.
-func (mr *MockStoreMockRecorder) ListWebByNoteIds(arg0, arg1 interface{}) *gomock.Call {
+// UpsertUserTwoFactor indicates an expected call of UpsertUserTwoFactor.
+func (mr *MockStoreMockRecorder) UpsertUserTwoFactor(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebByNoteIds", reflect.TypeOf((*MockStore)(nil).ListWebByNoteIds), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserTwoFactor", reflect.TypeOf((*MockStore)(nil).UpsertUserTwoFactor), arg0, arg1)
 }
 
-// ListWebsByUserId mocks base method.
-func (m *MockStore) ListWebsByUserId(arg0 context.Context, arg1 db.ListWebsByUserIdParams) ([]db.Web, error) {
+// UseEmailChangeToken mocks base method.
+func (m *MockStore) UseEmailChangeToken(arg0 context.Context, arg1 string) (db.EmailChangeToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListWebsByUserId", arg0, arg1)
-	ret0, _ := ret[0].([]db.Web)
+	ret := m.ctrl.Call(m, "UseEmailChangeToken", arg0, arg1)
+	ret0, _ := ret[0].(db.EmailChangeToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListWebsByUserId indicates an expected call of ListWebsByUserId.
-func (mr *MockStoreMockRecorder) ListWebsByUserId(arg0, arg1 interface{}) *gomock.Call {
+// UseEmailChangeToken indicates an expected call of UseEmailChangeToken.
+func (mr *MockStoreMockRecorder) UseEmailChangeToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebsByUserId", reflect.TypeOf((*MockStore)(nil).ListWebsByUserId), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseEmailChangeToken", reflect.TypeOf((*MockStore)(nil).UseEmailChangeToken), arg0, arg1)
 }
 
-// TxCreateNote mocks base method.
-func (m *MockStore) TxCreateNote(arg0 context.Context, arg1 db.TxCreateNoteParams) (db.TxCreateNoteResult, error) {
+// UseLoginChallenge mocks base method.
+func (m *MockStore) UseLoginChallenge(arg0 context.Context, arg1 string) (db.LoginChallenge, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TxCreateNote", arg0, arg1)
-	ret0, _ := ret[0].(db.TxCreateNoteResult)
+	ret := m.ctrl.Call(m, "UseLoginChallenge", arg0, arg1)
+	ret0, _ := ret[0].(db.LoginChallenge)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TxCreateNote indicates an expected call of TxCreateNote.
-func (mr *MockStoreMockRecorder) TxCreateNote(arg0, arg1 interface{}) *gomock.Call {
+// UseLoginChallenge indicates an expected call of UseLoginChallenge.
+func (mr *MockStoreMockRecorder) UseLoginChallenge(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxCreateNote", reflect.TypeOf((*MockStore)(nil).TxCreateNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseLoginChallenge", reflect.TypeOf((*MockStore)(nil).UseLoginChallenge), arg0, arg1)
 }
 
-// TxDeleteNote mocks base method.
-func (m *MockStore) TxDeleteNote(arg0 context.Context, arg1 db.TxDeleteNoteParams) error {
+// UseOidcState mocks base method.
+func (m *MockStore) UseOidcState(arg0 context.Context, arg1 string) (db.OidcState, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TxDeleteNote", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UseOidcState", arg0, arg1)
+	ret0, _ := ret[0].(db.OidcState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// TxDeleteNote indicates an expected call of TxDeleteNote.
-func (mr *MockStoreMockRecorder) TxDeleteNote(arg0, arg1 interface{}) *gomock.Call {
+// UseOidcState indicates an expected call of UseOidcState.
+func (mr *MockStoreMockRecorder) UseOidcState(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxDeleteNote", reflect.TypeOf((*MockStore)(nil).TxDeleteNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOidcState", reflect.TypeOf((*MockStore)(nil).UseOidcState), arg0, arg1)
 }
 
-// TxUpdateNote mocks base method.
-func (m *MockStore) TxUpdateNote(arg0 context.Context, arg1 db.TxUpdateNoteParams) (db.TxUpdateNoteResult, error) {
+// UsePasswordResetToken mocks base method.
+func (m *MockStore) UsePasswordResetToken(arg0 context.Context, arg1 string) (db.PasswordResetToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TxUpdateNote", arg0, arg1)
-	ret0, _ := ret[0].(db.TxUpdateNoteResult)
+	ret := m.ctrl.Call(m, "UsePasswordResetToken", arg0, arg1)
+	ret0, _ := ret[0].(db.PasswordResetToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// TxUpdateNote indicates an expected call of TxUpdateNote.
-func (mr *MockStoreMockRecorder) TxUpdateNote(arg0, arg1 interface{}) *gomock.Call {
+// UsePasswordResetToken indicates an expected call of UsePasswordResetToken.
+func (mr *MockStoreMockRecorder) UsePasswordResetToken(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxUpdateNote", reflect.TypeOf((*MockStore)(nil).TxUpdateNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UsePasswordResetToken", reflect.TypeOf((*MockStore)(nil).UsePasswordResetToken), arg0, arg1)
 }
 
-// UpdateNote mocks base method.
-func (m *MockStore) UpdateNote(arg0 context.Context, arg1 db.UpdateNoteParams) (db.Note, error) {
+// UseRecoveryCode mocks base method.
+func (m *MockStore) UseRecoveryCode(arg0 context.Context, arg1 db.UseRecoveryCodeParams) (db.RecoveryCode, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateNote", arg0, arg1)
-	ret0, _ := ret[0].(db.Note)
+	ret := m.ctrl.Call(m, "UseRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(db.RecoveryCode)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateNote indicates an expected call of UpdateNote.
-func (mr *MockStoreMockRecorder) UpdateNote(arg0, arg1 interface{}) *gomock.Call {
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockStoreMockRecorder) UseRecoveryCode(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNote", reflect.TypeOf((*MockStore)(nil).UpdateNote), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockStore)(nil).UseRecoveryCode), arg0, arg1)
 }
 
-// UpdateUser mocks base method.
-func (m *MockStore) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (db.User, error) {
+// UseTotpStep mocks base method.
+func (m *MockStore) UseTotpStep(arg0 context.Context, arg1 db.UseTotpStepParams) (db.UserTwoFactor, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "UseTotpStep", arg0, arg1)
+	ret0, _ := ret[0].(db.UserTwoFactor)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateUser indicates an expected call of UpdateUser.
-func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call {
+// UseTotpStep indicates an expected call of UseTotpStep.
+func (mr *MockStoreMockRecorder) UseTotpStep(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTotpStep", reflect.TypeOf((*MockStore)(nil).UseTotpStep), arg0, arg1)
 }
@@ -0,0 +1,46 @@
+-- name: CreateDeviceAuthorization :one
+INSERT INTO device_authorizations (
+  device_code_hash,
+  user_code,
+  client_id,
+  interval_seconds,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetDeviceAuthorization :one
+SELECT * FROM device_authorizations
+WHERE device_code_hash = $1 LIMIT 1;
+
+-- name: UpdateDeviceAuthorizationPoll :exec
+UPDATE device_authorizations
+SET
+  last_polled_at = now(),
+  interval_seconds = $2
+WHERE device_code_hash = $1;
+
+-- name: ApproveDeviceAuthorization :one
+-- no row is returned when the code is unknown, expired or already answered
+UPDATE device_authorizations
+SET
+  status = 'approved',
+  user_id = $2
+WHERE user_code = $1 AND status = 'pending' AND expires_at > now()
+RETURNING *;
+
+-- name: DenyDeviceAuthorization :one
+UPDATE device_authorizations
+SET
+  status = 'denied',
+  user_id = $2
+WHERE user_code = $1 AND status = 'pending' AND expires_at > now()
+RETURNING *;
+
+-- name: ConsumeDeviceAuthorization :one
+-- no row is returned when the tokens were already handed out
+UPDATE device_authorizations
+SET status = 'consumed'
+WHERE device_code_hash = $1 AND status = 'approved'
+RETURNING *;
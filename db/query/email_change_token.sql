@@ -0,0 +1,24 @@
+-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+  token_hash,
+  user_id,
+  new_email,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UseEmailChangeToken :one
+-- no row is returned when the token is unknown, expired or already used
+UPDATE email_change_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
+
+-- name: InvalidateEmailChangeTokensByUserId :exec
+UPDATE email_change_tokens
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL;
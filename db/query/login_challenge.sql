@@ -0,0 +1,25 @@
+-- name: CreateLoginChallenge :one
+INSERT INTO login_challenges (
+  token_hash,
+  user_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: IncrementLoginChallengeAttempts :one
+-- no row is returned when the challenge is unknown, expired, already used or out of attempts
+UPDATE login_challenges
+SET attempts = attempts + 1
+WHERE token_hash = sqlc.arg(token_hash)
+  AND used_at IS NULL
+  AND expires_at > now()
+  AND attempts < sqlc.arg(max_attempts)
+RETURNING *;
+
+-- name: UseLoginChallenge :one
+UPDATE login_challenges
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL
+RETURNING *;
@@ -0,0 +1,30 @@
+-- name: ListLoginThrottles :many
+SELECT * FROM login_throttles
+WHERE key = ANY(@keys::varchar[]);
+
+-- name: RecordLoginFailure :one
+-- the count starts over when the last failure is older than window_start
+INSERT INTO login_throttles (
+  key,
+  failures,
+  last_failed_at
+) VALUES (
+  @key, 1, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+  failures = CASE
+    WHEN login_throttles.last_failed_at < @window_start THEN 1
+    ELSE login_throttles.failures + 1
+  END,
+  last_failed_at = now()
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_throttles
+SET locked_until = $2
+WHERE key = $1;
+
+-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttles
+WHERE key = $1;
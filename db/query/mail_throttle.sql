@@ -0,0 +1,12 @@
+-- name: ClaimMailSend :one
+-- no row is returned while the last mail to the key was sent after sent_before, otherwise it is sent now
+INSERT INTO mail_throttles (
+  key,
+  sent_at
+) VALUES (
+  @key, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET sent_at = now()
+WHERE mail_throttles.sent_at < @sent_before
+RETURNING *;
@@ -14,20 +14,56 @@ SELECT * FROM notes
 WHERE id = $1 LIMIT 1;
 
 -- name: ListNotesByUserId :many
+-- only notes that have every tag in tags are listed, tags is ignored when empty
 SELECT * FROM notes
-WHERE user_id = $1
-LIMIT $2
-OFFSET $3;
+WHERE notes.user_id = sqlc.arg(user_id)
+  AND (
+    coalesce(cardinality(sqlc.arg(tags)::varchar[]), 0) = 0
+    OR notes.id IN (
+      SELECT note_tags.note_id FROM note_tags
+      INNER JOIN tags ON tags.id = note_tags.tag_id
+      WHERE tags.user_id = sqlc.arg(user_id) AND tags.name = ANY(sqlc.arg(tags)::varchar[])
+      GROUP BY note_tags.note_id
+      HAVING count(*) = cardinality(sqlc.arg(tags)::varchar[])
+    )
+  )
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateNote :one
+-- no row is updated when expected_version is given and the note has moved past it
 UPDATE notes
 SET
-  title = $2,
-  content = $3,
-  is_public = $4
-WHERE id = $1
+  title = sqlc.arg(title),
+  content = sqlc.arg(content),
+  is_public = sqlc.arg(is_public),
+  version = version + 1,
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(expected_version)::integer IS NULL OR version = sqlc.narg(expected_version)::integer)
 RETURNING *;
 
 -- name: DeleteNote :exec
 DELETE FROM notes
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListNotesByUserIdAfterId :many
+SELECT * FROM notes
+WHERE user_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3;
+
+-- name: ListPublicNotesByUserId :many
+SELECT * FROM notes
+WHERE user_id = $1 AND is_public = true
+ORDER BY updated_at DESC, id
+LIMIT $2
+OFFSET $3;
+
+-- name: GetPublicNotesState :one
+-- changes whenever a public note is added or updated, profiles.public_notes_removed_at covers the ones made private or deleted
+SELECT
+  count(*) AS count,
+  coalesce(max(updated_at), 'epoch'::timestamptz)::timestamptz AS last_updated_at
+FROM notes
+WHERE user_id = $1 AND is_public = true;
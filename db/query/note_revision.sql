@@ -0,0 +1,33 @@
+-- name: CreateNoteRevision :one
+-- revisions are numbered per note, callers hold the lock on the note row
+INSERT INTO note_revisions (
+  note_id,
+  revision,
+  author_id,
+  title,
+  content,
+  is_public,
+  web_ids
+)
+SELECT
+  sqlc.arg(note_id),
+  coalesce(max(revision), 0) + 1,
+  sqlc.arg(author_id),
+  sqlc.arg(title),
+  sqlc.arg(content),
+  sqlc.arg(is_public),
+  coalesce(sqlc.arg(web_ids)::uuid[], '{}')
+FROM note_revisions
+WHERE note_id = sqlc.arg(note_id)
+RETURNING *;
+
+-- name: GetNoteRevision :one
+SELECT * FROM note_revisions
+WHERE note_id = $1 AND revision = $2 LIMIT 1;
+
+-- name: ListNoteRevisions :many
+SELECT * FROM note_revisions
+WHERE note_id = $1
+ORDER BY revision DESC
+LIMIT $2
+OFFSET $3;
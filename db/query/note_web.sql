@@ -21,4 +21,8 @@ WHERE note_id = $1 AND web_id = $2;
 
 -- name: DeleteNoteWebsByNoteId :exec
 DELETE FROM note_webs
-WHERE note_id = $1;
\ No newline at end of file
+WHERE note_id = $1;
+
+-- name: ListNoteWebsByNoteIds :many
+SELECT * FROM note_webs
+WHERE note_id = ANY(@ids::uuid[]);
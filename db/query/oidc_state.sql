@@ -0,0 +1,18 @@
+-- name: CreateOidcState :one
+INSERT INTO oidc_states (
+  state_hash,
+  nonce,
+  code_verifier,
+  user_id,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: UseOidcState :one
+-- no row is returned when the state is unknown, expired or already used
+UPDATE oidc_states
+SET used_at = now()
+WHERE state_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING *;
@@ -0,0 +1,23 @@
+-- name: CreatePasswordResetToken :one
+INSERT INTO password_reset_tokens (
+  token_hash,
+  user_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: UsePasswordResetToken :one
+-- no row is returned when the token is unknown, expired or already used
+UPDATE password_reset_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING *;
+
+-- name: InvalidatePasswordResetTokensByUserId :exec
+UPDATE password_reset_tokens
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL;
@@ -0,0 +1,38 @@
+-- name: CreatePersonalAccessToken :one
+INSERT INTO personal_access_tokens (
+  user_id,
+  name,
+  token_hash,
+  scopes,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetPersonalAccessToken :one
+SELECT * FROM personal_access_tokens
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPersonalAccessTokenByHash :one
+SELECT * FROM personal_access_tokens
+WHERE token_hash = $1 LIMIT 1;
+
+-- name: ListPersonalAccessTokensByUserId :many
+SELECT * FROM personal_access_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: TouchPersonalAccessToken :exec
+-- written at most once a minute, so that a busy script doesn't update the row on every request
+UPDATE personal_access_tokens
+SET last_used_at = now()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute');
+
+-- name: DeletePersonalAccessToken :exec
+DELETE FROM personal_access_tokens
+WHERE id = $1;
+
+-- name: DeletePersonalAccessTokensByUserId :exec
+DELETE FROM personal_access_tokens
+WHERE user_id = $1;
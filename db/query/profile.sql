@@ -0,0 +1,39 @@
+-- name: UpsertProfile :one
+INSERT INTO profiles (
+  user_id,
+  handle,
+  display_name,
+  bio
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (user_id) DO UPDATE
+SET handle = EXCLUDED.handle,
+  display_name = EXCLUDED.display_name,
+  bio = EXCLUDED.bio,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetProfile :one
+SELECT * FROM profiles
+WHERE user_id = $1 LIMIT 1;
+
+-- name: GetPublicProfileByHandle :one
+-- the profiles of blocked users aren't public
+SELECT profiles.* FROM profiles
+JOIN users ON users.id = profiles.user_id
+WHERE profiles.handle = $1 AND users.blocked_at IS NULL
+LIMIT 1;
+
+-- name: GetPublicProfile :one
+SELECT profiles.* FROM profiles
+JOIN users ON users.id = profiles.user_id
+WHERE profiles.user_id = $1 AND users.blocked_at IS NULL
+LIMIT 1;
+
+-- name: UpdateProfileAvatar :one
+UPDATE profiles
+SET avatar_key = $2,
+  updated_at = now()
+WHERE user_id = $1
+RETURNING *;
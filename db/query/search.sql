@@ -0,0 +1,62 @@
+-- name: Search :many
+-- headlines are expensive, so they are only built for the requested page
+SELECT
+  hits.type,
+  hits.id,
+  hits.title,
+  hits.url,
+  hits.is_public,
+  hits.rank,
+  hits.created_at,
+  ts_headline(
+    'simple',
+    hits.body,
+    websearch_to_tsquery('simple', sqlc.arg(query)::varchar),
+    sqlc.arg(headline_options)::varchar
+  )::varchar AS snippet
+FROM (
+  SELECT
+    'note'::varchar AS type,
+    n.id,
+    n.title,
+    ''::varchar AS url,
+    n.is_public,
+    n.content AS body,
+    ts_rank(d.document, websearch_to_tsquery('simple', sqlc.arg(query)::varchar))::real AS rank,
+    n.created_at
+  FROM notes n
+  JOIN note_search_documents d ON d.note_id = n.id
+  WHERE sqlc.arg(include_notes)::boolean
+    AND n.user_id = sqlc.arg(user_id)
+    AND d.document @@ websearch_to_tsquery('simple', sqlc.arg(query)::varchar)
+    AND (sqlc.narg(is_public)::boolean IS NULL OR n.is_public = sqlc.narg(is_public)::boolean)
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR n.created_at >= sqlc.narg(created_from)::timestamptz)
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR n.created_at < sqlc.narg(created_to)::timestamptz)
+  UNION ALL
+  SELECT
+    'web'::varchar AS type,
+    w.id,
+    w.title,
+    w.url,
+    false AS is_public,
+    CASE WHEN w.article_text = '' THEN w.title ELSE w.article_text END AS body,
+    ts_rank(d.document, websearch_to_tsquery('simple', sqlc.arg(query)::varchar))::real AS rank,
+    w.created_at
+  FROM webs w
+  JOIN web_search_documents d ON d.web_id = w.id
+  WHERE sqlc.arg(include_webs)::boolean
+    AND w.user_id = sqlc.arg(user_id)
+    AND d.document @@ websearch_to_tsquery('simple', sqlc.arg(query)::varchar)
+    AND (sqlc.narg(is_public)::boolean IS NULL OR NOT sqlc.narg(is_public)::boolean)
+    AND (sqlc.narg(created_from)::timestamptz IS NULL OR w.created_at >= sqlc.narg(created_from)::timestamptz)
+    AND (sqlc.narg(created_to)::timestamptz IS NULL OR w.created_at < sqlc.narg(created_to)::timestamptz)
+    AND (
+      sqlc.narg(domain)::varchar IS NULL
+      OR lower(substring(w.url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = lower(sqlc.narg(domain)::varchar)
+      OR lower(substring(w.url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) LIKE '%.' || lower(sqlc.narg(domain)::varchar)
+    )
+  ORDER BY rank DESC, created_at DESC
+  LIMIT sqlc.arg(page_limit)
+  OFFSET sqlc.arg(page_offset)
+) AS hits
+ORDER BY hits.rank DESC, hits.created_at DESC;
@@ -0,0 +1,99 @@
+-- name: CreateTag :one
+INSERT INTO tags (
+  user_id,
+  name
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: UpsertTag :one
+INSERT INTO tags (
+  user_id,
+  name
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: GetTag :one
+SELECT * FROM tags
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTagsByUserId :many
+SELECT * FROM tags
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: UpdateTagName :one
+UPDATE tags
+SET name = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE id = $1;
+
+-- name: CreateNoteTag :one
+INSERT INTO note_tags (
+  note_id,
+  tag_id
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: DeleteNoteTagsByNoteId :exec
+DELETE FROM note_tags
+WHERE note_id = $1;
+
+-- name: ListTagsByNoteId :many
+SELECT tags.* FROM tags
+INNER JOIN note_tags ON tags.id = note_tags.tag_id
+WHERE note_tags.note_id = $1
+ORDER BY tags.name;
+
+-- name: ListTagsByNoteIds :many
+SELECT tags.*, note_tags.note_id FROM tags
+INNER JOIN note_tags ON tags.id = note_tags.tag_id
+WHERE note_tags.note_id = ANY(@ids::uuid[])
+ORDER BY tags.name;
+
+-- name: MergeNoteTags :exec
+INSERT INTO note_tags (note_id, tag_id)
+SELECT note_id, sqlc.arg(target_id)::uuid FROM note_tags
+WHERE tag_id = sqlc.arg(source_id)::uuid
+ON CONFLICT DO NOTHING;
+
+-- name: CreateWebTag :one
+INSERT INTO web_tags (
+  web_id,
+  tag_id
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: DeleteWebTagsByWebId :exec
+DELETE FROM web_tags
+WHERE web_id = $1;
+
+-- name: ListTagsByWebId :many
+SELECT tags.* FROM tags
+INNER JOIN web_tags ON tags.id = web_tags.tag_id
+WHERE web_tags.web_id = $1
+ORDER BY tags.name;
+
+-- name: ListTagsByWebIds :many
+SELECT tags.*, web_tags.web_id FROM tags
+INNER JOIN web_tags ON tags.id = web_tags.tag_id
+WHERE web_tags.web_id = ANY(@ids::uuid[])
+ORDER BY tags.name;
+
+-- name: MergeWebTags :exec
+INSERT INTO web_tags (web_id, tag_id)
+SELECT web_id, sqlc.arg(target_id)::uuid FROM web_tags
+WHERE tag_id = sqlc.arg(source_id)::uuid
+ON CONFLICT DO NOTHING;
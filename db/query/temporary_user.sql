@@ -11,4 +11,18 @@ RETURNING *;
 
 -- name: GetTemporaryUserByEmailAndToken :one
 SELECT * FROM temporary_users
-WHERE email = $1 AND token = $2 LIMIT 1;
\ No newline at end of file
+WHERE email = $1 AND token = $2 LIMIT 1;
+
+-- name: GetLatestTemporaryUserByEmail :one
+SELECT * FROM temporary_users
+WHERE email = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: DeleteTemporaryUsersByEmail :exec
+DELETE FROM temporary_users
+WHERE email = $1;
+
+-- name: DeleteExpiredTemporaryUsers :execrows
+DELETE FROM temporary_users
+WHERE expires_at < now();
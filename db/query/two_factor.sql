@@ -0,0 +1,57 @@
+-- name: UpsertUserTwoFactor :one
+-- starts the enrollment over with a new secret, no row is returned when 2fa is already enabled
+INSERT INTO user_two_factors (
+  user_id,
+  totp_secret
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  totp_secret = EXCLUDED.totp_secret,
+  last_used_step = 0,
+  created_at = now()
+WHERE user_two_factors.enabled_at IS NULL
+RETURNING *;
+
+-- name: GetUserTwoFactor :one
+SELECT * FROM user_two_factors
+WHERE user_id = $1 LIMIT 1;
+
+-- name: EnableUserTwoFactor :one
+UPDATE user_two_factors
+SET
+  enabled_at = now(),
+  last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NULL
+RETURNING *;
+
+-- name: UseTotpStep :one
+-- no row is returned when a code of this step or a later one was already used
+UPDATE user_two_factors
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2
+RETURNING *;
+
+-- name: DeleteUserTwoFactor :exec
+DELETE FROM user_two_factors
+WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+  code_hash,
+  user_id
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE code_hash = $1 AND user_id = $2 AND used_at IS NULL
+RETURNING *;
+
+-- name: DeleteRecoveryCodesByUserId :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1;
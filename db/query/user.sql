@@ -15,12 +15,6 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM users
 WHERE email = $1 LIMIT 1;
 
--- name: ListUsers :many
-SELECT * FROM users
-ORDER BY id
-LIMIT $1
-OFFSET $2;
-
 -- name: UpdateUser :one
 UPDATE users
 SET email = $2
@@ -29,4 +23,50 @@ RETURNING *;
 
 -- name: DeleteUser :exec
 DELETE FROM users
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET
+  hashed_password = $2,
+  password_changed_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserAuthorization :one
+-- what every authenticated request is checked against, session_blocked is false when there is no such session
+-- as for personal access tokens
+SELECT u.password_changed_at, u.role, u.blocked_at, coalesce(s.is_blocked, false)::bool AS session_blocked
+FROM users u
+LEFT JOIN sessions s ON s.id = @session_id AND s.user_id = u.id
+WHERE u.id = @id LIMIT 1;
+
+-- name: SearchUsers :many
+-- email is matched as a substring, an empty one matches every user
+SELECT * FROM users
+WHERE email ILIKE '%' || @email::varchar || '%'
+ORDER BY created_at DESC, id
+LIMIT $1
+OFFSET $2;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserBlockedAt :one
+UPDATE users
+SET blocked_at = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: GetSystemStats :one
+SELECT
+  (SELECT count(*) FROM users) AS users,
+  (SELECT count(*) FROM users WHERE blocked_at IS NOT NULL) AS blocked_users,
+  (SELECT count(*) FROM temporary_users) AS pending_registrations,
+  (SELECT count(*) FROM sessions WHERE is_blocked = false AND rotated_at IS NULL AND expires_at > now()) AS active_sessions,
+  (SELECT count(*) FROM notes) AS notes,
+  (SELECT count(*) FROM webs) AS webs,
+  (SELECT count(*) FROM web_jobs) AS pending_web_jobs;
@@ -0,0 +1,27 @@
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  user_id,
+  issuer,
+  subject,
+  email
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE id = $1 LIMIT 1;
+
+-- name: GetUserIdentityBySubject :one
+SELECT * FROM user_identities
+WHERE issuer = $1 AND subject = $2 LIMIT 1;
+
+-- name: ListUserIdentitiesByUserId :many
+SELECT * FROM user_identities
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteUserIdentity :exec
+DELETE FROM user_identities
+WHERE id = $1;
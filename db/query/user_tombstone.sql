@@ -0,0 +1,12 @@
+-- name: CreateUserTombstone :one
+INSERT INTO user_tombstones (
+  email_hash
+) VALUES (
+  $1
+)
+ON CONFLICT (email_hash) DO UPDATE SET deleted_at = now()
+RETURNING *;
+
+-- name: GetUserTombstone :one
+SELECT * FROM user_tombstones
+WHERE email_hash = $1 LIMIT 1;
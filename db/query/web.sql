@@ -4,9 +4,10 @@ INSERT INTO webs (
   url,
   title,
   thumbnail_url,
-  html
+  html,
+  status
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
@@ -15,10 +16,68 @@ SELECT * FROM webs
 WHERE id = $1 LIMIT 1;
 
 -- name: ListWebsByUserId :many
+-- only webs that have every tag in tags are listed, tags is ignored when empty
 SELECT * FROM webs
-WHERE user_id = $1
-LIMIT $2
-OFFSET $3;
+WHERE webs.user_id = sqlc.arg(user_id)
+  AND (
+    coalesce(cardinality(sqlc.arg(tags)::varchar[]), 0) = 0
+    OR webs.id IN (
+      SELECT web_tags.web_id FROM web_tags
+      INNER JOIN tags ON tags.id = web_tags.tag_id
+      WHERE tags.user_id = sqlc.arg(user_id) AND tags.name = ANY(sqlc.arg(tags)::varchar[])
+      GROUP BY web_tags.web_id
+      HAVING count(*) = cardinality(sqlc.arg(tags)::varchar[])
+    )
+  )
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: UpdateWebContent :one
+UPDATE webs
+SET
+  title = $2,
+  thumbnail_url = $3,
+  html = $4,
+  article_text = $5,
+  article_html = $6,
+  article_markdown = $7,
+  author = $8,
+  site_name = $9,
+  description = $10,
+  published_at = $11,
+  reading_minutes = $12,
+  status = 'done',
+  error_reason = ''
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateWebArticle :one
+UPDATE webs
+SET
+  article_text = $2,
+  article_html = $3,
+  article_markdown = $4,
+  author = $5,
+  site_name = $6,
+  description = $7,
+  published_at = $8,
+  reading_minutes = $9
+WHERE id = $1
+RETURNING *;
+
+-- name: ListDoneWebsAfterId :many
+SELECT * FROM webs
+WHERE status = 'done' AND id > $1
+ORDER BY id
+LIMIT $2;
+
+-- name: UpdateWebStatus :one
+UPDATE webs
+SET
+  status = $2,
+  error_reason = $3
+WHERE id = $1
+RETURNING *;
 
 -- name: DeleteWeb :exec
 DELETE FROM webs
@@ -32,4 +91,10 @@ WHERE note_webs.note_id = $1;
 -- name: ListWebByNoteIds :many
 SELECT webs.*, note_webs.note_id FROM webs
 INNER JOIN note_webs ON webs.id = note_webs.web_id
-WHERE note_webs.note_id = ANY(@ids::uuid[]);
\ No newline at end of file
+WHERE note_webs.note_id = ANY(@ids::uuid[]);
+
+-- name: ListWebsByUserIdAfterId :many
+SELECT * FROM webs
+WHERE user_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3;
@@ -0,0 +1,55 @@
+-- name: CreateWebJob :one
+INSERT INTO web_jobs (
+  web_id,
+  max_attempts
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetWebJobByWebId :one
+SELECT * FROM web_jobs
+WHERE web_id = $1 LIMIT 1;
+
+-- name: ResetWebJob :one
+INSERT INTO web_jobs (
+  web_id,
+  max_attempts
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (web_id) DO UPDATE
+SET
+  attempts = 0,
+  max_attempts = EXCLUDED.max_attempts,
+  run_at = now(),
+  locked_until = NULL,
+  last_error = ''
+RETURNING *;
+
+-- name: ClaimWebJob :one
+UPDATE web_jobs
+SET
+  attempts = attempts + 1,
+  locked_until = sqlc.arg(locked_until)::timestamptz
+WHERE id = (
+  SELECT id FROM web_jobs
+  WHERE run_at <= now()
+    AND (locked_until IS NULL OR locked_until < now())
+  ORDER BY run_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RetryWebJob :exec
+UPDATE web_jobs
+SET
+  run_at = $2,
+  locked_until = NULL,
+  last_error = $3
+WHERE id = $1;
+
+-- name: DeleteWebJob :exec
+DELETE FROM web_jobs
+WHERE id = $1;
@@ -0,0 +1,185 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: device_authorization.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const approveDeviceAuthorization = `-- name: ApproveDeviceAuthorization :one
+UPDATE device_authorizations
+SET
+  status = 'approved',
+  user_id = $2
+WHERE user_code = $1 AND status = 'pending' AND expires_at > now()
+RETURNING device_code_hash, user_code, client_id, status, user_id, interval_seconds, last_polled_at, expires_at, created_at
+`
+
+type ApproveDeviceAuthorizationParams struct {
+	UserCode string        `json:"user_code"`
+	UserID   uuid.NullUUID `json:"user_id"`
+}
+
+// no row is returned when the code is unknown, expired or already answered
+func (q *Queries) ApproveDeviceAuthorization(ctx context.Context, arg ApproveDeviceAuthorizationParams) (DeviceAuthorization, error) {
+	row := q.db.QueryRowContext(ctx, approveDeviceAuthorization, arg.UserCode, arg.UserID)
+	var i DeviceAuthorization
+	err := row.Scan(
+		&i.DeviceCodeHash,
+		&i.UserCode,
+		&i.ClientID,
+		&i.Status,
+		&i.UserID,
+		&i.IntervalSeconds,
+		&i.LastPolledAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumeDeviceAuthorization = `-- name: ConsumeDeviceAuthorization :one
+UPDATE device_authorizations
+SET status = 'consumed'
+WHERE device_code_hash = $1 AND status = 'approved'
+RETURNING device_code_hash, user_code, client_id, status, user_id, interval_seconds, last_polled_at, expires_at, created_at
+`
+
+// no row is returned when the tokens were already handed out
+func (q *Queries) ConsumeDeviceAuthorization(ctx context.Context, deviceCodeHash string) (DeviceAuthorization, error) {
+	row := q.db.QueryRowContext(ctx, consumeDeviceAuthorization, deviceCodeHash)
+	var i DeviceAuthorization
+	err := row.Scan(
+		&i.DeviceCodeHash,
+		&i.UserCode,
+		&i.ClientID,
+		&i.Status,
+		&i.UserID,
+		&i.IntervalSeconds,
+		&i.LastPolledAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createDeviceAuthorization = `-- name: CreateDeviceAuthorization :one
+INSERT INTO device_authorizations (
+  device_code_hash,
+  user_code,
+  client_id,
+  interval_seconds,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING device_code_hash, user_code, client_id, status, user_id, interval_seconds, last_polled_at, expires_at, created_at
+`
+
+type CreateDeviceAuthorizationParams struct {
+	DeviceCodeHash  string    `json:"device_code_hash"`
+	UserCode        string    `json:"user_code"`
+	ClientID        string    `json:"client_id"`
+	IntervalSeconds int32     `json:"interval_seconds"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateDeviceAuthorization(ctx context.Context, arg CreateDeviceAuthorizationParams) (DeviceAuthorization, error) {
+	row := q.db.QueryRowContext(ctx, createDeviceAuthorization,
+		arg.DeviceCodeHash,
+		arg.UserCode,
+		arg.ClientID,
+		arg.IntervalSeconds,
+		arg.ExpiresAt,
+	)
+	var i DeviceAuthorization
+	err := row.Scan(
+		&i.DeviceCodeHash,
+		&i.UserCode,
+		&i.ClientID,
+		&i.Status,
+		&i.UserID,
+		&i.IntervalSeconds,
+		&i.LastPolledAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const denyDeviceAuthorization = `-- name: DenyDeviceAuthorization :one
+UPDATE device_authorizations
+SET
+  status = 'denied',
+  user_id = $2
+WHERE user_code = $1 AND status = 'pending' AND expires_at > now()
+RETURNING device_code_hash, user_code, client_id, status, user_id, interval_seconds, last_polled_at, expires_at, created_at
+`
+
+type DenyDeviceAuthorizationParams struct {
+	UserCode string        `json:"user_code"`
+	UserID   uuid.NullUUID `json:"user_id"`
+}
+
+func (q *Queries) DenyDeviceAuthorization(ctx context.Context, arg DenyDeviceAuthorizationParams) (DeviceAuthorization, error) {
+	row := q.db.QueryRowContext(ctx, denyDeviceAuthorization, arg.UserCode, arg.UserID)
+	var i DeviceAuthorization
+	err := row.Scan(
+		&i.DeviceCodeHash,
+		&i.UserCode,
+		&i.ClientID,
+		&i.Status,
+		&i.UserID,
+		&i.IntervalSeconds,
+		&i.LastPolledAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDeviceAuthorization = `-- name: GetDeviceAuthorization :one
+SELECT device_code_hash, user_code, client_id, status, user_id, interval_seconds, last_polled_at, expires_at, created_at FROM device_authorizations
+WHERE device_code_hash = $1 LIMIT 1
+`
+
+func (q *Queries) GetDeviceAuthorization(ctx context.Context, deviceCodeHash string) (DeviceAuthorization, error) {
+	row := q.db.QueryRowContext(ctx, getDeviceAuthorization, deviceCodeHash)
+	var i DeviceAuthorization
+	err := row.Scan(
+		&i.DeviceCodeHash,
+		&i.UserCode,
+		&i.ClientID,
+		&i.Status,
+		&i.UserID,
+		&i.IntervalSeconds,
+		&i.LastPolledAt,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateDeviceAuthorizationPoll = `-- name: UpdateDeviceAuthorizationPoll :exec
+UPDATE device_authorizations
+SET
+  last_polled_at = now(),
+  interval_seconds = $2
+WHERE device_code_hash = $1
+`
+
+type UpdateDeviceAuthorizationPollParams struct {
+	DeviceCodeHash  string `json:"device_code_hash"`
+	IntervalSeconds int32  `json:"interval_seconds"`
+}
+
+func (q *Queries) UpdateDeviceAuthorizationPoll(ctx context.Context, arg UpdateDeviceAuthorizationPollParams) error {
+	_, err := q.db.ExecContext(ctx, updateDeviceAuthorizationPoll, arg.DeviceCodeHash, arg.IntervalSeconds)
+	return err
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomDeviceAuthorization(t *testing.T, expiresAt time.Time) DeviceAuthorization {
+	deviceCode, err := util.NewSecretToken()
+	require.NoError(t, err)
+	userCode, err := util.NewUserCode()
+	require.NoError(t, err)
+
+	arg := CreateDeviceAuthorizationParams{
+		DeviceCodeHash:  util.HashSecretToken(deviceCode),
+		UserCode:        util.NormalizeUserCode(userCode),
+		ClientID:        "inkclip-extension",
+		IntervalSeconds: 5,
+		ExpiresAt:       expiresAt,
+	}
+	authorization, err := testQueries.CreateDeviceAuthorization(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserCode, authorization.UserCode)
+	require.Equal(t, "pending", authorization.Status)
+	require.False(t, authorization.UserID.Valid)
+	require.False(t, authorization.LastPolledAt.Valid)
+	return authorization
+}
+
+func TestApproveDeviceAuthorization(t *testing.T) {
+	user := createRandomUser(t)
+	authorization := createRandomDeviceAuthorization(t, time.Now().Add(time.Minute))
+	userID := uuid.NullUUID{UUID: user.ID, Valid: true}
+
+	approved, err := testQueries.ApproveDeviceAuthorization(context.Background(), ApproveDeviceAuthorizationParams{
+		UserCode: authorization.UserCode,
+		UserID:   userID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "approved", approved.Status)
+	require.Equal(t, userID, approved.UserID)
+
+	// answered once
+	_, err = testQueries.DenyDeviceAuthorization(context.Background(), DenyDeviceAuthorizationParams{
+		UserCode: authorization.UserCode,
+		UserID:   userID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	consumed, err := testQueries.ConsumeDeviceAuthorization(context.Background(), authorization.DeviceCodeHash)
+	require.NoError(t, err)
+	require.Equal(t, "consumed", consumed.Status)
+
+	_, err = testQueries.ConsumeDeviceAuthorization(context.Background(), authorization.DeviceCodeHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestApproveExpiredDeviceAuthorization(t *testing.T) {
+	user := createRandomUser(t)
+	authorization := createRandomDeviceAuthorization(t, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.ApproveDeviceAuthorization(context.Background(), ApproveDeviceAuthorizationParams{
+		UserCode: authorization.UserCode,
+		UserID:   uuid.NullUUID{UUID: user.ID, Valid: true},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUpdateDeviceAuthorizationPoll(t *testing.T) {
+	authorization := createRandomDeviceAuthorization(t, time.Now().Add(time.Minute))
+
+	err := testQueries.UpdateDeviceAuthorizationPoll(context.Background(), UpdateDeviceAuthorizationPollParams{
+		DeviceCodeHash:  authorization.DeviceCodeHash,
+		IntervalSeconds: 10,
+	})
+	require.NoError(t, err)
+
+	polled, err := testQueries.GetDeviceAuthorization(context.Background(), authorization.DeviceCodeHash)
+	require.NoError(t, err)
+	require.Equal(t, int32(10), polled.IntervalSeconds)
+	require.True(t, polled.LastPolledAt.Valid)
+}
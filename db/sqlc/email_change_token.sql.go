@@ -0,0 +1,86 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: email_change_token.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createEmailChangeToken = `-- name: CreateEmailChangeToken :one
+INSERT INTO email_change_tokens (
+  token_hash,
+  user_id,
+  new_email,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING token_hash, user_id, new_email, expires_at, used_at, created_at
+`
+
+type CreateEmailChangeTokenParams struct {
+	TokenHash string    `json:"token_hash"`
+	UserID    uuid.UUID `json:"user_id"`
+	NewEmail  string    `json:"new_email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateEmailChangeToken(ctx context.Context, arg CreateEmailChangeTokenParams) (EmailChangeToken, error) {
+	row := q.db.QueryRowContext(ctx, createEmailChangeToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.NewEmail,
+		arg.ExpiresAt,
+	)
+	var i EmailChangeToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.NewEmail,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const invalidateEmailChangeTokensByUserId = `-- name: InvalidateEmailChangeTokensByUserId :exec
+UPDATE email_change_tokens
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) InvalidateEmailChangeTokensByUserId(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, invalidateEmailChangeTokensByUserId, userID)
+	return err
+}
+
+const useEmailChangeToken = `-- name: UseEmailChangeToken :one
+UPDATE email_change_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING token_hash, user_id, new_email, expires_at, used_at, created_at
+`
+
+// no row is returned when the token is unknown, expired or already used
+func (q *Queries) UseEmailChangeToken(ctx context.Context, tokenHash string) (EmailChangeToken, error) {
+	row := q.db.QueryRowContext(ctx, useEmailChangeToken, tokenHash)
+	var i EmailChangeToken
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.NewEmail,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,95 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: login_challenge.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createLoginChallenge = `-- name: CreateLoginChallenge :one
+INSERT INTO login_challenges (
+  token_hash,
+  user_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING token_hash, user_id, attempts, expires_at, used_at, created_at
+`
+
+type CreateLoginChallengeParams struct {
+	TokenHash string    `json:"token_hash"`
+	UserID    uuid.UUID `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateLoginChallenge(ctx context.Context, arg CreateLoginChallengeParams) (LoginChallenge, error) {
+	row := q.db.QueryRowContext(ctx, createLoginChallenge, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	var i LoginChallenge
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const incrementLoginChallengeAttempts = `-- name: IncrementLoginChallengeAttempts :one
+UPDATE login_challenges
+SET attempts = attempts + 1
+WHERE token_hash = $1
+  AND used_at IS NULL
+  AND expires_at > now()
+  AND attempts < $2
+RETURNING token_hash, user_id, attempts, expires_at, used_at, created_at
+`
+
+type IncrementLoginChallengeAttemptsParams struct {
+	TokenHash   string `json:"token_hash"`
+	MaxAttempts int32  `json:"max_attempts"`
+}
+
+// no row is returned when the challenge is unknown, expired, already used or out of attempts
+func (q *Queries) IncrementLoginChallengeAttempts(ctx context.Context, arg IncrementLoginChallengeAttemptsParams) (LoginChallenge, error) {
+	row := q.db.QueryRowContext(ctx, incrementLoginChallengeAttempts, arg.TokenHash, arg.MaxAttempts)
+	var i LoginChallenge
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useLoginChallenge = `-- name: UseLoginChallenge :one
+UPDATE login_challenges
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL
+RETURNING token_hash, user_id, attempts, expires_at, used_at, created_at
+`
+
+func (q *Queries) UseLoginChallenge(ctx context.Context, tokenHash string) (LoginChallenge, error) {
+	row := q.db.QueryRowContext(ctx, useLoginChallenge, tokenHash)
+	var i LoginChallenge
+	err := row.Scan(
+		&i.TokenHash,
+		&i.UserID,
+		&i.Attempts,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,109 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: login_throttle.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const deleteLoginThrottle = `-- name: DeleteLoginThrottle :exec
+DELETE FROM login_throttles
+WHERE key = $1
+`
+
+func (q *Queries) DeleteLoginThrottle(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginThrottle, key)
+	return err
+}
+
+const listLoginThrottles = `-- name: ListLoginThrottles :many
+SELECT key, failures, last_failed_at, locked_until FROM login_throttles
+WHERE key = ANY($1::varchar[])
+`
+
+func (q *Queries) ListLoginThrottles(ctx context.Context, keys []string) ([]LoginThrottle, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginThrottles, pq.Array(keys))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginThrottle{}
+	for rows.Next() {
+		var i LoginThrottle
+		if err := rows.Scan(
+			&i.Key,
+			&i.Failures,
+			&i.LastFailedAt,
+			&i.LockedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockLogin = `-- name: LockLogin :exec
+UPDATE login_throttles
+SET locked_until = $2
+WHERE key = $1
+`
+
+type LockLoginParams struct {
+	Key         string       `json:"key"`
+	LockedUntil sql.NullTime `json:"locked_until"`
+}
+
+func (q *Queries) LockLogin(ctx context.Context, arg LockLoginParams) error {
+	_, err := q.db.ExecContext(ctx, lockLogin, arg.Key, arg.LockedUntil)
+	return err
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (
+  key,
+  failures,
+  last_failed_at
+) VALUES (
+  $1, 1, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+  failures = CASE
+    WHEN login_throttles.last_failed_at < $2 THEN 1
+    ELSE login_throttles.failures + 1
+  END,
+  last_failed_at = now()
+RETURNING key, failures, last_failed_at, locked_until
+`
+
+type RecordLoginFailureParams struct {
+	Key         string    `json:"key"`
+	WindowStart time.Time `json:"window_start"`
+}
+
+// the count starts over when the last failure is older than window_start
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, arg.Key, arg.WindowStart)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Key,
+		&i.Failures,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return i, err
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	key := "email:" + util.RandomEmail()
+	windowStart := time.Now().Add(-time.Hour)
+
+	for i := 1; i <= 3; i++ {
+		throttle, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			Key:         key,
+			WindowStart: windowStart,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(i), throttle.Failures)
+		require.False(t, throttle.LockedUntil.Valid)
+	}
+
+	// the failures before the window are forgotten
+	throttle, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		Key:         key,
+		WindowStart: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), throttle.Failures)
+}
+
+func TestLockLogin(t *testing.T) {
+	key := "ip:" + util.RandomString(12)
+	other := "ip:" + util.RandomString(12)
+	for _, k := range []string{key, other} {
+		_, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			Key:         k,
+			WindowStart: time.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	lockedUntil := time.Now().Add(time.Minute)
+	err := testQueries.LockLogin(context.Background(), LockLoginParams{
+		Key:         key,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+	})
+	require.NoError(t, err)
+
+	throttles, err := testQueries.ListLoginThrottles(context.Background(), []string{key, other, "ip:unknown"})
+	require.NoError(t, err)
+	require.Len(t, throttles, 2)
+	for _, throttle := range throttles {
+		if throttle.Key == key {
+			require.WithinDuration(t, lockedUntil, throttle.LockedUntil.Time, time.Second)
+		} else {
+			require.False(t, throttle.LockedUntil.Valid)
+		}
+	}
+
+	err = testQueries.DeleteLoginThrottle(context.Background(), key)
+	require.NoError(t, err)
+	throttles, err = testQueries.ListLoginThrottles(context.Background(), []string{key})
+	require.NoError(t, err)
+	require.Empty(t, throttles)
+}
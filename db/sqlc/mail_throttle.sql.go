@@ -0,0 +1,37 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: mail_throttle.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const claimMailSend = `-- name: ClaimMailSend :one
+INSERT INTO mail_throttles (
+  key,
+  sent_at
+) VALUES (
+  $1, now()
+)
+ON CONFLICT (key) DO UPDATE
+SET sent_at = now()
+WHERE mail_throttles.sent_at < $2
+RETURNING key, sent_at
+`
+
+type ClaimMailSendParams struct {
+	Key        string    `json:"key"`
+	SentBefore time.Time `json:"sent_before"`
+}
+
+// no row is returned while the last mail to the key was sent after sent_before, otherwise it is sent now
+func (q *Queries) ClaimMailSend(ctx context.Context, arg ClaimMailSendParams) (MailThrottle, error) {
+	row := q.db.QueryRowContext(ctx, claimMailSend, arg.Key, arg.SentBefore)
+	var i MailThrottle
+	err := row.Scan(&i.Key, &i.SentAt)
+	return i, err
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimMailSend(t *testing.T) {
+	key := "password_reset:" + util.RandomEmail()
+
+	throttle, err := testQueries.ClaimMailSend(context.Background(), ClaimMailSendParams{
+		Key:        key,
+		SentBefore: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, key, throttle.Key)
+	require.WithinDuration(t, time.Now(), throttle.SentAt, time.Second)
+
+	// too soon after the first one
+	_, err = testQueries.ClaimMailSend(context.Background(), ClaimMailSendParams{
+		Key:        key,
+		SentBefore: time.Now().Add(-time.Minute),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	again, err := testQueries.ClaimMailSend(context.Background(), ClaimMailSendParams{
+		Key:        key,
+		SentBefore: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.True(t, again.SentAt.After(throttle.SentAt))
+}
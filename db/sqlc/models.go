@@ -5,11 +5,54 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type DeviceAuthorization struct {
+	DeviceCodeHash  string        `json:"device_code_hash"`
+	UserCode        string        `json:"user_code"`
+	ClientID        string        `json:"client_id"`
+	Status          string        `json:"status"`
+	UserID          uuid.NullUUID `json:"user_id"`
+	IntervalSeconds int32         `json:"interval_seconds"`
+	LastPolledAt    sql.NullTime  `json:"last_polled_at"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
+
+type EmailChangeToken struct {
+	TokenHash string       `json:"token_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	NewEmail  string       `json:"new_email"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type LoginChallenge struct {
+	TokenHash string       `json:"token_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Attempts  int32        `json:"attempts"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type LoginThrottle struct {
+	Key          string       `json:"key"`
+	Failures     int32        `json:"failures"`
+	LastFailedAt time.Time    `json:"last_failed_at"`
+	LockedUntil  sql.NullTime `json:"locked_until"`
+}
+
+type MailThrottle struct {
+	Key    string    `json:"key"`
+	SentAt time.Time `json:"sent_at"`
+}
+
 type Note struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"user_id"`
@@ -17,6 +60,30 @@ type Note struct {
 	Content   string    `json:"content"`
 	IsPublic  bool      `json:"is_public"`
 	CreatedAt time.Time `json:"created_at"`
+	Version   int32     `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NoteRevision struct {
+	ID        int64       `json:"id"`
+	NoteID    uuid.UUID   `json:"note_id"`
+	Revision  int32       `json:"revision"`
+	AuthorID  uuid.UUID   `json:"author_id"`
+	Title     string      `json:"title"`
+	Content   string      `json:"content"`
+	IsPublic  bool        `json:"is_public"`
+	WebIds    []uuid.UUID `json:"web_ids"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type NoteSearchDocument struct {
+	NoteID   uuid.UUID   `json:"note_id"`
+	Document interface{} `json:"document"`
+}
+
+type NoteTag struct {
+	NoteID uuid.UUID `json:"note_id"`
+	TagID  uuid.UUID `json:"tag_id"`
 }
 
 type NoteWeb struct {
@@ -24,15 +91,74 @@ type NoteWeb struct {
 	WebID  uuid.UUID `json:"web_id"`
 }
 
+type OidcState struct {
+	StateHash    string        `json:"state_hash"`
+	Nonce        string        `json:"nonce"`
+	CodeVerifier string        `json:"code_verifier"`
+	UserID       uuid.NullUUID `json:"user_id"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	UsedAt       sql.NullTime  `json:"used_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	TokenHash string       `json:"token_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Name       string       `json:"name"`
+	TokenHash  string       `json:"token_hash"`
+	Scopes     []string     `json:"scopes"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type Profile struct {
+	UserID               uuid.UUID `json:"user_id"`
+	Handle               string    `json:"handle"`
+	DisplayName          string    `json:"display_name"`
+	Bio                  string    `json:"bio"`
+	AvatarKey            string    `json:"avatar_key"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	PublicNotesRemovedAt time.Time `json:"public_notes_removed_at"`
+}
+
+type RecoveryCode struct {
+	CodeHash  string       `json:"code_hash"`
+	UserID    uuid.UUID    `json:"user_id"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBlocked    bool      `json:"is_blocked"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID     `json:"id"`
+	UserID       uuid.UUID     `json:"user_id"`
+	RefreshToken string        `json:"refresh_token"`
+	UserAgent    string        `json:"user_agent"`
+	ClientIp     string        `json:"client_ip"`
+	IsBlocked    bool          `json:"is_blocked"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+	FamilyID     uuid.UUID     `json:"family_id"`
+	ParentID     uuid.NullUUID `json:"parent_id"`
+	RotatedAt    sql.NullTime  `json:"rotated_at"`
+}
+
+type Tag struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	NoteCount int32     `json:"note_count"`
+	WebCount  int32     `json:"web_count"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type TemporaryUser struct {
@@ -44,19 +170,74 @@ type TemporaryUser struct {
 }
 
 type User struct {
-	ID                uuid.UUID `json:"id"`
-	Email             string    `json:"email"`
-	HashedPassword    string    `json:"hashed_password"`
-	PasswordChangedAt time.Time `json:"password_changed_at"`
-	CreatedAt         time.Time `json:"created_at"`
+	ID                uuid.UUID    `json:"id"`
+	Email             string       `json:"email"`
+	HashedPassword    string       `json:"hashed_password"`
+	PasswordChangedAt time.Time    `json:"password_changed_at"`
+	CreatedAt         time.Time    `json:"created_at"`
+	Role              string       `json:"role"`
+	BlockedAt         sql.NullTime `json:"blocked_at"`
+}
+
+type UserIdentity struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserTombstone struct {
+	EmailHash string    `json:"email_hash"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type UserTwoFactor struct {
+	UserID       uuid.UUID    `json:"user_id"`
+	TotpSecret   string       `json:"totp_secret"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
 }
 
 type Web struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	Url          string    `json:"url"`
-	Title        string    `json:"title"`
-	ThumbnailUrl string    `json:"thumbnail_url"`
-	Html         string    `json:"html"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID              uuid.UUID    `json:"id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	Url             string       `json:"url"`
+	Title           string       `json:"title"`
+	ThumbnailUrl    string       `json:"thumbnail_url"`
+	Html            string       `json:"html"`
+	CreatedAt       time.Time    `json:"created_at"`
+	Status          string       `json:"status"`
+	ErrorReason     string       `json:"error_reason"`
+	ArticleText     string       `json:"article_text"`
+	ArticleHtml     string       `json:"article_html"`
+	ArticleMarkdown string       `json:"article_markdown"`
+	Author          string       `json:"author"`
+	SiteName        string       `json:"site_name"`
+	Description     string       `json:"description"`
+	PublishedAt     sql.NullTime `json:"published_at"`
+	ReadingMinutes  int32        `json:"reading_minutes"`
+}
+
+type WebJob struct {
+	ID          int64        `json:"id"`
+	WebID       uuid.UUID    `json:"web_id"`
+	Attempts    int32        `json:"attempts"`
+	MaxAttempts int32        `json:"max_attempts"`
+	RunAt       time.Time    `json:"run_at"`
+	LockedUntil sql.NullTime `json:"locked_until"`
+	LastError   string       `json:"last_error"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type WebSearchDocument struct {
+	WebID    uuid.UUID   `json:"web_id"`
+	Document interface{} `json:"document"`
+}
+
+type WebTag struct {
+	WebID uuid.UUID `json:"web_id"`
+	TagID uuid.UUID `json:"tag_id"`
 }
@@ -7,8 +7,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createNote = `-- name: CreateNote :one
@@ -20,7 +23,7 @@ INSERT INTO notes (
 ) VALUES (
   $1, $2, $3, $4
 )
-RETURNING id, user_id, title, content, is_public, created_at
+RETURNING id, user_id, title, content, is_public, created_at, version, updated_at
 `
 
 type CreateNoteParams struct {
@@ -45,6 +48,8 @@ func (q *Queries) CreateNote(ctx context.Context, arg CreateNoteParams) (Note, e
 		&i.Content,
 		&i.IsPublic,
 		&i.CreatedAt,
+		&i.Version,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -60,7 +65,7 @@ func (q *Queries) DeleteNote(ctx context.Context, id uuid.UUID) error {
 }
 
 const getNote = `-- name: GetNote :one
-SELECT id, user_id, title, content, is_public, created_at FROM notes
+SELECT id, user_id, title, content, is_public, created_at, version, updated_at FROM notes
 WHERE id = $1 LIMIT 1
 `
 
@@ -74,25 +79,65 @@ func (q *Queries) GetNote(ctx context.Context, id uuid.UUID) (Note, error) {
 		&i.Content,
 		&i.IsPublic,
 		&i.CreatedAt,
+		&i.Version,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getPublicNotesState = `-- name: GetPublicNotesState :one
+SELECT
+  count(*) AS count,
+  coalesce(max(updated_at), 'epoch'::timestamptz)::timestamptz AS last_updated_at
+FROM notes
+WHERE user_id = $1 AND is_public = true
+`
+
+type GetPublicNotesStateRow struct {
+	Count         int64     `json:"count"`
+	LastUpdatedAt time.Time `json:"last_updated_at"`
+}
+
+// changes whenever a public note is added or updated, profiles.public_notes_removed_at covers the ones made private or deleted
+func (q *Queries) GetPublicNotesState(ctx context.Context, userID uuid.UUID) (GetPublicNotesStateRow, error) {
+	row := q.db.QueryRowContext(ctx, getPublicNotesState, userID)
+	var i GetPublicNotesStateRow
+	err := row.Scan(&i.Count, &i.LastUpdatedAt)
+	return i, err
+}
+
 const listNotesByUserId = `-- name: ListNotesByUserId :many
-SELECT id, user_id, title, content, is_public, created_at FROM notes
-WHERE user_id = $1
-LIMIT $2
+SELECT id, user_id, title, content, is_public, created_at, version, updated_at FROM notes
+WHERE notes.user_id = $1
+  AND (
+    coalesce(cardinality($2::varchar[]), 0) = 0
+    OR notes.id IN (
+      SELECT note_tags.note_id FROM note_tags
+      INNER JOIN tags ON tags.id = note_tags.tag_id
+      WHERE tags.user_id = $1 AND tags.name = ANY($2::varchar[])
+      GROUP BY note_tags.note_id
+      HAVING count(*) = cardinality($2::varchar[])
+    )
+  )
+LIMIT $4
 OFFSET $3
 `
 
 type ListNotesByUserIdParams struct {
 	UserID uuid.UUID `json:"user_id"`
-	Limit  int32     `json:"limit"`
+	Tags   []string  `json:"tags"`
 	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
 }
 
+// only notes that have every tag in tags are listed, tags is ignored when empty
 func (q *Queries) ListNotesByUserId(ctx context.Context, arg ListNotesByUserIdParams) ([]Note, error) {
-	rows, err := q.db.QueryContext(ctx, listNotesByUserId, arg.UserID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listNotesByUserId,
+		arg.UserID,
+		pq.Array(arg.Tags),
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +152,99 @@ func (q *Queries) ListNotesByUserId(ctx context.Context, arg ListNotesByUserIdPa
 			&i.Content,
 			&i.IsPublic,
 			&i.CreatedAt,
+			&i.Version,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNotesByUserIdAfterId = `-- name: ListNotesByUserIdAfterId :many
+SELECT id, user_id, title, content, is_public, created_at, version, updated_at FROM notes
+WHERE user_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListNotesByUserIdAfterIdParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	ID     uuid.UUID `json:"id"`
+	Limit  int32     `json:"limit"`
+}
+
+func (q *Queries) ListNotesByUserIdAfterId(ctx context.Context, arg ListNotesByUserIdAfterIdParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listNotesByUserIdAfterId, arg.UserID, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.IsPublic,
+			&i.CreatedAt,
+			&i.Version,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublicNotesByUserId = `-- name: ListPublicNotesByUserId :many
+SELECT id, user_id, title, content, is_public, created_at, version, updated_at FROM notes
+WHERE user_id = $1 AND is_public = true
+ORDER BY updated_at DESC, id
+LIMIT $2
+OFFSET $3
+`
+
+type ListPublicNotesByUserIdParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) ListPublicNotesByUserId(ctx context.Context, arg ListPublicNotesByUserIdParams) ([]Note, error) {
+	rows, err := q.db.QueryContext(ctx, listPublicNotesByUserId, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Note{}
+	for rows.Next() {
+		var i Note
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Content,
+			&i.IsPublic,
+			&i.CreatedAt,
+			&i.Version,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -124,26 +262,32 @@ func (q *Queries) ListNotesByUserId(ctx context.Context, arg ListNotesByUserIdPa
 const updateNote = `-- name: UpdateNote :one
 UPDATE notes
 SET
-  title = $2,
-  content = $3,
-  is_public = $4
-WHERE id = $1
-RETURNING id, user_id, title, content, is_public, created_at
+  title = $1,
+  content = $2,
+  is_public = $3,
+  version = version + 1,
+  updated_at = now()
+WHERE id = $4
+  AND ($5::integer IS NULL OR version = $5::integer)
+RETURNING id, user_id, title, content, is_public, created_at, version, updated_at
 `
 
 type UpdateNoteParams struct {
-	ID       uuid.UUID `json:"id"`
-	Title    string    `json:"title"`
-	Content  string    `json:"content"`
-	IsPublic bool      `json:"is_public"`
+	Title           string        `json:"title"`
+	Content         string        `json:"content"`
+	IsPublic        bool          `json:"is_public"`
+	ID              uuid.UUID     `json:"id"`
+	ExpectedVersion sql.NullInt32 `json:"expected_version"`
 }
 
+// no row is updated when expected_version is given and the note has moved past it
 func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error) {
 	row := q.db.QueryRowContext(ctx, updateNote,
-		arg.ID,
 		arg.Title,
 		arg.Content,
 		arg.IsPublic,
+		arg.ID,
+		arg.ExpectedVersion,
 	)
 	var i Note
 	err := row.Scan(
@@ -153,6 +297,8 @@ func (q *Queries) UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, e
 		&i.Content,
 		&i.IsPublic,
 		&i.CreatedAt,
+		&i.Version,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
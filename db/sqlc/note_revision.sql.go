@@ -0,0 +1,144 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: note_revision.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createNoteRevision = `-- name: CreateNoteRevision :one
+INSERT INTO note_revisions (
+  note_id,
+  revision,
+  author_id,
+  title,
+  content,
+  is_public,
+  web_ids
+)
+SELECT
+  $1,
+  coalesce(max(revision), 0) + 1,
+  $2,
+  $3,
+  $4,
+  $5,
+  coalesce($6::uuid[], '{}')
+FROM note_revisions
+WHERE note_id = $1
+RETURNING id, note_id, revision, author_id, title, content, is_public, web_ids, created_at
+`
+
+type CreateNoteRevisionParams struct {
+	NoteID   uuid.UUID   `json:"note_id"`
+	AuthorID uuid.UUID   `json:"author_id"`
+	Title    string      `json:"title"`
+	Content  string      `json:"content"`
+	IsPublic bool        `json:"is_public"`
+	WebIds   []uuid.UUID `json:"web_ids"`
+}
+
+// revisions are numbered per note, callers hold the lock on the note row
+func (q *Queries) CreateNoteRevision(ctx context.Context, arg CreateNoteRevisionParams) (NoteRevision, error) {
+	row := q.db.QueryRowContext(ctx, createNoteRevision,
+		arg.NoteID,
+		arg.AuthorID,
+		arg.Title,
+		arg.Content,
+		arg.IsPublic,
+		pq.Array(arg.WebIds),
+	)
+	var i NoteRevision
+	err := row.Scan(
+		&i.ID,
+		&i.NoteID,
+		&i.Revision,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.IsPublic,
+		pq.Array(&i.WebIds),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getNoteRevision = `-- name: GetNoteRevision :one
+SELECT id, note_id, revision, author_id, title, content, is_public, web_ids, created_at FROM note_revisions
+WHERE note_id = $1 AND revision = $2 LIMIT 1
+`
+
+type GetNoteRevisionParams struct {
+	NoteID   uuid.UUID `json:"note_id"`
+	Revision int32     `json:"revision"`
+}
+
+func (q *Queries) GetNoteRevision(ctx context.Context, arg GetNoteRevisionParams) (NoteRevision, error) {
+	row := q.db.QueryRowContext(ctx, getNoteRevision, arg.NoteID, arg.Revision)
+	var i NoteRevision
+	err := row.Scan(
+		&i.ID,
+		&i.NoteID,
+		&i.Revision,
+		&i.AuthorID,
+		&i.Title,
+		&i.Content,
+		&i.IsPublic,
+		pq.Array(&i.WebIds),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listNoteRevisions = `-- name: ListNoteRevisions :many
+SELECT id, note_id, revision, author_id, title, content, is_public, web_ids, created_at FROM note_revisions
+WHERE note_id = $1
+ORDER BY revision DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListNoteRevisionsParams struct {
+	NoteID uuid.UUID `json:"note_id"`
+	Limit  int32     `json:"limit"`
+	Offset int32     `json:"offset"`
+}
+
+func (q *Queries) ListNoteRevisions(ctx context.Context, arg ListNoteRevisionsParams) ([]NoteRevision, error) {
+	rows, err := q.db.QueryContext(ctx, listNoteRevisions, arg.NoteID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NoteRevision{}
+	for rows.Next() {
+		var i NoteRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.NoteID,
+			&i.Revision,
+			&i.AuthorID,
+			&i.Title,
+			&i.Content,
+			&i.IsPublic,
+			pq.Array(&i.WebIds),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
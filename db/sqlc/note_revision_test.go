@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomNoteRevision(t *testing.T, note Note, webIDs []uuid.UUID) NoteRevision {
+	arg := CreateNoteRevisionParams{
+		NoteID:   note.ID,
+		AuthorID: note.UserID,
+		Title:    util.RandomString(6),
+		Content:  util.RandomString(20),
+		IsPublic: note.IsPublic,
+		WebIds:   webIDs,
+	}
+	revision, err := testQueries.CreateNoteRevision(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.NoteID, revision.NoteID)
+	require.Equal(t, arg.AuthorID, revision.AuthorID)
+	require.Equal(t, arg.Title, revision.Title)
+	require.Equal(t, arg.Content, revision.Content)
+	require.Equal(t, arg.IsPublic, revision.IsPublic)
+	require.NotZero(t, revision.CreatedAt)
+	return revision
+}
+
+func TestCreateNoteRevision(t *testing.T) {
+	user := createRandomUser(t)
+	note := createRandomNote(t, user)
+	web := createRandomWeb(t, user)
+
+	revision1 := createRandomNoteRevision(t, note, nil)
+	require.Equal(t, int32(1), revision1.Revision)
+	require.Empty(t, revision1.WebIds)
+
+	revision2 := createRandomNoteRevision(t, note, []uuid.UUID{web.ID})
+	require.Equal(t, int32(2), revision2.Revision)
+	require.Equal(t, []uuid.UUID{web.ID}, revision2.WebIds)
+
+	// numbering is per note
+	other := createRandomNote(t, user)
+	require.Equal(t, int32(1), createRandomNoteRevision(t, other, nil).Revision)
+}
+
+func TestGetNoteRevision(t *testing.T) {
+	user := createRandomUser(t)
+	note := createRandomNote(t, user)
+	revision1 := createRandomNoteRevision(t, note, nil)
+
+	revision2, err := testQueries.GetNoteRevision(context.Background(), GetNoteRevisionParams{
+		NoteID:   note.ID,
+		Revision: revision1.Revision,
+	})
+	require.NoError(t, err)
+	require.Equal(t, revision1, revision2)
+
+	_, err = testQueries.GetNoteRevision(context.Background(), GetNoteRevisionParams{
+		NoteID:   note.ID,
+		Revision: revision1.Revision + 1,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListNoteRevisions(t *testing.T) {
+	user := createRandomUser(t)
+	note := createRandomNote(t, user)
+	for i := 0; i < 6; i++ {
+		createRandomNoteRevision(t, note, nil)
+	}
+
+	revisions, err := testQueries.ListNoteRevisions(context.Background(), ListNoteRevisionsParams{
+		NoteID: note.ID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, revisions, 5)
+	for i, revision := range revisions {
+		require.Equal(t, int32(6-i), revision.Revision)
+	}
+}
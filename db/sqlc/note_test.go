@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/inkclip/backend/util"
 	"github.com/stretchr/testify/require"
@@ -105,3 +106,50 @@ func createRandomNote(t *testing.T, user User) Note {
 
 	return note
 }
+
+func TestListPublicNotesByUserId(t *testing.T) {
+	user := createRandomUser(t)
+
+	state, err := testQueries.GetPublicNotesState(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Zero(t, state.Count)
+
+	createRandomNote(t, user)
+	var public []Note
+	for i := 0; i < 3; i++ {
+		note, err := testQueries.CreateNote(context.Background(), CreateNoteParams{
+			UserID:   user.ID,
+			Title:    util.RandomString(6),
+			Content:  util.RandomString(6),
+			IsPublic: true,
+		})
+		require.NoError(t, err)
+		public = append(public, note)
+	}
+
+	// updating the first one moves it to the top
+	updated, err := testQueries.UpdateNote(context.Background(), UpdateNoteParams{
+		ID:       public[0].ID,
+		Title:    public[0].Title,
+		Content:  util.RandomString(6),
+		IsPublic: true,
+	})
+	require.NoError(t, err)
+
+	notes, err := testQueries.ListPublicNotesByUserId(context.Background(), ListPublicNotesByUserIdParams{
+		UserID: user.ID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+	require.Equal(t, updated.ID, notes[0].ID)
+	for _, note := range notes {
+		require.True(t, note.IsPublic)
+	}
+
+	state, err = testQueries.GetPublicNotesState(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), state.Count)
+	require.WithinDuration(t, updated.UpdatedAt, state.LastUpdatedAt, time.Millisecond)
+}
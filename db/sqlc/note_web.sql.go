@@ -9,6 +9,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createNoteWeb = `-- name: CreateNoteWeb :one
@@ -102,3 +103,31 @@ func (q *Queries) ListNoteWebsByNoteId(ctx context.Context, noteID uuid.UUID) ([
 	}
 	return items, nil
 }
+
+const listNoteWebsByNoteIds = `-- name: ListNoteWebsByNoteIds :many
+SELECT note_id, web_id FROM note_webs
+WHERE note_id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListNoteWebsByNoteIds(ctx context.Context, ids []uuid.UUID) ([]NoteWeb, error) {
+	rows, err := q.db.QueryContext(ctx, listNoteWebsByNoteIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []NoteWeb{}
+	for rows.Next() {
+		var i NoteWeb
+		if err := rows.Scan(&i.NoteID, &i.WebID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
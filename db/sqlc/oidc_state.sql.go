@@ -0,0 +1,78 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: oidc_state.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createOidcState = `-- name: CreateOidcState :one
+INSERT INTO oidc_states (
+  state_hash,
+  nonce,
+  code_verifier,
+  user_id,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING state_hash, nonce, code_verifier, user_id, expires_at, used_at, created_at
+`
+
+type CreateOidcStateParams struct {
+	StateHash    string        `json:"state_hash"`
+	Nonce        string        `json:"nonce"`
+	CodeVerifier string        `json:"code_verifier"`
+	UserID       uuid.NullUUID `json:"user_id"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+}
+
+func (q *Queries) CreateOidcState(ctx context.Context, arg CreateOidcStateParams) (OidcState, error) {
+	row := q.db.QueryRowContext(ctx, createOidcState,
+		arg.StateHash,
+		arg.Nonce,
+		arg.CodeVerifier,
+		arg.UserID,
+		arg.ExpiresAt,
+	)
+	var i OidcState
+	err := row.Scan(
+		&i.StateHash,
+		&i.Nonce,
+		&i.CodeVerifier,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useOidcState = `-- name: UseOidcState :one
+UPDATE oidc_states
+SET used_at = now()
+WHERE state_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING state_hash, nonce, code_verifier, user_id, expires_at, used_at, created_at
+`
+
+// no row is returned when the state is unknown, expired or already used
+func (q *Queries) UseOidcState(ctx context.Context, stateHash string) (OidcState, error) {
+	row := q.db.QueryRowContext(ctx, useOidcState, stateHash)
+	var i OidcState
+	err := row.Scan(
+		&i.StateHash,
+		&i.Nonce,
+		&i.CodeVerifier,
+		&i.UserID,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPersonalAccessToken(t *testing.T, user User) PersonalAccessToken {
+	token, err := util.NewSecretToken()
+	require.NoError(t, err)
+
+	arg := CreatePersonalAccessTokenParams{
+		UserID:    user.ID,
+		Name:      util.RandomString(8),
+		TokenHash: util.HashSecretToken(token),
+		Scopes:    []string{"webs:read", "webs:write"},
+	}
+	pat, err := testQueries.CreatePersonalAccessToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, pat.Name)
+	require.Equal(t, arg.TokenHash, pat.TokenHash)
+	require.Equal(t, arg.Scopes, pat.Scopes)
+	require.False(t, pat.LastUsedAt.Valid)
+	require.False(t, pat.ExpiresAt.Valid)
+	return pat
+}
+
+func TestGetPersonalAccessTokenByHash(t *testing.T) {
+	pat := createRandomPersonalAccessToken(t, createRandomUser(t))
+
+	got, err := testQueries.GetPersonalAccessTokenByHash(context.Background(), pat.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, pat.ID, got.ID)
+	require.Equal(t, pat.Scopes, got.Scopes)
+}
+
+func TestListPersonalAccessTokensByUserId(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomPersonalAccessToken(t, createRandomUser(t))
+	for i := 0; i < 3; i++ {
+		createRandomPersonalAccessToken(t, user)
+	}
+
+	pats, err := testQueries.ListPersonalAccessTokensByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, pats, 3)
+	for _, pat := range pats {
+		require.Equal(t, user.ID, pat.UserID)
+	}
+}
+
+func TestTouchPersonalAccessToken(t *testing.T) {
+	pat := createRandomPersonalAccessToken(t, createRandomUser(t))
+
+	err := testQueries.TouchPersonalAccessToken(context.Background(), pat.ID)
+	require.NoError(t, err)
+
+	touched, err := testQueries.GetPersonalAccessToken(context.Background(), pat.ID)
+	require.NoError(t, err)
+	require.True(t, touched.LastUsedAt.Valid)
+	require.WithinDuration(t, time.Now(), touched.LastUsedAt.Time, time.Second)
+
+	// within a minute the row isn't written again
+	err = testQueries.TouchPersonalAccessToken(context.Background(), pat.ID)
+	require.NoError(t, err)
+
+	got, err := testQueries.GetPersonalAccessToken(context.Background(), pat.ID)
+	require.NoError(t, err)
+	require.Equal(t, touched.LastUsedAt.Time, got.LastUsedAt.Time)
+}
+
+func TestDeletePersonalAccessToken(t *testing.T) {
+	pat := createRandomPersonalAccessToken(t, createRandomUser(t))
+
+	err := testQueries.DeletePersonalAccessToken(context.Background(), pat.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetPersonalAccessTokenByHash(context.Background(), pat.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
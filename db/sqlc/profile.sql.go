@@ -0,0 +1,154 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: profile.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const getProfile = `-- name: GetProfile :one
+SELECT user_id, handle, display_name, bio, avatar_key, created_at, updated_at, public_notes_removed_at FROM profiles
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetProfile(ctx context.Context, userID uuid.UUID) (Profile, error) {
+	row := q.db.QueryRowContext(ctx, getProfile, userID)
+	var i Profile
+	err := row.Scan(
+		&i.UserID,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublicNotesRemovedAt,
+	)
+	return i, err
+}
+
+const getPublicProfile = `-- name: GetPublicProfile :one
+SELECT profiles.user_id, profiles.handle, profiles.display_name, profiles.bio, profiles.avatar_key, profiles.created_at, profiles.updated_at, profiles.public_notes_removed_at FROM profiles
+JOIN users ON users.id = profiles.user_id
+WHERE profiles.user_id = $1 AND users.blocked_at IS NULL
+LIMIT 1
+`
+
+func (q *Queries) GetPublicProfile(ctx context.Context, userID uuid.UUID) (Profile, error) {
+	row := q.db.QueryRowContext(ctx, getPublicProfile, userID)
+	var i Profile
+	err := row.Scan(
+		&i.UserID,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublicNotesRemovedAt,
+	)
+	return i, err
+}
+
+const getPublicProfileByHandle = `-- name: GetPublicProfileByHandle :one
+SELECT profiles.user_id, profiles.handle, profiles.display_name, profiles.bio, profiles.avatar_key, profiles.created_at, profiles.updated_at, profiles.public_notes_removed_at FROM profiles
+JOIN users ON users.id = profiles.user_id
+WHERE profiles.handle = $1 AND users.blocked_at IS NULL
+LIMIT 1
+`
+
+// the profiles of blocked users aren't public
+func (q *Queries) GetPublicProfileByHandle(ctx context.Context, handle string) (Profile, error) {
+	row := q.db.QueryRowContext(ctx, getPublicProfileByHandle, handle)
+	var i Profile
+	err := row.Scan(
+		&i.UserID,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublicNotesRemovedAt,
+	)
+	return i, err
+}
+
+const updateProfileAvatar = `-- name: UpdateProfileAvatar :one
+UPDATE profiles
+SET avatar_key = $2,
+  updated_at = now()
+WHERE user_id = $1
+RETURNING user_id, handle, display_name, bio, avatar_key, created_at, updated_at, public_notes_removed_at
+`
+
+type UpdateProfileAvatarParams struct {
+	UserID    uuid.UUID `json:"user_id"`
+	AvatarKey string    `json:"avatar_key"`
+}
+
+func (q *Queries) UpdateProfileAvatar(ctx context.Context, arg UpdateProfileAvatarParams) (Profile, error) {
+	row := q.db.QueryRowContext(ctx, updateProfileAvatar, arg.UserID, arg.AvatarKey)
+	var i Profile
+	err := row.Scan(
+		&i.UserID,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublicNotesRemovedAt,
+	)
+	return i, err
+}
+
+const upsertProfile = `-- name: UpsertProfile :one
+INSERT INTO profiles (
+  user_id,
+  handle,
+  display_name,
+  bio
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (user_id) DO UPDATE
+SET handle = EXCLUDED.handle,
+  display_name = EXCLUDED.display_name,
+  bio = EXCLUDED.bio,
+  updated_at = now()
+RETURNING user_id, handle, display_name, bio, avatar_key, created_at, updated_at, public_notes_removed_at
+`
+
+type UpsertProfileParams struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Handle      string    `json:"handle"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+}
+
+func (q *Queries) UpsertProfile(ctx context.Context, arg UpsertProfileParams) (Profile, error) {
+	row := q.db.QueryRowContext(ctx, upsertProfile,
+		arg.UserID,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+	)
+	var i Profile
+	err := row.Scan(
+		&i.UserID,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarKey,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PublicNotesRemovedAt,
+	)
+	return i, err
+}
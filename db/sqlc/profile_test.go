@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomProfile(t *testing.T, user User) Profile {
+	arg := UpsertProfileParams{
+		UserID:      user.ID,
+		Handle:      strings.ToLower(util.RandomString(12)),
+		DisplayName: util.RandomName(),
+		Bio:         util.RandomString(20),
+	}
+
+	profile, err := testQueries.UpsertProfile(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, profile.UserID)
+	require.Equal(t, arg.Handle, profile.Handle)
+	require.Equal(t, arg.DisplayName, profile.DisplayName)
+	require.Equal(t, arg.Bio, profile.Bio)
+	require.Empty(t, profile.AvatarKey)
+	return profile
+}
+
+func TestUpsertProfile(t *testing.T) {
+	user := createRandomUser(t)
+	profile1 := createRandomProfile(t, user)
+
+	_, err := testQueries.UpdateProfileAvatar(context.Background(), UpdateProfileAvatarParams{
+		UserID:    user.ID,
+		AvatarKey: "avatars/a.png",
+	})
+	require.NoError(t, err)
+
+	profile2, err := testQueries.UpsertProfile(context.Background(), UpsertProfileParams{
+		UserID: user.ID,
+		Handle: profile1.Handle + "_2",
+	})
+	require.NoError(t, err)
+	require.Equal(t, profile1.Handle+"_2", profile2.Handle)
+	require.Empty(t, profile2.DisplayName)
+	require.Equal(t, "avatars/a.png", profile2.AvatarKey)
+	require.WithinDuration(t, profile1.CreatedAt, profile2.CreatedAt, time.Second)
+
+	other := createRandomUser(t)
+	_, err = testQueries.UpsertProfile(context.Background(), UpsertProfileParams{
+		UserID: other.ID,
+		Handle: profile2.Handle,
+	})
+	require.Error(t, err)
+	require.Equal(t, "unique_violation", err.(*pq.Error).Code.Name())
+}
+
+func TestGetPublicProfileByHandle(t *testing.T) {
+	user := createRandomUser(t)
+	profile := createRandomProfile(t, user)
+
+	got, err := testQueries.GetPublicProfileByHandle(context.Background(), profile.Handle)
+	require.NoError(t, err)
+	require.Equal(t, profile.UserID, got.UserID)
+
+	got, err = testQueries.GetPublicProfile(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, profile.Handle, got.Handle)
+
+	_, err = testQueries.UpdateUserBlockedAt(context.Background(), UpdateUserBlockedAtParams{
+		ID:        user.ID,
+		BlockedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetPublicProfileByHandle(context.Background(), profile.Handle)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.GetPublicProfile(context.Background(), user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err = testQueries.GetProfile(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, profile.Handle, got.Handle)
+}
+
+func TestProfilePublicNotesRemovedAt(t *testing.T) {
+	user := createRandomUser(t)
+	profile := createRandomProfile(t, user)
+	require.True(t, profile.PublicNotesRemovedAt.Equal(time.Unix(0, 0)))
+
+	note, err := testQueries.CreateNote(context.Background(), CreateNoteParams{
+		UserID:   user.ID,
+		Title:    util.RandomString(6),
+		Content:  util.RandomString(6),
+		IsPublic: true,
+	})
+	require.NoError(t, err)
+
+	// an update that keeps the note public isn't a removal
+	_, err = testQueries.UpdateNote(context.Background(), UpdateNoteParams{
+		ID:       note.ID,
+		Title:    note.Title,
+		Content:  util.RandomString(6),
+		IsPublic: true,
+	})
+	require.NoError(t, err)
+	got, err := testQueries.GetPublicProfile(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, got.PublicNotesRemovedAt.Equal(time.Unix(0, 0)))
+
+	_, err = testQueries.UpdateNote(context.Background(), UpdateNoteParams{
+		ID:       note.ID,
+		Title:    note.Title,
+		Content:  note.Content,
+		IsPublic: false,
+	})
+	require.NoError(t, err)
+	got, err = testQueries.GetPublicProfile(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now(), got.PublicNotesRemovedAt, time.Second)
+	madePrivateAt := got.PublicNotesRemovedAt
+
+	// deleting a private note doesn't change what others see
+	err = testQueries.DeleteNote(context.Background(), note.ID)
+	require.NoError(t, err)
+	got, err = testQueries.GetPublicProfile(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, got.PublicNotesRemovedAt.Equal(madePrivateAt))
+
+	public := createRandomNote(t, user)
+	_, err = testQueries.UpdateNote(context.Background(), UpdateNoteParams{
+		ID:       public.ID,
+		Title:    public.Title,
+		Content:  public.Content,
+		IsPublic: true,
+	})
+	require.NoError(t, err)
+	err = testQueries.DeleteNote(context.Background(), public.ID)
+	require.NoError(t, err)
+	got, err = testQueries.GetPublicProfile(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, got.PublicNotesRemovedAt.After(madePrivateAt))
+}
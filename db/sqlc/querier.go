@@ -6,37 +6,157 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// no row is returned when the code is unknown, expired or already answered
+	ApproveDeviceAuthorization(ctx context.Context, arg ApproveDeviceAuthorizationParams) (DeviceAuthorization, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) error
+	BlockSessionsByUserId(ctx context.Context, userID uuid.UUID) error
+	// no row is returned while the last mail to the key was sent after sent_before, otherwise it is sent now
+	ClaimMailSend(ctx context.Context, arg ClaimMailSendParams) (MailThrottle, error)
+	ClaimWebJob(ctx context.Context, lockedUntil time.Time) (WebJob, error)
+	// no row is returned when the tokens were already handed out
+	ConsumeDeviceAuthorization(ctx context.Context, deviceCodeHash string) (DeviceAuthorization, error)
+	CreateDeviceAuthorization(ctx context.Context, arg CreateDeviceAuthorizationParams) (DeviceAuthorization, error)
+	CreateEmailChangeToken(ctx context.Context, arg CreateEmailChangeTokenParams) (EmailChangeToken, error)
+	CreateLoginChallenge(ctx context.Context, arg CreateLoginChallengeParams) (LoginChallenge, error)
 	CreateNote(ctx context.Context, arg CreateNoteParams) (Note, error)
+	// revisions are numbered per note, callers hold the lock on the note row
+	CreateNoteRevision(ctx context.Context, arg CreateNoteRevisionParams) (NoteRevision, error)
+	CreateNoteTag(ctx context.Context, arg CreateNoteTagParams) (NoteTag, error)
 	CreateNoteWeb(ctx context.Context, arg CreateNoteWebParams) (NoteWeb, error)
+	CreateOidcState(ctx context.Context, arg CreateOidcStateParams) (OidcState, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreatePersonalAccessToken(ctx context.Context, arg CreatePersonalAccessTokenParams) (PersonalAccessToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error)
 	CreateTemporaryUser(ctx context.Context, arg CreateTemporaryUserParams) (TemporaryUser, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	CreateUserTombstone(ctx context.Context, emailHash string) (UserTombstone, error)
 	CreateWeb(ctx context.Context, arg CreateWebParams) (Web, error)
+	CreateWebJob(ctx context.Context, arg CreateWebJobParams) (WebJob, error)
+	CreateWebTag(ctx context.Context, arg CreateWebTagParams) (WebTag, error)
+	DeleteExpiredTemporaryUsers(ctx context.Context) (int64, error)
+	DeleteLoginThrottle(ctx context.Context, key string) error
 	DeleteNote(ctx context.Context, id uuid.UUID) error
+	DeleteNoteTagsByNoteId(ctx context.Context, noteID uuid.UUID) error
 	DeleteNoteWeb(ctx context.Context, arg DeleteNoteWebParams) error
 	DeleteNoteWebsByNoteId(ctx context.Context, noteID uuid.UUID) error
+	DeletePersonalAccessToken(ctx context.Context, id uuid.UUID) error
+	DeletePersonalAccessTokensByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteRecoveryCodesByUserId(ctx context.Context, userID uuid.UUID) error
+	DeleteTag(ctx context.Context, id uuid.UUID) error
+	DeleteTemporaryUsersByEmail(ctx context.Context, email string) error
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	DeleteUserIdentity(ctx context.Context, id uuid.UUID) error
+	DeleteUserTwoFactor(ctx context.Context, userID uuid.UUID) error
 	DeleteWeb(ctx context.Context, id uuid.UUID) error
+	DeleteWebJob(ctx context.Context, id int64) error
+	DeleteWebTagsByWebId(ctx context.Context, webID uuid.UUID) error
+	DenyDeviceAuthorization(ctx context.Context, arg DenyDeviceAuthorizationParams) (DeviceAuthorization, error)
+	EnableUserTwoFactor(ctx context.Context, arg EnableUserTwoFactorParams) (UserTwoFactor, error)
+	GetDeviceAuthorization(ctx context.Context, deviceCodeHash string) (DeviceAuthorization, error)
+	GetLatestTemporaryUserByEmail(ctx context.Context, email string) (TemporaryUser, error)
 	GetNote(ctx context.Context, id uuid.UUID) (Note, error)
+	GetNoteRevision(ctx context.Context, arg GetNoteRevisionParams) (NoteRevision, error)
 	GetNoteWeb(ctx context.Context, arg GetNoteWebParams) (NoteWeb, error)
+	GetPersonalAccessToken(ctx context.Context, id uuid.UUID) (PersonalAccessToken, error)
+	GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (PersonalAccessToken, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (Profile, error)
+	// changes whenever a public note is added or updated, profiles.public_notes_removed_at covers the ones made private or deleted
+	GetPublicNotesState(ctx context.Context, userID uuid.UUID) (GetPublicNotesStateRow, error)
+	GetPublicProfile(ctx context.Context, userID uuid.UUID) (Profile, error)
+	// the profiles of blocked users aren't public
+	GetPublicProfileByHandle(ctx context.Context, handle string) (Profile, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemStats(ctx context.Context) (GetSystemStatsRow, error)
+	GetTag(ctx context.Context, id uuid.UUID) (Tag, error)
 	GetTemporaryUserByEmailAndToken(ctx context.Context, arg GetTemporaryUserByEmailAndTokenParams) (TemporaryUser, error)
 	GetUser(ctx context.Context, id uuid.UUID) (User, error)
+	// what every authenticated request is checked against, session_blocked is false when there is no such session
+	// as for personal access tokens
+	GetUserAuthorization(ctx context.Context, arg GetUserAuthorizationParams) (GetUserAuthorizationRow, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserIdentity(ctx context.Context, id uuid.UUID) (UserIdentity, error)
+	GetUserIdentityBySubject(ctx context.Context, arg GetUserIdentityBySubjectParams) (UserIdentity, error)
+	GetUserTombstone(ctx context.Context, emailHash string) (UserTombstone, error)
+	GetUserTwoFactor(ctx context.Context, userID uuid.UUID) (UserTwoFactor, error)
 	GetWeb(ctx context.Context, id uuid.UUID) (Web, error)
+	GetWebJobByWebId(ctx context.Context, webID uuid.UUID) (WebJob, error)
+	// no row is returned when the challenge is unknown, expired, already used or out of attempts
+	IncrementLoginChallengeAttempts(ctx context.Context, arg IncrementLoginChallengeAttemptsParams) (LoginChallenge, error)
+	InvalidateEmailChangeTokensByUserId(ctx context.Context, userID uuid.UUID) error
+	InvalidatePasswordResetTokensByUserId(ctx context.Context, userID uuid.UUID) error
+	// only the latest session of each family is active
+	ListActiveSessionsByUserId(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	ListDoneWebsAfterId(ctx context.Context, arg ListDoneWebsAfterIdParams) ([]Web, error)
+	ListLoginThrottles(ctx context.Context, keys []string) ([]LoginThrottle, error)
+	ListNoteRevisions(ctx context.Context, arg ListNoteRevisionsParams) ([]NoteRevision, error)
 	ListNoteWebsByNoteId(ctx context.Context, noteID uuid.UUID) ([]NoteWeb, error)
+	ListNoteWebsByNoteIds(ctx context.Context, ids []uuid.UUID) ([]NoteWeb, error)
+	// only notes that have every tag in tags are listed, tags is ignored when empty
 	ListNotesByUserId(ctx context.Context, arg ListNotesByUserIdParams) ([]Note, error)
-	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	ListNotesByUserIdAfterId(ctx context.Context, arg ListNotesByUserIdAfterIdParams) ([]Note, error)
+	ListPersonalAccessTokensByUserId(ctx context.Context, userID uuid.UUID) ([]PersonalAccessToken, error)
+	ListPublicNotesByUserId(ctx context.Context, arg ListPublicNotesByUserIdParams) ([]Note, error)
+	ListTagsByNoteId(ctx context.Context, noteID uuid.UUID) ([]Tag, error)
+	ListTagsByNoteIds(ctx context.Context, ids []uuid.UUID) ([]ListTagsByNoteIdsRow, error)
+	ListTagsByUserId(ctx context.Context, userID uuid.UUID) ([]Tag, error)
+	ListTagsByWebId(ctx context.Context, webID uuid.UUID) ([]Tag, error)
+	ListTagsByWebIds(ctx context.Context, ids []uuid.UUID) ([]ListTagsByWebIdsRow, error)
+	ListUserIdentitiesByUserId(ctx context.Context, userID uuid.UUID) ([]UserIdentity, error)
 	ListWebByNoteId(ctx context.Context, noteID uuid.UUID) ([]Web, error)
 	ListWebByNoteIds(ctx context.Context, ids []uuid.UUID) ([]ListWebByNoteIdsRow, error)
+	// only webs that have every tag in tags are listed, tags is ignored when empty
 	ListWebsByUserId(ctx context.Context, arg ListWebsByUserIdParams) ([]Web, error)
+	ListWebsByUserIdAfterId(ctx context.Context, arg ListWebsByUserIdAfterIdParams) ([]Web, error)
+	LockLogin(ctx context.Context, arg LockLoginParams) error
+	MergeNoteTags(ctx context.Context, arg MergeNoteTagsParams) error
+	MergeWebTags(ctx context.Context, arg MergeWebTagsParams) error
+	// the count starts over when the last failure is older than window_start
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error)
+	ResetWebJob(ctx context.Context, arg ResetWebJobParams) (WebJob, error)
+	RetryWebJob(ctx context.Context, arg RetryWebJobParams) error
+	// no row is returned when the session has already been rotated
+	RotateSession(ctx context.Context, id uuid.UUID) (Session, error)
+	// headlines are expensive, so they are only built for the requested page
+	Search(ctx context.Context, arg SearchParams) ([]SearchRow, error)
+	// email is matched as a substring, an empty one matches every user
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
+	// written at most once a minute, so that a busy script doesn't update the row on every request
+	TouchPersonalAccessToken(ctx context.Context, id uuid.UUID) error
+	UpdateDeviceAuthorizationPoll(ctx context.Context, arg UpdateDeviceAuthorizationPollParams) error
+	// no row is updated when expected_version is given and the note has moved past it
 	UpdateNote(ctx context.Context, arg UpdateNoteParams) (Note, error)
+	UpdateProfileAvatar(ctx context.Context, arg UpdateProfileAvatarParams) (Profile, error)
+	UpdateTagName(ctx context.Context, arg UpdateTagNameParams) (Tag, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserBlockedAt(ctx context.Context, arg UpdateUserBlockedAtParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateWebArticle(ctx context.Context, arg UpdateWebArticleParams) (Web, error)
+	UpdateWebContent(ctx context.Context, arg UpdateWebContentParams) (Web, error)
+	UpdateWebStatus(ctx context.Context, arg UpdateWebStatusParams) (Web, error)
+	UpsertProfile(ctx context.Context, arg UpsertProfileParams) (Profile, error)
+	UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error)
+	// starts the enrollment over with a new secret, no row is returned when 2fa is already enabled
+	UpsertUserTwoFactor(ctx context.Context, arg UpsertUserTwoFactorParams) (UserTwoFactor, error)
+	// no row is returned when the token is unknown, expired or already used
+	UseEmailChangeToken(ctx context.Context, tokenHash string) (EmailChangeToken, error)
+	UseLoginChallenge(ctx context.Context, tokenHash string) (LoginChallenge, error)
+	// no row is returned when the state is unknown, expired or already used
+	UseOidcState(ctx context.Context, stateHash string) (OidcState, error)
+	// no row is returned when the token is unknown, expired or already used
+	UsePasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	// no row is returned when a code of this step or a later one was already used
+	UseTotpStep(ctx context.Context, arg UseTotpStepParams) (UserTwoFactor, error)
 }
 
 var _ Querier = (*Queries)(nil)
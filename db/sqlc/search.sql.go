@@ -0,0 +1,147 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: search.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const search = `-- name: Search :many
+SELECT
+  hits.type,
+  hits.id,
+  hits.title,
+  hits.url,
+  hits.is_public,
+  hits.rank,
+  hits.created_at,
+  ts_headline(
+    'simple',
+    hits.body,
+    websearch_to_tsquery('simple', $1::varchar),
+    $2::varchar
+  )::varchar AS snippet
+FROM (
+  SELECT
+    'note'::varchar AS type,
+    n.id,
+    n.title,
+    ''::varchar AS url,
+    n.is_public,
+    n.content AS body,
+    ts_rank(d.document, websearch_to_tsquery('simple', $1::varchar))::real AS rank,
+    n.created_at
+  FROM notes n
+  JOIN note_search_documents d ON d.note_id = n.id
+  WHERE $5::boolean
+    AND n.user_id = $6
+    AND d.document @@ websearch_to_tsquery('simple', $1::varchar)
+    AND ($7::boolean IS NULL OR n.is_public = $7::boolean)
+    AND ($8::timestamptz IS NULL OR n.created_at >= $8::timestamptz)
+    AND ($9::timestamptz IS NULL OR n.created_at < $9::timestamptz)
+  UNION ALL
+  SELECT
+    'web'::varchar AS type,
+    w.id,
+    w.title,
+    w.url,
+    false AS is_public,
+    CASE WHEN w.article_text = '' THEN w.title ELSE w.article_text END AS body,
+    ts_rank(d.document, websearch_to_tsquery('simple', $1::varchar))::real AS rank,
+    w.created_at
+  FROM webs w
+  JOIN web_search_documents d ON d.web_id = w.id
+  WHERE $10::boolean
+    AND w.user_id = $6
+    AND d.document @@ websearch_to_tsquery('simple', $1::varchar)
+    AND ($7::boolean IS NULL OR NOT $7::boolean)
+    AND ($8::timestamptz IS NULL OR w.created_at >= $8::timestamptz)
+    AND ($9::timestamptz IS NULL OR w.created_at < $9::timestamptz)
+    AND (
+      $11::varchar IS NULL
+      OR lower(substring(w.url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) = lower($11::varchar)
+      OR lower(substring(w.url from '^[a-zA-Z][a-zA-Z0-9+.-]*://([^/?#:]+)')) LIKE '%.' || lower($11::varchar)
+    )
+  ORDER BY rank DESC, created_at DESC
+  LIMIT $4
+  OFFSET $3
+) AS hits
+ORDER BY hits.rank DESC, hits.created_at DESC
+`
+
+type SearchParams struct {
+	Query           string         `json:"query"`
+	HeadlineOptions string         `json:"headline_options"`
+	PageOffset      int32          `json:"page_offset"`
+	PageLimit       int32          `json:"page_limit"`
+	IncludeNotes    bool           `json:"include_notes"`
+	UserID          uuid.UUID      `json:"user_id"`
+	IsPublic        sql.NullBool   `json:"is_public"`
+	CreatedFrom     sql.NullTime   `json:"created_from"`
+	CreatedTo       sql.NullTime   `json:"created_to"`
+	IncludeWebs     bool           `json:"include_webs"`
+	Domain          sql.NullString `json:"domain"`
+}
+
+type SearchRow struct {
+	Type      string    `json:"type"`
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Url       string    `json:"url"`
+	IsPublic  bool      `json:"is_public"`
+	Rank      float32   `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
+	Snippet   string    `json:"snippet"`
+}
+
+// headlines are expensive, so they are only built for the requested page
+func (q *Queries) Search(ctx context.Context, arg SearchParams) ([]SearchRow, error) {
+	rows, err := q.db.QueryContext(ctx, search,
+		arg.Query,
+		arg.HeadlineOptions,
+		arg.PageOffset,
+		arg.PageLimit,
+		arg.IncludeNotes,
+		arg.UserID,
+		arg.IsPublic,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.IncludeWebs,
+		arg.Domain,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchRow{}
+	for rows.Next() {
+		var i SearchRow
+		if err := rows.Scan(
+			&i.Type,
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.IsPublic,
+			&i.Rank,
+			&i.CreatedAt,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
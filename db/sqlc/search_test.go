@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+const searchHeadlineOptions = "StartSel=<b>, StopSel=</b>"
+
+func TestSearch(t *testing.T) {
+	user := createRandomUser(t)
+	word := util.RandomString(12)
+
+	note, err := testQueries.CreateNote(context.Background(), CreateNoteParams{
+		UserID:   user.ID,
+		Title:    util.RandomString(6),
+		Content:  "a note about " + word,
+		IsPublic: true,
+	})
+	require.NoError(t, err)
+
+	web := createRandomWeb(t, user)
+	web, err = testQueries.UpdateWebArticle(context.Background(), UpdateWebArticleParams{
+		ID:          web.ID,
+		ArticleText: "an article about " + word,
+	})
+	require.NoError(t, err)
+
+	// other users never see each other's results
+	createRandomNote(t, createRandomUser(t))
+
+	arg := SearchParams{
+		Query:           word,
+		HeadlineOptions: searchHeadlineOptions,
+		UserID:          user.ID,
+		IncludeNotes:    true,
+		IncludeWebs:     true,
+		PageLimit:       10,
+	}
+	rows, err := testQueries.Search(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	for _, row := range rows {
+		switch row.Type {
+		case "note":
+			require.Equal(t, note.ID, row.ID)
+			require.Equal(t, "a note about <b>"+word+"</b>", row.Snippet)
+		case "web":
+			require.Equal(t, web.ID, row.ID)
+			require.Equal(t, web.Url, row.Url)
+			require.Equal(t, "an article about <b>"+word+"</b>", row.Snippet)
+		default:
+			t.Fatalf("unexpected type %s", row.Type)
+		}
+		require.Greater(t, row.Rank, float32(0))
+	}
+
+	arg.IncludeWebs = false
+	rows, err = testQueries.Search(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, note.ID, rows[0].ID)
+
+	arg.IncludeWebs = true
+	arg.IsPublic = sql.NullBool{Bool: false, Valid: true}
+	rows, err = testQueries.Search(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, web.ID, rows[0].ID)
+
+	arg.IsPublic = sql.NullBool{}
+	arg.CreatedFrom = sql.NullTime{Time: note.CreatedAt.AddDate(0, 0, 1), Valid: true}
+	rows, err = testQueries.Search(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+func TestSearchUpdatedNote(t *testing.T) {
+	user := createRandomUser(t)
+	note := createRandomNote(t, user)
+	word := util.RandomString(12)
+
+	_, err := testQueries.UpdateNote(context.Background(), UpdateNoteParams{
+		ID:      note.ID,
+		Title:   word,
+		Content: note.Content,
+	})
+	require.NoError(t, err)
+
+	rows, err := testQueries.Search(context.Background(), SearchParams{
+		Query:           word,
+		HeadlineOptions: searchHeadlineOptions,
+		UserID:          user.ID,
+		IncludeNotes:    true,
+		PageLimit:       10,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, note.ID, rows[0].ID)
+}
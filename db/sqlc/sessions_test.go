@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User, expiresAt time.Time) Session {
+	id := uuid.New()
+	arg := CreateSessionParams{
+		ID:           id,
+		UserID:       user.ID,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    util.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    expiresAt,
+		FamilyID:     id,
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.UserID, session.UserID)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.False(t, session.ParentID.Valid)
+	require.False(t, session.RotatedAt.Valid)
+	require.False(t, session.IsBlocked)
+	require.NotZero(t, session.CreatedAt)
+	return session
+}
+
+func rotateRandomSession(t *testing.T, store Store, session Session) Session {
+	newSession, err := store.TxRotateSession(context.Background(), TxRotateSessionParams{
+		SessionID: session.ID,
+		NewSession: CreateSessionParams{
+			ID:           uuid.New(),
+			RefreshToken: util.RandomString(32),
+			UserAgent:    session.UserAgent,
+			ClientIp:     session.ClientIp,
+			ExpiresAt:    session.ExpiresAt,
+		},
+	})
+	require.NoError(t, err)
+	return newSession
+}
+
+func TestTxRotateSession(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user, time.Now().Add(time.Hour))
+
+	session2 := rotateRandomSession(t, store, session1)
+	require.Equal(t, user.ID, session2.UserID)
+	require.Equal(t, session1.FamilyID, session2.FamilyID)
+	require.Equal(t, uuid.NullUUID{UUID: session1.ID, Valid: true}, session2.ParentID)
+
+	session3 := rotateRandomSession(t, store, session2)
+	require.Equal(t, session1.FamilyID, session3.FamilyID)
+	require.Equal(t, uuid.NullUUID{UUID: session2.ID, Valid: true}, session3.ParentID)
+
+	rotated, err := store.GetSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+	require.True(t, rotated.RotatedAt.Valid)
+
+	// a session is replaced only once
+	_, err = store.TxRotateSession(context.Background(), TxRotateSessionParams{
+		SessionID: session1.ID,
+		NewSession: CreateSessionParams{
+			ID:           uuid.New(),
+			RefreshToken: util.RandomString(32),
+			ExpiresAt:    session1.ExpiresAt,
+		},
+	})
+	require.ErrorIs(t, err, ErrSessionRotated)
+
+	sessions, err := store.ListActiveSessionsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, session3.ID, sessions[0].ID)
+}
+
+func TestBlockSessionFamily(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user, time.Now().Add(time.Hour))
+	session2 := rotateRandomSession(t, store, session1)
+	other := createRandomSession(t, user, time.Now().Add(time.Hour))
+
+	err := store.BlockSessionFamily(context.Background(), session1.FamilyID)
+	require.NoError(t, err)
+
+	for _, id := range []uuid.UUID{session1.ID, session2.ID} {
+		got, err := store.GetSession(context.Background(), id)
+		require.NoError(t, err)
+		require.True(t, got.IsBlocked)
+	}
+
+	got, err := store.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	require.False(t, got.IsBlocked)
+
+	// the access tokens of the family are refused on their next request
+	authorization, err := store.GetUserAuthorization(context.Background(), GetUserAuthorizationParams{
+		ID:        user.ID,
+		SessionID: session2.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, authorization.SessionBlocked)
+
+	authorization, err = store.GetUserAuthorization(context.Background(), GetUserAuthorizationParams{
+		ID:        user.ID,
+		SessionID: other.ID,
+	})
+	require.NoError(t, err)
+	require.False(t, authorization.SessionBlocked)
+}
+
+func TestListActiveSessionsByUserId(t *testing.T) {
+	user := createRandomUser(t)
+	active := createRandomSession(t, user, time.Now().Add(time.Hour))
+	createRandomSession(t, user, time.Now().Add(-time.Hour))
+	blocked := createRandomSession(t, user, time.Now().Add(time.Hour))
+	err := testQueries.BlockSessionFamily(context.Background(), blocked.FamilyID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListActiveSessionsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, active.ID, sessions[0].ID)
+}
+
+func TestBlockSessionsByUserId(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user, time.Now().Add(time.Hour))
+	}
+	otherSession := createRandomSession(t, other, time.Now().Add(time.Hour))
+
+	err := testQueries.BlockSessionsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+
+	sessions, err := testQueries.ListActiveSessionsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	got, err := testQueries.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, got.IsBlocked)
+}
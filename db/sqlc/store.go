@@ -12,6 +12,22 @@ type Store interface {
 	TxCreateNote(ctx context.Context, arg TxCreateNoteParams) (TxCreateNoteResult, error)
 	TxDeleteNote(ctx context.Context, arg TxDeleteNoteParams) error
 	TxUpdateNote(ctx context.Context, arg TxUpdateNoteParams) (TxUpdateNoteResult, error)
+	TxCreateWeb(ctx context.Context, arg TxCreateWebParams) (TxCreateWebResult, error)
+	TxRefetchWeb(ctx context.Context, arg TxRefetchWebParams) (TxRefetchWebResult, error)
+	TxCompleteWebJob(ctx context.Context, arg TxCompleteWebJobParams) (Web, error)
+	TxFailWebJob(ctx context.Context, arg TxFailWebJobParams) (Web, error)
+	TxSetWebTags(ctx context.Context, arg TxSetWebTagsParams) ([]Tag, error)
+	TxMergeTags(ctx context.Context, arg TxMergeTagsParams) (Tag, error)
+	TxRotateSession(ctx context.Context, arg TxRotateSessionParams) (Session, error)
+	TxResetPassword(ctx context.Context, arg TxResetPasswordParams) (User, error)
+	TxChangePassword(ctx context.Context, arg TxChangePasswordParams) (User, error)
+	TxChangeEmail(ctx context.Context, arg TxChangeEmailParams) (User, error)
+	TxDeleteUser(ctx context.Context, arg TxDeleteUserParams) (UserTombstone, error)
+	TxVerifyUser(ctx context.Context, arg CreateUserParams) (User, error)
+	TxEnableTwoFactor(ctx context.Context, arg TxEnableTwoFactorParams) (UserTwoFactor, error)
+	TxDisableTwoFactor(ctx context.Context, arg TxDisableTwoFactorParams) error
+	TxCreateUserWithIdentity(ctx context.Context, arg TxCreateUserWithIdentityParams) (User, error)
+	TxBlockUser(ctx context.Context, arg TxBlockUserParams) (User, error)
 }
 
 // SQLStore providers all functions to execute SQL queries and transactions
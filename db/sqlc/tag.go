@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// upsertTags returns the tags of the user with the given names, creating the missing ones
+func upsertTags(ctx context.Context, q *Queries, userID uuid.UUID, names []string) ([]Tag, error) {
+	tags := make([]Tag, len(names))
+	for i, name := range names {
+		tag, err := q.UpsertTag(ctx, UpsertTagParams{
+			UserID: userID,
+			Name:   name,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tags[i] = tag
+	}
+	return tags, nil
+}
+
+// addWebTags attaches the tags with the given names to the web
+func addWebTags(ctx context.Context, q *Queries, web Web, names []string) ([]Tag, error) {
+	tags, err := upsertTags(ctx, q, web.UserID, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		_, err = q.CreateWebTag(ctx, CreateWebTagParams{
+			WebID: web.ID,
+			TagID: tag.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// addNoteTags attaches the tags with the given names to the note
+func addNoteTags(ctx context.Context, q *Queries, note Note, names []string) ([]Tag, error) {
+	tags, err := upsertTags(ctx, q, note.UserID, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		_, err = q.CreateNoteTag(ctx, CreateNoteTagParams{
+			NoteID: note.ID,
+			TagID:  tag.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
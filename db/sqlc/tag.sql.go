@@ -0,0 +1,432 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: tag.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const createNoteTag = `-- name: CreateNoteTag :one
+INSERT INTO note_tags (
+  note_id,
+  tag_id
+) VALUES (
+  $1, $2
+)
+RETURNING note_id, tag_id
+`
+
+type CreateNoteTagParams struct {
+	NoteID uuid.UUID `json:"note_id"`
+	TagID  uuid.UUID `json:"tag_id"`
+}
+
+func (q *Queries) CreateNoteTag(ctx context.Context, arg CreateNoteTagParams) (NoteTag, error) {
+	row := q.db.QueryRowContext(ctx, createNoteTag, arg.NoteID, arg.TagID)
+	var i NoteTag
+	err := row.Scan(&i.NoteID, &i.TagID)
+	return i, err
+}
+
+const createTag = `-- name: CreateTag :one
+INSERT INTO tags (
+  user_id,
+  name
+) VALUES (
+  $1, $2
+)
+RETURNING id, user_id, name, note_count, web_count, created_at
+`
+
+type CreateTagParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+}
+
+func (q *Queries) CreateTag(ctx context.Context, arg CreateTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, createTag, arg.UserID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.NoteCount,
+		&i.WebCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebTag = `-- name: CreateWebTag :one
+INSERT INTO web_tags (
+  web_id,
+  tag_id
+) VALUES (
+  $1, $2
+)
+RETURNING web_id, tag_id
+`
+
+type CreateWebTagParams struct {
+	WebID uuid.UUID `json:"web_id"`
+	TagID uuid.UUID `json:"tag_id"`
+}
+
+func (q *Queries) CreateWebTag(ctx context.Context, arg CreateWebTagParams) (WebTag, error) {
+	row := q.db.QueryRowContext(ctx, createWebTag, arg.WebID, arg.TagID)
+	var i WebTag
+	err := row.Scan(&i.WebID, &i.TagID)
+	return i, err
+}
+
+const deleteNoteTagsByNoteId = `-- name: DeleteNoteTagsByNoteId :exec
+DELETE FROM note_tags
+WHERE note_id = $1
+`
+
+func (q *Queries) DeleteNoteTagsByNoteId(ctx context.Context, noteID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteNoteTagsByNoteId, noteID)
+	return err
+}
+
+const deleteTag = `-- name: DeleteTag :exec
+DELETE FROM tags
+WHERE id = $1
+`
+
+func (q *Queries) DeleteTag(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTag, id)
+	return err
+}
+
+const deleteWebTagsByWebId = `-- name: DeleteWebTagsByWebId :exec
+DELETE FROM web_tags
+WHERE web_id = $1
+`
+
+func (q *Queries) DeleteWebTagsByWebId(ctx context.Context, webID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWebTagsByWebId, webID)
+	return err
+}
+
+const getTag = `-- name: GetTag :one
+SELECT id, user_id, name, note_count, web_count, created_at FROM tags
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTag(ctx context.Context, id uuid.UUID) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTag, id)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.NoteCount,
+		&i.WebCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listTagsByNoteId = `-- name: ListTagsByNoteId :many
+SELECT tags.id, tags.user_id, tags.name, tags.note_count, tags.web_count, tags.created_at FROM tags
+INNER JOIN note_tags ON tags.id = note_tags.tag_id
+WHERE note_tags.note_id = $1
+ORDER BY tags.name
+`
+
+func (q *Queries) ListTagsByNoteId(ctx context.Context, noteID uuid.UUID) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByNoteId, noteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.NoteCount,
+			&i.WebCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsByNoteIds = `-- name: ListTagsByNoteIds :many
+SELECT tags.id, tags.user_id, tags.name, tags.note_count, tags.web_count, tags.created_at, note_tags.note_id FROM tags
+INNER JOIN note_tags ON tags.id = note_tags.tag_id
+WHERE note_tags.note_id = ANY($1::uuid[])
+ORDER BY tags.name
+`
+
+type ListTagsByNoteIdsRow struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	NoteCount int32     `json:"note_count"`
+	WebCount  int32     `json:"web_count"`
+	CreatedAt time.Time `json:"created_at"`
+	NoteID    uuid.UUID `json:"note_id"`
+}
+
+func (q *Queries) ListTagsByNoteIds(ctx context.Context, ids []uuid.UUID) ([]ListTagsByNoteIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByNoteIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTagsByNoteIdsRow{}
+	for rows.Next() {
+		var i ListTagsByNoteIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.NoteCount,
+			&i.WebCount,
+			&i.CreatedAt,
+			&i.NoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsByUserId = `-- name: ListTagsByUserId :many
+SELECT id, user_id, name, note_count, web_count, created_at FROM tags
+WHERE user_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListTagsByUserId(ctx context.Context, userID uuid.UUID) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.NoteCount,
+			&i.WebCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsByWebId = `-- name: ListTagsByWebId :many
+SELECT tags.id, tags.user_id, tags.name, tags.note_count, tags.web_count, tags.created_at FROM tags
+INNER JOIN web_tags ON tags.id = web_tags.tag_id
+WHERE web_tags.web_id = $1
+ORDER BY tags.name
+`
+
+func (q *Queries) ListTagsByWebId(ctx context.Context, webID uuid.UUID) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByWebId, webID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Tag{}
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.NoteCount,
+			&i.WebCount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsByWebIds = `-- name: ListTagsByWebIds :many
+SELECT tags.id, tags.user_id, tags.name, tags.note_count, tags.web_count, tags.created_at, web_tags.web_id FROM tags
+INNER JOIN web_tags ON tags.id = web_tags.tag_id
+WHERE web_tags.web_id = ANY($1::uuid[])
+ORDER BY tags.name
+`
+
+type ListTagsByWebIdsRow struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	NoteCount int32     `json:"note_count"`
+	WebCount  int32     `json:"web_count"`
+	CreatedAt time.Time `json:"created_at"`
+	WebID     uuid.UUID `json:"web_id"`
+}
+
+func (q *Queries) ListTagsByWebIds(ctx context.Context, ids []uuid.UUID) ([]ListTagsByWebIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByWebIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTagsByWebIdsRow{}
+	for rows.Next() {
+		var i ListTagsByWebIdsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.NoteCount,
+			&i.WebCount,
+			&i.CreatedAt,
+			&i.WebID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const mergeNoteTags = `-- name: MergeNoteTags :exec
+INSERT INTO note_tags (note_id, tag_id)
+SELECT note_id, $1::uuid FROM note_tags
+WHERE tag_id = $2::uuid
+ON CONFLICT DO NOTHING
+`
+
+type MergeNoteTagsParams struct {
+	TargetID uuid.UUID `json:"target_id"`
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+func (q *Queries) MergeNoteTags(ctx context.Context, arg MergeNoteTagsParams) error {
+	_, err := q.db.ExecContext(ctx, mergeNoteTags, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const mergeWebTags = `-- name: MergeWebTags :exec
+INSERT INTO web_tags (web_id, tag_id)
+SELECT web_id, $1::uuid FROM web_tags
+WHERE tag_id = $2::uuid
+ON CONFLICT DO NOTHING
+`
+
+type MergeWebTagsParams struct {
+	TargetID uuid.UUID `json:"target_id"`
+	SourceID uuid.UUID `json:"source_id"`
+}
+
+func (q *Queries) MergeWebTags(ctx context.Context, arg MergeWebTagsParams) error {
+	_, err := q.db.ExecContext(ctx, mergeWebTags, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const updateTagName = `-- name: UpdateTagName :one
+UPDATE tags
+SET name = $2
+WHERE id = $1
+RETURNING id, user_id, name, note_count, web_count, created_at
+`
+
+type UpdateTagNameParams struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+func (q *Queries) UpdateTagName(ctx context.Context, arg UpdateTagNameParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, updateTagName, arg.ID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.NoteCount,
+		&i.WebCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertTag = `-- name: UpsertTag :one
+INSERT INTO tags (
+  user_id,
+  name
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id, name) DO UPDATE SET name = EXCLUDED.name
+RETURNING id, user_id, name, note_count, web_count, created_at
+`
+
+type UpsertTagParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+}
+
+func (q *Queries) UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, upsertTag, arg.UserID, arg.Name)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.NoteCount,
+		&i.WebCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
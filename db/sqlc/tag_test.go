@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTag(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomTag(t, user)
+}
+
+func TestUpsertTag(t *testing.T) {
+	user := createRandomUser(t)
+	tag := createRandomTag(t, user)
+
+	got, err := testQueries.UpsertTag(context.Background(), UpsertTagParams{
+		UserID: user.ID,
+		Name:   tag.Name,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tag.ID, got.ID)
+
+	// names are only unique per user
+	other, err := testQueries.UpsertTag(context.Background(), UpsertTagParams{
+		UserID: createRandomUser(t).ID,
+		Name:   tag.Name,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, tag.ID, other.ID)
+}
+
+func TestUpdateTagName(t *testing.T) {
+	user := createRandomUser(t)
+	tag := createRandomTag(t, user)
+
+	name := util.RandomString(8)
+	got, err := testQueries.UpdateTagName(context.Background(), UpdateTagNameParams{
+		ID:   tag.ID,
+		Name: name,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tag.ID, got.ID)
+	require.Equal(t, name, got.Name)
+}
+
+func TestDeleteTag(t *testing.T) {
+	user := createRandomUser(t)
+	tag := createRandomTag(t, user)
+
+	err := testQueries.DeleteTag(context.Background(), tag.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetTag(context.Background(), tag.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTagCounts(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	name := util.RandomString(8)
+
+	note, err := store.TxCreateNote(context.Background(), TxCreateNoteParams{
+		CreateNoteParams: CreateNoteParams{
+			UserID:  user.ID,
+			Title:   util.RandomString(6),
+			Content: util.RandomString(6),
+		},
+		WebIds:   []uuid.UUID{web.ID},
+		TagNames: []string{name},
+	})
+	require.NoError(t, err)
+	require.Len(t, note.Tags, 1)
+
+	tags, err := store.TxSetWebTags(context.Background(), TxSetWebTagsParams{
+		Web:      web,
+		TagNames: []string{name},
+	})
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, note.Tags[0].ID, tags[0].ID)
+
+	tag, err := store.GetTag(context.Background(), tags[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), tag.NoteCount)
+	require.Equal(t, int32(1), tag.WebCount)
+
+	err = store.DeleteNoteTagsByNoteId(context.Background(), note.Note.ID)
+	require.NoError(t, err)
+
+	tag, err = store.GetTag(context.Background(), tag.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), tag.NoteCount)
+	require.Equal(t, int32(1), tag.WebCount)
+
+	list, err := store.ListTagsByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, tag, list[0])
+}
+
+func TestTxMergeTags(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	web1 := createRandomWeb(t, user)
+	web2 := createRandomWeb(t, user)
+	source := util.RandomString(8)
+	target := util.RandomString(8)
+
+	_, err := store.TxSetWebTags(context.Background(), TxSetWebTagsParams{Web: web1, TagNames: []string{source, target}})
+	require.NoError(t, err)
+	tags, err := store.TxSetWebTags(context.Background(), TxSetWebTagsParams{Web: web2, TagNames: []string{source}})
+	require.NoError(t, err)
+	sourceTag := tags[0]
+
+	targetTag, err := store.UpsertTag(context.Background(), UpsertTagParams{UserID: user.ID, Name: target})
+	require.NoError(t, err)
+
+	merged, err := store.TxMergeTags(context.Background(), TxMergeTagsParams{
+		SourceID: sourceTag.ID,
+		TargetID: targetTag.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, targetTag.ID, merged.ID)
+	require.Equal(t, int32(2), merged.WebCount)
+
+	_, err = store.GetTag(context.Background(), sourceTag.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	webs, err := store.ListWebsByUserId(context.Background(), ListWebsByUserIdParams{
+		UserID: user.ID,
+		Tags:   []string{target},
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, webs, 2)
+}
+
+func TestListNotesByUserIdWithTags(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	tag1 := util.RandomString(8)
+	tag2 := util.RandomString(8)
+
+	create := func(tags ...string) Note {
+		result, err := store.TxCreateNote(context.Background(), TxCreateNoteParams{
+			CreateNoteParams: CreateNoteParams{
+				UserID:  user.ID,
+				Title:   util.RandomString(6),
+				Content: util.RandomString(6),
+			},
+			WebIds:   []uuid.UUID{web.ID},
+			TagNames: tags,
+		})
+		require.NoError(t, err)
+		return result.Note
+	}
+	both := create(tag1, tag2)
+	create(tag1)
+	create()
+
+	notes, err := store.ListNotesByUserId(context.Background(), ListNotesByUserIdParams{
+		UserID: user.ID,
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+
+	notes, err = store.ListNotesByUserId(context.Background(), ListNotesByUserIdParams{
+		UserID: user.ID,
+		Tags:   []string{tag1},
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+
+	notes, err = store.ListNotesByUserId(context.Background(), ListNotesByUserIdParams{
+		UserID: user.ID,
+		Tags:   []string{tag1, tag2},
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Equal(t, both.ID, notes[0].ID)
+
+	rows, err := store.ListTagsByNoteIds(context.Background(), []uuid.UUID{both.ID})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+}
+
+func createRandomTag(t *testing.T, user User) Tag {
+	arg := CreateTagParams{
+		UserID: user.ID,
+		Name:   util.RandomString(8),
+	}
+	tag, err := testQueries.CreateTag(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, tag)
+
+	require.Equal(t, arg.UserID, tag.UserID)
+	require.Equal(t, arg.Name, tag.Name)
+	require.Zero(t, tag.NoteCount)
+	require.Zero(t, tag.WebCount)
+	require.NotZero(t, tag.CreatedAt)
+	return tag
+}
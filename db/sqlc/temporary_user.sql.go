@@ -47,6 +47,49 @@ func (q *Queries) CreateTemporaryUser(ctx context.Context, arg CreateTemporaryUs
 	return i, err
 }
 
+const deleteExpiredTemporaryUsers = `-- name: DeleteExpiredTemporaryUsers :execrows
+DELETE FROM temporary_users
+WHERE expires_at < now()
+`
+
+func (q *Queries) DeleteExpiredTemporaryUsers(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredTemporaryUsers)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTemporaryUsersByEmail = `-- name: DeleteTemporaryUsersByEmail :exec
+DELETE FROM temporary_users
+WHERE email = $1
+`
+
+func (q *Queries) DeleteTemporaryUsersByEmail(ctx context.Context, email string) error {
+	_, err := q.db.ExecContext(ctx, deleteTemporaryUsersByEmail, email)
+	return err
+}
+
+const getLatestTemporaryUserByEmail = `-- name: GetLatestTemporaryUserByEmail :one
+SELECT email, hashed_password, token, expires_at, created_at FROM temporary_users
+WHERE email = $1
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestTemporaryUserByEmail(ctx context.Context, email string) (TemporaryUser, error) {
+	row := q.db.QueryRowContext(ctx, getLatestTemporaryUserByEmail, email)
+	var i TemporaryUser
+	err := row.Scan(
+		&i.Email,
+		&i.HashedPassword,
+		&i.Token,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getTemporaryUserByEmailAndToken = `-- name: GetTemporaryUserByEmailAndToken :one
 SELECT email, hashed_password, token, expires_at, created_at FROM temporary_users
 WHERE email = $1 AND token = $2 LIMIT 1
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -52,3 +53,44 @@ func createRandomTemporaryUser(t *testing.T) TemporaryUser {
 	require.NotZero(t, tmpUser.CreatedAt)
 	return tmpUser
 }
+
+func TestGetLatestTemporaryUserByEmail(t *testing.T) {
+	tmpUser := createRandomTemporaryUser(t)
+	latest, err := testQueries.CreateTemporaryUser(context.Background(), CreateTemporaryUserParams{
+		Email:          tmpUser.Email,
+		HashedPassword: tmpUser.HashedPassword,
+		Token:          util.RandomString(6),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	got, err := testQueries.GetLatestTemporaryUserByEmail(context.Background(), tmpUser.Email)
+	require.NoError(t, err)
+	require.Equal(t, latest.Token, got.Token)
+}
+
+func TestDeleteExpiredTemporaryUsers(t *testing.T) {
+	expired, err := testQueries.CreateTemporaryUser(context.Background(), CreateTemporaryUserParams{
+		Email:          util.RandomEmail(),
+		HashedPassword: util.RandomString(10),
+		Token:          util.RandomString(6),
+		ExpiresAt:      time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	pending, err := testQueries.CreateTemporaryUser(context.Background(), CreateTemporaryUserParams{
+		Email:          util.RandomEmail(),
+		HashedPassword: util.RandomString(10),
+		Token:          util.RandomString(6),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	deleted, err := testQueries.DeleteExpiredTemporaryUsers(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testQueries.GetLatestTemporaryUserByEmail(context.Background(), expired.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetLatestTemporaryUserByEmail(context.Background(), pending.Email)
+	require.NoError(t, err)
+}
@@ -0,0 +1,189 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: two_factor.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+  code_hash,
+  user_id
+) VALUES (
+  $1, $2
+)
+RETURNING code_hash, user_id, used_at, created_at
+`
+
+type CreateRecoveryCodeParams struct {
+	CodeHash string    `json:"code_hash"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, createRecoveryCode, arg.CodeHash, arg.UserID)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.UserID,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodesByUserId = `-- name: DeleteRecoveryCodesByUserId :exec
+DELETE FROM recovery_codes
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodesByUserId(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodesByUserId, userID)
+	return err
+}
+
+const deleteUserTwoFactor = `-- name: DeleteUserTwoFactor :exec
+DELETE FROM user_two_factors
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTwoFactor, userID)
+	return err
+}
+
+const enableUserTwoFactor = `-- name: EnableUserTwoFactor :one
+UPDATE user_two_factors
+SET
+  enabled_at = now(),
+  last_used_step = $2
+WHERE user_id = $1 AND enabled_at IS NULL
+RETURNING user_id, totp_secret, enabled_at, last_used_step, created_at
+`
+
+type EnableUserTwoFactorParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	LastUsedStep int64     `json:"last_used_step"`
+}
+
+func (q *Queries) EnableUserTwoFactor(ctx context.Context, arg EnableUserTwoFactorParams) (UserTwoFactor, error) {
+	row := q.db.QueryRowContext(ctx, enableUserTwoFactor, arg.UserID, arg.LastUsedStep)
+	var i UserTwoFactor
+	err := row.Scan(
+		&i.UserID,
+		&i.TotpSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserTwoFactor = `-- name: GetUserTwoFactor :one
+SELECT user_id, totp_secret, enabled_at, last_used_step, created_at FROM user_two_factors
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserTwoFactor(ctx context.Context, userID uuid.UUID) (UserTwoFactor, error) {
+	row := q.db.QueryRowContext(ctx, getUserTwoFactor, userID)
+	var i UserTwoFactor
+	err := row.Scan(
+		&i.UserID,
+		&i.TotpSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertUserTwoFactor = `-- name: UpsertUserTwoFactor :one
+INSERT INTO user_two_factors (
+  user_id,
+  totp_secret
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+  totp_secret = EXCLUDED.totp_secret,
+  last_used_step = 0,
+  created_at = now()
+WHERE user_two_factors.enabled_at IS NULL
+RETURNING user_id, totp_secret, enabled_at, last_used_step, created_at
+`
+
+type UpsertUserTwoFactorParams struct {
+	UserID     uuid.UUID `json:"user_id"`
+	TotpSecret string    `json:"totp_secret"`
+}
+
+// starts the enrollment over with a new secret, no row is returned when 2fa is already enabled
+func (q *Queries) UpsertUserTwoFactor(ctx context.Context, arg UpsertUserTwoFactorParams) (UserTwoFactor, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserTwoFactor, arg.UserID, arg.TotpSecret)
+	var i UserTwoFactor
+	err := row.Scan(
+		&i.UserID,
+		&i.TotpSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE recovery_codes
+SET used_at = now()
+WHERE code_hash = $1 AND user_id = $2 AND used_at IS NULL
+RETURNING code_hash, user_id, used_at, created_at
+`
+
+type UseRecoveryCodeParams struct {
+	CodeHash string    `json:"code_hash"`
+	UserID   uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, useRecoveryCode, arg.CodeHash, arg.UserID)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.UserID,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useTotpStep = `-- name: UseTotpStep :one
+UPDATE user_two_factors
+SET last_used_step = $2
+WHERE user_id = $1 AND last_used_step < $2
+RETURNING user_id, totp_secret, enabled_at, last_used_step, created_at
+`
+
+type UseTotpStepParams struct {
+	UserID       uuid.UUID `json:"user_id"`
+	LastUsedStep int64     `json:"last_used_step"`
+}
+
+// no row is returned when a code of this step or a later one was already used
+func (q *Queries) UseTotpStep(ctx context.Context, arg UseTotpStepParams) (UserTwoFactor, error) {
+	row := q.db.QueryRowContext(ctx, useTotpStep, arg.UserID, arg.LastUsedStep)
+	var i UserTwoFactor
+	err := row.Scan(
+		&i.UserID,
+		&i.TotpSecret,
+		&i.EnabledAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TxBlockUserParams struct {
+	UserID uuid.UUID
+}
+
+// TxBlockUser blocks the user and its sessions, so that refresh tokens stop working too
+func (store *SQLStore) TxBlockUser(ctx context.Context, arg TxBlockUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.UpdateUserBlockedAt(ctx, UpdateUserBlockedAtParams{
+			ID:        arg.UserID,
+			BlockedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.BlockSessionsByUserId(ctx, arg.UserID)
+	})
+
+	return user, err
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBlockUser(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+
+	blocked, err := store.TxBlockUser(context.Background(), TxBlockUserParams{UserID: user.ID})
+	require.NoError(t, err)
+	require.True(t, blocked.BlockedAt.Valid)
+	require.WithinDuration(t, time.Now(), blocked.BlockedAt.Time, time.Second)
+
+	got, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsBlocked)
+
+	unblocked, err := store.UpdateUserBlockedAt(context.Background(), UpdateUserBlockedAtParams{
+		ID:        user.ID,
+		BlockedAt: sql.NullTime{},
+	})
+	require.NoError(t, err)
+	require.False(t, unblocked.BlockedAt.Valid)
+}
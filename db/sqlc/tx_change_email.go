@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrInvalidEmailChangeToken is returned by TxChangeEmail when the token is unknown, expired or already used
+	ErrInvalidEmailChangeToken = errors.New("email change token is invalid or expired")
+	// ErrEmailAlreadyUsed is returned by TxChangeEmail when another user took the address after the change was requested
+	ErrEmailAlreadyUsed = errors.New("email is already used by another user")
+)
+
+type TxChangeEmailParams struct {
+	TokenHash string
+}
+
+// TxChangeEmail uses up the token and swaps the email of the user for the one it was issued for.
+// Other pending changes and password reset links sent to the old address stop working.
+func (store *SQLStore) TxChangeEmail(ctx context.Context, arg TxChangeEmailParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		token, err := q.UseEmailChangeToken(ctx, arg.TokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidEmailChangeToken
+			}
+			return err
+		}
+
+		user, err = q.UpdateUser(ctx, UpdateUserParams{
+			ID:    token.UserID,
+			Email: token.NewEmail,
+		})
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+				return ErrEmailAlreadyUsed
+			}
+			return err
+		}
+
+		err = q.InvalidateEmailChangeTokensByUserId(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return q.InvalidatePasswordResetTokensByUserId(ctx, user.ID)
+	})
+
+	return user, err
+}
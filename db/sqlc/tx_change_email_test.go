@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailChangeToken(t *testing.T, user User, newEmail string, expiresAt time.Time) string {
+	token, err := util.NewSecretToken()
+	require.NoError(t, err)
+
+	arg := CreateEmailChangeTokenParams{
+		TokenHash: util.HashSecretToken(token),
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		ExpiresAt: expiresAt,
+	}
+	changeToken, err := testQueries.CreateEmailChangeToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewEmail, changeToken.NewEmail)
+	require.False(t, changeToken.UsedAt.Valid)
+	return token
+}
+
+func TestTxChangeEmail(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	newEmail := util.RandomEmail()
+	token := createRandomEmailChangeToken(t, user, newEmail, time.Now().Add(time.Hour))
+	otherToken := createRandomEmailChangeToken(t, user, util.RandomEmail(), time.Now().Add(time.Hour))
+	resetToken, _ := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+
+	updated, err := store.TxChangeEmail(context.Background(), TxChangeEmailParams{
+		TokenHash: util.HashSecretToken(token),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.Equal(t, newEmail, updated.Email)
+
+	for _, used := range []string{token, otherToken} {
+		_, err = store.TxChangeEmail(context.Background(), TxChangeEmailParams{
+			TokenHash: util.HashSecretToken(used),
+		})
+		require.ErrorIs(t, err, ErrInvalidEmailChangeToken)
+	}
+
+	// the link was sent to the old address
+	_, err = store.TxResetPassword(context.Background(), TxResetPasswordParams{
+		TokenHash:      util.HashSecretToken(resetToken),
+		HashedPassword: util.RandomString(10),
+	})
+	require.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+}
+
+func TestTxChangeEmailAlreadyUsed(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+	token := createRandomEmailChangeToken(t, user, other.Email, time.Now().Add(time.Hour))
+
+	_, err := store.TxChangeEmail(context.Background(), TxChangeEmailParams{
+		TokenHash: util.HashSecretToken(token),
+	})
+	require.ErrorIs(t, err, ErrEmailAlreadyUsed)
+
+	got, err := store.GetUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, got.Email)
+}
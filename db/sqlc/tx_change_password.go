@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TxChangePasswordParams struct {
+	UserID         uuid.UUID
+	HashedPassword string
+}
+
+// TxChangePassword sets the new password and logs the user out everywhere
+func (store *SQLStore) TxChangePassword(ctx context.Context, arg TxChangePasswordParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = setPassword(ctx, q, arg.UserID, arg.HashedPassword)
+		return err
+	})
+
+	return user, err
+}
+
+// setPassword updates the password, then blocks every session, reset link and personal access token issued with the old one
+func setPassword(ctx context.Context, q *Queries, userID uuid.UUID, hashedPassword string) (User, error) {
+	// tokens are rejected when they were issued before password_changed_at, so it is taken from the clock they are issued with.
+	// postgres keeps microseconds, rounding up keeps every token issued before the change earlier than it.
+	user, err := q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+		ID:                userID,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now().Truncate(time.Microsecond).Add(time.Microsecond),
+	})
+	if err != nil {
+		return user, err
+	}
+
+	err = q.InvalidatePasswordResetTokensByUserId(ctx, user.ID)
+	if err != nil {
+		return user, err
+	}
+
+	err = q.BlockSessionsByUserId(ctx, user.ID)
+	if err != nil {
+		return user, err
+	}
+
+	err = q.DeletePersonalAccessTokensByUserId(ctx, user.ID)
+	return user, err
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxChangePassword(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+	token, _ := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+	pat := createRandomPersonalAccessToken(t, user)
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	changing := time.Now()
+	updated, err := store.TxChangePassword(context.Background(), TxChangePasswordParams{
+		UserID:         user.ID,
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, updated.HashedPassword)
+	// tokens issued before the change are earlier than it, the ones issued once it returns are later
+	require.True(t, updated.PasswordChangedAt.After(changing))
+	require.True(t, time.Now().After(updated.PasswordChangedAt))
+
+	authorization, err := store.GetUserAuthorization(context.Background(), GetUserAuthorizationParams{ID: user.ID})
+	require.NoError(t, err)
+	require.True(t, updated.PasswordChangedAt.Equal(authorization.PasswordChangedAt))
+
+	got, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsBlocked)
+
+	_, err = store.GetPersonalAccessToken(context.Background(), pat.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.TxResetPassword(context.Background(), TxResetPasswordParams{
+		TokenHash:      util.HashSecretToken(token),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+)
+
+type TxCompleteWebJobParams struct {
+	JobID                  int64
+	UpdateWebContentParams UpdateWebContentParams
+}
+
+// TxCompleteWebJob stores the fetched content and removes the finished job
+func (store *SQLStore) TxCompleteWebJob(ctx context.Context, arg TxCompleteWebJobParams) (Web, error) {
+	var web Web
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		web, err = q.UpdateWebContent(ctx, arg.UpdateWebContentParams)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteWebJob(ctx, arg.JobID)
+	})
+
+	return web, err
+}
@@ -9,11 +9,15 @@ import (
 type TxCreateNoteParams struct {
 	CreateNoteParams CreateNoteParams
 	WebIds           []uuid.UUID
+	TagNames         []string
 }
 
 type TxCreateNoteResult struct {
 	Note Note
 	Webs []Web
+	Tags []Tag
+	// Revision is the first revision of the note
+	Revision NoteRevision
 }
 
 func (store *SQLStore) TxCreateNote(ctx context.Context, arg TxCreateNoteParams) (TxCreateNoteResult, error) {
@@ -42,6 +46,19 @@ func (store *SQLStore) TxCreateNote(ctx context.Context, arg TxCreateNoteParams)
 			}
 		}
 
+		result.Tags, err = addNoteTags(ctx, q, result.Note, arg.TagNames)
+		if err != nil {
+			return err
+		}
+
+		result.Revision, err = q.CreateNoteRevision(ctx, CreateNoteRevisionParams{
+			NoteID:   result.Note.ID,
+			AuthorID: result.Note.UserID,
+			Title:    result.Note.Title,
+			Content:  result.Note.Content,
+			IsPublic: result.Note.IsPublic,
+			WebIds:   arg.WebIds,
+		})
 		return err
 	})
 
@@ -27,6 +27,7 @@ func TestTxCreateNote(t *testing.T) {
 			Url:          util.RandomURL(),
 			Title:        util.RandomName(),
 			ThumbnailUrl: util.RandomThumbnailURL(),
+			Status:       WebStatusDone,
 		}
 		web, err := store.CreateWeb(context.Background(), arg)
 		require.NoError(t, err)
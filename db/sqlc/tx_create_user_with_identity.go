@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrIdentityAlreadyLinked is returned by TxCreateUserWithIdentity when the identity logged in as another user meanwhile
+var ErrIdentityAlreadyLinked = errors.New("identity is already linked to a user")
+
+type TxCreateUserWithIdentityParams struct {
+	Email          string
+	HashedPassword string
+	Issuer         string
+	Subject        string
+}
+
+// TxCreateUserWithIdentity registers the user of an identity that logs in for the first time
+func (store *SQLStore) TxCreateUserWithIdentity(ctx context.Context, arg TxCreateUserWithIdentityParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, CreateUserParams{
+			Email:          arg.Email,
+			HashedPassword: arg.HashedPassword,
+		})
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+				return ErrEmailAlreadyUsed
+			}
+			return err
+		}
+
+		_, err = q.CreateUserIdentity(ctx, CreateUserIdentityParams{
+			UserID:  user.ID,
+			Issuer:  arg.Issuer,
+			Subject: arg.Subject,
+			Email:   arg.Email,
+		})
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+				return ErrIdentityAlreadyLinked
+			}
+			return err
+		}
+		return nil
+	})
+
+	return user, err
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateUserWithIdentity(t *testing.T) {
+	store := NewStore(testDB)
+	arg := TxCreateUserWithIdentityParams{
+		Email:          util.RandomEmail(),
+		HashedPassword: util.RandomString(20),
+		Issuer:         util.RandomURL(),
+		Subject:        util.RandomString(12),
+	}
+
+	user, err := store.TxCreateUserWithIdentity(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Email, user.Email)
+
+	identity, err := testQueries.GetUserIdentityBySubject(context.Background(), GetUserIdentityBySubjectParams{
+		Issuer:  arg.Issuer,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, identity.UserID)
+	require.Equal(t, arg.Email, identity.Email)
+
+	// same identity with another email
+	other := arg
+	other.Email = util.RandomEmail()
+	_, err = store.TxCreateUserWithIdentity(context.Background(), other)
+	require.ErrorIs(t, err, ErrIdentityAlreadyLinked)
+	_, err = testQueries.GetUserByEmail(context.Background(), other.Email)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// same email with another identity
+	other = arg
+	other.Subject = util.RandomString(12)
+	_, err = store.TxCreateUserWithIdentity(context.Background(), other)
+	require.ErrorIs(t, err, ErrEmailAlreadyUsed)
+}
+
+func TestUserIdentity(t *testing.T) {
+	user := createRandomUser(t)
+	issuer := util.RandomURL()
+
+	identity, err := testQueries.CreateUserIdentity(context.Background(), CreateUserIdentityParams{
+		UserID:  user.ID,
+		Issuer:  issuer,
+		Subject: util.RandomString(12),
+		Email:   user.Email,
+	})
+	require.NoError(t, err)
+
+	identities, err := testQueries.ListUserIdentitiesByUserId(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	require.Equal(t, identity.ID, identities[0].ID)
+
+	err = testQueries.DeleteUserIdentity(context.Background(), identity.ID)
+	require.NoError(t, err)
+	_, err = testQueries.GetUserIdentity(context.Background(), identity.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestUseOidcState(t *testing.T) {
+	arg := CreateOidcStateParams{
+		StateHash:    util.HashSecretToken(util.RandomString(32)),
+		Nonce:        util.RandomString(16),
+		CodeVerifier: util.RandomString(43),
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+	_, err := testQueries.CreateOidcState(context.Background(), arg)
+	require.NoError(t, err)
+
+	state, err := testQueries.UseOidcState(context.Background(), arg.StateHash)
+	require.NoError(t, err)
+	require.Equal(t, arg.Nonce, state.Nonce)
+	require.Equal(t, uuid.NullUUID{}, state.UserID)
+
+	// a state can be used once
+	_, err = testQueries.UseOidcState(context.Background(), arg.StateHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.StateHash = util.HashSecretToken(util.RandomString(32))
+	arg.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = testQueries.CreateOidcState(context.Background(), arg)
+	require.NoError(t, err)
+	_, err = testQueries.UseOidcState(context.Background(), arg.StateHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
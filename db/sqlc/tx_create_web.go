@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+)
+
+type TxCreateWebParams struct {
+	CreateWebParams CreateWebParams
+	MaxAttempts     int32
+	TagNames        []string
+}
+
+type TxCreateWebResult struct {
+	Web  Web
+	Job  WebJob
+	Tags []Tag
+}
+
+// TxCreateWeb inserts a pending web together with the job that fetches it
+func (store *SQLStore) TxCreateWeb(ctx context.Context, arg TxCreateWebParams) (TxCreateWebResult, error) {
+	var result TxCreateWebResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Web, err = q.CreateWeb(ctx, arg.CreateWebParams)
+		if err != nil {
+			return err
+		}
+
+		result.Job, err = q.CreateWebJob(ctx, CreateWebJobParams{
+			WebID:       result.Web.ID,
+			MaxAttempts: arg.MaxAttempts,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Tags, err = addWebTags(ctx, q, result.Web, arg.TagNames)
+		return err
+	})
+
+	return result, err
+}
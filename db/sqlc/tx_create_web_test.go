@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxCreateWeb(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	url := util.RandomURL()
+	arg := TxCreateWebParams{
+		CreateWebParams: CreateWebParams{
+			UserID: user.ID,
+			Url:    url,
+			Title:  url,
+			Status: WebStatusPending,
+		},
+		MaxAttempts: 5,
+	}
+	result, err := store.TxCreateWeb(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, user.ID, result.Web.UserID)
+	require.Equal(t, WebStatusPending, result.Web.Status)
+	require.Equal(t, result.Web.ID, result.Job.WebID)
+	require.Equal(t, arg.MaxAttempts, result.Job.MaxAttempts)
+
+	web, err := store.TxCompleteWebJob(context.Background(), TxCompleteWebJobParams{
+		JobID: result.Job.ID,
+		UpdateWebContentParams: UpdateWebContentParams{
+			ID:           result.Web.ID,
+			Title:        util.RandomName(),
+			ThumbnailUrl: util.RandomThumbnailURL(),
+			Html:         util.RandomHTML(),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebStatusDone, web.Status)
+
+	_, err = store.GetWebJobByWebId(context.Background(), web.ID)
+	require.Error(t, err)
+}
+
+func TestTxFailAndRefetchWeb(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	url := util.RandomURL()
+	result, err := store.TxCreateWeb(context.Background(), TxCreateWebParams{
+		CreateWebParams: CreateWebParams{
+			UserID: user.ID,
+			Url:    url,
+			Title:  url,
+			Status: WebStatusPending,
+		},
+		MaxAttempts: 5,
+	})
+	require.NoError(t, err)
+
+	reason := util.RandomString(10)
+	web, err := store.TxFailWebJob(context.Background(), TxFailWebJobParams{
+		JobID:       result.Job.ID,
+		WebID:       result.Web.ID,
+		ErrorReason: reason,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebStatusFailed, web.Status)
+	require.Equal(t, reason, web.ErrorReason)
+
+	refetched, err := store.TxRefetchWeb(context.Background(), TxRefetchWebParams{
+		WebID:       web.ID,
+		MaxAttempts: 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebStatusPending, refetched.Web.Status)
+	require.Empty(t, refetched.Web.ErrorReason)
+	require.Equal(t, web.ID, refetched.Job.WebID)
+	require.Zero(t, refetched.Job.Attempts)
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type TxDeleteUserParams struct {
+	UserID uuid.UUID
+	// EmailHash is kept in user_tombstones after the user is gone
+	EmailHash string
+}
+
+// TxDeleteUser records the tombstone and deletes the user, webs, notes, tags and sessions go with it by cascade
+func (store *SQLStore) TxDeleteUser(ctx context.Context, arg TxDeleteUserParams) (UserTombstone, error) {
+	var tombstone UserTombstone
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		tombstone, err = q.CreateUserTombstone(ctx, arg.EmailHash)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUser(ctx, arg.UserID)
+	})
+
+	return tombstone, err
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxDeleteUser(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+	emailHash := util.HashSecretToken(user.Email)
+
+	tombstone, err := store.TxDeleteUser(context.Background(), TxDeleteUserParams{
+		UserID:    user.ID,
+		EmailHash: emailHash,
+	})
+	require.NoError(t, err)
+	require.Equal(t, emailHash, tombstone.EmailHash)
+	require.WithinDuration(t, time.Now(), tombstone.DeletedAt, time.Minute)
+
+	_, err = store.GetUser(context.Background(), user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetWeb(context.Background(), web.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.GetSession(context.Background(), session.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := store.GetUserTombstone(context.Background(), emailHash)
+	require.NoError(t, err)
+	require.Equal(t, tombstone.DeletedAt, got.DeletedAt)
+}
@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type TxFailWebJobParams struct {
+	JobID       int64
+	WebID       uuid.UUID
+	ErrorReason string
+}
+
+// TxFailWebJob marks the web as failed and removes the job that gave up on it
+func (store *SQLStore) TxFailWebJob(ctx context.Context, arg TxFailWebJobParams) (Web, error) {
+	var web Web
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		web, err = q.UpdateWebStatus(ctx, UpdateWebStatusParams{
+			ID:          arg.WebID,
+			Status:      WebStatusFailed,
+			ErrorReason: arg.ErrorReason,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteWebJob(ctx, arg.JobID)
+	})
+
+	return web, err
+}
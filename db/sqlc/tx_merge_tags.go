@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type TxMergeTagsParams struct {
+	SourceID uuid.UUID
+	TargetID uuid.UUID
+}
+
+// TxMergeTags moves every note and web of the source tag to the target tag, then deletes the source
+func (store *SQLStore) TxMergeTags(ctx context.Context, arg TxMergeTagsParams) (Tag, error) {
+	var target Tag
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.MergeNoteTags(ctx, MergeNoteTagsParams{
+			SourceID: arg.SourceID,
+			TargetID: arg.TargetID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.MergeWebTags(ctx, MergeWebTagsParams{
+			SourceID: arg.SourceID,
+			TargetID: arg.TargetID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteTag(ctx, arg.SourceID)
+		if err != nil {
+			return err
+		}
+
+		// counts were updated by the triggers
+		target, err = q.GetTag(ctx, arg.TargetID)
+		return err
+	})
+
+	return target, err
+}
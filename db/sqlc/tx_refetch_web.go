@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type TxRefetchWebParams struct {
+	WebID       uuid.UUID
+	MaxAttempts int32
+}
+
+type TxRefetchWebResult struct {
+	Web Web
+	Job WebJob
+}
+
+// TxRefetchWeb puts a web back into the pending state and (re)schedules its job
+func (store *SQLStore) TxRefetchWeb(ctx context.Context, arg TxRefetchWebParams) (TxRefetchWebResult, error) {
+	var result TxRefetchWebResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Web, err = q.UpdateWebStatus(ctx, UpdateWebStatusParams{
+			ID:          arg.WebID,
+			Status:      WebStatusPending,
+			ErrorReason: "",
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Job, err = q.ResetWebJob(ctx, ResetWebJobParams{
+			WebID:       arg.WebID,
+			MaxAttempts: arg.MaxAttempts,
+		})
+		return err
+	})
+
+	return result, err
+}
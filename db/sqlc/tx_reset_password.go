@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrInvalidPasswordResetToken is returned by TxResetPassword when the token is unknown, expired or already used
+var ErrInvalidPasswordResetToken = errors.New("password reset token is invalid or expired")
+
+type TxResetPasswordParams struct {
+	TokenHash      string
+	HashedPassword string
+}
+
+// TxResetPassword uses up the token, sets the new password and logs the user out everywhere
+func (store *SQLStore) TxResetPassword(ctx context.Context, arg TxResetPasswordParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		token, err := q.UsePasswordResetToken(ctx, arg.TokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvalidPasswordResetToken
+			}
+			return err
+		}
+
+		user, err = setPassword(ctx, q, token.UserID, arg.HashedPassword)
+		return err
+	})
+
+	return user, err
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordResetToken(t *testing.T, user User, expiresAt time.Time) (string, PasswordResetToken) {
+	token, err := util.NewSecretToken()
+	require.NoError(t, err)
+
+	arg := CreatePasswordResetTokenParams{
+		TokenHash: util.HashSecretToken(token),
+		UserID:    user.ID,
+		ExpiresAt: expiresAt,
+	}
+	resetToken, err := testQueries.CreatePasswordResetToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.TokenHash, resetToken.TokenHash)
+	require.Equal(t, arg.UserID, resetToken.UserID)
+	require.False(t, resetToken.UsedAt.Valid)
+	return token, resetToken
+}
+
+func TestTxResetPassword(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+	token, _ := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+	otherToken, _ := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	updated, err := store.TxResetPassword(context.Background(), TxResetPasswordParams{
+		TokenHash:      util.HashSecretToken(token),
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.Equal(t, hashedPassword, updated.HashedPassword)
+	require.WithinDuration(t, time.Now(), updated.PasswordChangedAt, time.Minute)
+
+	got, err := store.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, got.IsBlocked)
+
+	// the token and every other link sent before are used up
+	for _, used := range []string{token, otherToken} {
+		_, err = store.TxResetPassword(context.Background(), TxResetPasswordParams{
+			TokenHash:      util.HashSecretToken(used),
+			HashedPassword: hashedPassword,
+		})
+		require.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+	}
+}
+
+func TestTxResetPasswordExpiredToken(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	token, _ := createRandomPasswordResetToken(t, user, time.Now().Add(-time.Minute))
+
+	_, err := store.TxResetPassword(context.Background(), TxResetPasswordParams{
+		TokenHash:      util.HashSecretToken(token),
+		HashedPassword: util.RandomString(10),
+	})
+	require.ErrorIs(t, err, ErrInvalidPasswordResetToken)
+
+	got, err := store.GetUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.HashedPassword, got.HashedPassword)
+}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionRotated is returned by TxRotateSession when the session has already been replaced
+var ErrSessionRotated = errors.New("session has already been rotated")
+
+type TxRotateSessionParams struct {
+	SessionID uuid.UUID
+	// NewSession is the replacement, its user, family and parent are taken from the rotated session
+	NewSession CreateSessionParams
+}
+
+// TxRotateSession marks the session as rotated and creates its successor in the same family
+func (store *SQLStore) TxRotateSession(ctx context.Context, arg TxRotateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		rotated, err := q.RotateSession(ctx, arg.SessionID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSessionRotated
+			}
+			return err
+		}
+
+		newSession := arg.NewSession
+		newSession.UserID = rotated.UserID
+		newSession.FamilyID = rotated.FamilyID
+		newSession.ParentID = uuid.NullUUID{UUID: rotated.ID, Valid: true}
+		session, err = q.CreateSession(ctx, newSession)
+		return err
+	})
+
+	return session, err
+}
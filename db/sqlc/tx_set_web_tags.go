@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+)
+
+type TxSetWebTagsParams struct {
+	Web      Web
+	TagNames []string
+}
+
+// TxSetWebTags replaces the tags of a web
+func (store *SQLStore) TxSetWebTags(ctx context.Context, arg TxSetWebTagsParams) ([]Tag, error) {
+	var tags []Tag
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteWebTagsByWebId(ctx, arg.Web.ID)
+		if err != nil {
+			return err
+		}
+
+		tags, err = addWebTags(ctx, q, arg.Web, arg.TagNames)
+		return err
+	})
+
+	return tags, err
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrTwoFactorNotPending is returned by TxEnableTwoFactor when there is no enrollment waiting for confirmation
+var ErrTwoFactorNotPending = errors.New("two factor authentication is not being enrolled")
+
+type TxEnableTwoFactorParams struct {
+	UserID uuid.UUID
+	// Step is the step of the code that confirmed the enrollment, it can't be used again
+	Step               int64
+	RecoveryCodeHashes []string
+}
+
+// TxEnableTwoFactor turns 2fa on and replaces the recovery codes of the user
+func (store *SQLStore) TxEnableTwoFactor(ctx context.Context, arg TxEnableTwoFactorParams) (UserTwoFactor, error) {
+	var twoFactor UserTwoFactor
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		twoFactor, err = q.EnableUserTwoFactor(ctx, EnableUserTwoFactorParams{
+			UserID:       arg.UserID,
+			LastUsedStep: arg.Step,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTwoFactorNotPending
+			}
+			return err
+		}
+
+		err = q.DeleteRecoveryCodesByUserId(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		for _, hash := range arg.RecoveryCodeHashes {
+			_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+				CodeHash: hash,
+				UserID:   arg.UserID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return twoFactor, err
+}
+
+type TxDisableTwoFactorParams struct {
+	UserID uuid.UUID
+}
+
+// TxDisableTwoFactor turns 2fa off and drops the recovery codes of the user
+func (store *SQLStore) TxDisableTwoFactor(ctx context.Context, arg TxDisableTwoFactorParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteRecoveryCodesByUserId(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteUserTwoFactor(ctx, arg.UserID)
+	})
+}
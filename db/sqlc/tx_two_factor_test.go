@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/inkclip/backend/totp"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserTwoFactor(t *testing.T, user User) UserTwoFactor {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	twoFactor, err := testQueries.UpsertUserTwoFactor(context.Background(), UpsertUserTwoFactorParams{
+		UserID:     user.ID,
+		TotpSecret: secret,
+	})
+	require.NoError(t, err)
+	require.Equal(t, secret, twoFactor.TotpSecret)
+	require.False(t, twoFactor.EnabledAt.Valid)
+	return twoFactor
+}
+
+func TestTxEnableTwoFactor(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomUserTwoFactor(t, user)
+
+	hashes := []string{util.HashSecretToken(util.RandomString(16)), util.HashSecretToken(util.RandomString(16))}
+	twoFactor, err := store.TxEnableTwoFactor(context.Background(), TxEnableTwoFactorParams{
+		UserID:             user.ID,
+		Step:               10,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+	require.True(t, twoFactor.EnabledAt.Valid)
+	require.Equal(t, int64(10), twoFactor.LastUsedStep)
+
+	_, err = store.TxEnableTwoFactor(context.Background(), TxEnableTwoFactorParams{UserID: user.ID, Step: 11})
+	require.ErrorIs(t, err, ErrTwoFactorNotPending)
+
+	// the secret can't be replaced once enabled
+	_, err = testQueries.UpsertUserTwoFactor(context.Background(), UpsertUserTwoFactorParams{
+		UserID:     user.ID,
+		TotpSecret: "AAAA",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// a code is used once
+	_, err = testQueries.UseTotpStep(context.Background(), UseTotpStepParams{UserID: user.ID, LastUsedStep: 10})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.UseTotpStep(context.Background(), UseTotpStepParams{UserID: user.ID, LastUsedStep: 11})
+	require.NoError(t, err)
+
+	arg := UseRecoveryCodeParams{CodeHash: hashes[0], UserID: user.ID}
+	_, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	_, err = testQueries.UseRecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTxDisableTwoFactor(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomUserTwoFactor(t, user)
+
+	hash := util.HashSecretToken(util.RandomString(16))
+	_, err := store.TxEnableTwoFactor(context.Background(), TxEnableTwoFactorParams{
+		UserID:             user.ID,
+		Step:               1,
+		RecoveryCodeHashes: []string{hash},
+	})
+	require.NoError(t, err)
+
+	err = store.TxDisableTwoFactor(context.Background(), TxDisableTwoFactorParams{UserID: user.ID})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetUserTwoFactor(context.Background(), user.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{CodeHash: hash, UserID: user.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -2,18 +2,29 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/google/uuid"
 )
 
+// ErrNoteVersionMismatch is returned by TxUpdateNote when UpdateNoteParams.ExpectedVersion is not the current version
+var ErrNoteVersionMismatch = errors.New("note has been updated since the expected version")
+
 type TxUpdateNoteParams struct {
 	UpdateNoteParams UpdateNoteParams
 	WebIds           []uuid.UUID
+	TagNames         []string
+	// AuthorID is the user making the change, recorded in the revision
+	AuthorID uuid.UUID
 }
 
 type TxUpdateNoteResult struct {
 	Note Note
 	Webs []Web
+	Tags []Tag
+	// Revision is the revision appended by this update
+	Revision NoteRevision
 }
 
 func (store *SQLStore) TxUpdateNote(ctx context.Context, arg TxUpdateNoteParams) (TxUpdateNoteResult, error) {
@@ -23,6 +34,13 @@ func (store *SQLStore) TxUpdateNote(ctx context.Context, arg TxUpdateNoteParams)
 		var err error
 
 		result.Note, err = q.UpdateNote(ctx, arg.UpdateNoteParams)
+		if err == sql.ErrNoRows && arg.UpdateNoteParams.ExpectedVersion.Valid {
+			// tell a stale version apart from a missing note
+			if _, err := q.GetNote(ctx, arg.UpdateNoteParams.ID); err != nil {
+				return err
+			}
+			return ErrNoteVersionMismatch
+		}
 		if err != nil {
 			return err
 		}
@@ -58,6 +76,23 @@ func (store *SQLStore) TxUpdateNote(ctx context.Context, arg TxUpdateNoteParams)
 			}
 		}
 
+		err = q.DeleteNoteTagsByNoteId(ctx, result.Note.ID)
+		if err != nil {
+			return err
+		}
+		result.Tags, err = addNoteTags(ctx, q, result.Note, arg.TagNames)
+		if err != nil {
+			return err
+		}
+
+		result.Revision, err = q.CreateNoteRevision(ctx, CreateNoteRevisionParams{
+			NoteID:   result.Note.ID,
+			AuthorID: arg.AuthorID,
+			Title:    result.Note.Title,
+			Content:  result.Note.Content,
+			IsPublic: result.Note.IsPublic,
+			WebIds:   arg.WebIds,
+		})
 		return err
 	})
 
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/google/uuid"
@@ -27,6 +28,7 @@ func TestTxUpdateNote(t *testing.T) {
 			Url:          util.RandomURL(),
 			Title:        util.RandomName(),
 			ThumbnailUrl: util.RandomThumbnailURL(),
+			Status:       WebStatusDone,
 		}
 		web, err := store.CreateWeb(context.Background(), arg)
 		require.NoError(t, err)
@@ -55,6 +57,7 @@ func TestTxUpdateNote(t *testing.T) {
 			Url:          util.RandomURL(),
 			Title:        util.RandomName(),
 			ThumbnailUrl: util.RandomThumbnailURL(),
+			Status:       WebStatusDone,
 		}
 		web, err := store.CreateWeb(context.Background(), arg)
 		require.NoError(t, err)
@@ -63,6 +66,7 @@ func TestTxUpdateNote(t *testing.T) {
 	updateNoteArg := TxUpdateNoteParams{
 		UpdateNoteParams: updateNoteParams,
 		WebIds:           updateWebIDs,
+		AuthorID:         user.ID,
 	}
 	result, err := store.TxUpdateNote(context.Background(), updateNoteArg)
 	require.NoError(t, err)
@@ -74,6 +78,10 @@ func TestTxUpdateNote(t *testing.T) {
 	require.Equal(t, result.Note.Content, updateNoteParams.Content)
 	require.Equal(t, result.Note.UserID, createNoteResult.Note.UserID)
 
+	require.Equal(t, createNoteResult.Revision.Revision+1, result.Revision.Revision)
+	require.Equal(t, user.ID, result.Revision.AuthorID)
+	require.ElementsMatch(t, updateWebIDs, result.Revision.WebIds)
+
 	updatedNote, err := store.GetNote(context.Background(), createNoteResult.Note.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, updatedNote)
@@ -84,3 +92,37 @@ func TestTxUpdateNote(t *testing.T) {
 	require.Equal(t, updatedNote.Content, updateNoteParams.Content)
 	require.Equal(t, updatedNote.UserID, createNoteResult.Note.UserID)
 }
+
+func TestTxUpdateNoteExpectedVersion(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	note := createRandomNote(t, user)
+	require.Equal(t, int32(1), note.Version)
+
+	arg := TxUpdateNoteParams{
+		UpdateNoteParams: UpdateNoteParams{
+			ID:              note.ID,
+			Title:           util.RandomString(6),
+			Content:         util.RandomString(6),
+			ExpectedVersion: sql.NullInt32{Int32: note.Version, Valid: true},
+		},
+		AuthorID: user.ID,
+	}
+	result, err := store.TxUpdateNote(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, note.Version+1, result.Note.Version)
+	require.True(t, result.Note.UpdatedAt.After(note.UpdatedAt) || result.Note.UpdatedAt.Equal(note.UpdatedAt))
+
+	// the same expected version is stale now
+	arg.UpdateNoteParams.Title = util.RandomString(6)
+	_, err = store.TxUpdateNote(context.Background(), arg)
+	require.ErrorIs(t, err, ErrNoteVersionMismatch)
+
+	current, err := store.GetNote(context.Background(), note.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Note, current)
+
+	arg.UpdateNoteParams.ID = uuid.New()
+	_, err = store.TxUpdateNote(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
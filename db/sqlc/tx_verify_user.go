@@ -0,0 +1,22 @@
+package db
+
+import (
+	"context"
+)
+
+// TxVerifyUser creates the user of a verified registration and removes every pending registration of the email
+func (store *SQLStore) TxVerifyUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return q.DeleteTemporaryUsersByEmail(ctx, user.Email)
+	})
+
+	return user, err
+}
@@ -0,0 +1,28 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxVerifyUser(t *testing.T) {
+	store := NewStore(testDB)
+	tmpUser := createRandomTemporaryUser(t)
+
+	user, err := store.TxVerifyUser(context.Background(), CreateUserParams{
+		Email:          tmpUser.Email,
+		HashedPassword: tmpUser.HashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tmpUser.Email, user.Email)
+
+	_, err = store.GetTemporaryUserByEmailAndToken(context.Background(), GetTemporaryUserByEmailAndTokenParams{
+		Email: tmpUser.Email,
+		Token: tmpUser.Token,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+}
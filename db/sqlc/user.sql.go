@@ -7,6 +7,8 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,7 +20,7 @@ INSERT INTO users (
 ) VALUES (
   $1, $2
 )
-RETURNING id, email, hashed_password, password_changed_at, created_at
+RETURNING id, email, hashed_password, password_changed_at, created_at, role, blocked_at
 `
 
 type CreateUserParams struct {
@@ -35,6 +37,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.HashedPassword,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
 	)
 	return i, err
 }
@@ -49,8 +53,44 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getSystemStats = `-- name: GetSystemStats :one
+SELECT
+  (SELECT count(*) FROM users) AS users,
+  (SELECT count(*) FROM users WHERE blocked_at IS NOT NULL) AS blocked_users,
+  (SELECT count(*) FROM temporary_users) AS pending_registrations,
+  (SELECT count(*) FROM sessions WHERE is_blocked = false AND rotated_at IS NULL AND expires_at > now()) AS active_sessions,
+  (SELECT count(*) FROM notes) AS notes,
+  (SELECT count(*) FROM webs) AS webs,
+  (SELECT count(*) FROM web_jobs) AS pending_web_jobs
+`
+
+type GetSystemStatsRow struct {
+	Users                int64 `json:"users"`
+	BlockedUsers         int64 `json:"blocked_users"`
+	PendingRegistrations int64 `json:"pending_registrations"`
+	ActiveSessions       int64 `json:"active_sessions"`
+	Notes                int64 `json:"notes"`
+	Webs                 int64 `json:"webs"`
+	PendingWebJobs       int64 `json:"pending_web_jobs"`
+}
+
+func (q *Queries) GetSystemStats(ctx context.Context) (GetSystemStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getSystemStats)
+	var i GetSystemStatsRow
+	err := row.Scan(
+		&i.Users,
+		&i.BlockedUsers,
+		&i.PendingRegistrations,
+		&i.ActiveSessions,
+		&i.Notes,
+		&i.Webs,
+		&i.PendingWebJobs,
+	)
+	return i, err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, email, hashed_password, password_changed_at, created_at FROM users
+SELECT id, email, hashed_password, password_changed_at, created_at, role, blocked_at FROM users
 WHERE id = $1 LIMIT 1
 `
 
@@ -63,12 +103,47 @@ func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.HashedPassword,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
+	)
+	return i, err
+}
+
+const getUserAuthorization = `-- name: GetUserAuthorization :one
+SELECT u.password_changed_at, u.role, u.blocked_at, coalesce(s.is_blocked, false)::bool AS session_blocked
+FROM users u
+LEFT JOIN sessions s ON s.id = $1 AND s.user_id = u.id
+WHERE u.id = $2 LIMIT 1
+`
+
+type GetUserAuthorizationParams struct {
+	SessionID uuid.UUID `json:"session_id"`
+	ID        uuid.UUID `json:"id"`
+}
+
+type GetUserAuthorizationRow struct {
+	PasswordChangedAt time.Time    `json:"password_changed_at"`
+	Role              string       `json:"role"`
+	BlockedAt         sql.NullTime `json:"blocked_at"`
+	SessionBlocked    bool         `json:"session_blocked"`
+}
+
+// what every authenticated request is checked against, session_blocked is false when there is no such session
+// as for personal access tokens
+func (q *Queries) GetUserAuthorization(ctx context.Context, arg GetUserAuthorizationParams) (GetUserAuthorizationRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserAuthorization, arg.SessionID, arg.ID)
+	var i GetUserAuthorizationRow
+	err := row.Scan(
+		&i.PasswordChangedAt,
+		&i.Role,
+		&i.BlockedAt,
+		&i.SessionBlocked,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, hashed_password, password_changed_at, created_at FROM users
+SELECT id, email, hashed_password, password_changed_at, created_at, role, blocked_at FROM users
 WHERE email = $1 LIMIT 1
 `
 
@@ -81,24 +156,29 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.HashedPassword,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
 	)
 	return i, err
 }
 
-const listUsers = `-- name: ListUsers :many
-SELECT id, email, hashed_password, password_changed_at, created_at FROM users
-ORDER BY id
+const searchUsers = `-- name: SearchUsers :many
+SELECT id, email, hashed_password, password_changed_at, created_at, role, blocked_at FROM users
+WHERE email ILIKE '%' || $3::varchar || '%'
+ORDER BY created_at DESC, id
 LIMIT $1
 OFFSET $2
 `
 
-type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type SearchUsersParams struct {
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	Email  string `json:"email"`
 }
 
-func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+// email is matched as a substring, an empty one matches every user
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers, arg.Limit, arg.Offset, arg.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -112,6 +192,8 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.HashedPassword,
 			&i.PasswordChangedAt,
 			&i.CreatedAt,
+			&i.Role,
+			&i.BlockedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -130,7 +212,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET email = $2
 WHERE id = $1
-RETURNING id, email, hashed_password, password_changed_at, created_at
+RETURNING id, email, hashed_password, password_changed_at, created_at, role, blocked_at
 `
 
 type UpdateUserParams struct {
@@ -147,6 +229,92 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.HashedPassword,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
+	)
+	return i, err
+}
+
+const updateUserBlockedAt = `-- name: UpdateUserBlockedAt :one
+UPDATE users
+SET blocked_at = $2
+WHERE id = $1
+RETURNING id, email, hashed_password, password_changed_at, created_at, role, blocked_at
+`
+
+type UpdateUserBlockedAtParams struct {
+	ID        uuid.UUID    `json:"id"`
+	BlockedAt sql.NullTime `json:"blocked_at"`
+}
+
+func (q *Queries) UpdateUserBlockedAt(ctx context.Context, arg UpdateUserBlockedAtParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserBlockedAt, arg.ID, arg.BlockedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.HashedPassword,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE users
+SET
+  hashed_password = $2,
+  password_changed_at = $3
+WHERE id = $1
+RETURNING id, email, hashed_password, password_changed_at, created_at, role, blocked_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID                uuid.UUID `json:"id"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword, arg.PasswordChangedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.HashedPassword,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING id, email, hashed_password, password_changed_at, created_at, role, blocked_at
+`
+
+type UpdateUserRoleParams struct {
+	ID   uuid.UUID `json:"id"`
+	Role string    `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.HashedPassword,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.BlockedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,139 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: user_identity.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const createUserIdentity = `-- name: CreateUserIdentity :one
+INSERT INTO user_identities (
+  user_id,
+  issuer,
+  subject,
+  email
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, user_id, issuer, subject, email, created_at
+`
+
+type CreateUserIdentityParams struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Issuer  string    `json:"issuer"`
+	Subject string    `json:"subject"`
+	Email   string    `json:"email"`
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, createUserIdentity,
+		arg.UserID,
+		arg.Issuer,
+		arg.Subject,
+		arg.Email,
+	)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteUserIdentity = `-- name: DeleteUserIdentity :exec
+DELETE FROM user_identities
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUserIdentity(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserIdentity, id)
+	return err
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT id, user_id, issuer, subject, email, created_at FROM user_identities
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserIdentity(ctx context.Context, id uuid.UUID) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, getUserIdentity, id)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserIdentityBySubject = `-- name: GetUserIdentityBySubject :one
+SELECT id, user_id, issuer, subject, email, created_at FROM user_identities
+WHERE issuer = $1 AND subject = $2 LIMIT 1
+`
+
+type GetUserIdentityBySubjectParams struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetUserIdentityBySubject(ctx context.Context, arg GetUserIdentityBySubjectParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, getUserIdentityBySubject, arg.Issuer, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Issuer,
+		&i.Subject,
+		&i.Email,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listUserIdentitiesByUserId = `-- name: ListUserIdentitiesByUserId :many
+SELECT id, user_id, issuer, subject, email, created_at FROM user_identities
+WHERE user_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) ListUserIdentitiesByUserId(ctx context.Context, userID uuid.UUID) ([]UserIdentity, error) {
+	rows, err := q.db.QueryContext(ctx, listUserIdentitiesByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserIdentity{}
+	for rows.Next() {
+		var i UserIdentity
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Issuer,
+			&i.Subject,
+			&i.Email,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
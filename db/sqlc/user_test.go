@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 	"time"
 
@@ -76,21 +77,47 @@ func TestDeleteUser(t *testing.T) {
 	require.Empty(t, user2)
 }
 
-func TestListUsers(t *testing.T) {
-	for i := 0; i < 10; i++ {
-		createRandomUser(t)
-	}
+func TestSearchUsers(t *testing.T) {
+	user := createRandomUser(t)
+	createRandomUser(t)
 
-	arg := ListUsersParams{
+	users, err := testQueries.SearchUsers(context.Background(), SearchUsersParams{
 		Limit:  5,
-		Offset: 5,
-	}
+		Offset: 0,
+		Email:  strings.ToUpper(user.Email[:len(user.Email)-4]),
+	})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, user.ID, users[0].ID)
 
-	users, err := testQueries.ListUsers(context.Background(), arg)
+	users, err = testQueries.SearchUsers(context.Background(), SearchUsersParams{Limit: 2})
 	require.NoError(t, err)
-	require.Len(t, users, 5)
+	require.Len(t, users, 2)
+}
 
-	for _, user := range users {
-		require.NotEmpty(t, user)
-	}
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+	require.Equal(t, "user", user1.Role)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		ID:   user1.ID,
+		Role: "admin",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "admin", user2.Role)
+
+	authorization, err := testQueries.GetUserAuthorization(context.Background(), GetUserAuthorizationParams{ID: user1.ID})
+	require.NoError(t, err)
+	require.Equal(t, "admin", authorization.Role)
+	require.False(t, authorization.BlockedAt.Valid)
+	require.False(t, authorization.SessionBlocked)
+}
+
+func TestGetSystemStats(t *testing.T) {
+	createRandomUser(t)
+
+	stats, err := testQueries.GetSystemStats(context.Background())
+	require.NoError(t, err)
+	require.Positive(t, stats.Users)
+	require.LessOrEqual(t, stats.BlockedUsers, stats.Users)
 }
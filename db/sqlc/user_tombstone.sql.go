@@ -0,0 +1,39 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: user_tombstone.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createUserTombstone = `-- name: CreateUserTombstone :one
+INSERT INTO user_tombstones (
+  email_hash
+) VALUES (
+  $1
+)
+ON CONFLICT (email_hash) DO UPDATE SET deleted_at = now()
+RETURNING email_hash, deleted_at
+`
+
+func (q *Queries) CreateUserTombstone(ctx context.Context, emailHash string) (UserTombstone, error) {
+	row := q.db.QueryRowContext(ctx, createUserTombstone, emailHash)
+	var i UserTombstone
+	err := row.Scan(&i.EmailHash, &i.DeletedAt)
+	return i, err
+}
+
+const getUserTombstone = `-- name: GetUserTombstone :one
+SELECT email_hash, deleted_at FROM user_tombstones
+WHERE email_hash = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserTombstone(ctx context.Context, emailHash string) (UserTombstone, error) {
+	row := q.db.QueryRowContext(ctx, getUserTombstone, emailHash)
+	var i UserTombstone
+	err := row.Scan(&i.EmailHash, &i.DeletedAt)
+	return i, err
+}
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,11 +20,12 @@ INSERT INTO webs (
   url,
   title,
   thumbnail_url,
-  html
+  html,
+  status
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6
 )
-RETURNING id, user_id, url, title, thumbnail_url, html, created_at
+RETURNING id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes
 `
 
 type CreateWebParams struct {
@@ -32,6 +34,7 @@ type CreateWebParams struct {
 	Title        string    `json:"title"`
 	ThumbnailUrl string    `json:"thumbnail_url"`
 	Html         string    `json:"html"`
+	Status       string    `json:"status"`
 }
 
 func (q *Queries) CreateWeb(ctx context.Context, arg CreateWebParams) (Web, error) {
@@ -41,6 +44,7 @@ func (q *Queries) CreateWeb(ctx context.Context, arg CreateWebParams) (Web, erro
 		arg.Title,
 		arg.ThumbnailUrl,
 		arg.Html,
+		arg.Status,
 	)
 	var i Web
 	err := row.Scan(
@@ -51,6 +55,16 @@ func (q *Queries) CreateWeb(ctx context.Context, arg CreateWebParams) (Web, erro
 		&i.ThumbnailUrl,
 		&i.Html,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ErrorReason,
+		&i.ArticleText,
+		&i.ArticleHtml,
+		&i.ArticleMarkdown,
+		&i.Author,
+		&i.SiteName,
+		&i.Description,
+		&i.PublishedAt,
+		&i.ReadingMinutes,
 	)
 	return i, err
 }
@@ -66,7 +80,7 @@ func (q *Queries) DeleteWeb(ctx context.Context, id uuid.UUID) error {
 }
 
 const getWeb = `-- name: GetWeb :one
-SELECT id, user_id, url, title, thumbnail_url, html, created_at FROM webs
+SELECT id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes FROM webs
 WHERE id = $1 LIMIT 1
 `
 
@@ -81,12 +95,75 @@ func (q *Queries) GetWeb(ctx context.Context, id uuid.UUID) (Web, error) {
 		&i.ThumbnailUrl,
 		&i.Html,
 		&i.CreatedAt,
+		&i.Status,
+		&i.ErrorReason,
+		&i.ArticleText,
+		&i.ArticleHtml,
+		&i.ArticleMarkdown,
+		&i.Author,
+		&i.SiteName,
+		&i.Description,
+		&i.PublishedAt,
+		&i.ReadingMinutes,
 	)
 	return i, err
 }
 
+const listDoneWebsAfterId = `-- name: ListDoneWebsAfterId :many
+SELECT id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes FROM webs
+WHERE status = 'done' AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListDoneWebsAfterIdParams struct {
+	ID    uuid.UUID `json:"id"`
+	Limit int32     `json:"limit"`
+}
+
+func (q *Queries) ListDoneWebsAfterId(ctx context.Context, arg ListDoneWebsAfterIdParams) ([]Web, error) {
+	rows, err := q.db.QueryContext(ctx, listDoneWebsAfterId, arg.ID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Web{}
+	for rows.Next() {
+		var i Web
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Url,
+			&i.Title,
+			&i.ThumbnailUrl,
+			&i.Html,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ErrorReason,
+			&i.ArticleText,
+			&i.ArticleHtml,
+			&i.ArticleMarkdown,
+			&i.Author,
+			&i.SiteName,
+			&i.Description,
+			&i.PublishedAt,
+			&i.ReadingMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listWebByNoteId = `-- name: ListWebByNoteId :many
-SELECT webs.id, webs.user_id, webs.url, webs.title, webs.thumbnail_url, webs.html, webs.created_at FROM webs
+SELECT webs.id, webs.user_id, webs.url, webs.title, webs.thumbnail_url, webs.html, webs.created_at, webs.status, webs.error_reason, webs.article_text, webs.article_html, webs.article_markdown, webs.author, webs.site_name, webs.description, webs.published_at, webs.reading_minutes FROM webs
 INNER JOIN note_webs ON webs.id = note_webs.web_id
 WHERE note_webs.note_id = $1
 `
@@ -108,6 +185,16 @@ func (q *Queries) ListWebByNoteId(ctx context.Context, noteID uuid.UUID) ([]Web,
 			&i.ThumbnailUrl,
 			&i.Html,
 			&i.CreatedAt,
+			&i.Status,
+			&i.ErrorReason,
+			&i.ArticleText,
+			&i.ArticleHtml,
+			&i.ArticleMarkdown,
+			&i.Author,
+			&i.SiteName,
+			&i.Description,
+			&i.PublishedAt,
+			&i.ReadingMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -123,20 +210,30 @@ func (q *Queries) ListWebByNoteId(ctx context.Context, noteID uuid.UUID) ([]Web,
 }
 
 const listWebByNoteIds = `-- name: ListWebByNoteIds :many
-SELECT webs.id, webs.user_id, webs.url, webs.title, webs.thumbnail_url, webs.html, webs.created_at, note_webs.note_id FROM webs
+SELECT webs.id, webs.user_id, webs.url, webs.title, webs.thumbnail_url, webs.html, webs.created_at, webs.status, webs.error_reason, webs.article_text, webs.article_html, webs.article_markdown, webs.author, webs.site_name, webs.description, webs.published_at, webs.reading_minutes, note_webs.note_id FROM webs
 INNER JOIN note_webs ON webs.id = note_webs.web_id
 WHERE note_webs.note_id = ANY($1::uuid[])
 `
 
 type ListWebByNoteIdsRow struct {
-	ID           uuid.UUID `json:"id"`
-	UserID       uuid.UUID `json:"user_id"`
-	Url          string    `json:"url"`
-	Title        string    `json:"title"`
-	ThumbnailUrl string    `json:"thumbnail_url"`
-	Html         string    `json:"html"`
-	CreatedAt    time.Time `json:"created_at"`
-	NoteID       uuid.UUID `json:"note_id"`
+	ID              uuid.UUID    `json:"id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	Url             string       `json:"url"`
+	Title           string       `json:"title"`
+	ThumbnailUrl    string       `json:"thumbnail_url"`
+	Html            string       `json:"html"`
+	CreatedAt       time.Time    `json:"created_at"`
+	Status          string       `json:"status"`
+	ErrorReason     string       `json:"error_reason"`
+	ArticleText     string       `json:"article_text"`
+	ArticleHtml     string       `json:"article_html"`
+	ArticleMarkdown string       `json:"article_markdown"`
+	Author          string       `json:"author"`
+	SiteName        string       `json:"site_name"`
+	Description     string       `json:"description"`
+	PublishedAt     sql.NullTime `json:"published_at"`
+	ReadingMinutes  int32        `json:"reading_minutes"`
+	NoteID          uuid.UUID    `json:"note_id"`
 }
 
 func (q *Queries) ListWebByNoteIds(ctx context.Context, ids []uuid.UUID) ([]ListWebByNoteIdsRow, error) {
@@ -156,6 +253,16 @@ func (q *Queries) ListWebByNoteIds(ctx context.Context, ids []uuid.UUID) ([]List
 			&i.ThumbnailUrl,
 			&i.Html,
 			&i.CreatedAt,
+			&i.Status,
+			&i.ErrorReason,
+			&i.ArticleText,
+			&i.ArticleHtml,
+			&i.ArticleMarkdown,
+			&i.Author,
+			&i.SiteName,
+			&i.Description,
+			&i.PublishedAt,
+			&i.ReadingMinutes,
 			&i.NoteID,
 		); err != nil {
 			return nil, err
@@ -172,20 +279,91 @@ func (q *Queries) ListWebByNoteIds(ctx context.Context, ids []uuid.UUID) ([]List
 }
 
 const listWebsByUserId = `-- name: ListWebsByUserId :many
-SELECT id, user_id, url, title, thumbnail_url, html, created_at FROM webs
-WHERE user_id = $1
-LIMIT $2
+SELECT id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes FROM webs
+WHERE webs.user_id = $1
+  AND (
+    coalesce(cardinality($2::varchar[]), 0) = 0
+    OR webs.id IN (
+      SELECT web_tags.web_id FROM web_tags
+      INNER JOIN tags ON tags.id = web_tags.tag_id
+      WHERE tags.user_id = $1 AND tags.name = ANY($2::varchar[])
+      GROUP BY web_tags.web_id
+      HAVING count(*) = cardinality($2::varchar[])
+    )
+  )
+LIMIT $4
 OFFSET $3
 `
 
 type ListWebsByUserIdParams struct {
 	UserID uuid.UUID `json:"user_id"`
-	Limit  int32     `json:"limit"`
+	Tags   []string  `json:"tags"`
 	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
 }
 
+// only webs that have every tag in tags are listed, tags is ignored when empty
 func (q *Queries) ListWebsByUserId(ctx context.Context, arg ListWebsByUserIdParams) ([]Web, error) {
-	rows, err := q.db.QueryContext(ctx, listWebsByUserId, arg.UserID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listWebsByUserId,
+		arg.UserID,
+		pq.Array(arg.Tags),
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Web{}
+	for rows.Next() {
+		var i Web
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Url,
+			&i.Title,
+			&i.ThumbnailUrl,
+			&i.Html,
+			&i.CreatedAt,
+			&i.Status,
+			&i.ErrorReason,
+			&i.ArticleText,
+			&i.ArticleHtml,
+			&i.ArticleMarkdown,
+			&i.Author,
+			&i.SiteName,
+			&i.Description,
+			&i.PublishedAt,
+			&i.ReadingMinutes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebsByUserIdAfterId = `-- name: ListWebsByUserIdAfterId :many
+SELECT id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes FROM webs
+WHERE user_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListWebsByUserIdAfterIdParams struct {
+	UserID uuid.UUID `json:"user_id"`
+	ID     uuid.UUID `json:"id"`
+	Limit  int32     `json:"limit"`
+}
+
+func (q *Queries) ListWebsByUserIdAfterId(ctx context.Context, arg ListWebsByUserIdAfterIdParams) ([]Web, error) {
+	rows, err := q.db.QueryContext(ctx, listWebsByUserIdAfterId, arg.UserID, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -201,6 +379,16 @@ func (q *Queries) ListWebsByUserId(ctx context.Context, arg ListWebsByUserIdPara
 			&i.ThumbnailUrl,
 			&i.Html,
 			&i.CreatedAt,
+			&i.Status,
+			&i.ErrorReason,
+			&i.ArticleText,
+			&i.ArticleHtml,
+			&i.ArticleMarkdown,
+			&i.Author,
+			&i.SiteName,
+			&i.Description,
+			&i.PublishedAt,
+			&i.ReadingMinutes,
 		); err != nil {
 			return nil, err
 		}
@@ -214,3 +402,178 @@ func (q *Queries) ListWebsByUserId(ctx context.Context, arg ListWebsByUserIdPara
 	}
 	return items, nil
 }
+
+const updateWebArticle = `-- name: UpdateWebArticle :one
+UPDATE webs
+SET
+  article_text = $2,
+  article_html = $3,
+  article_markdown = $4,
+  author = $5,
+  site_name = $6,
+  description = $7,
+  published_at = $8,
+  reading_minutes = $9
+WHERE id = $1
+RETURNING id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes
+`
+
+type UpdateWebArticleParams struct {
+	ID              uuid.UUID    `json:"id"`
+	ArticleText     string       `json:"article_text"`
+	ArticleHtml     string       `json:"article_html"`
+	ArticleMarkdown string       `json:"article_markdown"`
+	Author          string       `json:"author"`
+	SiteName        string       `json:"site_name"`
+	Description     string       `json:"description"`
+	PublishedAt     sql.NullTime `json:"published_at"`
+	ReadingMinutes  int32        `json:"reading_minutes"`
+}
+
+func (q *Queries) UpdateWebArticle(ctx context.Context, arg UpdateWebArticleParams) (Web, error) {
+	row := q.db.QueryRowContext(ctx, updateWebArticle,
+		arg.ID,
+		arg.ArticleText,
+		arg.ArticleHtml,
+		arg.ArticleMarkdown,
+		arg.Author,
+		arg.SiteName,
+		arg.Description,
+		arg.PublishedAt,
+		arg.ReadingMinutes,
+	)
+	var i Web
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.Title,
+		&i.ThumbnailUrl,
+		&i.Html,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ErrorReason,
+		&i.ArticleText,
+		&i.ArticleHtml,
+		&i.ArticleMarkdown,
+		&i.Author,
+		&i.SiteName,
+		&i.Description,
+		&i.PublishedAt,
+		&i.ReadingMinutes,
+	)
+	return i, err
+}
+
+const updateWebContent = `-- name: UpdateWebContent :one
+UPDATE webs
+SET
+  title = $2,
+  thumbnail_url = $3,
+  html = $4,
+  article_text = $5,
+  article_html = $6,
+  article_markdown = $7,
+  author = $8,
+  site_name = $9,
+  description = $10,
+  published_at = $11,
+  reading_minutes = $12,
+  status = 'done',
+  error_reason = ''
+WHERE id = $1
+RETURNING id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes
+`
+
+type UpdateWebContentParams struct {
+	ID              uuid.UUID    `json:"id"`
+	Title           string       `json:"title"`
+	ThumbnailUrl    string       `json:"thumbnail_url"`
+	Html            string       `json:"html"`
+	ArticleText     string       `json:"article_text"`
+	ArticleHtml     string       `json:"article_html"`
+	ArticleMarkdown string       `json:"article_markdown"`
+	Author          string       `json:"author"`
+	SiteName        string       `json:"site_name"`
+	Description     string       `json:"description"`
+	PublishedAt     sql.NullTime `json:"published_at"`
+	ReadingMinutes  int32        `json:"reading_minutes"`
+}
+
+func (q *Queries) UpdateWebContent(ctx context.Context, arg UpdateWebContentParams) (Web, error) {
+	row := q.db.QueryRowContext(ctx, updateWebContent,
+		arg.ID,
+		arg.Title,
+		arg.ThumbnailUrl,
+		arg.Html,
+		arg.ArticleText,
+		arg.ArticleHtml,
+		arg.ArticleMarkdown,
+		arg.Author,
+		arg.SiteName,
+		arg.Description,
+		arg.PublishedAt,
+		arg.ReadingMinutes,
+	)
+	var i Web
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.Title,
+		&i.ThumbnailUrl,
+		&i.Html,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ErrorReason,
+		&i.ArticleText,
+		&i.ArticleHtml,
+		&i.ArticleMarkdown,
+		&i.Author,
+		&i.SiteName,
+		&i.Description,
+		&i.PublishedAt,
+		&i.ReadingMinutes,
+	)
+	return i, err
+}
+
+const updateWebStatus = `-- name: UpdateWebStatus :one
+UPDATE webs
+SET
+  status = $2,
+  error_reason = $3
+WHERE id = $1
+RETURNING id, user_id, url, title, thumbnail_url, html, created_at, status, error_reason, article_text, article_html, article_markdown, author, site_name, description, published_at, reading_minutes
+`
+
+type UpdateWebStatusParams struct {
+	ID          uuid.UUID `json:"id"`
+	Status      string    `json:"status"`
+	ErrorReason string    `json:"error_reason"`
+}
+
+func (q *Queries) UpdateWebStatus(ctx context.Context, arg UpdateWebStatusParams) (Web, error) {
+	row := q.db.QueryRowContext(ctx, updateWebStatus, arg.ID, arg.Status, arg.ErrorReason)
+	var i Web
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Url,
+		&i.Title,
+		&i.ThumbnailUrl,
+		&i.Html,
+		&i.CreatedAt,
+		&i.Status,
+		&i.ErrorReason,
+		&i.ArticleText,
+		&i.ArticleHtml,
+		&i.ArticleMarkdown,
+		&i.Author,
+		&i.SiteName,
+		&i.Description,
+		&i.PublishedAt,
+		&i.ReadingMinutes,
+	)
+	return i, err
+}
@@ -0,0 +1,165 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: web_job.sql
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const claimWebJob = `-- name: ClaimWebJob :one
+UPDATE web_jobs
+SET
+  attempts = attempts + 1,
+  locked_until = $1::timestamptz
+WHERE id = (
+  SELECT id FROM web_jobs
+  WHERE run_at <= now()
+    AND (locked_until IS NULL OR locked_until < now())
+  ORDER BY run_at
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, web_id, attempts, max_attempts, run_at, locked_until, last_error, created_at
+`
+
+func (q *Queries) ClaimWebJob(ctx context.Context, lockedUntil time.Time) (WebJob, error) {
+	row := q.db.QueryRowContext(ctx, claimWebJob, lockedUntil)
+	var i WebJob
+	err := row.Scan(
+		&i.ID,
+		&i.WebID,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedUntil,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebJob = `-- name: CreateWebJob :one
+INSERT INTO web_jobs (
+  web_id,
+  max_attempts
+) VALUES (
+  $1, $2
+)
+RETURNING id, web_id, attempts, max_attempts, run_at, locked_until, last_error, created_at
+`
+
+type CreateWebJobParams struct {
+	WebID       uuid.UUID `json:"web_id"`
+	MaxAttempts int32     `json:"max_attempts"`
+}
+
+func (q *Queries) CreateWebJob(ctx context.Context, arg CreateWebJobParams) (WebJob, error) {
+	row := q.db.QueryRowContext(ctx, createWebJob, arg.WebID, arg.MaxAttempts)
+	var i WebJob
+	err := row.Scan(
+		&i.ID,
+		&i.WebID,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedUntil,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteWebJob = `-- name: DeleteWebJob :exec
+DELETE FROM web_jobs
+WHERE id = $1
+`
+
+func (q *Queries) DeleteWebJob(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteWebJob, id)
+	return err
+}
+
+const getWebJobByWebId = `-- name: GetWebJobByWebId :one
+SELECT id, web_id, attempts, max_attempts, run_at, locked_until, last_error, created_at FROM web_jobs
+WHERE web_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetWebJobByWebId(ctx context.Context, webID uuid.UUID) (WebJob, error) {
+	row := q.db.QueryRowContext(ctx, getWebJobByWebId, webID)
+	var i WebJob
+	err := row.Scan(
+		&i.ID,
+		&i.WebID,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedUntil,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const resetWebJob = `-- name: ResetWebJob :one
+INSERT INTO web_jobs (
+  web_id,
+  max_attempts
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (web_id) DO UPDATE
+SET
+  attempts = 0,
+  max_attempts = EXCLUDED.max_attempts,
+  run_at = now(),
+  locked_until = NULL,
+  last_error = ''
+RETURNING id, web_id, attempts, max_attempts, run_at, locked_until, last_error, created_at
+`
+
+type ResetWebJobParams struct {
+	WebID       uuid.UUID `json:"web_id"`
+	MaxAttempts int32     `json:"max_attempts"`
+}
+
+func (q *Queries) ResetWebJob(ctx context.Context, arg ResetWebJobParams) (WebJob, error) {
+	row := q.db.QueryRowContext(ctx, resetWebJob, arg.WebID, arg.MaxAttempts)
+	var i WebJob
+	err := row.Scan(
+		&i.ID,
+		&i.WebID,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedUntil,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const retryWebJob = `-- name: RetryWebJob :exec
+UPDATE web_jobs
+SET
+  run_at = $2,
+  locked_until = NULL,
+  last_error = $3
+WHERE id = $1
+`
+
+type RetryWebJobParams struct {
+	ID        int64     `json:"id"`
+	RunAt     time.Time `json:"run_at"`
+	LastError string    `json:"last_error"`
+}
+
+func (q *Queries) RetryWebJob(ctx context.Context, arg RetryWebJobParams) error {
+	_, err := q.db.ExecContext(ctx, retryWebJob, arg.ID, arg.RunAt, arg.LastError)
+	return err
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebJob(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	createRandomWebJob(t, web)
+}
+
+func TestClaimWebJob(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	job := createRandomWebJob(t, web)
+
+	lockedUntil := time.Now().Add(time.Minute)
+	claimed, err := testQueries.ClaimWebJob(context.Background(), lockedUntil)
+	require.NoError(t, err)
+	require.NotZero(t, claimed.ID)
+	require.True(t, claimed.LockedUntil.Valid)
+
+	if claimed.ID == job.ID {
+		require.Equal(t, int32(1), claimed.Attempts)
+		require.WithinDuration(t, lockedUntil, claimed.LockedUntil.Time, time.Second)
+	}
+
+	err = testQueries.DeleteWebJob(context.Background(), claimed.ID)
+	require.NoError(t, err)
+}
+
+func TestRetryWebJob(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	job := createRandomWebJob(t, web)
+
+	arg := RetryWebJobParams{
+		ID:        job.ID,
+		RunAt:     time.Now().Add(time.Hour),
+		LastError: util.RandomString(10),
+	}
+	err := testQueries.RetryWebJob(context.Background(), arg)
+	require.NoError(t, err)
+
+	got, err := testQueries.GetWebJobByWebId(context.Background(), web.ID)
+	require.NoError(t, err)
+	require.Equal(t, arg.LastError, got.LastError)
+	require.WithinDuration(t, arg.RunAt, got.RunAt, time.Second)
+	require.False(t, got.LockedUntil.Valid)
+}
+
+func TestResetWebJob(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	job := createRandomWebJob(t, web)
+
+	err := testQueries.RetryWebJob(context.Background(), RetryWebJobParams{
+		ID:        job.ID,
+		RunAt:     time.Now().Add(time.Hour),
+		LastError: util.RandomString(10),
+	})
+	require.NoError(t, err)
+
+	reset, err := testQueries.ResetWebJob(context.Background(), ResetWebJobParams{
+		WebID:       web.ID,
+		MaxAttempts: 3,
+	})
+	require.NoError(t, err)
+	require.Equal(t, job.ID, reset.ID)
+	require.Zero(t, reset.Attempts)
+	require.Equal(t, int32(3), reset.MaxAttempts)
+	require.Empty(t, reset.LastError)
+}
+
+func TestDeleteWebJob(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+	job := createRandomWebJob(t, web)
+
+	err := testQueries.DeleteWebJob(context.Background(), job.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWebJobByWebId(context.Background(), web.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func createRandomWebJob(t *testing.T, web Web) WebJob {
+	arg := CreateWebJobParams{
+		WebID:       web.ID,
+		MaxAttempts: 5,
+	}
+
+	job, err := testQueries.CreateWebJob(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, job)
+
+	require.Equal(t, arg.WebID, job.WebID)
+	require.Equal(t, arg.MaxAttempts, job.MaxAttempts)
+	require.Zero(t, job.Attempts)
+	require.False(t, job.LockedUntil.Valid)
+	require.NotZero(t, job.RunAt)
+	require.NotZero(t, job.CreatedAt)
+	return job
+}
@@ -0,0 +1,8 @@
+package db
+
+// Values of webs.status
+const (
+	WebStatusPending = "pending"
+	WebStatusDone    = "done"
+	WebStatusFailed  = "failed"
+)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -118,6 +119,100 @@ func TestListWebByNoteId(t *testing.T) {
 	}
 }
 
+func TestUpdateWebContent(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+
+	_, err := testQueries.UpdateWebStatus(context.Background(), UpdateWebStatusParams{
+		ID:          web.ID,
+		Status:      WebStatusFailed,
+		ErrorReason: util.RandomString(10),
+	})
+	require.NoError(t, err)
+
+	arg := UpdateWebContentParams{
+		ID:              web.ID,
+		Title:           util.RandomName(),
+		ThumbnailUrl:    util.RandomThumbnailURL(),
+		Html:            util.RandomHTML(),
+		ArticleText:     util.RandomString(50),
+		ArticleHtml:     util.RandomHTML(),
+		ArticleMarkdown: util.RandomString(50),
+		Author:          util.RandomName(),
+		SiteName:        util.RandomName(),
+		Description:     util.RandomString(20),
+		PublishedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+		ReadingMinutes:  int32(util.RandomInt(1, 30)),
+	}
+	updated, err := testQueries.UpdateWebContent(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Title, updated.Title)
+	require.Equal(t, arg.ThumbnailUrl, updated.ThumbnailUrl)
+	require.Equal(t, arg.Html, updated.Html)
+	require.Equal(t, arg.ArticleText, updated.ArticleText)
+	require.Equal(t, arg.ArticleHtml, updated.ArticleHtml)
+	require.Equal(t, arg.ArticleMarkdown, updated.ArticleMarkdown)
+	require.Equal(t, arg.Author, updated.Author)
+	require.Equal(t, arg.SiteName, updated.SiteName)
+	require.Equal(t, arg.Description, updated.Description)
+	require.WithinDuration(t, arg.PublishedAt.Time, updated.PublishedAt.Time, time.Second)
+	require.Equal(t, arg.ReadingMinutes, updated.ReadingMinutes)
+	require.Equal(t, WebStatusDone, updated.Status)
+	require.Empty(t, updated.ErrorReason)
+}
+
+func TestUpdateWebArticle(t *testing.T) {
+	user := createRandomUser(t)
+	web := createRandomWeb(t, user)
+
+	arg := UpdateWebArticleParams{
+		ID:              web.ID,
+		ArticleText:     util.RandomString(50),
+		ArticleHtml:     util.RandomHTML(),
+		ArticleMarkdown: util.RandomString(50),
+		Author:          util.RandomName(),
+		SiteName:        util.RandomName(),
+		Description:     util.RandomString(20),
+		ReadingMinutes:  int32(util.RandomInt(1, 30)),
+	}
+	updated, err := testQueries.UpdateWebArticle(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, web.Title, updated.Title)
+	require.Equal(t, web.Html, updated.Html)
+	require.Equal(t, arg.ArticleText, updated.ArticleText)
+	require.Equal(t, arg.ArticleHtml, updated.ArticleHtml)
+	require.Equal(t, arg.ArticleMarkdown, updated.ArticleMarkdown)
+	require.Equal(t, arg.Author, updated.Author)
+	require.Equal(t, arg.SiteName, updated.SiteName)
+	require.Equal(t, arg.Description, updated.Description)
+	require.False(t, updated.PublishedAt.Valid)
+	require.Equal(t, arg.ReadingMinutes, updated.ReadingMinutes)
+}
+
+func TestListDoneWebsAfterId(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomWeb(t, user)
+	}
+
+	webs, err := testQueries.ListDoneWebsAfterId(context.Background(), ListDoneWebsAfterIdParams{
+		ID:    uuid.Nil,
+		Limit: 3,
+	})
+	require.NoError(t, err)
+	require.Len(t, webs, 3)
+
+	next, err := testQueries.ListDoneWebsAfterId(context.Background(), ListDoneWebsAfterIdParams{
+		ID:    webs[2].ID,
+		Limit: 3,
+	})
+	require.NoError(t, err)
+	for _, web := range next {
+		require.Equal(t, WebStatusDone, web.Status)
+		require.Greater(t, web.ID.String(), webs[2].ID.String())
+	}
+}
+
 func createRandomWeb(t *testing.T, user User) Web {
 	ThumbnailURL := util.RandomThumbnailURL()
 	arg := CreateWebParams{
@@ -126,6 +221,7 @@ func createRandomWeb(t *testing.T, user User) Web {
 		Title:        util.RandomName(),
 		Html:         util.RandomHTML(),
 		ThumbnailUrl: ThumbnailURL,
+		Status:       WebStatusDone,
 	}
 
 	web, err := testQueries.CreateWeb(context.Background(), arg)
@@ -137,6 +233,8 @@ func createRandomWeb(t *testing.T, user User) Web {
 	require.Equal(t, arg.Title, web.Title)
 	require.Equal(t, arg.ThumbnailUrl, web.ThumbnailUrl)
 	require.Equal(t, arg.Html, web.Html)
+	require.Equal(t, arg.Status, web.Status)
+	require.Empty(t, web.ErrorReason)
 
 	require.NotZero(t, web.CreatedAt)
 	return web
@@ -0,0 +1,107 @@
+package diff
+
+import "strings"
+
+// Op tells what happened to a line
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Line is a line of the diff, lines of the old text are deleted and lines of the new text are inserted
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxCells bounds the memory used by the lcs table, bigger inputs are diffed as a full replacement
+const maxCells = 4 << 20
+
+// Lines returns a line by line diff turning a into b
+func Lines(a string, b string) []Line {
+	return diff(splitLines(a), splitLines(b))
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func diff(a []string, b []string) []Line {
+	ret := []Line{}
+
+	// the common prefix and suffix don't need the lcs table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		ret = append(ret, Line{Op: Equal, Text: line})
+	}
+	ret = append(ret, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ret = append(ret, Line{Op: Equal, Text: line})
+	}
+	return ret
+}
+
+func middle(a []string, b []string) []Line {
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		return replace(a, b)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+				lcs[i*width+j] = lcs[(i+1)*width+j]
+			default:
+				lcs[i*width+j] = lcs[i*width+j+1]
+			}
+		}
+	}
+
+	ret := []Line{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ret = append(ret, Line{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[(i+1)*width+j] >= lcs[i*width+j+1]:
+			ret = append(ret, Line{Op: Delete, Text: a[i]})
+			i++
+		default:
+			ret = append(ret, Line{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+	return append(ret, replace(a[i:], b[j:])...)
+}
+
+func replace(a []string, b []string) []Line {
+	ret := make([]Line, 0, len(a)+len(b))
+	for _, line := range a {
+		ret = append(ret, Line{Op: Delete, Text: line})
+	}
+	for _, line := range b {
+		ret = append(ret, Line{Op: Insert, Text: line})
+	}
+	return ret
+}
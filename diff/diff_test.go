@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "Empty",
+			a:    "",
+			b:    "",
+			want: []Line{},
+		},
+		{
+			name: "Same",
+			a:    "a\nb",
+			b:    "a\nb",
+			want: []Line{{Equal, "a"}, {Equal, "b"}},
+		},
+		{
+			name: "FromEmpty",
+			a:    "",
+			b:    "a\nb",
+			want: []Line{{Insert, "a"}, {Insert, "b"}},
+		},
+		{
+			name: "ToEmpty",
+			a:    "a",
+			b:    "",
+			want: []Line{{Delete, "a"}},
+		},
+		{
+			name: "ChangedLine",
+			a:    "a\nb\nc",
+			b:    "a\nx\nc",
+			want: []Line{{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}},
+		},
+		{
+			name: "InsertedAndDeleted",
+			a:    "a\nb\nc\nd",
+			b:    "b\nc\ne\nd",
+			want: []Line{{Delete, "a"}, {Equal, "b"}, {Equal, "c"}, {Insert, "e"}, {Equal, "d"}},
+		},
+		{
+			name: "Moved",
+			a:    "a\nb\nc",
+			b:    "c\na\nb",
+			want: []Line{{Insert, "c"}, {Equal, "a"}, {Equal, "b"}, {Delete, "c"}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, Lines(tc.a, tc.b))
+		})
+	}
+}
+
+func TestLinesAppliesToNew(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix"
+	b := "zero\none\nthree\nfour!\nfive\nsix\nseven"
+
+	var old, new []string
+	for _, line := range Lines(a, b) {
+		if line.Op != Insert {
+			old = append(old, line.Text)
+		}
+		if line.Op != Delete {
+			new = append(new, line.Text)
+		}
+	}
+	require.Equal(t, a, strings.Join(old, "\n"))
+	require.Equal(t, b, strings.Join(new, "\n"))
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	n := 3000
+	a := make([]string, n)
+	b := make([]string, n)
+	for i := 0; i < n; i++ {
+		a[i] = "a" + strings.Repeat("x", i%7)
+		b[i] = "b" + strings.Repeat("x", i%7)
+	}
+
+	got := Lines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+	require.Len(t, got, 2*n)
+	require.Equal(t, Delete, got[0].Op)
+	require.Equal(t, Insert, got[len(got)-1].Op)
+}
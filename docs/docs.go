@@ -16,7 +16,22 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/notes": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/token.JSONWebKeySet"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats": {
             "get": {
                 "security": [
                     {
@@ -24,78 +39,103 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "note"
+                    "admin"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.adminStatsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "admin"
                 ],
                 "parameters": [
                     {
-                        "minimum": 1,
                         "type": "integer",
+                        "description": "Page ID",
                         "name": "page_id",
                         "in": "query",
                         "required": true
                     },
                     {
-                        "maximum": 10,
-                        "minimum": 5,
                         "type": "integer",
+                        "description": "Page size",
                         "name": "page_size",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the email",
+                        "name": "email",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.listNoteResponse"
+                            "$ref": "#/definitions/api.listAdminUserResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/admin/users/{id}": {
+            "get": {
                 "security": [
                     {
                         "AccessToken": []
                     }
                 ],
                 "tags": [
-                    "note"
+                    "admin"
                 ],
                 "parameters": [
                     {
-                        "description": "query params",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.createNoteRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.noteResponse"
+                            "$ref": "#/definitions/api.adminUserResponse"
                         }
                     }
                 }
             }
         },
-        "/notes/{id}": {
-            "get": {
+        "/admin/users/{id}/block": {
+            "post": {
                 "security": [
                     {
                         "AccessToken": []
                     }
                 ],
                 "tags": [
-                    "note"
+                    "admin"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Note ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -105,11 +145,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.noteResponse"
+                            "$ref": "#/definitions/api.adminUserResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/role": {
             "put": {
                 "security": [
                     {
@@ -117,12 +159,12 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "note"
+                    "admin"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Web ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -133,7 +175,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.putNoteRequest"
+                            "$ref": "#/definitions/api.updateAdminUserRoleRequest"
                         }
                     }
                 ],
@@ -141,11 +183,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.noteResponse"
+                            "$ref": "#/definitions/api.adminUserResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/sessions": {
             "delete": {
                 "security": [
                     {
@@ -153,12 +197,12 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "note"
+                    "admin"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Notes ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -174,15 +218,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/public_notes/{id}": {
-            "get": {
+        "/admin/users/{id}/unblock": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
                 "tags": [
-                    "note"
+                    "admin"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Note ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -192,65 +241,40 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.noteResponse"
+                            "$ref": "#/definitions/api.adminUserResponse"
                         }
                     }
                 }
             }
         },
-        "/register": {
-            "post": {
-                "tags": [
-                    "user"
-                ],
-                "parameters": [
-                    {
-                        "description": "query params",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.registerRequest"
-                        }
-                    }
+        "/avatars/{name}": {
+            "get": {
+                "produces": [
+                    "image/png"
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": ""
-                        }
-                    }
-                }
-            }
-        },
-        "/users": {
-            "post": {
                 "tags": [
-                    "user"
+                    "profile"
                 ],
                 "parameters": [
                     {
-                        "description": "query params",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.createUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Avatar file name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.userResponse"
+                            "type": "file"
                         }
                     }
                 }
             }
         },
-        "/users/login": {
+        "/email/confirm": {
             "post": {
                 "tags": [
                     "user"
@@ -262,7 +286,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.loginUserRequest"
+                            "$ref": "#/definitions/api.confirmEmailChangeRequest"
                         }
                     }
                 ],
@@ -270,13 +294,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.loginUserRedirectResponse"
+                            "$ref": "#/definitions/api.userResponse"
                         }
                     }
                 }
             }
         },
-        "/users/me": {
+        "/notes": {
             "get": {
                 "security": [
                     {
@@ -284,48 +308,52 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "user"
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/api.userResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/users/renew_access": {
-            "post": {
-                "tags": [
-                    "user"
+                    "note"
                 ],
                 "parameters": [
                     {
-                        "description": "query params",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.renewAccessTokenRequest"
-                        }
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 10,
+                        "minimum": 5,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maxItems": 10,
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "description": "only notes that have all of these tags",
+                        "name": "tags",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.renewAccessTokenResponse"
+                            "$ref": "#/definitions/api.listNoteResponse"
                         }
                     }
                 }
-            }
-        },
-        "/verify": {
+            },
             "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
                 "tags": [
-                    "user"
+                    "note"
                 ],
                 "parameters": [
                     {
@@ -334,7 +362,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.vertifyRequest"
+                            "$ref": "#/definitions/api.createNoteRequest"
                         }
                     }
                 ],
@@ -342,13 +370,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.userResponse"
+                            "$ref": "#/definitions/api.noteResponse"
                         }
                     }
                 }
             }
         },
-        "/webs": {
+        "/notes/{id}": {
             "get": {
                 "security": [
                     {
@@ -356,22 +384,14 @@ const docTemplate = `{
                     }
                 ],
                 "tags": [
-                    "web"
+                    "note"
                 ],
                 "parameters": [
                     {
-                        "minimum": 1,
-                        "type": "integer",
-                        "name": "page_id",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 10,
-                        "minimum": 5,
-                        "type": "integer",
-                        "name": "page_size",
-                        "in": "query",
+                        "type": "string",
+                        "description": "Note ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -379,28 +399,41 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.listWebResponse"
+                            "$ref": "#/definitions/api.noteResponse"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "AccessToken": []
                     }
                 ],
                 "tags": [
-                    "web"
+                    "note"
                 ],
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Web ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
                     {
                         "description": "query params",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.createWebRequest"
+                            "$ref": "#/definitions/api.putNoteRequest"
                         }
                     }
                 ],
@@ -408,26 +441,30 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.webResponse"
+                            "$ref": "#/definitions/api.noteResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.noteVersionMismatchResponse"
                         }
                     }
                 }
-            }
-        },
-        "/webs/{id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "AccessToken": []
                     }
                 ],
                 "tags": [
-                    "web"
+                    "note"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Web ID",
+                        "description": "Notes ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -437,31 +474,263 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.webResponse"
+                            "type": ""
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/notes/{id}/diff": {
+            "get": {
                 "security": [
                     {
                         "AccessToken": []
                     }
                 ],
                 "tags": [
-                    "web"
+                    "note"
                 ],
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Web ID",
+                        "description": "Note ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.diffNoteResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "note"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 10,
+                        "minimum": 5,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listNoteRevisionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/revisions/{revision}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "note"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.noteRevisionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notes/{id}/revisions/{revision}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "note"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the note",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.noteResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api.noteVersionMismatchResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device/approve": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.answerDeviceCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device/code": {
+            "post": {
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.deviceCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.deviceCodeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/device/deny": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.answerDeviceCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
                         "description": "OK",
                         "schema": {
                             "type": ""
@@ -469,10 +738,2407 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.createNoteRequest": {
+        },
+        "/oauth/token": {
+            "post": {
+                "tags": [
+                    "oauth"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.oauthTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.oauthTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/callback": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.oidcCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRedirectResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserChallengeResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oidc/login": {
+            "get": {
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.oidcAuthorizationResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.forgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.resetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{handle}": {
+            "get": {
+                "tags": [
+                    "profile"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.profileResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public_notes/{id}": {
+            "get": {
+                "produces": [
+                    "text/html",
+                    "application/json"
+                ],
+                "tags": [
+                    "note"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Note ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.noteResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/register/resend": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.resendVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "search"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "only webs from this domain or its subdomains",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339, inclusive",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "name": "is_public",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 10,
+                        "minimum": 5,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maxLength": 200,
+                        "type": "string",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339, exclusive",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "note",
+                            "web"
+                        ],
+                        "type": "string",
+                        "description": "note or web, both when empty",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.searchResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listTagResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.tagResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.renameTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.tagResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "tag"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.mergeTagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.tagResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/u/{handle}": {
+            "get": {
+                "tags": [
+                    "author"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "1 when not given",
+                        "name": "pageID",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 10,
+                        "minimum": 5,
+                        "type": "integer",
+                        "description": "10 when not given",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.authorPageResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/u/{handle}/feed.atom": {
+            "get": {
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "author"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Atom document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/u/{handle}/feed.rss": {
+            "get": {
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "author"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Handle",
+                        "name": "handle",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "RSS document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRedirectResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserChallengeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": ""
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/2fa": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.loginTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRedirectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/logout": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.deleteUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/2fa": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.setupTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.setupTwoFactorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.disableTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.confirmTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.confirmTwoFactorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/email": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.changeEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/export": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/identities": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listIdentityResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.oidcCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.identityResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/identities/link": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.oidcAuthorizationResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/identities/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Identity ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.identityResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.changePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.loginUserRedirectResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/profile": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.profileResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.putProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.profileResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/profile/avatar": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.profileResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "profile"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.profileResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listSessionResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.sessionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listPersonalAccessTokenResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createPersonalAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.createPersonalAccessTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Personal access token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.personalAccessTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/renew_access": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.renewAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.renewAccessTokenResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify": {
+            "post": {
+                "tags": [
+                    "user"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.vertifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.userResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webs": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "web"
+                ],
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "name": "page_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 10,
+                        "minimum": 5,
+                        "type": "integer",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maxItems": 10,
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "description": "only webs that have all of these tags",
+                        "name": "tags",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.listWebResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "web"
+                ],
+                "parameters": [
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.createWebRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.webResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "web"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Web ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.webResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "web"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Web ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": ""
+                        }
+                    }
+                }
+            }
+        },
+        "/webs/{id}/refetch": {
+            "post": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "web"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Web ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.webResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webs/{id}/tags": {
+            "put": {
+                "security": [
+                    {
+                        "AccessToken": []
+                    }
+                ],
+                "tags": [
+                    "web"
+                ],
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Web ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "query params",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.putWebTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.webResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.adminStatsResponse": {
+            "type": "object",
+            "properties": {
+                "active_sessions": {
+                    "type": "integer"
+                },
+                "blocked_users": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "integer"
+                },
+                "pending_registrations": {
+                    "type": "integer"
+                },
+                "pending_web_jobs": {
+                    "description": "webs waiting to be fetched or retried",
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
+                },
+                "webs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.adminUserResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "email",
+                "id",
+                "password_changed_at",
+                "role"
+            ],
+            "properties": {
+                "blocked_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "password_changed_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "user or admin",
+                    "type": "string"
+                }
+            }
+        },
+        "api.answerDeviceCodeRequest": {
+            "type": "object",
+            "required": [
+                "user_code"
+            ],
+            "properties": {
+                "user_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.authorPageResponse": {
+            "type": "object",
+            "properties": {
+                "note_count": {
+                    "description": "NoteCount is the number of public notes over every page",
+                    "type": "integer"
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.noteResponse"
+                    }
+                },
+                "profile": {
+                    "$ref": "#/definitions/api.profileResponse"
+                }
+            }
+        },
+        "api.changeEmailRequest": {
+            "type": "object",
+            "required": [
+                "new_email",
+                "password"
+            ],
+            "properties": {
+                "new_email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.changePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "api.confirmEmailChangeRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.confirmTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.confirmTwoFactorResponse": {
+            "type": "object",
+            "required": [
+                "recovery_codes"
+            ],
+            "properties": {
+                "recovery_codes": {
+                    "description": "RecoveryCodes are only shown here, each one can replace a code once",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.createNoteRequest": {
+            "type": "object",
+            "required": [
+                "content",
+                "is_public",
+                "title"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 10000
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "description": "tags that don't exist yet are created",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "minLength": 1
+                },
+                "web_ids": {
+                    "type": "array",
+                    "maxItems": 5,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.createPersonalAccessTokenRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "description": "RFC3339, the token never expires when empty",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.createPersonalAccessTokenResponse": {
+            "type": "object",
+            "required": [
+                "personal_access_token",
+                "token"
+            ],
+            "properties": {
+                "personal_access_token": {
+                    "$ref": "#/definitions/api.personalAccessTokenResponse"
+                },
+                "token": {
+                    "description": "Token is only shown here, it is stored hashed",
+                    "type": "string"
+                }
+            }
+        },
+        "api.createTagRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 30
+                }
+            }
+        },
+        "api.createUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "api.createWebRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "tags": {
+                    "description": "tags that don't exist yet are created",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.deleteUserRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.deviceCodeRequest": {
+            "type": "object",
+            "required": [
+                "client_id"
+            ],
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.deviceCodeResponse": {
+            "type": "object",
+            "required": [
+                "device_code",
+                "expires_in",
+                "interval",
+                "user_code",
+                "verification_uri",
+                "verification_uri_complete"
+            ],
+            "properties": {
+                "device_code": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "seconds to wait between two polls of POST /oauth/token",
+                    "type": "integer"
+                },
+                "user_code": {
+                    "description": "UserCode is shown to the user, who types it on the verification page",
+                    "type": "string"
+                },
+                "verification_uri": {
+                    "type": "string"
+                },
+                "verification_uri_complete": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.diffNoteResponse": {
+            "type": "object",
+            "required": [
+                "added_webs",
+                "content",
+                "from",
+                "removed_webs",
+                "title",
+                "to"
+            ],
+            "properties": {
+                "added_webs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/diff.Line"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "is_public": {
+                    "description": "IsPublic is set only when the visibility changed, to the value of the newer revision",
+                    "type": "boolean"
+                },
+                "removed_webs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/diff.Line"
+                    }
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.disableTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "description": "Code is a code from the authenticator app or a recovery code",
+                    "type": "string"
+                }
+            }
+        },
+        "api.forgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.identityResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "email",
+                "id",
+                "issuer",
+                "subject"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issuer": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.listAdminUserResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.adminUserResponse"
+                    }
+                }
+            }
+        },
+        "api.listIdentityResponse": {
+            "type": "object",
+            "properties": {
+                "identities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.identityResponse"
+                    }
+                }
+            }
+        },
+        "api.listNoteResponse": {
+            "type": "object",
+            "properties": {
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.noteResponse"
+                    }
+                }
+            }
+        },
+        "api.listNoteRevisionResponse": {
+            "type": "object",
+            "properties": {
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.noteRevisionResponse"
+                    }
+                }
+            }
+        },
+        "api.listPersonalAccessTokenResponse": {
+            "type": "object",
+            "properties": {
+                "personal_access_tokens": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.personalAccessTokenResponse"
+                    }
+                }
+            }
+        },
+        "api.listSessionResponse": {
+            "type": "object",
+            "properties": {
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.sessionResponse"
+                    }
+                }
+            }
+        },
+        "api.listTagResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.tagResponse"
+                    }
+                }
+            }
+        },
+        "api.listWebResponse": {
+            "type": "object",
+            "properties": {
+                "webs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.webResponse"
+                    }
+                }
+            }
+        },
+        "api.loginTwoFactorRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Code is a code from the authenticator app or a recovery code",
+                    "type": "string"
+                }
+            }
+        },
+        "api.loginUserChallengeResponse": {
+            "type": "object",
+            "required": [
+                "challenge_expires_at",
+                "challenge_token",
+                "two_factor_required"
+            ],
+            "properties": {
+                "challenge_expires_at": {
+                    "type": "string"
+                },
+                "challenge_token": {
+                    "type": "string"
+                },
+                "two_factor_required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.loginUserRedirectResponse": {
+            "type": "object",
+            "required": [
+                "access_token",
+                "access_token_expires_at",
+                "refresh_token",
+                "refresh_token_expires_at",
+                "session_id",
+                "user"
+            ],
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "access_token_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "refresh_token_expires_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/api.userResponse"
+                }
+            }
+        },
+        "api.loginUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "api.mergeTagRequest": {
+            "type": "object",
+            "required": [
+                "target_id"
+            ],
+            "properties": {
+                "target_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.noteResponse": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "etag": {
+                    "description": "ETag is what getNote sends in the ETag header, send it back in If-Match to update the note",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is bumped by every update",
+                    "type": "integer"
+                },
+                "webs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.webResponse"
+                    }
+                }
+            }
+        },
+        "api.noteRevisionResponse": {
+            "type": "object",
+            "required": [
+                "author_id",
+                "content",
+                "created_at",
+                "is_public",
+                "revision",
+                "title",
+                "web_ids"
+            ],
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "web_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.noteVersionMismatchResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "etag": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the current version of the note",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.oauthTokenRequest": {
+            "type": "object",
+            "required": [
+                "client_id",
+                "device_code",
+                "grant_type"
+            ],
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "device_code": {
+                    "type": "string"
+                },
+                "grant_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.oauthTokenResponse": {
+            "type": "object",
+            "required": [
+                "access_token",
+                "access_token_expires_at",
+                "expires_in",
+                "refresh_token",
+                "refresh_token_expires_at",
+                "session_id",
+                "token_type",
+                "user"
+            ],
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "access_token_expires_at": {
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "seconds until the access token expires",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "refresh_token_expires_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                },
+                "user": {
+                    "$ref": "#/definitions/api.userResponse"
+                }
+            }
+        },
+        "api.oidcAuthorizationResponse": {
+            "type": "object",
+            "required": [
+                "authorization_url"
+            ],
+            "properties": {
+                "authorization_url": {
+                    "description": "AuthorizationURL is where to send the browser to log in at the provider",
+                    "type": "string"
+                }
+            }
+        },
+        "api.oidcCallbackRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "state"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.personalAccessTokenResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "never expires when empty",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.profileResponse": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "description": "empty when there is no avatar",
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "handle": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.putNoteRequest": {
             "type": "object",
             "required": [
                 "content",
@@ -481,16 +3147,22 @@ const docTemplate = `{
             ],
             "properties": {
                 "content": {
-                    "type": "string",
-                    "maxLength": 10000
+                    "type": "string"
                 },
                 "is_public": {
                     "type": "boolean"
                 },
+                "tags": {
+                    "description": "replaces the tags of the note, tags that don't exist yet are created",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "title": {
-                    "type": "string",
-                    "maxLength": 100,
-                    "minLength": 1
+                    "description": "ID      string   ` + "`" + `uri:\"id\" binding:\"required,uuid\"` + "`" + `",
+                    "type": "string"
                 },
                 "web_ids": {
                     "type": "array",
@@ -502,65 +3174,79 @@ const docTemplate = `{
                 }
             }
         },
-        "api.createUserRequest": {
+        "api.putProfileRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "handle"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
+                "bio": {
+                    "type": "string",
+                    "maxLength": 300
                 },
-                "password": {
+                "display_name": {
                     "type": "string",
-                    "minLength": 8
+                    "maxLength": 50
+                },
+                "handle": {
+                    "type": "string"
                 }
             }
         },
-        "api.createWebRequest": {
+        "api.putWebTagsRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "description": "replaces the tags of the web, tags that don't exist yet are created",
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.registerRequest": {
             "type": "object",
             "required": [
-                "url"
+                "email",
+                "password"
             ],
             "properties": {
-                "url": {
+                "email": {
                     "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
                 }
             }
         },
-        "api.listNoteResponse": {
+        "api.renameTagRequest": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "notes": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.noteResponse"
-                    }
+                "name": {
+                    "type": "string",
+                    "maxLength": 30
                 }
             }
         },
-        "api.listWebResponse": {
+        "api.renewAccessTokenRequest": {
             "type": "object",
+            "required": [
+                "refresh_token"
+            ],
             "properties": {
-                "webs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.webResponse"
-                    }
+                "refresh_token": {
+                    "type": "string"
                 }
             }
         },
-        "api.loginUserRedirectResponse": {
+        "api.renewAccessTokenResponse": {
             "type": "object",
-            "required": [
-                "access_token",
-                "access_token_expires_at",
-                "refresh_token",
-                "refresh_token_expires_at",
-                "session_id",
-                "user"
-            ],
             "properties": {
                 "access_token": {
                     "type": "string"
@@ -576,34 +3262,59 @@ const docTemplate = `{
                 },
                 "session_id": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/api.userResponse"
                 }
             }
         },
-        "api.loginUserRequest": {
+        "api.resendVerificationRequest": {
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "email"
             ],
             "properties": {
                 "email": {
                     "type": "string"
-                },
+                }
+            }
+        },
+        "api.resetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "token"
+            ],
+            "properties": {
                 "password": {
                     "type": "string",
                     "minLength": 8
+                },
+                "token": {
+                    "type": "string"
                 }
             }
         },
-        "api.noteResponse": {
+        "api.searchResponse": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
-                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.searchResultResponse"
+                    }
+                }
+            }
+        },
+        "api.searchResultResponse": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "is_public",
+                "rank",
+                "snippet",
+                "title",
+                "type"
+            ],
+            "properties": {
                 "created_at": {
                     "type": "string"
                 },
@@ -613,83 +3324,122 @@ const docTemplate = `{
                 "is_public": {
                     "type": "boolean"
                 },
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "description": "html escaped text, matches are wrapped in \u003cmark\u003e",
+                    "type": "string"
+                },
                 "title": {
                     "type": "string"
                 },
-                "user_id": {
+                "type": {
                     "type": "string"
                 },
-                "webs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/api.webResponse"
-                    }
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "api.putNoteRequest": {
+        "api.sessionResponse": {
             "type": "object",
             "required": [
-                "content",
-                "is_public",
-                "title"
+                "client_ip",
+                "created_at",
+                "current",
+                "expires_at",
+                "id",
+                "user_agent"
             ],
             "properties": {
-                "content": {
+                "client_ip": {
                     "type": "string"
                 },
-                "is_public": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current is set on the session of the token making the request",
                     "type": "boolean"
                 },
-                "title": {
-                    "description": "ID      string   ` + "`" + `uri:\"id\" binding:\"required,uuid\"` + "`" + `",
+                "expires_at": {
                     "type": "string"
                 },
-                "web_ids": {
-                    "type": "array",
-                    "maxItems": 5,
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
-                    }
+                "id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
                 }
             }
         },
-        "api.registerRequest": {
+        "api.setupTwoFactorRequest": {
             "type": "object",
             "required": [
-                "email",
                 "password"
             ],
             "properties": {
-                "email": {
-                    "type": "string"
-                },
                 "password": {
-                    "type": "string",
-                    "minLength": 8
+                    "type": "string"
                 }
             }
         },
-        "api.renewAccessTokenRequest": {
+        "api.setupTwoFactorResponse": {
             "type": "object",
             "required": [
-                "refresh_token"
+                "otpauth_uri",
+                "secret"
             ],
             "properties": {
-                "refresh_token": {
+                "otpauth_uri": {
+                    "description": "OtpauthURI is meant to be shown as a QR code",
+                    "type": "string"
+                },
+                "secret": {
                     "type": "string"
                 }
             }
         },
-        "api.renewAccessTokenResponse": {
+        "api.tagResponse": {
             "type": "object",
+            "required": [
+                "created_at",
+                "id",
+                "name",
+                "note_count",
+                "web_count"
+            ],
             "properties": {
-                "access_token": {
+                "created_at": {
                     "type": "string"
                 },
-                "access_token_expires_at": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
                     "type": "string"
+                },
+                "note_count": {
+                    "type": "integer"
+                },
+                "web_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.updateAdminUserRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "admin"
+                    ]
                 }
             }
         },
@@ -699,7 +3449,8 @@ const docTemplate = `{
                 "created_at",
                 "email",
                 "id",
-                "password_changed_at"
+                "password_changed_at",
+                "role"
             ],
             "properties": {
                 "created_at": {
@@ -713,6 +3464,10 @@ const docTemplate = `{
                 },
                 "password_changed_at": {
                     "type": "string"
+                },
+                "role": {
+                    "description": "user or admin",
+                    "type": "string"
                 }
             }
         },
@@ -737,21 +3492,60 @@ const docTemplate = `{
                 "created_at",
                 "html",
                 "id",
+                "status",
                 "thumbnail_url",
                 "title",
                 "url",
                 "user_id"
             ],
             "properties": {
+                "article_html": {
+                    "type": "string"
+                },
+                "article_markdown": {
+                    "type": "string"
+                },
+                "article_text": {
+                    "type": "string"
+                },
+                "author": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "description": {
+                    "description": "readable article extracted from the page, empty until the web is clipped",
+                    "type": "string"
+                },
+                "error_reason": {
+                    "type": "string"
+                },
                 "html": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "published_at": {
+                    "type": "string"
+                },
+                "reading_minutes": {
+                    "type": "integer"
+                },
+                "site_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tags": {
+                    "description": "only set by the /webs endpoints",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "thumbnail_url": {
                     "type": "string"
                 },
@@ -765,6 +3559,72 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "diff.Line": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "$ref": "#/definitions/diff.Op"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "diff.Op": {
+            "type": "string",
+            "enum": [
+                "equal",
+                "insert",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "Equal",
+                "Insert",
+                "Delete"
+            ]
+        },
+        "token.JSONWebKey": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "description": "Ed25519",
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "description": "RSA",
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "token.JSONWebKeySet": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/token.JSONWebKey"
+                    }
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,158 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ManifestName is the path of the manifest in the archive
+const ManifestName = "manifest.json"
+
+// Manifest describes everything in the archive, the content of notes and webs is in the files it points to
+type Manifest struct {
+	ExportedAt time.Time `json:"exported_at"`
+	User       User      `json:"user"`
+	Notes      []Note    `json:"notes"`
+	Webs       []Web     `json:"webs"`
+}
+
+type User struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Note struct {
+	ID        uuid.UUID   `json:"id"`
+	Title     string      `json:"title"`
+	IsPublic  bool        `json:"is_public"`
+	Version   int32       `json:"version"`
+	WebIDs    []uuid.UUID `json:"web_ids"`
+	Tags      []string    `json:"tags"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	// Path is the markdown file of the note
+	Path string `json:"path"`
+}
+
+type Web struct {
+	ID           uuid.UUID  `json:"id"`
+	URL          string     `json:"url"`
+	Title        string     `json:"title"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	SiteName     string     `json:"site_name"`
+	Author       string     `json:"author"`
+	Description  string     `json:"description"`
+	PublishedAt  *time.Time `json:"published_at"`
+	Tags         []string   `json:"tags"`
+	CreatedAt    time.Time  `json:"created_at"`
+	// HTMLPath is the page as it was fetched
+	HTMLPath string `json:"html_path"`
+	// ArticlePath is the readable part of the page as markdown, empty when it wasn't extracted
+	ArticlePath string `json:"article_path"`
+}
+
+// Writer writes a zip archive as notes and webs are added, so that the whole export never sits in memory.
+// The manifest is written on Close.
+type Writer struct {
+	zw       *zip.Writer
+	manifest Manifest
+}
+
+func NewWriter(w io.Writer, user User) *Writer {
+	return &Writer{
+		zw: zip.NewWriter(w),
+		manifest: Manifest{
+			ExportedAt: time.Now().UTC(),
+			User:       user,
+			Notes:      []Note{},
+			Webs:       []Web{},
+		},
+	}
+}
+
+// AddNote writes the content of the note as markdown with its title as heading
+func (w *Writer) AddNote(note Note, content string) error {
+	note.Path = fmt.Sprintf("notes/%s.md", note.ID)
+	if note.WebIDs == nil {
+		note.WebIDs = []uuid.UUID{}
+	}
+	if note.Tags == nil {
+		note.Tags = []string{}
+	}
+
+	var b strings.Builder
+	if note.Title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", note.Title)
+	}
+	b.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		b.WriteString("\n")
+	}
+
+	if err := w.writeFile(note.Path, note.UpdatedAt, b.String()); err != nil {
+		return err
+	}
+	w.manifest.Notes = append(w.manifest.Notes, note)
+	return nil
+}
+
+// AddWeb writes the stored html of the web and its article when there is one
+func (w *Writer) AddWeb(web Web, html string, articleMarkdown string) error {
+	web.HTMLPath = fmt.Sprintf("webs/%s.html", web.ID)
+	if web.Tags == nil {
+		web.Tags = []string{}
+	}
+	if err := w.writeFile(web.HTMLPath, web.CreatedAt, html); err != nil {
+		return err
+	}
+
+	if articleMarkdown != "" {
+		web.ArticlePath = fmt.Sprintf("webs/%s.md", web.ID)
+		if err := w.writeFile(web.ArticlePath, web.CreatedAt, articleMarkdown); err != nil {
+			return err
+		}
+	}
+
+	w.manifest.Webs = append(w.manifest.Webs, web)
+	return nil
+}
+
+// Close writes the manifest and finishes the archive, it doesn't close the underlying writer
+func (w *Writer) Close() error {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     ManifestName,
+		Method:   zip.Deflate,
+		Modified: w.manifest.ExportedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(w.manifest); err != nil {
+		return err
+	}
+
+	return w.zw.Close()
+}
+
+func (w *Writer) writeFile(name string, modified time.Time, content string) error {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
@@ -0,0 +1,73 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func readFile(t *testing.T, r *zip.Reader, name string) string {
+	f, err := r.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	user := User{ID: uuid.New(), Email: "user@example.com", CreatedAt: time.Now()}
+	w := NewWriter(&buf, user)
+
+	note := Note{ID: uuid.New(), Title: "Title", Tags: []string{"go"}, WebIDs: []uuid.UUID{uuid.New()}}
+	require.NoError(t, w.AddNote(note, "content"))
+
+	web := Web{ID: uuid.New(), URL: "https://example.com"}
+	require.NoError(t, w.AddWeb(web, "<html></html>", "# Article\n"))
+
+	bare := Web{ID: uuid.New(), URL: "https://example.com/bare"}
+	require.NoError(t, w.AddWeb(bare, "<html></html>", ""))
+
+	require.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, r, ManifestName)), &manifest))
+	require.Equal(t, user.ID, manifest.User.ID)
+	require.Equal(t, user.Email, manifest.User.Email)
+
+	require.Len(t, manifest.Notes, 1)
+	require.Equal(t, note.Tags, manifest.Notes[0].Tags)
+	require.Equal(t, note.WebIDs, manifest.Notes[0].WebIDs)
+	require.Equal(t, "# Title\n\ncontent\n", readFile(t, r, manifest.Notes[0].Path))
+
+	require.Len(t, manifest.Webs, 2)
+	require.Equal(t, "<html></html>", readFile(t, r, manifest.Webs[0].HTMLPath))
+	require.Equal(t, "# Article\n", readFile(t, r, manifest.Webs[0].ArticlePath))
+	require.Empty(t, manifest.Webs[1].ArticlePath)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, User{ID: uuid.New()})
+	require.NoError(t, w.Close())
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, r.File, 1)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, r, ManifestName)), &manifest))
+	require.NotNil(t, manifest.Notes)
+	require.NotNil(t, manifest.Webs)
+}
@@ -0,0 +1,227 @@
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const (
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+
+	atomNS = "http://www.w3.org/2005/Atom"
+)
+
+// Feed is rendered as Atom 1.0 (RFC 4287) or RSS 2.0, entries are expected newest first
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the page of the feed for people
+	Link string
+	// SelfLink is where the feed itself is served, it is also the id of the Atom feed
+	SelfLink string
+	Author   Person
+	Updated  time.Time
+	Entries  []Entry
+}
+
+type Person struct {
+	Name string
+	URI  string
+}
+
+type Entry struct {
+	// ID never changes for the entry, readers use it to tell entries apart
+	ID        string
+	Title     string
+	Link      string
+	Summary   string
+	Content   string
+	Published time.Time
+	Updated   time.Time
+	// Related are the pages the entry is about, the first one is the RSS enclosure
+	Related []Link
+}
+
+type Link struct {
+	Href  string
+	Title string
+	// Type is the media type of Href, text/html when empty
+	Type string
+}
+
+func (link Link) mediaType() string {
+	if link.Type == "" {
+		return "text/html"
+	}
+	return link.Type
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomPerson  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel   string `xml:"rel,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Links     []atomLink `xml:"link"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+	Content   *atomText  `xml:"content,omitempty"`
+}
+
+// Atom returns the feed as an Atom document
+func (feed *Feed) Atom() ([]byte, error) {
+	doc := atomFeed{
+		XMLNS:    atomNS,
+		ID:       feed.SelfLink,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  feed.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: feed.SelfLink},
+			{Rel: "alternate", Type: "text/html", Href: feed.Link},
+		},
+		Author:  atomPerson{Name: feed.Author.Name, URI: feed.Author.URI},
+		Entries: make([]atomEntry, len(feed.Entries)),
+	}
+
+	for i, entry := range feed.Entries {
+		e := atomEntry{
+			ID:        entry.ID,
+			Title:     entry.Title,
+			Published: entry.Published.UTC().Format(time.RFC3339),
+			Updated:   entry.Updated.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Rel: "alternate", Type: "text/html", Href: entry.Link}},
+		}
+		for _, link := range entry.Related {
+			e.Links = append(e.Links, atomLink{Rel: "related", Type: link.mediaType(), Href: link.Href, Title: link.Title})
+		}
+		if entry.Summary != "" {
+			e.Summary = &atomText{Type: "text", Body: entry.Summary}
+		}
+		if entry.Content != "" {
+			e.Content = &atomText{Type: "text", Body: entry.Content}
+		}
+		doc.Entries[i] = e
+	}
+
+	return marshal(doc)
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	SelfLink      rssSelf   `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+// rssSelf is the atom:link RSS validators recommend, so readers know where the feed moved
+type rssSelf struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description,omitempty"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL string `xml:"url,attr"`
+	// the size isn't known without fetching the page, 0 is what readers expect then
+	Length int    `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// RSS returns the feed as an RSS 2.0 document.
+// RSS has room for one enclosure per item, so only the first related link is kept, the summary is the description.
+func (feed *Feed) RSS() ([]byte, error) {
+	description := feed.Description
+	if description == "" {
+		// required by RSS
+		description = feed.Title
+	}
+
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  atomNS,
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   description,
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+			SelfLink:      rssSelf{Rel: "self", Type: "application/rss+xml", Href: feed.SelfLink},
+			Items:         make([]rssItem, len(feed.Entries)),
+		},
+	}
+
+	for i, entry := range feed.Entries {
+		item := rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			Description: entry.Summary,
+			GUID:        rssGUID{IsPermaLink: false, Value: entry.ID},
+			PubDate:     entry.Published.UTC().Format(time.RFC1123Z),
+		}
+		if len(entry.Related) > 0 {
+			item.Enclosure = &rssEnclosure{URL: entry.Related[0].Href, Type: entry.Related[0].mediaType()}
+		}
+		doc.Channel.Items[i] = item
+	}
+
+	return marshal(doc)
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,133 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() Feed {
+	published := time.Date(2022, 12, 1, 9, 30, 0, 0, time.FixedZone("JST", 9*60*60))
+	return Feed{
+		Title:       "Notes of ink",
+		Description: "What ink clipped",
+		Link:        "https://inkclip.app/u/ink",
+		SelfLink:    "https://api.inkclip.app/u/ink/feed.atom",
+		Author:      Person{Name: "Ink", URI: "https://inkclip.app/u/ink"},
+		Updated:     published.Add(time.Hour),
+		Entries: []Entry{
+			{
+				ID:        "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+				Title:     "Fish & <chips>",
+				Link:      "https://api.inkclip.app/public_notes/6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+				Summary:   "a summary",
+				Content:   "# Fish\n\n& chips",
+				Published: published,
+				Updated:   published.Add(time.Hour),
+				Related: []Link{
+					{Href: "https://example.com/a?x=1&y=2", Title: "A"},
+					{Href: "https://example.com/b", Title: "B"},
+				},
+			},
+			{
+				ID:        "urn:uuid:6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+				Title:     "No webs",
+				Link:      "https://api.inkclip.app/public_notes/6ba7b811-9dad-11d1-80b4-00c04fd430c8",
+				Published: published,
+				Updated:   published,
+			},
+		},
+	}
+}
+
+func TestAtom(t *testing.T) {
+	feed := testFeed()
+	body, err := feed.Atom()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(body), xml.Header))
+
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			ID      string `xml:"id"`
+			Title   string `xml:"title"`
+			Updated string `xml:"updated"`
+			Content string `xml:"content"`
+			Summary string `xml:"summary"`
+			Links   []struct {
+				Rel  string `xml:"rel,attr"`
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	require.Equal(t, feed.SelfLink, doc.ID)
+	require.Equal(t, "2022-12-01T01:30:00Z", doc.Updated)
+	require.Len(t, doc.Entries, 2)
+
+	entry := doc.Entries[0]
+	require.Equal(t, feed.Entries[0].ID, entry.ID)
+	require.Equal(t, "Fish & <chips>", entry.Title)
+	require.Equal(t, feed.Entries[0].Content, entry.Content)
+	require.Len(t, entry.Links, 3)
+	require.Equal(t, "alternate", entry.Links[0].Rel)
+	require.Equal(t, "related", entry.Links[1].Rel)
+	require.Equal(t, "https://example.com/a?x=1&y=2", entry.Links[1].Href)
+
+	require.Empty(t, doc.Entries[1].Summary)
+	require.NotContains(t, string(body), "<summary type=\"text\"></summary>")
+}
+
+func TestRSS(t *testing.T) {
+	feed := testFeed()
+	feed.Description = ""
+	body, err := feed.RSS()
+	require.NoError(t, err)
+
+	var doc struct {
+		XMLName xml.Name `xml:"rss"`
+		Version string   `xml:"version,attr"`
+		Channel struct {
+			Title         string `xml:"title"`
+			Description   string `xml:"description"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title string `xml:"title"`
+				GUID  struct {
+					IsPermaLink string `xml:"isPermaLink,attr"`
+					Value       string `xml:",chardata"`
+				} `xml:"guid"`
+				PubDate   string `xml:"pubDate"`
+				Enclosure *struct {
+					URL    string `xml:"url,attr"`
+					Length string `xml:"length,attr"`
+					Type   string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &doc))
+
+	require.Equal(t, "2.0", doc.Version)
+	require.Equal(t, feed.Title, doc.Channel.Description)
+	require.Equal(t, "Thu, 01 Dec 2022 01:30:00 +0000", doc.Channel.LastBuildDate)
+	require.Contains(t, string(body), `<atom:link rel="self" type="application/rss+xml" href="https://api.inkclip.app/u/ink/feed.atom"></atom:link>`)
+	require.Len(t, doc.Channel.Items, 2)
+
+	item := doc.Channel.Items[0]
+	require.Equal(t, "Fish & <chips>", item.Title)
+	require.Equal(t, "false", item.GUID.IsPermaLink)
+	require.Equal(t, feed.Entries[0].ID, item.GUID.Value)
+	require.NotNil(t, item.Enclosure)
+	require.Equal(t, "https://example.com/a?x=1&y=2", item.Enclosure.URL)
+	require.Equal(t, "0", item.Enclosure.Length)
+	require.Equal(t, "text/html", item.Enclosure.Type)
+
+	require.Nil(t, doc.Channel.Items[1].Enclosure)
+}
@@ -0,0 +1,265 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/inkclip/backend/config"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxBodySize  = 5 << 20
+	defaultMaxRedirects = 5
+	userAgent           = "inkclip-fetcher/1.0 (+https://inkclip.app)"
+)
+
+var (
+	ErrInvalidURL             = errors.New("url is invalid")
+	ErrForbiddenAddress       = errors.New("url resolves to a forbidden address")
+	ErrTooManyRedirects       = errors.New("too many redirects")
+	ErrBodyTooLarge           = errors.New("response body is too large")
+	ErrUnsupportedContentType = errors.New("content type is not supported")
+	ErrUpstreamStatus         = errors.New("upstream returned an error status")
+	ErrUpstream               = errors.New("cannot fetch url")
+)
+
+// Fetcher downloads remote pages on behalf of users
+type Fetcher interface {
+	// Fetch downloads the html page at rawURL
+	Fetch(ctx context.Context, rawURL string) (*Page, error)
+}
+
+// Page is a fetched html document
+type Page struct {
+	// URL is the final url after following redirects
+	URL         string
+	ContentType string
+	Body        []byte
+}
+
+type HTTPFetcher struct {
+	client       *http.Client
+	maxBodySize  int64
+	maxRedirects int
+
+	// overridable in tests
+	lookupIPAddr func(ctx context.Context, host string) ([]net.IPAddr, error)
+	isBlockedIP  func(ip net.IP) bool
+}
+
+func NewHTTPFetcher(config config.Config) Fetcher {
+	fetcher := &HTTPFetcher{
+		maxBodySize:  config.FetchMaxBodySize,
+		maxRedirects: config.FetchMaxRedirects,
+		lookupIPAddr: net.DefaultResolver.LookupIPAddr,
+		isBlockedIP:  isBlockedIP,
+	}
+	if fetcher.maxBodySize <= 0 {
+		fetcher.maxBodySize = defaultMaxBodySize
+	}
+	if fetcher.maxRedirects <= 0 {
+		fetcher.maxRedirects = defaultMaxRedirects
+	}
+
+	timeout := config.FetchTimeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	transport := &http.Transport{
+		// never go through a proxy, otherwise the address check would only see the proxy
+		Proxy:                 nil,
+		DialContext:           fetcher.dialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	fetcher.client = &http.Client{
+		Transport:     transport,
+		Timeout:       timeout,
+		CheckRedirect: fetcher.checkRedirect,
+	}
+
+	return fetcher
+}
+
+func (fetcher *HTTPFetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := validateURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	res, err := fetcher.client.Do(req)
+	if err != nil {
+		return nil, unwrapClientError(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: %s", ErrUpstreamStatus, res.Status)
+	}
+
+	if res.ContentLength > fetcher.maxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, fetcher.maxBodySize+1))
+	if err != nil {
+		return nil, unwrapClientError(err)
+	}
+	if int64(len(body)) > fetcher.maxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	if !isHTML(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, contentType)
+	}
+
+	return &Page{
+		URL:         res.Request.URL.String(),
+		ContentType: contentType,
+		Body:        body,
+	}, nil
+}
+
+func (fetcher *HTTPFetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) > fetcher.maxRedirects {
+		return ErrTooManyRedirects
+	}
+	if _, err := validateURL(req.URL.String()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// dialContext resolves the host itself and connects to a checked address,
+// so every hop of a redirect chain is validated and DNS rebinding can't swap the ip afterwards.
+func (fetcher *HTTPFetcher) dialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := fetcher.lookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address found for %s", host)
+	}
+	for _, addr := range addrs {
+		if fetcher.isBlockedIP(addr.IP) {
+			return nil, fmt.Errorf("%w: %s", ErrForbiddenAddress, addr.IP)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	var conn net.Conn
+	for _, addr := range addrs {
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+func validateURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidURL, u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials are not allowed", ErrInvalidURL)
+	}
+	return u, nil
+}
+
+func unwrapClientError(err error) error {
+	for _, target := range []error{ErrInvalidURL, ErrForbiddenAddress, ErrTooManyRedirects} {
+		if errors.Is(err, target) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrUpstream, err)
+}
+
+func isHTML(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	mediaType = strings.ToLower(mediaType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+var blockedNetworks = func() []*net.IPNet {
+	cidrs := []string{
+		"0.0.0.0/8",          // "this" network
+		"100.64.0.0/10",      // carrier-grade NAT
+		"192.0.0.0/24",       // IETF protocol assignments
+		"192.0.2.0/24",       // TEST-NET-1
+		"198.18.0.0/15",      // benchmarking
+		"198.51.100.0/24",    // TEST-NET-2
+		"203.0.113.0/24",     // TEST-NET-3
+		"240.0.0.0/4",        // reserved
+		"255.255.255.255/32", // broadcast
+		"64:ff9b::/96",       // NAT64
+		"2001:db8::/32",      // documentation
+	}
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}()
+
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
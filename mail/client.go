@@ -2,14 +2,20 @@ package mail
 
 import (
 	"fmt"
+	"html"
 	"net/smtp"
 	"os"
+	"time"
 
 	"github.com/inkclip/backend/config"
 )
 
 type Client interface {
 	VertifyMailContent(recipient string, token string) SendContent
+	PasswordResetMailContent(recipient string, token string) SendContent
+	EmailChangeMailContent(recipient string, token string) SendContent
+	EmailChangeNoticeMailContent(recipient string, newEmail string) SendContent
+	LoginLockoutMailContent(recipient string, lockedUntil time.Time) SendContent
 	Send(content SendContent) error
 }
 
@@ -40,6 +46,54 @@ func (client *MailClient) VertifyMailContent(recipient string, token string) Sen
 	}
 }
 
+func (client *MailClient) PasswordResetMailContent(recipient string, token string) SendContent {
+	link := fmt.Sprintf("<a href='%s/password/reset?token=%s'>reset password</a>", client.config.FrontURL, token)
+	return SendContent{
+		Recipient: recipient,
+		Subject:   "Reset your password",
+		Body: fmt.Sprintf(
+			"Please click the following link to set a new password: %s<br>The link expires in %s. If you didn't ask for it, you can ignore this mail.",
+			link, client.config.PasswordResetTokenDuration,
+		),
+	}
+}
+
+func (client *MailClient) EmailChangeMailContent(recipient string, token string) SendContent {
+	link := fmt.Sprintf("<a href='%s/email/confirm?token=%s'>confirm</a>", client.config.FrontURL, token)
+	return SendContent{
+		Recipient: recipient,
+		Subject:   "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Please click the following link to use this address for your account: %s<br>The link expires in %s.",
+			link, client.config.EmailChangeTokenDuration,
+		),
+	}
+}
+
+func (client *MailClient) EmailChangeNoticeMailContent(recipient string, newEmail string) SendContent {
+	// the new address is only checked to be an email, it could still carry markup
+	return SendContent{
+		Recipient: recipient,
+		Subject:   "Your email address is being changed",
+		Body: fmt.Sprintf(
+			"A change of the email address of your account to %s was requested. It takes effect once the new address is confirmed.<br>If you didn't ask for it, please change your password.",
+			html.EscapeString(newEmail),
+		),
+	}
+}
+
+func (client *MailClient) LoginLockoutMailContent(recipient string, lockedUntil time.Time) SendContent {
+	link := fmt.Sprintf("<a href='%s/password/forgot'>reset your password</a>", client.config.FrontURL)
+	return SendContent{
+		Recipient: recipient,
+		Subject:   "Sign in to your account is locked",
+		Body: fmt.Sprintf(
+			"There were too many failed attempts to sign in to your account, so signing in is locked until %s.<br>If it wasn't you, someone may be guessing your password, you can %s.",
+			lockedUntil.UTC().Format(time.RFC1123), link,
+		),
+	}
+}
+
 func (client *MailClient) Send(content SendContent) error {
 	from := "noreply@inkclip.app"
 	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
@@ -1,7 +1,9 @@
 package mail
 
 import (
+	"html"
 	"testing"
+	"time"
 
 	"github.com/inkclip/backend/util"
 	"github.com/stretchr/testify/require"
@@ -27,3 +29,59 @@ func TestVertifyMailContent(t *testing.T) {
 	err := client.Send(arg)
 	require.NoError(t, err)
 }
+
+func TestPasswordResetMailContent(t *testing.T) {
+	client := newMailClient(t)
+	recipient := util.RandomEmail()
+	token := util.RandomString(10)
+	arg := client.PasswordResetMailContent(recipient, token)
+	require.Equal(t, recipient, arg.Recipient)
+	require.Contains(t, arg.Body, token)
+
+	err := client.Send(arg)
+	require.NoError(t, err)
+}
+
+func TestEmailChangeMailContent(t *testing.T) {
+	client := newMailClient(t)
+	recipient := util.RandomEmail()
+	token := util.RandomString(10)
+	arg := client.EmailChangeMailContent(recipient, token)
+	require.Equal(t, recipient, arg.Recipient)
+	require.Contains(t, arg.Body, token)
+
+	err := client.Send(arg)
+	require.NoError(t, err)
+}
+
+func TestEmailChangeNoticeMailContent(t *testing.T) {
+	client := newMailClient(t)
+	recipient := util.RandomEmail()
+	newEmail := util.RandomEmail()
+	arg := client.EmailChangeNoticeMailContent(recipient, newEmail)
+	require.Equal(t, recipient, arg.Recipient)
+	require.Contains(t, arg.Body, newEmail)
+
+	err := client.Send(arg)
+	require.NoError(t, err)
+}
+
+func TestEmailChangeNoticeMailContentEscaped(t *testing.T) {
+	client := newMailClient(t)
+	newEmail := `"<a href='https://example.com'>x</a>"@example.com`
+	arg := client.EmailChangeNoticeMailContent(util.RandomEmail(), newEmail)
+	require.NotContains(t, arg.Body, "<a href")
+	require.Contains(t, arg.Body, html.EscapeString(newEmail))
+}
+
+func TestLoginLockoutMailContent(t *testing.T) {
+	client := newMailClient(t)
+	recipient := util.RandomEmail()
+	lockedUntil := time.Now().Add(time.Minute)
+	arg := client.LoginLockoutMailContent(recipient, lockedUntil)
+	require.Equal(t, recipient, arg.Recipient)
+	require.Contains(t, arg.Body, lockedUntil.UTC().Format(time.RFC1123))
+
+	err := client.Send(arg)
+	require.NoError(t, err)
+}
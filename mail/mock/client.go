@@ -6,6 +6,7 @@ package mockmail
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	mail "github.com/inkclip/backend/mail"
@@ -34,6 +35,62 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// EmailChangeMailContent mocks base method.
+func (m *MockClient) EmailChangeMailContent(arg0, arg1 string) mail.SendContent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmailChangeMailContent", arg0, arg1)
+	ret0, _ := ret[0].(mail.SendContent)
+	return ret0
+}
+
+// EmailChangeMailContent indicates an expected call of EmailChangeMailContent.
+func (mr *MockClientMockRecorder) EmailChangeMailContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmailChangeMailContent", reflect.TypeOf((*MockClient)(nil).EmailChangeMailContent), arg0, arg1)
+}
+
+// EmailChangeNoticeMailContent mocks base method.
+func (m *MockClient) EmailChangeNoticeMailContent(arg0, arg1 string) mail.SendContent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmailChangeNoticeMailContent", arg0, arg1)
+	ret0, _ := ret[0].(mail.SendContent)
+	return ret0
+}
+
+// EmailChangeNoticeMailContent indicates an expected call of EmailChangeNoticeMailContent.
+func (mr *MockClientMockRecorder) EmailChangeNoticeMailContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmailChangeNoticeMailContent", reflect.TypeOf((*MockClient)(nil).EmailChangeNoticeMailContent), arg0, arg1)
+}
+
+// LoginLockoutMailContent mocks base method.
+func (m *MockClient) LoginLockoutMailContent(arg0 string, arg1 time.Time) mail.SendContent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginLockoutMailContent", arg0, arg1)
+	ret0, _ := ret[0].(mail.SendContent)
+	return ret0
+}
+
+// LoginLockoutMailContent indicates an expected call of LoginLockoutMailContent.
+func (mr *MockClientMockRecorder) LoginLockoutMailContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginLockoutMailContent", reflect.TypeOf((*MockClient)(nil).LoginLockoutMailContent), arg0, arg1)
+}
+
+// PasswordResetMailContent mocks base method.
+func (m *MockClient) PasswordResetMailContent(arg0, arg1 string) mail.SendContent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PasswordResetMailContent", arg0, arg1)
+	ret0, _ := ret[0].(mail.SendContent)
+	return ret0
+}
+
+// PasswordResetMailContent indicates an expected call of PasswordResetMailContent.
+func (mr *MockClientMockRecorder) PasswordResetMailContent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PasswordResetMailContent", reflect.TypeOf((*MockClient)(nil).PasswordResetMailContent), arg0, arg1)
+}
+
 // Send mocks base method.
 func (m *MockClient) Send(arg0 mail.SendContent) error {
 	m.ctrl.T.Helper()
@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/inkclip/backend/api"
+	"github.com/inkclip/backend/blob"
 	"github.com/inkclip/backend/config"
 	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/fetcher"
 	"github.com/inkclip/backend/mail"
+	"github.com/inkclip/backend/worker"
+	"go.uber.org/zap"
 
 	_ "github.com/lib/pq"
 )
@@ -35,15 +43,41 @@ func main() {
 
 	mailClient := mail.NewMailClient(config)
 
-	server, err := api.NewServer(config, store, mailClient)
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal("cannot create logger: ", err)
+	}
+	defer logger.Sync()
+
+	fetcher := fetcher.NewHTTPFetcher(config)
+	// fly stops the app with SIGINT, docker with SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool := worker.NewPool(config, store, fetcher, logger)
+	pool.Start(ctx)
+
+	sweeper := worker.NewSweeper(config, store, logger)
+	sweeper.Start(ctx)
+
+	blobStore, err := blob.NewStore(config)
+	if err != nil {
+		log.Fatal("cannot create blob store: ", err)
+	}
+
+	server, err := api.NewServer(config, store, mailClient, blobStore)
 	if err != nil {
 		log.Fatal("cannot create server: ", err)
 	}
 
-	err = server.Start(config.ServerAddress)
+	err = server.Start(ctx, config.ServerAddress)
 	if err != nil {
 		log.Fatal("cannot start server: ", err)
 	}
+
+	// the jobs that were claimed are finished, so that they aren't left locked for the next start
+	log.Println("waiting for the workers to finish")
+	pool.Wait()
 }
 
 func runDBMigration(migrationURL string, dbSource string) {
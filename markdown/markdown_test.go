@@ -0,0 +1,60 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "Markdown",
+			source:   "# Title\n\nSome **bold** and ~~gone~~.\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n- [x] done",
+			contains: []string{"<h1>Title</h1>", "<strong>bold</strong>", "<del>gone</del>", "<table>", "<td>1</td>", `<input checked="" disabled="" type="checkbox">`},
+		},
+		{
+			name:     "RawHTML",
+			source:   "hi <script>alert(1)</script> <img src=x onerror=alert(1)>\n\n<iframe src=\"https://evil.example\"></iframe>",
+			contains: []string{"hi"},
+			// the text between the dropped tags stays, as text
+			excludes: []string{"<script", "onerror", "<iframe"},
+		},
+		{
+			name:     "JavascriptLink",
+			source:   "[click](javascript:alert(1)) and [data](data:text/html;base64,PHNjcmlwdD4=)",
+			excludes: []string{"javascript:", "data:text/html"},
+		},
+		{
+			name:     "ExternalLink",
+			source:   "see https://example.com/a",
+			contains: []string{`href="https://example.com/a"`, "nofollow", "noreferrer", `target="_blank"`},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			html, err := ToHTML(tc.source)
+			require.NoError(t, err)
+			for _, s := range tc.contains {
+				require.Contains(t, html, s)
+			}
+			for _, s := range tc.excludes {
+				require.NotContains(t, html, s)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	source := "# Fish & chips\n\nA *very* good\n[place](https://example.com) to eat.\n\n```go\nfmt.Println()\n```\n\n<div>raw</div>\n\n- one\n- two <https://example.com/b>"
+	require.Equal(t, "Fish & chips A very good place to eat. one two https://example.com/b", PlainText(source))
+	require.Empty(t, PlainText(""))
+}
@@ -0,0 +1,196 @@
+package oidc
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/inkclip/backend/oidc/oidctest"
+	"github.com/stretchr/testify/require"
+)
+
+const redirectURL = "https://inkclip.example/oidc/callback"
+
+func newTestProvider(t *testing.T) (*Provider, *oidctest.Provider) {
+	stub := oidctest.NewProvider("client", "secret&with=symbols")
+	t.Cleanup(stub.Close)
+
+	provider := NewProvider(Config{
+		Issuer:       stub.Issuer(),
+		ClientID:     stub.ClientID,
+		ClientSecret: stub.ClientSecret,
+		RedirectURL:  redirectURL,
+	}, nil)
+	return provider, stub
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	verifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+	require.Len(t, verifier, 43)
+}
+
+func TestFlow(t *testing.T) {
+	provider, stub := newTestProvider(t)
+	stub.SetNextUser(oidctest.User{Subject: "1234", Email: "user@example.com", EmailVerified: true})
+
+	verifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, redirectURL, u.Query().Get("redirect_uri"))
+	require.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+
+	code, state, err := stub.Authorize(authURL)
+	require.NoError(t, err)
+	require.Equal(t, "state", state)
+
+	rawIDToken, err := provider.Exchange(context.Background(), code, verifier)
+	require.NoError(t, err)
+
+	idToken, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce")
+	require.NoError(t, err)
+	require.Equal(t, stub.Issuer(), idToken.Issuer)
+	require.Equal(t, "1234", idToken.Subject)
+	require.Equal(t, "user@example.com", idToken.Email)
+	require.True(t, idToken.EmailVerified)
+
+	// the code is used once
+	_, err = provider.Exchange(context.Background(), code, verifier)
+	require.Error(t, err)
+}
+
+func TestExchangeWrongVerifier(t *testing.T) {
+	provider, stub := newTestProvider(t)
+
+	verifier, err := NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, _, err := stub.Authorize(authURL)
+	require.NoError(t, err)
+
+	other, err := NewCodeVerifier()
+	require.NoError(t, err)
+	_, err = provider.Exchange(context.Background(), code, other)
+	require.Error(t, err)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, stub := newTestProvider(t)
+	user := oidctest.User{Subject: "1234", Email: "user@example.com"}
+
+	testCases := []struct {
+		name   string
+		claims func(claims jwt.MapClaims)
+		nonce  string
+		ok     bool
+	}{
+		{
+			name:   "OK",
+			claims: func(claims jwt.MapClaims) {},
+			nonce:  "nonce",
+			ok:     true,
+		},
+		{
+			name: "AudienceList",
+			claims: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{"other", stub.ClientID}
+			},
+			nonce: "nonce",
+			ok:    true,
+		},
+		{
+			name:   "WrongNonce",
+			claims: func(claims jwt.MapClaims) {},
+			nonce:  "other",
+		},
+		{
+			name: "WrongIssuer",
+			claims: func(claims jwt.MapClaims) {
+				claims["iss"] = "https://evil.example"
+			},
+			nonce: "nonce",
+		},
+		{
+			name: "WrongAudience",
+			claims: func(claims jwt.MapClaims) {
+				claims["aud"] = "other"
+			},
+			nonce: "nonce",
+		},
+		{
+			name: "Expired",
+			claims: func(claims jwt.MapClaims) {
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			},
+			nonce: "nonce",
+		},
+		{
+			name: "NoExpiry",
+			claims: func(claims jwt.MapClaims) {
+				delete(claims, "exp")
+			},
+			nonce: "nonce",
+		},
+		{
+			name: "NoSubject",
+			claims: func(claims jwt.MapClaims) {
+				delete(claims, "sub")
+			},
+			nonce: "nonce",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			claims := stub.IDTokenClaims(user, "nonce")
+			tc.claims(claims)
+
+			_, err := provider.VerifyIDToken(context.Background(), stub.SignIDToken(claims), tc.nonce)
+			if tc.ok {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidIDToken)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenSignedByAnotherKey(t *testing.T) {
+	provider, stub := newTestProvider(t)
+	other := oidctest.NewProvider(stub.ClientID, stub.ClientSecret)
+	defer other.Close()
+
+	claims := stub.IDTokenClaims(oidctest.User{Subject: "1234"}, "nonce")
+	_, err := provider.VerifyIDToken(context.Background(), other.SignIDToken(claims), "nonce")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+
+	// HS256 with the public key as secret must not pass
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "test-key"
+	hs, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = provider.VerifyIDToken(context.Background(), hs, "nonce")
+	require.ErrorIs(t, err, ErrInvalidIDToken)
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	stub := oidctest.NewProvider("client", "secret")
+	defer stub.Close()
+
+	provider := NewProvider(Config{Issuer: stub.Issuer() + "/", ClientID: "client"}, nil)
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	require.Error(t, err)
+}
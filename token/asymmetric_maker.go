@@ -0,0 +1,247 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const minRSAKeySize = 2048
+
+// JSONWebKey is the public part of a key in the JWK format of RFC 7517
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// KeyPublisher is implemented by makers whose tokens can be verified by other services with public keys
+type KeyPublisher interface {
+	KeySet() JSONWebKeySet
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+	jwk    JSONWebKey
+}
+
+// AsymmetricMaker signs with one private key, Ed25519 (EdDSA) or RSA (RS256), and stamps its kid in the header.
+// Tokens are verified with the key of their kid, so keys can be rotated by signing with a new key
+// while the previous public key stays a verification key until its tokens have expired.
+type AsymmetricMaker struct {
+	signingKey crypto.PrivateKey
+	signingKID string
+	method     jwt.SigningMethod
+	keys       map[string]verificationKey
+	// kids keeps the order of the key set, the signing key first
+	kids []string
+}
+
+// NewAsymmetricMaker takes a PEM encoded private key, PKCS #8 or PKCS #1 for RSA,
+// and the PEM encoded public keys that are still accepted besides the signing key.
+func NewAsymmetricMaker(signingKeyPEM []byte, verificationKeysPEM [][]byte) (Maker, error) {
+	signingKey, err := parsePrivateKey(signingKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+
+	maker := &AsymmetricMaker{
+		signingKey: signingKey,
+		keys:       make(map[string]verificationKey),
+	}
+
+	signingPublicKey, err := maker.addKey(signingKey.(crypto.Signer).Public())
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	maker.signingKID = signingPublicKey.jwk.Kid
+	maker.method = signingPublicKey.method
+
+	for i, keyPEM := range verificationKeysPEM {
+		key, err := parsePublicKey(keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification key %d: %w", i, err)
+		}
+		if _, err := maker.addKey(key); err != nil {
+			return nil, fmt.Errorf("invalid verification key %d: %w", i, err)
+		}
+	}
+
+	return maker, nil
+}
+
+func (maker *AsymmetricMaker) addKey(key crypto.PublicKey) (verificationKey, error) {
+	var vk verificationKey
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		vk = verificationKey{
+			method: jwt.SigningMethodEdDSA,
+			key:    k,
+			jwk: JSONWebKey{
+				Kty: "OKP",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(k),
+			},
+		}
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeySize {
+			return vk, fmt.Errorf("rsa key must be at least %d bits", minRSAKeySize)
+		}
+		vk = verificationKey{
+			method: jwt.SigningMethodRS256,
+			key:    k,
+			jwk: JSONWebKey{
+				Kty: "RSA",
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			},
+		}
+	default:
+		return vk, fmt.Errorf("unsupported key type %T", key)
+	}
+	vk.jwk.Use = "sig"
+	vk.jwk.Alg = vk.method.Alg()
+	vk.jwk.Kid = thumbprint(vk.jwk)
+
+	// the signing key may also be listed as a verification key
+	if _, ok := maker.keys[vk.jwk.Kid]; !ok {
+		maker.keys[vk.jwk.Kid] = vk
+		maker.kids = append(maker.kids, vk.jwk.Kid)
+	}
+	return vk, nil
+}
+
+func (maker *AsymmetricMaker) CreateToken(userID uuid.UUID, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(userID, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	jwtToken := jwt.NewWithClaims(maker.method, payload)
+	jwtToken.Header["kid"] = maker.signingKID
+	token, err := jwtToken.SignedString(maker.signingKey)
+	return token, payload, err
+}
+
+func (maker *AsymmetricMaker) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		vk, ok := maker.keys[kid]
+		// the alg must be the one of the key, a HS256 token keyed with the public key is refused here
+		if !ok || token.Method.Alg() != vk.method.Alg() {
+			return nil, ErrInvalidToken
+		}
+		return vk.key, nil
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
+
+// KeySet returns the signing key and the verification keys
+func (maker *AsymmetricMaker) KeySet() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, len(maker.kids))}
+	for i, kid := range maker.kids {
+		set.Keys[i] = maker.keys[kid].jwk
+	}
+	return set
+}
+
+// thumbprint is the JWK thumbprint of RFC 7638, so the kid of a key is the same wherever it's loaded
+func thumbprint(jwk JSONWebKey) string {
+	// the required members in lexicographic order
+	var members interface{}
+	switch jwk.Kty {
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	}
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func parsePrivateKey(keyPEM []byte) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case ed25519.PrivateKey, *rsa.PrivateKey:
+			return key, nil
+		}
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+func parsePublicKey(keyPEM []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("key must be PEM encoded")
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
@@ -0,0 +1,204 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func randomEd25519KeyPEM(t *testing.T) (privatePEM []byte, publicPEM []byte) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return encodeKeyPairPEM(t, priv, pub)
+}
+
+func randomRSAKeyPEM(t *testing.T, bits int) (privatePEM []byte, publicPEM []byte) {
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return encodeKeyPairPEM(t, priv, &priv.PublicKey)
+}
+
+func encodeKeyPairPEM(t *testing.T, priv interface{}, pub interface{}) ([]byte, []byte) {
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func TestAsymmetricMaker(t *testing.T) {
+	ed25519Key, _ := randomEd25519KeyPEM(t)
+	rsaKey, _ := randomRSAKeyPEM(t, 2048)
+
+	testCases := []struct {
+		name string
+		key  []byte
+		alg  string
+	}{
+		{name: "Ed25519", key: ed25519Key, alg: "EdDSA"},
+		{name: "RSA", key: rsaKey, alg: "RS256"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewAsymmetricMaker(tc.key, nil)
+			require.NoError(t, err)
+
+			userID := uuid.New()
+			sessionID := uuid.New()
+			issuedAt := time.Now()
+
+			token, _, err := maker.CreateToken(userID, sessionID, time.Minute)
+			require.NoError(t, err)
+
+			parsed, _, err := new(jwt.Parser).ParseUnverified(token, &Payload{})
+			require.NoError(t, err)
+			require.Equal(t, tc.alg, parsed.Method.Alg())
+
+			keySet := maker.(KeyPublisher).KeySet()
+			require.Len(t, keySet.Keys, 1)
+			require.Equal(t, keySet.Keys[0].Kid, parsed.Header["kid"])
+			require.Equal(t, tc.alg, keySet.Keys[0].Alg)
+
+			payload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, userID, payload.UserID)
+			require.Equal(t, sessionID, payload.SessionID)
+			require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+			require.WithinDuration(t, issuedAt.Add(time.Minute), payload.ExpiresAt, time.Second)
+
+			token, _, err = maker.CreateToken(userID, sessionID, -time.Minute)
+			require.NoError(t, err)
+			payload, err = maker.VerifyToken(token)
+			require.EqualError(t, err, ErrExpiredToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestAsymmetricMakerRotation(t *testing.T) {
+	oldKey, oldPublicKey := randomEd25519KeyPEM(t)
+	newKey, newPublicKey := randomRSAKeyPEM(t, 2048)
+
+	oldMaker, err := NewAsymmetricMaker(oldKey, nil)
+	require.NoError(t, err)
+	oldToken, _, err := oldMaker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	// without the previous key its tokens are refused
+	newMaker, err := NewAsymmetricMaker(newKey, nil)
+	require.NoError(t, err)
+	_, err = newMaker.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// the signing key listed again as a verification key is published once
+	newMaker, err = NewAsymmetricMaker(newKey, [][]byte{oldPublicKey, newPublicKey})
+	require.NoError(t, err)
+	_, err = newMaker.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	newToken, _, err := newMaker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+	_, err = oldMaker.VerifyToken(newToken)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	keySet := newMaker.(KeyPublisher).KeySet()
+	require.Len(t, keySet.Keys, 2)
+	require.Equal(t, "RSA", keySet.Keys[0].Kty)
+	require.Equal(t, oldMaker.(KeyPublisher).KeySet().Keys[0], keySet.Keys[1])
+}
+
+func TestAsymmetricMakerInvalidToken(t *testing.T) {
+	key, publicKey := randomRSAKeyPEM(t, 2048)
+	maker, err := NewAsymmetricMaker(key, nil)
+	require.NoError(t, err)
+	kid := maker.(KeyPublisher).KeySet().Keys[0].Kid
+
+	payload, err := NewPayload(uuid.New(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		token func(t *testing.T) string
+	}{
+		{
+			name: "AlgNone",
+			token: func(t *testing.T) string {
+				jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
+				jwtToken.Header["kid"] = kid
+				token, err := jwtToken.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+				return token
+			},
+		},
+		{
+			name: "HS256WithPublicKey",
+			token: func(t *testing.T) string {
+				jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+				jwtToken.Header["kid"] = kid
+				token, err := jwtToken.SignedString(publicKey)
+				require.NoError(t, err)
+				return token
+			},
+		},
+		{
+			name: "UnknownKid",
+			token: func(t *testing.T) string {
+				other, _ := randomEd25519KeyPEM(t)
+				otherMaker, err := NewAsymmetricMaker(other, nil)
+				require.NoError(t, err)
+				token, _, err := otherMaker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+				require.NoError(t, err)
+				return token
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := maker.VerifyToken(tc.token(t))
+			require.EqualError(t, err, ErrInvalidToken.Error())
+			require.Nil(t, payload)
+		})
+	}
+}
+
+func TestNewAsymmetricMakerInvalidKey(t *testing.T) {
+	key, publicKey := randomEd25519KeyPEM(t)
+	weakKey, _ := randomRSAKeyPEM(t, 1024)
+
+	_, err := NewAsymmetricMaker([]byte("not a key"), nil)
+	require.Error(t, err)
+
+	_, err = NewAsymmetricMaker(publicKey, nil)
+	require.Error(t, err)
+
+	_, err = NewAsymmetricMaker(weakKey, nil)
+	require.Error(t, err)
+
+	_, err = NewAsymmetricMaker(key, [][]byte{key})
+	require.Error(t, err)
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 7638 section 3.1
+	jwk := JSONWebKey{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	require.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint(jwk))
+}
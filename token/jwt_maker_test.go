@@ -16,12 +16,14 @@ func TestJWTMaker(t *testing.T) {
 
 	userID, err := uuid.NewRandom()
 	require.NoError(t, err)
+	sessionID, err := uuid.NewRandom()
+	require.NoError(t, err)
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiresAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(userID, duration)
+	token, payload, err := maker.CreateToken(userID, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -32,6 +34,7 @@ func TestJWTMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, userID, payload.UserID)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiresAt, payload.ExpiresAt, time.Second)
 }
@@ -43,7 +46,7 @@ func TestExpiredJWTToken(t *testing.T) {
 	userID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(userID, -time.Minute)
+	token, payload, err := maker.CreateToken(userID, uuid.New(), -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -58,7 +61,7 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	userID, err := uuid.NewRandom()
 	require.NoError(t, err)
 
-	payload, err := NewPayload(userID, time.Minute)
+	payload, err := NewPayload(userID, uuid.New(), time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
@@ -8,8 +8,8 @@ import (
 
 // Maker is an interface for managing tokens
 type Maker interface {
-	// CreateToken creates a new token for a specific userID and duration
-	CreateToken(userID uuid.UUID, duration time.Duration) (string, *Payload, error)
+	// CreateToken creates a new token for a specific userID, session and duration
+	CreateToken(userID uuid.UUID, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error)
 
 	// VerifyToken checks if the token is valid or not
 	VerifyToken(token string) (*Payload, error)
@@ -0,0 +1,118 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+// makerTestCases are all the Maker implementations the shared tests run against
+func makerTestCases(t *testing.T) []struct {
+	name  string
+	maker Maker
+} {
+	jwtMaker, err := NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	signingKey, _ := randomEd25519KeyPEM(t)
+	asymmetricMaker, err := NewAsymmetricMaker(signingKey, nil)
+	require.NoError(t, err)
+
+	pasetoLocalMaker, err := NewPasetoLocalMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	pasetoPublicMaker, err := NewPasetoPublicMaker(signingKey)
+	require.NoError(t, err)
+
+	return []struct {
+		name  string
+		maker Maker
+	}{
+		{name: "JWT", maker: jwtMaker},
+		{name: "AsymmetricJWT", maker: asymmetricMaker},
+		{name: "PasetoLocal", maker: pasetoLocalMaker},
+		{name: "PasetoPublic", maker: pasetoPublicMaker},
+	}
+}
+
+func TestMakers(t *testing.T) {
+	for _, tc := range makerTestCases(t) {
+		maker := tc.maker
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Run("Valid", func(t *testing.T) {
+				userID := uuid.New()
+				sessionID := uuid.New()
+				issuedAt := time.Now()
+
+				token, created, err := maker.CreateToken(userID, sessionID, time.Minute)
+				require.NoError(t, err)
+				require.NotEmpty(t, token)
+
+				payload, err := maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, created.ID, payload.ID)
+				require.Equal(t, userID, payload.UserID)
+				require.Equal(t, sessionID, payload.SessionID)
+				require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+				require.WithinDuration(t, issuedAt.Add(time.Minute), payload.ExpiresAt, time.Second)
+			})
+
+			t.Run("Expired", func(t *testing.T) {
+				token, _, err := maker.CreateToken(uuid.New(), uuid.New(), -time.Minute)
+				require.NoError(t, err)
+
+				payload, err := maker.VerifyToken(token)
+				require.EqualError(t, err, ErrExpiredToken.Error())
+				require.Nil(t, payload)
+			})
+
+			t.Run("Tampered", func(t *testing.T) {
+				token, _, err := maker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+				require.NoError(t, err)
+
+				// flip a character in the middle of the signed part
+				i := len(token) / 2
+				c := "A"
+				if token[i] == 'A' {
+					c = "B"
+				}
+				tampered := token[:i] + c + token[i+1:]
+
+				payload, err := maker.VerifyToken(tampered)
+				require.EqualError(t, err, ErrInvalidToken.Error())
+				require.Nil(t, payload)
+			})
+
+			t.Run("Garbage", func(t *testing.T) {
+				for _, token := range []string{"", "garbage", strings.Repeat("a.", 3)} {
+					payload, err := maker.VerifyToken(token)
+					require.EqualError(t, err, ErrInvalidToken.Error())
+					require.Nil(t, payload)
+				}
+			})
+		})
+	}
+}
+
+func TestMakersRefuseEachOther(t *testing.T) {
+	testCases := makerTestCases(t)
+
+	for _, issuer := range testCases {
+		token, _, err := issuer.maker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+		require.NoError(t, err)
+
+		for _, verifier := range testCases {
+			if verifier.name == issuer.name {
+				continue
+			}
+			payload, err := verifier.maker.VerifyToken(token)
+			require.EqualError(t, err, ErrInvalidToken.Error(), "%s token verified by %s", issuer.name, verifier.name)
+			require.Nil(t, payload)
+		}
+	}
+}
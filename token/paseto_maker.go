@@ -0,0 +1,242 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+)
+
+// PASETO version 4, https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md
+// There is no algorithm in the token to pick, the header fixes both the primitives and the key type.
+const (
+	pasetoLocalHeader  = "v4.local."
+	pasetoPublicHeader = "v4.public."
+
+	pasetoLocalKeySize = 32
+	pasetoNonceSize    = 32
+	pasetoMACSize      = 32
+)
+
+// PasetoLocalMaker encrypts the payload with a shared key, v4.local
+type PasetoLocalMaker struct {
+	key []byte
+}
+
+func NewPasetoLocalMaker(key string) (Maker, error) {
+	if len(key) != pasetoLocalKeySize {
+		return nil, fmt.Errorf("key must be exactly %d characters", pasetoLocalKeySize)
+	}
+	return &PasetoLocalMaker{key: []byte(key)}, nil
+}
+
+func (maker *PasetoLocalMaker) CreateToken(userID uuid.UUID, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(userID, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+
+	nonce := make([]byte, pasetoNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.encrypt(message, nonce)
+	if err != nil {
+		return "", payload, err
+	}
+	return token, payload, nil
+}
+
+// encrypt makes the token of the message with the nonce, every token needs a new random one
+func (maker *PasetoLocalMaker) encrypt(message []byte, nonce []byte) (string, error) {
+	encryptionKey, counterNonce, authKey := maker.splitKey(nonce)
+	cipher, err := chacha20.NewUnauthenticatedCipher(encryptionKey, counterNonce)
+	if err != nil {
+		return "", err
+	}
+	ciphertext := make([]byte, len(message))
+	cipher.XORKeyStream(ciphertext, message)
+
+	tag := pasetoMAC(authKey, pae([]byte(pasetoLocalHeader), nonce, ciphertext, nil, nil))
+
+	body := make([]byte, 0, len(nonce)+len(ciphertext)+len(tag))
+	body = append(body, nonce...)
+	body = append(body, ciphertext...)
+	body = append(body, tag...)
+	return pasetoLocalHeader + base64.RawURLEncoding.EncodeToString(body), nil
+}
+
+func (maker *PasetoLocalMaker) VerifyToken(token string) (*Payload, error) {
+	message, err := maker.decrypt(token)
+	if err != nil {
+		return nil, err
+	}
+	return parsePasetoPayload(message)
+}
+
+// decrypt returns the message of the token once its tag is checked
+func (maker *PasetoLocalMaker) decrypt(token string) ([]byte, error) {
+	body, err := decodePaseto(token, pasetoLocalHeader)
+	if err != nil || len(body) < pasetoNonceSize+pasetoMACSize {
+		return nil, ErrInvalidToken
+	}
+	nonce := body[:pasetoNonceSize]
+	ciphertext := body[pasetoNonceSize : len(body)-pasetoMACSize]
+	tag := body[len(body)-pasetoMACSize:]
+
+	encryptionKey, counterNonce, authKey := maker.splitKey(nonce)
+	expected := pasetoMAC(authKey, pae([]byte(pasetoLocalHeader), nonce, ciphertext, nil, nil))
+	if subtle.ConstantTimeCompare(tag, expected) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(encryptionKey, counterNonce)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	message := make([]byte, len(ciphertext))
+	cipher.XORKeyStream(message, ciphertext)
+	return message, nil
+}
+
+// splitKey derives the encryption key, the XChaCha20 nonce and the authentication key for the nonce of a token
+func (maker *PasetoLocalMaker) splitKey(nonce []byte) (encryptionKey []byte, counterNonce []byte, authKey []byte) {
+	h, _ := blake2b.New(56, maker.key)
+	h.Write([]byte("paseto-encryption-key"))
+	h.Write(nonce)
+	tmp := h.Sum(nil)
+
+	h, _ = blake2b.New(32, maker.key)
+	h.Write([]byte("paseto-auth-key-for-aead"))
+	h.Write(nonce)
+	return tmp[:32], tmp[32:], h.Sum(nil)
+}
+
+func pasetoMAC(key []byte, message []byte) []byte {
+	h, _ := blake2b.New(pasetoMACSize, key)
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// PasetoPublicMaker signs the payload with an Ed25519 key, v4.public.
+// The payload isn't encrypted, anyone can read it but only the holder of the private key can create tokens.
+type PasetoPublicMaker struct {
+	privateKey ed25519.PrivateKey
+	publicKey  ed25519.PublicKey
+}
+
+// NewPasetoPublicMaker takes a PEM encoded PKCS #8 Ed25519 private key
+func NewPasetoPublicMaker(signingKeyPEM []byte) (Maker, error) {
+	key, err := parsePrivateKey(signingKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid signing key: v4.public needs an Ed25519 key")
+	}
+	return &PasetoPublicMaker{
+		privateKey: privateKey,
+		publicKey:  privateKey.Public().(ed25519.PublicKey),
+	}, nil
+}
+
+func (maker *PasetoPublicMaker) CreateToken(userID uuid.UUID, sessionID uuid.UUID, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(userID, sessionID, duration)
+	if err != nil {
+		return "", payload, err
+	}
+
+	message, err := json.Marshal(payload)
+	if err != nil {
+		return "", payload, err
+	}
+
+	return maker.sign(message), payload, nil
+}
+
+// sign makes the token of the message, Ed25519 signatures don't need a nonce
+func (maker *PasetoPublicMaker) sign(message []byte) string {
+	signature := ed25519.Sign(maker.privateKey, pae([]byte(pasetoPublicHeader), message, nil, nil))
+
+	body := make([]byte, 0, len(message)+len(signature))
+	body = append(body, message...)
+	body = append(body, signature...)
+	return pasetoPublicHeader + base64.RawURLEncoding.EncodeToString(body)
+}
+
+func (maker *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	message, err := maker.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return parsePasetoPayload(message)
+}
+
+// verify returns the message of the token once its signature is checked
+func (maker *PasetoPublicMaker) verify(token string) ([]byte, error) {
+	body, err := decodePaseto(token, pasetoPublicHeader)
+	if err != nil || len(body) < ed25519.SignatureSize {
+		return nil, ErrInvalidToken
+	}
+	message := body[:len(body)-ed25519.SignatureSize]
+	signature := body[len(body)-ed25519.SignatureSize:]
+
+	if !ed25519.Verify(maker.publicKey, pae([]byte(pasetoPublicHeader), message, nil, nil), signature) {
+		return nil, ErrInvalidToken
+	}
+	return message, nil
+}
+
+// decodePaseto returns the body of a token with the header, tokens with a footer are refused since none is issued
+func decodePaseto(token string, header string) ([]byte, error) {
+	if !strings.HasPrefix(token, header) {
+		return nil, ErrInvalidToken
+	}
+	body := strings.TrimPrefix(token, header)
+	if strings.Contains(body, ".") {
+		return nil, ErrInvalidToken
+	}
+	return base64.RawURLEncoding.Strict().DecodeString(body)
+}
+
+func parsePasetoPayload(message []byte) (*Payload, error) {
+	payload := &Payload{}
+	if err := json.Unmarshal(message, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// pae is the pre-authentication encoding of the pieces, so that their boundaries are authenticated too
+func pae(pieces ...[]byte) []byte {
+	var le64 [8]byte
+	binary.LittleEndian.PutUint64(le64[:], uint64(len(pieces)))
+	out := append([]byte{}, le64[:]...)
+	for _, piece := range pieces {
+		// the most significant bit is cleared by the spec, lengths never reach it
+		binary.LittleEndian.PutUint64(le64[:], uint64(len(piece))&^(1<<63))
+		out = append(out, le64[:]...)
+		out = append(out, piece...)
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package token
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPAE(t *testing.T) {
+	// test vectors of the PAE section of the PASETO spec
+	require.Equal(t, []byte("\x00\x00\x00\x00\x00\x00\x00\x00"), pae())
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), pae([]byte{}))
+	require.Equal(t, []byte("\x01\x00\x00\x00\x00\x00\x00\x00\x04\x00\x00\x00\x00\x00\x00\x00test"), pae([]byte("test")))
+}
+
+// test vectors of the PASETO spec, https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+// Only the ones without a footer and an implicit assertion, none are issued.
+func TestPasetoLocalVectors(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	require.NoError(t, err)
+	maker := &PasetoLocalMaker{key: key}
+	nonce := make([]byte, pasetoNonceSize)
+
+	testCases := []struct {
+		name    string
+		payload string
+		token   string
+	}{
+		{
+			name:    "4-E-1",
+			payload: `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg",
+		},
+		{
+			name:    "4-E-2",
+			payload: `{"data":"this is a hidden message","exp":"2022-01-01T00:00:00+00:00"}`,
+			token:   "v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := maker.encrypt([]byte(tc.payload), nonce)
+			require.NoError(t, err)
+			require.Equal(t, tc.token, token)
+
+			message, err := maker.decrypt(tc.token)
+			require.NoError(t, err)
+			require.Equal(t, tc.payload, string(message))
+		})
+	}
+}
+
+func TestPasetoPublicVectors(t *testing.T) {
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	publicKey, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	require.NoError(t, err)
+	maker := &PasetoPublicMaker{privateKey: secretKey, publicKey: publicKey}
+
+	// 4-S-1
+	payload := `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+	expected := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+	require.Equal(t, expected, maker.sign([]byte(payload)))
+
+	message, err := maker.verify(expected)
+	require.NoError(t, err)
+	require.Equal(t, payload, string(message))
+
+	// a changed payload doesn't verify with the signature of the vector
+	tampered := "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMy0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA"
+	_, err = maker.verify(tampered)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+}
+
+func TestPasetoLocalMaker(t *testing.T) {
+	maker, err := NewPasetoLocalMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.local."))
+
+	// the same payload is encrypted with a new nonce every time
+	other, _, err := maker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+	require.NotEqual(t, token[:60], other[:60])
+
+	otherKey, err := NewPasetoLocalMaker(util.RandomString(32))
+	require.NoError(t, err)
+	_, err = otherKey.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	// footers aren't issued so they're refused
+	_, err = maker.VerifyToken(token + ".Zm9vdGVy")
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	_, err = NewPasetoLocalMaker(util.RandomString(31))
+	require.Error(t, err)
+	_, err = NewPasetoLocalMaker(util.RandomString(33))
+	require.Error(t, err)
+}
+
+func TestPasetoPublicMaker(t *testing.T) {
+	key, _ := randomEd25519KeyPEM(t)
+	maker, err := NewPasetoPublicMaker(key)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(uuid.New(), uuid.New(), time.Minute)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, "v4.public."))
+
+	// the v4.local header on a public token doesn't make it verify
+	_, err = maker.VerifyToken("v4.local." + strings.TrimPrefix(token, "v4.public."))
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	otherKey, _ := randomEd25519KeyPEM(t)
+	otherMaker, err := NewPasetoPublicMaker(otherKey)
+	require.NoError(t, err)
+	_, err = otherMaker.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+
+	rsaKey, _ := randomRSAKeyPEM(t, 2048)
+	_, err = NewPasetoPublicMaker(rsaKey)
+	require.Error(t, err)
+}
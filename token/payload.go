@@ -13,13 +13,15 @@ var (
 )
 
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	// SessionID is the login session the token was issued for
+	SessionID uuid.UUID `json:"session_id"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
-func NewPayload(userID uuid.UUID, duration time.Duration) (*Payload, error) {
+func NewPayload(userID uuid.UUID, sessionID uuid.UUID, duration time.Duration) (*Payload, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -33,6 +35,7 @@ func NewPayload(userID uuid.UUID, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        id,
 		UserID:    userID,
+		SessionID: sessionID,
 		IssuedAt:  now,
 		ExpiresAt: now.Add(duration),
 	}
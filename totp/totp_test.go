@@ -0,0 +1,79 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the sha1 key of the test vectors in RFC 6238 appendix B
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// RFC 6238 gives 8 digits, the last 6 are the 6 digit code
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, v := range vectors {
+		code, err := Code(rfcSecret, time.Unix(v.unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, v.code, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	// a code from the previous period is still accepted
+	previous, err := Code(secret, now.Add(-Period))
+	require.NoError(t, err)
+	step, ok = Validate(secret, previous, now)
+	require.True(t, ok)
+	require.Equal(t, Step(now)-1, step)
+
+	old, err := Code(secret, now.Add(-3*Period))
+	require.NoError(t, err)
+	if old != code && old != previous {
+		_, ok = Validate(secret, old, now)
+		require.False(t, ok)
+	}
+
+	_, ok = Validate(secret, "12345", now)
+	require.False(t, ok)
+	_, ok = Validate("not base32!", code, now)
+	require.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	u, err := url.Parse(URI("inkclip", "user@example.com", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/inkclip:user@example.com", u.Path)
+	require.Equal(t, secret, u.Query().Get("secret"))
+	require.Equal(t, "inkclip", u.Query().Get("issuer"))
+}
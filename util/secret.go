@@ -0,0 +1,65 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// NewSecretToken returns a random url safe token for links sent by mail and other one time secrets
+func NewSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashSecretToken is how secret tokens are stored, they have enough entropy for a plain sha256
+func HashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewRecoveryCode returns a random code to type by hand, like abcd-efgh-ijkl-mnop
+func NewRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16], nil
+}
+
+// NormalizeRecoveryCode drops what people add or change when typing a recovery code, it's applied before hashing
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// userCodeAlphabet has no vowels so that codes never spell words, and no characters that look alike
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+// NewUserCode returns a random code to type on another device, like BCDF-GHJK
+func NewUserCode() (string, error) {
+	b := make([]byte, 8)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeAlphabet))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate user code: %w", err)
+		}
+		b[i] = userCodeAlphabet[n.Int64()]
+	}
+	return string(b[0:4]) + "-" + string(b[4:8]), nil
+}
+
+// NormalizeUserCode drops what people add or change when typing a user code, it's applied before storing and looking up
+func NormalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretToken(t *testing.T) {
+	token1, err := NewSecretToken()
+	require.NoError(t, err)
+	require.Len(t, token1, 43)
+
+	token2, err := NewSecretToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token1, token2)
+
+	hash := HashSecretToken(token1)
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, HashSecretToken(token1))
+	require.NotEqual(t, hash, HashSecretToken(token2))
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.Regexp(t, "^[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}-[a-z2-7]{4}$", code)
+
+	other, err := NewRecoveryCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code, other)
+
+	normalized := NormalizeRecoveryCode(code)
+	require.Len(t, normalized, 16)
+	require.Equal(t, normalized, NormalizeRecoveryCode(" "+strings.ToUpper(code)+" "))
+}
+
+func TestUserCode(t *testing.T) {
+	code, err := NewUserCode()
+	require.NoError(t, err)
+	require.Regexp(t, "^[BCDFGHJKLMNPQRSTVWXZ]{4}-[BCDFGHJKLMNPQRSTVWXZ]{4}$", code)
+
+	normalized := NormalizeUserCode(code)
+	require.Len(t, normalized, 8)
+	require.Equal(t, normalized, NormalizeUserCode(" "+strings.ToLower(code)+" "))
+}
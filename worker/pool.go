@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/inkclip/backend/config"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/fetcher"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultConcurrency  = 2
+	defaultPollInterval = 2 * time.Second
+	// lockDuration must outlive a single fetch, otherwise another worker would pick the job up again
+	lockDuration = 5 * time.Minute
+)
+
+// Pool runs background workers that process queued web jobs
+type Pool struct {
+	store        db.Store
+	fetcher      fetcher.Fetcher
+	logger       *zap.Logger
+	concurrency  int
+	pollInterval time.Duration
+	wg           sync.WaitGroup
+}
+
+func NewPool(config config.Config, store db.Store, fetcher fetcher.Fetcher, logger *zap.Logger) *Pool {
+	pool := &Pool{
+		store:        store,
+		fetcher:      fetcher,
+		logger:       logger,
+		concurrency:  config.WorkerConcurrency,
+		pollInterval: config.WorkerPollInterval,
+	}
+	if pool.concurrency <= 0 {
+		pool.concurrency = defaultConcurrency
+	}
+	if pool.pollInterval <= 0 {
+		pool.pollInterval = defaultPollInterval
+	}
+	return pool
+}
+
+// Start launches the workers. They run until ctx is cancelled, a job they already claimed is finished first.
+func (pool *Pool) Start(ctx context.Context) {
+	for i := 0; i < pool.concurrency; i++ {
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			pool.work(ctx)
+		}()
+	}
+}
+
+// Wait blocks until every worker has returned
+func (pool *Pool) Wait() {
+	pool.wg.Wait()
+}
+
+func (pool *Pool) work(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// a job cancelled halfway would stay locked for lockDuration, so it doesn't get ctx
+		processed, err := pool.RunOnce(context.Background())
+		if err != nil {
+			pool.logger.Error("web job failed", zap.Error(err))
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pool.pollInterval):
+		}
+	}
+}
+
+// RunOnce claims and processes a single job.
+// It reports false when there was nothing to do.
+func (pool *Pool) RunOnce(ctx context.Context) (bool, error) {
+	job, err := pool.store.ClaimWebJob(ctx, time.Now().Add(lockDuration))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, pool.processWebJob(ctx, job)
+}
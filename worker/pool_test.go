@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/inkclip/backend/config"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/fetcher"
+	mockfetcher "github.com/inkclip/backend/fetcher/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPoolFinishesClaimedJob(t *testing.T) {
+	web := randomPendingWeb(t)
+	job := randomWebJob(web, 1, 5)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	fetch := mockfetcher.NewMockFetcher(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store.EXPECT().
+		ClaimWebJob(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(job, nil)
+	// shutting down while the job runs
+	store.EXPECT().
+		GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+		Times(1).
+		DoAndReturn(func(_ context.Context, _ interface{}) (db.Web, error) {
+			cancel()
+			return web, nil
+		})
+	fetch.EXPECT().
+		Fetch(gomock.Any(), gomock.Eq(web.Url)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, url string) (*fetcher.Page, error) {
+			require.NoError(t, ctx.Err())
+			return &fetcher.Page{URL: url, ContentType: "text/html", Body: []byte("<html><body><p>done</p></body></html>")}, nil
+		})
+	store.EXPECT().
+		TxCompleteWebJob(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.Web{}, nil)
+
+	pool := NewPool(config.Config{WorkerConcurrency: 1, WorkerPollInterval: time.Hour}, store, fetch, zap.NewNop())
+	pool.Start(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		pool.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pool didn't stop after the job")
+	}
+}
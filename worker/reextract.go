@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/article"
+	db "github.com/inkclip/backend/db/sqlc"
+	"go.uber.org/zap"
+)
+
+const reextractBatchSize = 100
+
+// Reextract runs the article extraction again over the html stored for every clipped web.
+// It's meant to be run by hand after the extraction logic changes, and returns the number of updated webs.
+func Reextract(ctx context.Context, store db.Store, logger *zap.Logger) (int, error) {
+	updated := 0
+	lastID := uuid.Nil
+	for {
+		webs, err := store.ListDoneWebsAfterId(ctx, db.ListDoneWebsAfterIdParams{
+			ID:    lastID,
+			Limit: reextractBatchSize,
+		})
+		if err != nil {
+			return updated, err
+		}
+
+		for _, web := range webs {
+			lastID = web.ID
+
+			extracted, err := article.Extract([]byte(web.Html), web.Url)
+			if err != nil {
+				logger.Info("cannot extract article", zap.String("web_id", web.ID.String()), zap.Error(err))
+				continue
+			}
+
+			_, err = store.UpdateWebArticle(ctx, db.UpdateWebArticleParams{
+				ID:              web.ID,
+				ArticleText:     extracted.Text,
+				ArticleHtml:     extracted.HTML,
+				ArticleMarkdown: extracted.Markdown,
+				Author:          extracted.Author,
+				SiteName:        extracted.SiteName,
+				Description:     extracted.Description,
+				PublishedAt:     nullTime(extracted.PublishedAt),
+				ReadingMinutes:  extracted.ReadingMinutes,
+			})
+			if err != nil {
+				return updated, err
+			}
+			updated++
+		}
+
+		if len(webs) < reextractBatchSize {
+			return updated, nil
+		}
+	}
+}
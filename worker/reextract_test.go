@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReextract(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	// a full batch forces a second page
+	webs := make([]db.Web, reextractBatchSize)
+	for i := range webs {
+		webs[i] = randomPendingWeb(t)
+		webs[i].Status = db.WebStatusDone
+		webs[i].Html = "<html><body><p>Clipped pages keep a readable copy of the article.</p></body></html>"
+	}
+	last := webs[len(webs)-1]
+
+	gomock.InOrder(
+		store.EXPECT().
+			ListDoneWebsAfterId(gomock.Any(), gomock.Eq(db.ListDoneWebsAfterIdParams{ID: uuid.Nil, Limit: reextractBatchSize})).
+			Times(1).
+			Return(webs, nil),
+		store.EXPECT().
+			ListDoneWebsAfterId(gomock.Any(), gomock.Eq(db.ListDoneWebsAfterIdParams{ID: last.ID, Limit: reextractBatchSize})).
+			Times(1).
+			Return([]db.Web{}, nil),
+	)
+
+	store.EXPECT().
+		UpdateWebArticle(gomock.Any(), gomock.Any()).
+		Times(len(webs)).
+		DoAndReturn(func(_ context.Context, arg db.UpdateWebArticleParams) (db.Web, error) {
+			require.Equal(t, "Clipped pages keep a readable copy of the article.", arg.ArticleText)
+			require.Equal(t, int32(1), arg.ReadingMinutes)
+			require.False(t, arg.PublishedAt.Valid)
+			return db.Web{ID: arg.ID}, nil
+		})
+
+	updated, err := Reextract(context.Background(), store, zap.NewNop())
+	require.NoError(t, err)
+	require.Equal(t, len(webs), updated)
+}
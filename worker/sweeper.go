@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/inkclip/backend/config"
+	db "github.com/inkclip/backend/db/sqlc"
+	"go.uber.org/zap"
+)
+
+const defaultSweepInterval = time.Hour
+
+// Sweeper periodically purges rows that are no use once expired
+type Sweeper struct {
+	store    db.Store
+	logger   *zap.Logger
+	interval time.Duration
+}
+
+func NewSweeper(config config.Config, store db.Store, logger *zap.Logger) *Sweeper {
+	sweeper := &Sweeper{
+		store:    store,
+		logger:   logger,
+		interval: config.SweepInterval,
+	}
+	if sweeper.interval <= 0 {
+		sweeper.interval = defaultSweepInterval
+	}
+	return sweeper
+}
+
+// Start sweeps right away and then every interval until ctx is cancelled
+func (sweeper *Sweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(sweeper.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := sweeper.RunOnce(ctx); err != nil {
+				sweeper.logger.Error("sweep failed", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce deletes expired registrations and returns how many were deleted
+func (sweeper *Sweeper) RunOnce(ctx context.Context) (int64, error) {
+	deleted, err := sweeper.store.DeleteExpiredTemporaryUsers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		sweeper.logger.Info("expired registrations swept", zap.Int64("count", deleted))
+	}
+	return deleted, nil
+}
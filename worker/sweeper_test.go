@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/inkclip/backend/config"
+	mockdb "github.com/inkclip/backend/db/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSweeperRunOnce(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, deleted int64, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpiredTemporaryUsers(gomock.Any()).
+					Times(1).
+					Return(int64(3), nil)
+			},
+			check: func(t *testing.T, deleted int64, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), deleted)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					DeleteExpiredTemporaryUsers(gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			check: func(t *testing.T, deleted int64, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			sweeper := NewSweeper(config.Config{}, store, zap.NewNop())
+			deleted, err := sweeper.RunOnce(context.Background())
+			tc.check(t, deleted, err)
+		})
+	}
+}
+
+func TestNewSweeperDefaultInterval(t *testing.T) {
+	sweeper := NewSweeper(config.Config{}, nil, zap.NewNop())
+	require.Equal(t, defaultSweepInterval, sweeper.interval)
+}
@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dyatlov/go-opengraph/opengraph"
+	"github.com/inkclip/backend/article"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/fetcher"
+	"go.uber.org/zap"
+)
+
+const (
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+)
+
+func (pool *Pool) processWebJob(ctx context.Context, job db.WebJob) error {
+	web, err := pool.store.GetWeb(ctx, job.WebID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// the web was deleted while queued, the job goes away with it
+			return nil
+		}
+		return err
+	}
+
+	content, err := pool.clip(ctx, web.Url)
+	if err != nil {
+		return pool.handleWebJobError(ctx, job, err)
+	}
+
+	_, err = pool.store.TxCompleteWebJob(ctx, db.TxCompleteWebJobParams{
+		JobID: job.ID,
+		UpdateWebContentParams: db.UpdateWebContentParams{
+			ID:              web.ID,
+			Title:           content.Title,
+			ThumbnailUrl:    content.ThumbnailURL,
+			Html:            content.HTML,
+			ArticleText:     content.Article.Text,
+			ArticleHtml:     content.Article.HTML,
+			ArticleMarkdown: content.Article.Markdown,
+			Author:          content.Article.Author,
+			SiteName:        content.Article.SiteName,
+			Description:     content.Article.Description,
+			PublishedAt:     nullTime(content.Article.PublishedAt),
+			ReadingMinutes:  content.Article.ReadingMinutes,
+		},
+	})
+	return err
+}
+
+// nullTime maps the zero time to NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+func (pool *Pool) handleWebJobError(ctx context.Context, job db.WebJob, jobErr error) error {
+	if isPermanent(jobErr) || job.Attempts >= job.MaxAttempts {
+		pool.logger.Info("web job gave up",
+			zap.Int64("job_id", job.ID),
+			zap.String("web_id", job.WebID.String()),
+			zap.Int32("attempts", job.Attempts),
+			zap.Error(jobErr),
+		)
+		_, err := pool.store.TxFailWebJob(ctx, db.TxFailWebJobParams{
+			JobID:       job.ID,
+			WebID:       job.WebID,
+			ErrorReason: jobErr.Error(),
+		})
+		return err
+	}
+
+	return pool.store.RetryWebJob(ctx, db.RetryWebJobParams{
+		ID:        job.ID,
+		RunAt:     time.Now().Add(backoff(job.Attempts)),
+		LastError: jobErr.Error(),
+	})
+}
+
+type clipContent struct {
+	Title        string
+	ThumbnailURL string
+	HTML         string
+	Article      article.Article
+}
+
+func (pool *Pool) clip(ctx context.Context, url string) (clipContent, error) {
+	page, err := pool.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return clipContent{}, err
+	}
+
+	og := opengraph.NewOpenGraph()
+	err = og.ProcessHTML(bytes.NewReader(page.Body))
+	if err != nil {
+		return clipContent{}, err
+	}
+
+	// a page we can't find an article in is still worth keeping as a clip
+	extracted, err := article.Extract(page.Body, page.URL)
+	if err != nil {
+		pool.logger.Info("cannot extract article", zap.String("url", page.URL), zap.Error(err))
+		extracted = &article.Article{}
+	}
+
+	content := clipContent{
+		Title:   og.Title,
+		HTML:    string(page.Body),
+		Article: *extracted,
+	}
+	if content.Title == "" {
+		content.Title = extracted.Title
+	}
+	if content.Title == "" {
+		content.Title = url
+	}
+	if len(og.Images) != 0 {
+		content.ThumbnailURL = og.Images[0].URL
+	}
+
+	return content, nil
+}
+
+// isPermanent reports whether retrying can't change the outcome
+func isPermanent(err error) bool {
+	return errors.Is(err, fetcher.ErrInvalidURL) ||
+		errors.Is(err, fetcher.ErrForbiddenAddress) ||
+		errors.Is(err, fetcher.ErrTooManyRedirects) ||
+		errors.Is(err, fetcher.ErrBodyTooLarge) ||
+		errors.Is(err, fetcher.ErrUnsupportedContentType)
+}
+
+// backoff doubles the delay after every attempt, capped at maxBackoff
+func backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,301 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/inkclip/backend/config"
+	mockdb "github.com/inkclip/backend/db/mock"
+	db "github.com/inkclip/backend/db/sqlc"
+	"github.com/inkclip/backend/fetcher"
+	mockfetcher "github.com/inkclip/backend/fetcher/mock"
+	"github.com/inkclip/backend/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRunOnce(t *testing.T) {
+	web := randomPendingWeb(t)
+	title := util.RandomName()
+	thumbnailURL := util.RandomThumbnailURL()
+	paragraph := "Clipped pages keep a readable copy of the article so notes can quote it later."
+	html := fmt.Sprintf(`<html><head>
+		<meta property="og:title" content="%s" />
+		<meta property="og:image" content="%s" />
+		<meta property="og:site_name" content="Example" />
+		<meta name="author" content="Jane Doe" />
+		<meta name="description" content="A short summary" />
+		<meta property="article:published_time" content="2022-12-01T10:00:00Z" />
+		</head><body><article><p>%s</p></article></body></html>`, title, thumbnailURL, paragraph)
+
+	testCases := []struct {
+		name       string
+		job        db.WebJob
+		buildStubs func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob)
+		check      func(t *testing.T, processed bool, err error)
+	}{
+		{
+			name: "OK",
+			job:  randomWebJob(web, 1, 5),
+			buildStubs: func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				fetch.EXPECT().
+					Fetch(gomock.Any(), gomock.Eq(web.Url)).
+					Times(1).
+					Return(&fetcher.Page{URL: web.Url, ContentType: "text/html", Body: []byte(html)}, nil)
+
+				arg := db.TxCompleteWebJobParams{
+					JobID: job.ID,
+					UpdateWebContentParams: db.UpdateWebContentParams{
+						ID:              web.ID,
+						Title:           title,
+						ThumbnailUrl:    thumbnailURL,
+						Html:            html,
+						ArticleText:     paragraph,
+						ArticleHtml:     "<p>" + paragraph + "</p>",
+						ArticleMarkdown: paragraph,
+						Author:          "Jane Doe",
+						SiteName:        "Example",
+						Description:     "A short summary",
+						PublishedAt: sql.NullTime{
+							Time:  time.Date(2022, 12, 1, 10, 0, 0, 0, time.UTC),
+							Valid: true,
+						},
+						ReadingMinutes: 1,
+					},
+				}
+				store.EXPECT().
+					TxCompleteWebJob(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Web{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.True(t, processed)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "NoTitleFallsBackToURL",
+			job:  randomWebJob(web, 1, 5),
+			buildStubs: func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				fetch.EXPECT().
+					Fetch(gomock.Any(), gomock.Eq(web.Url)).
+					Times(1).
+					Return(&fetcher.Page{URL: web.Url, ContentType: "text/html", Body: []byte("")}, nil)
+
+				arg := db.TxCompleteWebJobParams{
+					JobID: job.ID,
+					UpdateWebContentParams: db.UpdateWebContentParams{
+						ID:    web.ID,
+						Title: web.Url,
+					},
+				}
+				store.EXPECT().
+					TxCompleteWebJob(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Web{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.True(t, processed)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "TemporaryErrorIsRetried",
+			job:  randomWebJob(web, 2, 5),
+			buildStubs: func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				fetch.EXPECT().
+					Fetch(gomock.Any(), gomock.Eq(web.Url)).
+					Times(1).
+					Return(nil, fetcher.ErrUpstreamStatus)
+
+				store.EXPECT().
+					RetryWebJob(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.RetryWebJobParams) error {
+						require.Equal(t, job.ID, arg.ID)
+						require.Equal(t, fetcher.ErrUpstreamStatus.Error(), arg.LastError)
+						require.WithinDuration(t, time.Now().Add(2*baseBackoff), arg.RunAt, time.Second)
+						return nil
+					})
+
+				store.EXPECT().
+					TxFailWebJob(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.True(t, processed)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "LastAttemptFails",
+			job:  randomWebJob(web, 5, 5),
+			buildStubs: func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				fetch.EXPECT().
+					Fetch(gomock.Any(), gomock.Eq(web.Url)).
+					Times(1).
+					Return(nil, fetcher.ErrUpstream)
+
+				arg := db.TxFailWebJobParams{
+					JobID:       job.ID,
+					WebID:       web.ID,
+					ErrorReason: fetcher.ErrUpstream.Error(),
+				}
+				store.EXPECT().
+					TxFailWebJob(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Web{}, nil)
+
+				store.EXPECT().
+					RetryWebJob(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.True(t, processed)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "PermanentErrorFailsImmediately",
+			job:  randomWebJob(web, 1, 5),
+			buildStubs: func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(web, nil)
+
+				fetch.EXPECT().
+					Fetch(gomock.Any(), gomock.Eq(web.Url)).
+					Times(1).
+					Return(nil, fetcher.ErrForbiddenAddress)
+
+				arg := db.TxFailWebJobParams{
+					JobID:       job.ID,
+					WebID:       web.ID,
+					ErrorReason: fetcher.ErrForbiddenAddress.Error(),
+				}
+				store.EXPECT().
+					TxFailWebJob(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Web{}, nil)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.True(t, processed)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "WebDeleted",
+			job:  randomWebJob(web, 1, 5),
+			buildStubs: func(store *mockdb.MockStore, fetch *mockfetcher.MockFetcher, job db.WebJob) {
+				store.EXPECT().
+					GetWeb(gomock.Any(), gomock.Eq(web.ID)).
+					Times(1).
+					Return(db.Web{}, sql.ErrNoRows)
+
+				fetch.EXPECT().
+					Fetch(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			check: func(t *testing.T, processed bool, err error) {
+				require.True(t, processed)
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			fetch := mockfetcher.NewMockFetcher(ctrl)
+
+			store.EXPECT().
+				ClaimWebJob(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(tc.job, nil)
+			tc.buildStubs(store, fetch, tc.job)
+
+			pool := NewPool(config.Config{}, store, fetch, zap.NewNop())
+			processed, err := pool.RunOnce(context.Background())
+			tc.check(t, processed, err)
+		})
+	}
+}
+
+func TestRunOnceNoJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	fetch := mockfetcher.NewMockFetcher(ctrl)
+
+	store.EXPECT().
+		ClaimWebJob(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.WebJob{}, sql.ErrNoRows)
+
+	pool := NewPool(config.Config{}, store, fetch, zap.NewNop())
+	processed, err := pool.RunOnce(context.Background())
+	require.False(t, processed)
+	require.NoError(t, err)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, baseBackoff, backoff(1))
+	require.Equal(t, 2*baseBackoff, backoff(2))
+	require.Equal(t, 4*baseBackoff, backoff(3))
+	require.Equal(t, maxBackoff, backoff(100))
+}
+
+func randomPendingWeb(t *testing.T) db.Web {
+	id, err := uuid.NewRandom()
+	require.NoError(t, err)
+	url := util.RandomURL()
+	return db.Web{
+		ID:     id,
+		UserID: uuid.New(),
+		Url:    url,
+		Title:  url,
+		Status: db.WebStatusPending,
+	}
+}
+
+func randomWebJob(web db.Web, attempts int32, maxAttempts int32) db.WebJob {
+	return db.WebJob{
+		ID:          util.RandomInt(1, 1000),
+		WebID:       web.ID,
+		Attempts:    attempts,
+		MaxAttempts: maxAttempts,
+		RunAt:       time.Now(),
+	}
+}